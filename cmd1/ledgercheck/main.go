@@ -0,0 +1,52 @@
+// Command ledgercheck checks the wallet ledger: every journal entry must
+// balance and every wallet's balance must match its ledger account. It
+// exits with status 1 if anything is left wrong. With -repair, wallets
+// that drifted are set back to their ledger balance.
+package main
+
+import (
+	"ecommerce_clean_arch/pkg/config"
+	"ecommerce_clean_arch/pkg/db"
+	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/usecase"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+func main() {
+	repair := flag.Bool("repair", false, "reset drifted wallet balances to the ledger's")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("Cannot load config:", err)
+	}
+	database, err := db.ConnectDatabase(cfg)
+	if err != nil {
+		log.Fatal("Cannot connect to database:", err)
+	}
+
+	ledgerUseCase := usecase.NewLedgerUseCase(*repository.NewLedgerRepository(database))
+	report, err := ledgerUseCase.Check(*repair)
+	if err != nil {
+		log.Fatal("Ledger check failed:", err)
+	}
+
+	for _, entry := range report.UnbalancedEntries {
+		fmt.Printf("journal entry %d (%s %d) has %d postings and is out by %s\n",
+			entry.JournalEntryID, entry.SourceType, entry.SourceID, entry.Postings, entry.Imbalance)
+	}
+	for _, wallet := range report.WalletDrift {
+		fmt.Printf("wallet of user %d holds %s, ledger says %s\n", wallet.UserID, wallet.WalletBalance, wallet.LedgerBalance)
+	}
+	if report.Repaired > 0 {
+		fmt.Printf("reset %d wallets to their ledger balance\n", report.Repaired)
+	}
+
+	if len(report.UnbalancedEntries) > 0 || len(report.WalletDrift) > report.Repaired {
+		os.Exit(1)
+	}
+	fmt.Println("ledger is consistent")
+}
@@ -5,6 +5,7 @@
 package mockrepository
 
 import (
+	money "ecommerce_clean_arch/pkg/money"
 	models "ecommerce_clean_arch/pkg/utils/models"
 	reflect "reflect"
 
@@ -50,18 +51,18 @@ func (mr *MockCartRepositoryMockRecorder) AddToCart(cart interface{}) *gomock.Ca
 }
 
 // CheckProductInCart mocks base method.
-func (m *MockCartRepository) CheckProductInCart(userID, productID int) (bool, error) {
+func (m *MockCartRepository) CheckProductInCart(userID, productID, variantID int) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckProductInCart", userID, productID)
+	ret := m.ctrl.Call(m, "CheckProductInCart", userID, productID, variantID)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CheckProductInCart indicates an expected call of CheckProductInCart.
-func (mr *MockCartRepositoryMockRecorder) CheckProductInCart(userID, productID interface{}) *gomock.Call {
+func (mr *MockCartRepositoryMockRecorder) CheckProductInCart(userID, productID, variantID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckProductInCart", reflect.TypeOf((*MockCartRepository)(nil).CheckProductInCart), userID, productID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckProductInCart", reflect.TypeOf((*MockCartRepository)(nil).CheckProductInCart), userID, productID, variantID)
 }
 
 // DisplayCart mocks base method.
@@ -95,32 +96,32 @@ func (mr *MockCartRepositoryMockRecorder) GetAllItemsFromCart(userID interface{}
 }
 
 // GetCartItem mocks base method.
-func (m *MockCartRepository) GetCartItem(userID, productID int) (*models.Cart, error) {
+func (m *MockCartRepository) GetCartItem(userID, productID, variantID int) (*models.Cart, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCartItem", userID, productID)
+	ret := m.ctrl.Call(m, "GetCartItem", userID, productID, variantID)
 	ret0, _ := ret[0].(*models.Cart)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCartItem indicates an expected call of GetCartItem.
-func (mr *MockCartRepositoryMockRecorder) GetCartItem(userID, productID interface{}) *gomock.Call {
+func (mr *MockCartRepositoryMockRecorder) GetCartItem(userID, productID, variantID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCartItem", reflect.TypeOf((*MockCartRepository)(nil).GetCartItem), userID, productID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCartItem", reflect.TypeOf((*MockCartRepository)(nil).GetCartItem), userID, productID, variantID)
 }
 
 // RemoveProductFromCart mocks base method.
-func (m *MockCartRepository) RemoveProductFromCart(userID, productID int, price float64) error {
+func (m *MockCartRepository) RemoveProductFromCart(userID, productID, variantID int, price money.Money) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveProductFromCart", userID, productID, price)
+	ret := m.ctrl.Call(m, "RemoveProductFromCart", userID, productID, variantID, price)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RemoveProductFromCart indicates an expected call of RemoveProductFromCart.
-func (mr *MockCartRepositoryMockRecorder) RemoveProductFromCart(userID, productID, price interface{}) *gomock.Call {
+func (mr *MockCartRepositoryMockRecorder) RemoveProductFromCart(userID, productID, variantID, price interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveProductFromCart", reflect.TypeOf((*MockCartRepository)(nil).RemoveProductFromCart), userID, productID, price)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveProductFromCart", reflect.TypeOf((*MockCartRepository)(nil).RemoveProductFromCart), userID, productID, variantID, price)
 }
 
 // UpdateCart mocks base method.
@@ -166,6 +166,34 @@ func (mr *MockOrderRepositoryMockRecorder) CreateOrderItems(tx, orderItems inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrderItems", reflect.TypeOf((*MockOrderRepository)(nil).CreateOrderItems), tx, orderItems)
 }
 
+// DecrementProductStock mocks base method.
+func (m *MockOrderRepository) DecrementProductStock(tx *gorm.DB, productID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecrementProductStock", tx, productID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecrementProductStock indicates an expected call of DecrementProductStock.
+func (mr *MockOrderRepositoryMockRecorder) DecrementProductStock(tx, productID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrementProductStock", reflect.TypeOf((*MockOrderRepository)(nil).DecrementProductStock), tx, productID, quantity)
+}
+
+// DecrementVariantStock mocks base method.
+func (m *MockOrderRepository) DecrementVariantStock(tx *gorm.DB, variantID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecrementVariantStock", tx, variantID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecrementVariantStock indicates an expected call of DecrementVariantStock.
+func (mr *MockOrderRepositoryMockRecorder) DecrementVariantStock(tx, variantID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrementVariantStock", reflect.TypeOf((*MockOrderRepository)(nil).DecrementVariantStock), tx, variantID, quantity)
+}
+
 // DoesCartExist mocks base method.
 func (m *MockOrderRepository) DoesCartExist(orderBody models.OrderFromCart, userID int) (bool, error) {
 	m.ctrl.T.Helper()
@@ -242,13 +270,14 @@ func (mr *MockOrderRepositoryMockRecorder) GetOrderDetails(userID interface{}) *
 }
 
 // GetOrderItemDetails mocks base method.
-func (m *MockOrderRepository) GetOrderItemDetails(tx *gorm.DB, orderItemID int) (int, int, error) {
+func (m *MockOrderRepository) GetOrderItemDetails(tx *gorm.DB, orderItemID int) (int, int, int, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetOrderItemDetails", tx, orderItemID)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(int)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret2, _ := ret[2].(int)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
 }
 
 // GetOrderItemDetails indicates an expected call of GetOrderItemDetails.
@@ -362,6 +391,34 @@ func (mr *MockOrderRepositoryMockRecorder) GetWalletAmount(userID interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletAmount", reflect.TypeOf((*MockOrderRepository)(nil).GetWalletAmount), userID)
 }
 
+// IncrementProductStock mocks base method.
+func (m *MockOrderRepository) IncrementProductStock(tx *gorm.DB, productID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementProductStock", tx, productID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementProductStock indicates an expected call of IncrementProductStock.
+func (mr *MockOrderRepositoryMockRecorder) IncrementProductStock(tx, productID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementProductStock", reflect.TypeOf((*MockOrderRepository)(nil).IncrementProductStock), tx, productID, quantity)
+}
+
+// IncrementVariantStock mocks base method.
+func (m *MockOrderRepository) IncrementVariantStock(tx *gorm.DB, variantID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementVariantStock", tx, variantID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementVariantStock indicates an expected call of IncrementVariantStock.
+func (mr *MockOrderRepositoryMockRecorder) IncrementVariantStock(tx, variantID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementVariantStock", reflect.TypeOf((*MockOrderRepository)(nil).IncrementVariantStock), tx, variantID, quantity)
+}
+
 // RecordCouponUsage mocks base method.
 func (m *MockOrderRepository) RecordCouponUsage(tx *gorm.DB, userID int, couponCode string) error {
 	m.ctrl.T.Helper()
@@ -404,20 +461,6 @@ func (mr *MockOrderRepositoryMockRecorder) RollbackTransaction(tx interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackTransaction", reflect.TypeOf((*MockOrderRepository)(nil).RollbackTransaction), tx)
 }
 
-// UpdateProductStock mocks base method.
-func (m *MockOrderRepository) UpdateProductStock(tx *gorm.DB, productID, newStock int) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateProductStock", tx, productID, newStock)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// UpdateProductStock indicates an expected call of UpdateProductStock.
-func (mr *MockOrderRepositoryMockRecorder) UpdateProductStock(tx, productID, newStock interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductStock", reflect.TypeOf((*MockOrderRepository)(nil).UpdateProductStock), tx, productID, newStock)
-}
-
 // UpdateQuantityOfProduct mocks base method.
 func (m *MockOrderRepository) UpdateQuantityOfProduct(orderProducts []models.OrderProducts) error {
 	m.ctrl.T.Helper()
@@ -50,6 +50,21 @@ func (mr *MockProductRepositoryMockRecorder) AddProduct(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockProductRepository)(nil).AddProduct), arg0)
 }
 
+// AddVariant mocks base method.
+func (m *MockProductRepository) AddVariant(variant models.AddVariant) (models.VariantResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddVariant", variant)
+	ret0, _ := ret[0].(models.VariantResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddVariant indicates an expected call of AddVariant.
+func (mr *MockProductRepositoryMockRecorder) AddVariant(variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddVariant", reflect.TypeOf((*MockProductRepository)(nil).AddVariant), variant)
+}
+
 // DeleteProduct mocks base method.
 func (m *MockProductRepository) DeleteProduct(productID int) error {
 	m.ctrl.T.Helper()
@@ -64,6 +79,20 @@ func (mr *MockProductRepositoryMockRecorder) DeleteProduct(productID interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockProductRepository)(nil).DeleteProduct), productID)
 }
 
+// DeleteVariant mocks base method.
+func (m *MockProductRepository) DeleteVariant(variantID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVariant", variantID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVariant indicates an expected call of DeleteVariant.
+func (mr *MockProductRepositoryMockRecorder) DeleteVariant(variantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVariant", reflect.TypeOf((*MockProductRepository)(nil).DeleteVariant), variantID)
+}
+
 // GetAllProducts mocks base method.
 func (m *MockProductRepository) GetAllProducts(showOutOfStock bool) ([]models.ProductResponse, error) {
 	m.ctrl.T.Helper()
@@ -109,6 +138,67 @@ func (mr *MockProductRepositoryMockRecorder) GetProductsByCategory(categoryID, s
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductsByCategory", reflect.TypeOf((*MockProductRepository)(nil).GetProductsByCategory), categoryID, sortBy)
 }
 
+// GetSearchFacets mocks base method.
+func (m *MockProductRepository) GetSearchFacets(query models.ProductSearchQuery) (models.ProductFacets, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSearchFacets", query)
+	ret0, _ := ret[0].(models.ProductFacets)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSearchFacets indicates an expected call of GetSearchFacets.
+func (mr *MockProductRepositoryMockRecorder) GetSearchFacets(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSearchFacets", reflect.TypeOf((*MockProductRepository)(nil).GetSearchFacets), query)
+}
+
+// GetVariantByID mocks base method.
+func (m *MockProductRepository) GetVariantByID(variantID int) (models.VariantResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariantByID", variantID)
+	ret0, _ := ret[0].(models.VariantResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariantByID indicates an expected call of GetVariantByID.
+func (mr *MockProductRepositoryMockRecorder) GetVariantByID(variantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariantByID", reflect.TypeOf((*MockProductRepository)(nil).GetVariantByID), variantID)
+}
+
+// GetVariantsByProductID mocks base method.
+func (m *MockProductRepository) GetVariantsByProductID(productID int) ([]models.VariantResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariantsByProductID", productID)
+	ret0, _ := ret[0].([]models.VariantResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariantsByProductID indicates an expected call of GetVariantsByProductID.
+func (mr *MockProductRepositoryMockRecorder) GetVariantsByProductID(productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariantsByProductID", reflect.TypeOf((*MockProductRepository)(nil).GetVariantsByProductID), productID)
+}
+
+// SearchProducts mocks base method.
+func (m *MockProductRepository) SearchProducts(query models.ProductSearchQuery) ([]models.ProductSearchResult, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", query)
+	ret0, _ := ret[0].([]models.ProductSearchResult)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockProductRepositoryMockRecorder) SearchProducts(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockProductRepository)(nil).SearchProducts), query)
+}
+
 // UpdateProduct mocks base method.
 func (m *MockProductRepository) UpdateProduct(products models.ProductResponse, productID int) (models.ProductResponse, error) {
 	m.ctrl.T.Helper()
@@ -137,3 +227,18 @@ func (mr *MockProductRepositoryMockRecorder) UpdateStock(productID, qty interfac
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStock", reflect.TypeOf((*MockProductRepository)(nil).UpdateStock), productID, qty)
 }
+
+// UpdateVariant mocks base method.
+func (m *MockProductRepository) UpdateVariant(variant models.AddVariant, variantID int) (models.VariantResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVariant", variant, variantID)
+	ret0, _ := ret[0].(models.VariantResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateVariant indicates an expected call of UpdateVariant.
+func (mr *MockProductRepositoryMockRecorder) UpdateVariant(variant, variantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVariant", reflect.TypeOf((*MockProductRepository)(nil).UpdateVariant), variant, variantID)
+}
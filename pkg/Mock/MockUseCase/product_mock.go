@@ -5,6 +5,7 @@
 package MockUseCase
 
 import (
+	domain "ecommerce_clean_arch/pkg/domain"
 	models "ecommerce_clean_arch/pkg/utils/models"
 	reflect "reflect"
 
@@ -35,18 +36,33 @@ func (m *MockProductUseCase) EXPECT() *MockProductUseCaseMockRecorder {
 }
 
 // AddProduct mocks base method.
-func (m *MockProductUseCase) AddProduct(product models.AddProduct) (models.ProductResponse, error) {
+func (m *MockProductUseCase) AddProduct(arg0 models.AddProduct) (models.ProductResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddProduct", product)
+	ret := m.ctrl.Call(m, "AddProduct", arg0)
 	ret0, _ := ret[0].(models.ProductResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddProduct indicates an expected call of AddProduct.
-func (mr *MockProductUseCaseMockRecorder) AddProduct(product interface{}) *gomock.Call {
+func (mr *MockProductUseCaseMockRecorder) AddProduct(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockProductUseCase)(nil).AddProduct), product)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockProductUseCase)(nil).AddProduct), arg0)
+}
+
+// AddVariant mocks base method.
+func (m *MockProductUseCase) AddVariant(variant models.AddVariant) (models.VariantResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddVariant", variant)
+	ret0, _ := ret[0].(models.VariantResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddVariant indicates an expected call of AddVariant.
+func (mr *MockProductUseCaseMockRecorder) AddVariant(variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddVariant", reflect.TypeOf((*MockProductUseCase)(nil).AddVariant), variant)
 }
 
 // DeleteProduct mocks base method.
@@ -63,11 +79,40 @@ func (mr *MockProductUseCaseMockRecorder) DeleteProduct(productID interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockProductUseCase)(nil).DeleteProduct), productID)
 }
 
+// DeleteVariant mocks base method.
+func (m *MockProductUseCase) DeleteVariant(variantID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVariant", variantID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVariant indicates an expected call of DeleteVariant.
+func (mr *MockProductUseCaseMockRecorder) DeleteVariant(variantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVariant", reflect.TypeOf((*MockProductUseCase)(nil).DeleteVariant), variantID)
+}
+
+// GetProductVariants mocks base method.
+func (m *MockProductUseCase) GetProductVariants(productID int) ([]models.VariantResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductVariants", productID)
+	ret0, _ := ret[0].([]models.VariantResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductVariants indicates an expected call of GetProductVariants.
+func (mr *MockProductUseCaseMockRecorder) GetProductVariants(productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductVariants", reflect.TypeOf((*MockProductUseCase)(nil).GetProductVariants), productID)
+}
+
 // SearchProduct mocks base method.
-func (m *MockProductUseCase) SearchProduct(categoryID, sortBy string) ([]models.ProductResponse, error) {
+func (m *MockProductUseCase) SearchProduct(categoryID, sortBy string) ([]domain.Products, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SearchProduct", categoryID, sortBy)
-	ret0, _ := ret[0].([]models.ProductResponse)
+	ret0, _ := ret[0].([]domain.Products)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -78,6 +123,21 @@ func (mr *MockProductUseCaseMockRecorder) SearchProduct(categoryID, sortBy inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProduct", reflect.TypeOf((*MockProductUseCase)(nil).SearchProduct), categoryID, sortBy)
 }
 
+// SearchProducts mocks base method.
+func (m *MockProductUseCase) SearchProducts(query models.ProductSearchQuery) (models.ProductSearchResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", query)
+	ret0, _ := ret[0].(models.ProductSearchResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockProductUseCaseMockRecorder) SearchProducts(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockProductUseCase)(nil).SearchProducts), query)
+}
+
 // UpdateProduct mocks base method.
 func (m *MockProductUseCase) UpdateProduct(products models.ProductResponse, productID int) (models.ProductResponse, error) {
 	m.ctrl.T.Helper()
@@ -92,3 +152,18 @@ func (mr *MockProductUseCaseMockRecorder) UpdateProduct(products, productID inte
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockProductUseCase)(nil).UpdateProduct), products, productID)
 }
+
+// UpdateVariant mocks base method.
+func (m *MockProductUseCase) UpdateVariant(variant models.AddVariant, variantID int) (models.VariantResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVariant", variant, variantID)
+	ret0, _ := ret[0].(models.VariantResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateVariant indicates an expected call of UpdateVariant.
+func (mr *MockProductUseCaseMockRecorder) UpdateVariant(variant, variantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVariant", reflect.TypeOf((*MockProductUseCase)(nil).UpdateVariant), variant, variantID)
+}
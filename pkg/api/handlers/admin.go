@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"ecommerce_clean_arch/pkg/domain"
 	"ecommerce_clean_arch/pkg/usecase"
 	"ecommerce_clean_arch/pkg/utils/models"
 	"ecommerce_clean_arch/pkg/utils/response"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -221,6 +223,431 @@ func (ad *AdminHandler) AdminCancelOrders(c *gin.Context) {
 	c.JSON(http.StatusOK, successRes)
 }
 
+// ListRefunds godoc
+// @Summary List refunds
+// @Description Lists refunds, optionally filtered by status (initiated, processed, failed)
+// @Tags Admin
+// @Produce json
+// @Param status query string false "Refund status"
+// @Success 200 {object} response.Response{}
+// @Failure 500 {object} response.Response{}
+// @Router /admin/refunds [get]
+func (ad *AdminHandler) ListRefunds(c *gin.Context) {
+	refunds, err := ad.adminUseCase.GetRefunds(c.Query("status"))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusInternalServerError, "Could not fetch refunds", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Refunds", refunds, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// RetryRefund godoc
+// @Summary Retry a failed refund
+// @Description Sends a failed refund to the payment gateway again
+// @Tags Admin
+// @Produce json
+// @Param refund_id query string true "Refund ID"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/refunds/retry [post]
+func (ad *AdminHandler) RetryRefund(c *gin.Context) {
+	refundID := c.Query("refund_id")
+	if refundID == "" {
+		errRes := response.ClientResponse(http.StatusBadRequest, "Refund ID is required", nil, nil)
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	refund, err := ad.adminUseCase.RetryRefund(refundID)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Failed to retry refund", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Refund sent to the payment gateway", refund, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ListWalletAdjustments godoc
+// @Summary List wallet adjustments
+// @Description Lists admin wallet adjustments, optionally filtered by status (pending, applied, rejected) and user
+// @Tags Admin
+// @Produce json
+// @Param status query string false "Adjustment status"
+// @Param user_id query string false "User ID"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/wallet-adjustments [get]
+func (ad *AdminHandler) ListWalletAdjustments(c *gin.Context) {
+	adjustments, err := ad.adminUseCase.GetWalletAdjustments(c.Query("status"), c.Query("user_id"))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not fetch wallet adjustments", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Wallet adjustments", adjustments, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// AdjustWallet godoc
+// @Summary Adjust a wallet
+// @Description Credits or debits a customer's wallet with a reason code and note, optionally linked to one of their orders; an adjustment above the approval threshold waits for a second admin
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param adjustment body models.WalletAdjustmentRequest true "Adjustment"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Failure 401 {object} response.Response{}
+// @Router /admin/wallet-adjustments [post]
+func (ad *AdminHandler) AdjustWallet(c *gin.Context) {
+	var req models.WalletAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Invalid input format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+	ID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	adjustment, err := ad.adminUseCase.AdjustWallet(req, ID.(int))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Failed to adjust wallet", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	message := "Wallet adjusted"
+	if adjustment.Status == models.AdjustmentPending {
+		message = "Adjustment is awaiting approval by another admin"
+	}
+	successRes := response.ClientResponse(http.StatusOK, message, adjustment, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ApproveWalletAdjustment godoc
+// @Summary Approve a wallet adjustment
+// @Description Applies a pending wallet adjustment made by another admin
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param decision body models.WalletAdjustmentDecision true "Adjustment and an optional note"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Failure 401 {object} response.Response{}
+// @Router /admin/wallet-adjustments/approve [put]
+func (ad *AdminHandler) ApproveWalletAdjustment(c *gin.Context) {
+	ad.reviewWalletAdjustment(c, ad.adminUseCase.ApproveWalletAdjustment, "Wallet adjustment approved")
+}
+
+// RejectWalletAdjustment godoc
+// @Summary Reject a wallet adjustment
+// @Description Turns down a pending wallet adjustment without moving any money
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param decision body models.WalletAdjustmentDecision true "Adjustment and the reason"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Failure 401 {object} response.Response{}
+// @Router /admin/wallet-adjustments/reject [put]
+func (ad *AdminHandler) RejectWalletAdjustment(c *gin.Context) {
+	ad.reviewWalletAdjustment(c, ad.adminUseCase.RejectWalletAdjustment, "Wallet adjustment rejected")
+}
+
+func (ad *AdminHandler) reviewWalletAdjustment(c *gin.Context,
+	review func(models.WalletAdjustmentDecision, int) (domain.WalletAdjustment, error), message string) {
+	var decision models.WalletAdjustmentDecision
+	if err := c.ShouldBindJSON(&decision); err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Invalid input format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+	ID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	adjustment, err := review(decision, ID.(int))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Failed to review wallet adjustment", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, message, adjustment, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ListExchanges godoc
+// @Summary List exchanges
+// @Description Lists size exchange requests, optionally filtered by status (requested, approved, rejected, completed)
+// @Tags Admin
+// @Produce json
+// @Param status query string false "Exchange status"
+// @Success 200 {object} response.Response{}
+// @Failure 500 {object} response.Response{}
+// @Router /admin/orders/exchanges [get]
+func (ad *AdminHandler) ListExchanges(c *gin.Context) {
+	exchanges, err := ad.adminUseCase.GetExchanges(c.Query("status"))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusInternalServerError, "Could not fetch exchanges", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Exchanges", exchanges, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ApproveExchange godoc
+// @Summary Approve an exchange
+// @Description Ships the new size on a zero-value replacement order that collects the old pair, and refunds a price difference owed to the customer
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param decision body models.ExchangeDecision true "Exchange and an optional note"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Failure 401 {object} response.Response{}
+// @Router /admin/orders/exchanges/approve [put]
+func (ad *AdminHandler) ApproveExchange(c *gin.Context) {
+	var decision models.ExchangeDecision
+	if err := c.ShouldBindJSON(&decision); err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Invalid input format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+	ID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	exchange, err := ad.adminUseCase.ApproveExchange(decision, ID.(int))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Failed to approve exchange", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Exchange approved", exchange, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// RejectExchange godoc
+// @Summary Reject an exchange
+// @Description Releases the held size and refunds any price difference already paid
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param decision body models.ExchangeDecision true "Exchange and an optional note"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Failure 401 {object} response.Response{}
+// @Router /admin/orders/exchanges/reject [put]
+func (ad *AdminHandler) RejectExchange(c *gin.Context) {
+	var decision models.ExchangeDecision
+	if err := c.ShouldBindJSON(&decision); err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Invalid input format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+	ID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	exchange, err := ad.adminUseCase.RejectExchange(decision, ID.(int))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Failed to reject exchange", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Exchange rejected", exchange, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// CompleteExchange godoc
+// @Summary Complete an exchange
+// @Description Records that the old pair of an approved exchange was collected and puts it back on stock
+// @Tags Admin
+// @Produce json
+// @Param exchange_id query string true "Exchange ID"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/orders/exchanges/complete [put]
+func (ad *AdminHandler) CompleteExchange(c *gin.Context) {
+	exchangeID := c.Query("exchange_id")
+	if exchangeID == "" {
+		errRes := response.ClientResponse(http.StatusBadRequest, "Exchange ID is required", nil, nil)
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	exchange, err := ad.adminUseCase.CompleteExchange(exchangeID)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Failed to complete exchange", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Exchange completed", exchange, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ListReturnRequests godoc
+// @Summary List return requests
+// @Description Lists return requests, optionally filtered by status (requested, approved, picked_up, inspected, refunded, rejected)
+// @Tags Admin
+// @Produce json
+// @Param status query string false "Return status"
+// @Success 200 {object} response.Response{}
+// @Failure 500 {object} response.Response{}
+// @Router /admin/orders/returns [get]
+func (ad *AdminHandler) ListReturnRequests(c *gin.Context) {
+	requests, err := ad.adminUseCase.GetReturnRequests(c.Query("status"))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusInternalServerError, "Could not fetch return requests", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Return requests", requests, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ApproveReturn godoc
+// @Summary Approve a return
+// @Description Accepts a return request so the parcel can be picked up
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param decision body models.ReturnDecision true "Return and an optional note"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/orders/returns/approve [put]
+func (ad *AdminHandler) ApproveReturn(c *gin.Context) {
+	ad.updateReturnStatus(c, models.ReturnApproved, "Return approved")
+}
+
+// MarkReturnPickedUp godoc
+// @Summary Mark a return picked up
+// @Description Records that the courier has collected the returned parcel
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param decision body models.ReturnDecision true "Return and an optional note"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/orders/returns/pickup [put]
+func (ad *AdminHandler) MarkReturnPickedUp(c *gin.Context) {
+	ad.updateReturnStatus(c, models.ReturnPickedUp, "Return picked up")
+}
+
+// RefundReturn godoc
+// @Summary Refund a return
+// @Description Marks an inspected return returned, restocks it and refunds the customer
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param decision body models.ReturnDecision true "Return and an optional note"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/orders/returns/refund [put]
+func (ad *AdminHandler) RefundReturn(c *gin.Context) {
+	ad.updateReturnStatus(c, models.ReturnRefunded, "Return refunded")
+}
+
+// RejectReturn godoc
+// @Summary Reject a return
+// @Description Turns down a return request that has not passed inspection
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param decision body models.ReturnDecision true "Return and the reason"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/orders/returns/reject [put]
+func (ad *AdminHandler) RejectReturn(c *gin.Context) {
+	ad.updateReturnStatus(c, models.ReturnRejected, "Return rejected")
+}
+
+func (ad *AdminHandler) updateReturnStatus(c *gin.Context, status, message string) {
+	var decision models.ReturnDecision
+	if err := c.ShouldBindJSON(&decision); err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Invalid input format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+	ID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	request, err := ad.adminUseCase.UpdateReturnStatus(decision, status, ID.(int))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Failed to update return", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, message, request, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// InspectReturn godoc
+// @Summary Inspect a return
+// @Description Records whether a picked up return passed inspection; a failed inspection rejects it
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param inspection body models.ReturnInspection true "Return, outcome and an optional note"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/orders/returns/inspect [put]
+func (ad *AdminHandler) InspectReturn(c *gin.Context) {
+	var inspection models.ReturnInspection
+	if err := c.ShouldBindJSON(&inspection); err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Invalid input format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+	ID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	request, err := ad.adminUseCase.InspectReturn(inspection, ID.(int))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Failed to record inspection", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Inspection recorded", request, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
 // ChangeOrderStatus godoc
 // @Summary Change order status
 // @Description Changes the status of an order
@@ -229,8 +656,10 @@ func (ad *AdminHandler) AdminCancelOrders(c *gin.Context) {
 // @Produce json
 // @Param order_id query string true "Order ID to update"
 // @Param order_status body string true "New order status"
+// @Param reason body string false "Reason shown on the order timeline"
 // @Success 200 {object} response.Response{}
 // @Failure 400 {object} response.Response{}
+// @Failure 401 {object} response.Response{}
 // @Failure 500 {object} response.Response{}
 // @Router /admin/update-order-status [put]
 func (ad *AdminHandler) ChangeOrderStatus(c *gin.Context) {
@@ -242,7 +671,8 @@ func (ad *AdminHandler) ChangeOrderStatus(c *gin.Context) {
 	}
 
 	var input struct {
-		OrderStatus string `json:"order_status"binding:"required"`
+		OrderStatus string `json:"order_status" binding:"required"`
+		Reason      string `json:"reason"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
 		errorRes := response.ClientResponse(http.StatusBadRequest, "Invalid input format", nil, err.Error())
@@ -250,7 +680,19 @@ func (ad *AdminHandler) ChangeOrderStatus(c *gin.Context) {
 		return
 	}
 
-	order, err := ad.adminUseCase.ChangeOrderStatus(orderID, input.OrderStatus)
+	ID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	order, err := ad.adminUseCase.ChangeOrderStatus(orderID, input.OrderStatus, ID.(int), input.Reason)
+	if errors.Is(err, usecase.ErrInvalidOrderTransition) {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Failed to update order status", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
 	if err != nil {
 		errorRes := response.ClientResponse(http.StatusInternalServerError, "Failed to update order status", nil, err.Error())
 		c.JSON(http.StatusInternalServerError, errorRes)
@@ -270,6 +712,338 @@ func (ad *AdminHandler) ChangeOrderStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// OrderTimeline godoc
+// @Summary Order timeline
+// @Description Lists every status change of an order with who made it and why
+// @Tags Admin
+// @Produce json
+// @Param order_id query string true "Order ID"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Failure 500 {object} response.Response{}
+// @Router /admin/orders/timeline [get]
+func (ad *AdminHandler) OrderTimeline(c *gin.Context) {
+	orderID := c.Query("order_id")
+	if orderID == "" {
+		errRes := response.ClientResponse(http.StatusBadRequest, "Order ID is required", nil, nil)
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	timeline, err := ad.adminUseCase.GetOrderTimeline(orderID)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusInternalServerError, "Could not fetch the order timeline", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Order timeline", timeline, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// PackingSlip godoc
+// @Summary Packing slip
+// @Description Generates a PDF packing slip for an order, listing its items and delivery address without prices
+// @Tags Admin
+// @Produce application/pdf
+// @Param order_id query string true "Order ID"
+// @Success 200 {file} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/orders/packing-slip [get]
+func (ad *AdminHandler) PackingSlip(c *gin.Context) {
+	orderID := c.Query("order_id")
+	if orderID == "" {
+		errRes := response.ClientResponse(http.StatusBadRequest, "Order ID is required", nil, nil)
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	pdfData, err := ad.adminUseCase.GeneratePackingSlip(orderID)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not generate the packing slip", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=packing_slip_"+orderID+".pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfData)
+}
+
+// PickList godoc
+// @Summary Pick list
+// @Description Generates a PDF pick list of every item in pending orders, grouped by product and size
+// @Tags Admin
+// @Produce application/pdf
+// @Success 200 {file} response.Response{}
+// @Failure 500 {object} response.Response{}
+// @Router /admin/orders/pick-list [get]
+func (ad *AdminHandler) PickList(c *gin.Context) {
+	pdfData, err := ad.adminUseCase.GeneratePickList()
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusInternalServerError, "Could not generate the pick list", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errorRes)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=pick_list.pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfData)
+}
+
+// ShippingLabel godoc
+// @Summary Shipping label
+// @Description Generates a 4x6 inch PDF shipping label with the delivery address and a Code 128 barcode of the order id
+// @Tags Admin
+// @Produce application/pdf
+// @Param order_id query string true "Order ID"
+// @Success 200 {file} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/orders/shipping-label [get]
+func (ad *AdminHandler) ShippingLabel(c *gin.Context) {
+	orderID := c.Query("order_id")
+	if orderID == "" {
+		errRes := response.ClientResponse(http.StatusBadRequest, "Order ID is required", nil, nil)
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	pdfData, err := ad.adminUseCase.GenerateShippingLabel(orderID)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not generate the shipping label", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=label_"+orderID+".pdf")
+	c.Data(http.StatusOK, "application/pdf", pdfData)
+}
+
+// BookShipment godoc
+// @Summary Book a shipment
+// @Description Books an order's parcel with the configured carrier and records its AWB number
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param booking body models.ShipmentBooking true "Order to ship"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/orders/shipments [post]
+func (ad *AdminHandler) BookShipment(c *gin.Context) {
+	var booking models.ShipmentBooking
+	if err := c.ShouldBindJSON(&booking); err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Invalid input format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	shipment, err := ad.adminUseCase.BookShipment(booking.OrderID)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not book the shipment", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Shipment booked", shipment, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ShipmentTracking godoc
+// @Summary Track a shipment
+// @Description Returns an order's shipment and the tracking events received from the carrier
+// @Tags Admin
+// @Produce json
+// @Param order_id query string true "Order ID"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/orders/shipment [get]
+func (ad *AdminHandler) ShipmentTracking(c *gin.Context) {
+	orderID := c.Query("order_id")
+	if orderID == "" {
+		errRes := response.ClientResponse(http.StatusBadRequest, "Order ID is required", nil, nil)
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	tracking, err := ad.adminUseCase.GetShipmentTracking(orderID)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not fetch the shipment", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Shipment tracking", tracking, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// UploadPincodes godoc
+// @Summary Upload pincode serviceability
+// @Description Loads a CSV of pincode, deliverable, cod_allowed and eta_days rows, adding new pincodes and replacing listed ones. A bad row rejects the whole file.
+// @Tags Admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Serviceability CSV"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/pincodes/upload [post]
+func (ad *AdminHandler) UploadPincodes(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "A CSV file is required", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not read the file", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+	defer file.Close()
+
+	rows, err := ad.adminUseCase.UploadPincodes(file)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not load the pincodes", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Pincodes uploaded", models.PincodeUpload{Rows: rows}, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ListPincodes godoc
+// @Summary List pincode serviceability
+// @Description Lists the serviceability table, optionally only pincodes starting with a prefix
+// @Tags Admin
+// @Produce json
+// @Param prefix query string false "Pincode prefix"
+// @Success 200 {object} response.Response{}
+// @Failure 500 {object} response.Response{}
+// @Router /admin/pincodes [get]
+func (ad *AdminHandler) ListPincodes(c *gin.Context) {
+	pincodes, err := ad.adminUseCase.GetPincodes(c.Query("prefix"))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusInternalServerError, "Could not fetch pincodes", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Pincodes", pincodes, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ListDeliveryRules godoc
+// @Summary List delivery rules
+// @Description Lists the delivery charge and cash on delivery rules in the order they are tried
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} response.Response{}
+// @Failure 500 {object} response.Response{}
+// @Router /admin/delivery-rules [get]
+func (ad *AdminHandler) ListDeliveryRules(c *gin.Context) {
+	deliveryRules, err := ad.adminUseCase.GetDeliveryRules()
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusInternalServerError, "Could not fetch delivery rules", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Delivery rules", deliveryRules, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// AddDeliveryRule godoc
+// @Summary Add a delivery rule
+// @Description Adds a rule that sets the delivery charge (flat_fee, percent_fee, free_shipping) or decides cash on delivery (block_cod, allow_cod) for carts meeting its conditions. Lower priorities are tried first.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param rule body domain.DeliveryRule true "Delivery rule"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/delivery-rules [post]
+func (ad *AdminHandler) AddDeliveryRule(c *gin.Context) {
+	var rule domain.DeliveryRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "fields provided are in wrong format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	rule, err := ad.adminUseCase.AddDeliveryRule(rule)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not add the delivery rule", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Delivery rule added", rule, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// UpdateDeliveryRule godoc
+// @Summary Update a delivery rule
+// @Description Replaces a delivery rule's conditions and action
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id query int true "Delivery rule ID"
+// @Param rule body domain.DeliveryRule true "Delivery rule"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/delivery-rules [put]
+func (ad *AdminHandler) UpdateDeliveryRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "check the parameter", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	var rule domain.DeliveryRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "fields provided are in wrong format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	rule, err = ad.adminUseCase.UpdateDeliveryRule(rule, ruleID)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not update the delivery rule", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Delivery rule updated", rule, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// DeleteDeliveryRule godoc
+// @Summary Delete a delivery rule
+// @Description Deletes a delivery rule by ID
+// @Tags Admin
+// @Produce json
+// @Param id query int true "Delivery rule ID"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Router /admin/delivery-rules [delete]
+func (ad *AdminHandler) DeleteDeliveryRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "check the parameter", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	if err := ad.adminUseCase.DeleteDeliveryRule(ruleID); err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not delete the delivery rule", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Delivery rule deleted", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
 // SalesReport godoc
 // @Summary Generate sales report
 // @Description Generates a sales report for a given date range
@@ -26,6 +26,7 @@ func NewCartHandler(usecase usecase.CartUseCase) *CartHandler {
 // @Produce json
 // @Param id header int true "User ID"
 // @Param product_id body int true "Product ID to add to cart"
+// @Param variant_id body int false "Size/colour variant ID, required when the product has variants"
 // @Param quantity body int true "Quantity of the product to add to cart"
 // @Success 200 {object} response.Response{}
 // @Failure 400 {object} response.Response{}
@@ -42,6 +43,7 @@ func (rt *CartHandler) AddToCart(c *gin.Context) {
 
 	type AddToCartRequest struct {
 		ProductID int `json:"product_id" binding:"required"`
+		VariantID int `json:"variant_id"`
 		Quantity  int `json:"quantity" binding:"required"`
 	}
 
@@ -56,14 +58,14 @@ func (rt *CartHandler) AddToCart(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errRes)
 		return
 	}
-	err := rt.cartUseCase.ValidateAddToCart(ID, req.ProductID, req.Quantity)
+	err := rt.cartUseCase.ValidateAddToCart(ID, req.ProductID, req.VariantID, req.Quantity)
 	if err != nil {
 		errRes := response.ClientResponse(http.StatusBadRequest, "The product cannot be added to cart", nil, err.Error())
 		c.JSON(http.StatusBadRequest, errRes)
 		return
 	}
 
-	cart, err := rt.cartUseCase.AddToCart(ID, req.ProductID, req.Quantity)
+	cart, err := rt.cartUseCase.AddToCart(ID, req.ProductID, req.VariantID, req.Quantity)
 	if err != nil {
 		errRes := response.ClientResponse(http.StatusBadRequest, "The product cannot be added to cart", nil, err.Error())
 		c.JSON(http.StatusBadRequest, errRes)
@@ -80,6 +82,7 @@ func (rt *CartHandler) AddToCart(c *gin.Context) {
 // @Produce json
 // @Param id header int true "User ID"
 // @Param product_id query string true "Product ID to remove from cart"
+// @Param variant_id query string false "Variant ID of the cart line"
 // @Success 200 {object} response.Response{}
 // @Failure 400 {object} response.Response{}
 // @Failure 401 {object} response.Response{}
@@ -105,7 +108,16 @@ func (rt *CartHandler) RemoveFromCart(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errRes)
 		return
 	}
-	cart, err := rt.cartUseCase.RemoveProductFromCart(ID, product_id)
+	variant_id := 0
+	if variantID := c.Query("variant_id"); variantID != "" {
+		variant_id, err = strconv.Atoi(variantID)
+		if err != nil {
+			errRes := response.ClientResponse(http.StatusBadRequest, "Invalid variant ID", nil, err.Error())
+			c.JSON(http.StatusBadRequest, errRes)
+			return
+		}
+	}
+	cart, err := rt.cartUseCase.RemoveProductFromCart(ID, product_id, variant_id)
 	if err != nil {
 		errRes := response.ClientResponse(http.StatusBadRequest, "could not remove the product from cart", nil, err.Error())
 		c.JSON(http.StatusBadRequest, errRes)
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"ecommerce_clean_arch/pkg/usecase"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"ecommerce_clean_arch/pkg/utils/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GiftCardHandler struct {
+	usecase usecase.GiftCardUseCase
+}
+
+func NewGiftCardHandler(usecase usecase.GiftCardUseCase) *GiftCardHandler {
+	return &GiftCardHandler{usecase: usecase}
+}
+
+// Purchase godoc
+// @Summary Buy a gift card
+// @Description Starts buying a gift card for a recipient and returns the gateway order to pay it through. The code is emailed to the recipient once the payment is verified
+// @Tags Gift Cards
+// @Accept json
+// @Produce json
+// @Param purchase body models.GiftCardPurchase true "Amount in rupees and recipient"
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Router /user/giftcards/purchase [post]
+func (g *GiftCardHandler) Purchase(c *gin.Context) {
+	var req models.GiftCardPurchase
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are in wrong format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	checkout, err := g.usecase.Purchase(userID.(int), req)
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "Failed to start gift card purchase", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "Gift card created, complete the payment to send it", checkout, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// VerifyPurchase godoc
+// @Summary Verify a gift card payment
+// @Description Verifies the online payment of a gift card, activates it and emails the code to the recipient
+// @Tags Gift Cards
+// @Accept json
+// @Produce json
+// @Param payment body models.GiftCardPayment true "Checkout result"
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Router /user/giftcards/purchase/verify [post]
+func (g *GiftCardHandler) VerifyPurchase(c *gin.Context) {
+	var payment models.GiftCardPayment
+	if err := c.ShouldBindJSON(&payment); err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are in wrong format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	card, err := g.usecase.VerifyPurchase(userID.(int), payment)
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "Failed to verify gift card payment", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "Gift card paid for and sent", card, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// GetPurchased godoc
+// @Summary List purchased gift cards
+// @Description Lists the gift cards the authenticated user has bought, without their codes
+// @Tags Gift Cards
+// @Produce json
+// @Success 200 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Failure 500 {object} response.ClientResponse
+// @Router /user/giftcards/purchased [get]
+func (g *GiftCardHandler) GetPurchased(c *gin.Context) {
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	cards, err := g.usecase.GetPurchased(userID.(int))
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusInternalServerError, "Could not fetch gift cards", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errRes)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "Gift cards fetched", cards, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ResendCode godoc
+// @Summary Resend a gift card code
+// @Description Replaces the code of a gift card the authenticated user bought and emails the new one to the recipient; the old code stops working
+// @Tags Gift Cards
+// @Produce json
+// @Param id query int true "Gift card ID"
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Router /user/giftcards/resend [post]
+func (g *GiftCardHandler) ResendCode(c *gin.Context) {
+	giftCardID, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "gift card id is not in the right format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	card, err := g.usecase.ResendCode(userID.(int), giftCardID)
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "Could not resend gift card", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "Gift card code replaced", card, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// Balance godoc
+// @Summary Check a gift card balance
+// @Description Shows the balance and expiry of the gift card a code belongs to
+// @Tags Gift Cards
+// @Accept json
+// @Produce json
+// @Param code body models.GiftCardCode true "Gift card code"
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Failure 404 {object} response.ClientResponse
+// @Router /user/giftcards/balance [post]
+func (g *GiftCardHandler) Balance(c *gin.Context) {
+	var req models.GiftCardCode
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are in wrong format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	balance, err := g.usecase.Balance(req.Code)
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusNotFound, "Gift card not found", nil, err.Error())
+		c.JSON(http.StatusNotFound, errRes)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "Gift card balance", balance, nil)
+	c.JSON(http.StatusOK, successRes)
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"ecommerce_clean_arch/pkg/shipping"
 	"ecommerce_clean_arch/pkg/usecase"
 	"ecommerce_clean_arch/pkg/utils/models"
 	"ecommerce_clean_arch/pkg/utils/response"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -21,12 +23,12 @@ func NewOrderHandler(useCase usecase.OrderUseCase) *OrderHandler {
 
 // OrderItemsFromCart godoc
 // @Summary Order items from cart
-// @Description Places an order for items in the user's cart using the provided coupon code
+// @Description Places an order for items in the user's cart using the provided coupon code. The payment method is COD, ONLINE, WALLET, or SPLIT to pay what the wallet holds and the rest online. A gift card code pays what the card holds first, and the payment method the rest; an order the card covers is paid by the card alone
 // @Tags Orders
 // @Accept json
 // @Produce json
 // @Param coupon_code query string true "Coupon Code"
-// @Param orderRequest body struct { AddressID int `json:"address_id" binding:"required"`; PaymentMethod string `json:"payment_method" binding:"required"` } true "Order request details"
+// @Param orderRequest body struct { AddressID int `json:"address_id" binding:"required"`; PaymentMethod string `json:"payment_method" binding:"required"`; GiftCardCode string `json:"gift_card_code"` } true "Order request details"
 // @Success 200 {object} response.ClientResponse
 // @Failure 400 {object} response.ClientResponse
 // @Failure 401 {object} response.ClientResponse
@@ -51,6 +53,7 @@ func (o *OrderHandler) OrderItemsFromCart(c *gin.Context) {
 		UserID        int
 		AddressID     int    `json:"address_id" binding:"required"`
 		PaymentMethod string `json:"payment_method" binding:"required"`
+		GiftCardCode  string `json:"gift_card_code"`
 	}
 
 	if err := c.ShouldBindJSON(&orderRequest); err != nil {
@@ -66,6 +69,7 @@ func (o *OrderHandler) OrderItemsFromCart(c *gin.Context) {
 		AddressID:     uint(addressId),
 		PaymentMethod: paymentMethod,
 		CouponCode:    couponCode,
+		GiftCardCode:  orderRequest.GiftCardCode,
 	}
 	orderSuccessResponse, err := o.orderUseCase.OrderItemsFromCart(order)
 	if err != nil {
@@ -116,6 +120,7 @@ func (o *OrderHandler) ViewOrders(c *gin.Context) {
 // @Description Cancels an order by its ID
 // @Tags Orders
 // @Param order_id query string true "Order ID"
+// @Param refund_to query string false "Refund destination for paid orders: wallet or source"
 // @Produce json
 // @Success 200 {object} response.ClientResponse
 // @Failure 400 {object} response.ClientResponse
@@ -137,7 +142,7 @@ func (o *OrderHandler) CancelOrders(c *gin.Context) {
 	}
 	userid := userID.(int)
 
-	err := o.orderUseCase.CancelOrders(orderID, userid)
+	err := o.orderUseCase.CancelOrders(orderID, userid, c.Query("refund_to"))
 	if err != nil {
 		errorRes := response.ClientResponse(http.StatusBadRequest, "Request not correct", nil, err.Error())
 		c.JSON(http.StatusBadRequest, errorRes)
@@ -150,9 +155,10 @@ func (o *OrderHandler) CancelOrders(c *gin.Context) {
 
 // CancelOrderItem godoc
 // @Summary Cancel an order item
-// @Description Cancels a specific order item by its ID
+// @Description Cancels a specific order item before delivery and refunds its share of the amount paid
 // @Tags Orders
 // @Param order_item_id query string true "Order Item ID"
+// @Param refund_to query string false "Refund destination for paid orders: wallet or source"
 // @Produce json
 // @Success 200 {object} response.ClientResponse
 // @Failure 400 {object} response.ClientResponse
@@ -173,7 +179,7 @@ func (o *OrderHandler) CancelOrderItem(c *gin.Context) {
 	}
 	userid := userID.(int)
 
-	orderDetails, err := o.orderUseCase.CancelOrderItem(orderItemID, userid)
+	orderDetails, err := o.orderUseCase.CancelOrderItem(orderItemID, userid, c.Query("refund_to"))
 	if err != nil {
 		errorRes := response.ClientResponse(http.StatusBadRequest, "Failed to cancel order item", nil, err.Error())
 		c.JSON(http.StatusBadRequest, errorRes)
@@ -183,17 +189,80 @@ func (o *OrderHandler) CancelOrderItem(c *gin.Context) {
 	c.JSON(http.StatusOK, successRes)
 }
 
-// ReturnUser Order godoc
-// @Summary Return a user order
-// @Description Initiates the return process for a specific order by its ID
+// RequestReturn godoc
+// @Summary Request a return
+// @Description Opens a return of a delivered order, or of one item when order_item_id is set. The refund goes out once the parcel has been picked up and passed inspection
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param return body models.ReturnRequest true "Order, optional item, reason code, comments, photo URL and refund destination"
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Router /user/order/return [post]
+func (o *OrderHandler) RequestReturn(c *gin.Context) {
+	var req models.ReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are in wrong format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	request, err := o.orderUseCase.RequestReturn(userID.(int), req)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "failed to request the return", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "return requested successfully", request, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// GetReturnRequests godoc
+// @Summary List return requests
+// @Description Lists the authenticated user's return requests with their current status
+// @Tags Orders
+// @Produce json
+// @Success 200 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Failure 500 {object} response.ClientResponse
+// @Router /user/order/returns [get]
+func (o *OrderHandler) GetReturnRequests(c *gin.Context) {
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	requests, err := o.orderUseCase.GetReturnRequests(userID.(int))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusInternalServerError, "Could not fetch return requests", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Return requests", requests, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// GetOrderTimeline godoc
+// @Summary Order timeline
+// @Description Lists every status change of one of the authenticated user's orders
 // @Tags Orders
 // @Param order_id query string true "Order ID"
 // @Produce json
 // @Success 200 {object} response.ClientResponse
 // @Failure 400 {object} response.ClientResponse
 // @Failure 401 {object} response.ClientResponse
-// @Router /orders/return [put]
-func (o *OrderHandler) ReturnUserOrder(c *gin.Context) {
+// @Router /user/order/timeline [get]
+func (o *OrderHandler) GetOrderTimeline(c *gin.Context) {
 	orderID := c.Query("order_id")
 	if orderID == "" {
 		errRes := response.ClientResponse(http.StatusBadRequest, "Order ID is required", nil, nil)
@@ -206,29 +275,154 @@ func (o *OrderHandler) ReturnUserOrder(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, errRes)
 		return
 	}
-	userid := userID.(int)
 
-	err := o.orderUseCase.ReturnUserOrder(orderID, userid)
+	timeline, err := o.orderUseCase.GetOrderTimeline(orderID, userID.(int))
 	if err != nil {
-		errorRes := response.ClientResponse(http.StatusBadRequest, "failed to Return the order", nil, err.Error())
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not fetch the order timeline", nil, err.Error())
 		c.JSON(http.StatusBadRequest, errorRes)
 		return
 	}
-	successRes := response.ClientResponse(http.StatusOK, "order returned successfully", nil, nil)
+
+	successRes := response.ClientResponse(http.StatusOK, "Order timeline", timeline, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
-// GenerateInvoice godoc
-// @Summary Generate an invoice
-// @Description Generates a PDF invoice for a specific order by its ID
+// GetRefunds godoc
+// @Summary List refunds
+// @Description Lists refunds on the authenticated user's orders with their current status
 // @Tags Orders
-// @Param order_id query string true "Order ID"
 // @Produce json
 // @Success 200 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Failure 500 {object} response.ClientResponse
+// @Router /user/order/refunds [get]
+func (o *OrderHandler) GetRefunds(c *gin.Context) {
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	refunds, err := o.orderUseCase.GetRefunds(userID.(int))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusInternalServerError, "Could not fetch refunds", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Refunds", refunds, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// RequestExchange godoc
+// @Summary Exchange an order item for another size
+// @Description Holds the requested size of a delivered item for exchange. A higher price is paid now from the wallet or online; a lower one is refunded once the exchange is approved
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param exchange body models.ExchangeRequest true "Order item, new variant and how to settle a price difference"
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Router /user/order/exchange [post]
+func (o *OrderHandler) RequestExchange(c *gin.Context) {
+	var req models.ExchangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are in wrong format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	exchange, err := o.orderUseCase.RequestExchange(userID.(int), req)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Failed to request exchange", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "Exchange requested successfully", exchange, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// VerifyExchangePayment godoc
+// @Summary Verify an exchange payment
+// @Description Records the online payment of an exchange's price difference
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param payment body models.ExchangePayment true "Checkout result"
+// @Success 200 {object} response.ClientResponse
 // @Failure 400 {object} response.ClientResponse
 // @Failure 401 {object} response.ClientResponse
+// @Router /user/order/exchange/verify [post]
+func (o *OrderHandler) VerifyExchangePayment(c *gin.Context) {
+	var payment models.ExchangePayment
+	if err := c.ShouldBindJSON(&payment); err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are in wrong format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	exchange, err := o.orderUseCase.VerifyExchangePayment(userID.(int), payment)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Failed to verify exchange payment", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "Exchange payment verified", exchange, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// GetExchanges godoc
+// @Summary List exchanges
+// @Description Lists the authenticated user's exchange requests with their current status
+// @Tags Orders
+// @Produce json
+// @Success 200 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
 // @Failure 500 {object} response.ClientResponse
-// @Router /orders/invoice [get]
+// @Router /user/order/exchanges [get]
+func (o *OrderHandler) GetExchanges(c *gin.Context) {
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	exchanges, err := o.orderUseCase.GetExchanges(userID.(int))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusInternalServerError, "Could not fetch exchanges", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Exchanges", exchanges, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// GenerateInvoice godoc
+// @Summary Download an invoice
+// @Description Returns the PDF tax invoice of an order. The first download of a confirmed order issues it with the next invoice number; later downloads return the same document
+// @Tags Orders
+// @Param order_id query string true "Order ID"
+// @Produce application/pdf
+// @Success 200 {file} file
+// @Failure 400 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Router /user/order/generate [get]
 func (o *OrderHandler) GenerateInvoice(c *gin.Context) {
 	userID, ok := c.Get("id")
 	if !ok {
@@ -249,19 +443,180 @@ func (o *OrderHandler) GenerateInvoice(c *gin.Context) {
 	pdf, err := o.orderUseCase.GenerateInvoice(orderID, userid)
 	if err != nil {
 		errorRes := response.ClientResponse(http.StatusBadRequest, "could not generate invoice", nil, err.Error())
-		c.JSON(http.StatusInternalServerError, errorRes)
+		c.JSON(http.StatusBadRequest, errorRes)
 		return
 	}
 
 	c.Header("Content-Disposition", "attachment; filename=invoice.pdf")
-	c.Header("Content-Type", "application/pdf")
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// GetCreditNotes godoc
+// @Summary List credit notes
+// @Description Lists the credit notes issued against an order's invoice for cancelled or returned items
+// @Tags Orders
+// @Param order_id query string true "Order ID"
+// @Produce json
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Router /user/order/credit-notes [get]
+func (o *OrderHandler) GetCreditNotes(c *gin.Context) {
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	orderID := c.Query("order_id")
+	if orderID == "" {
+		errRes := response.ClientResponse(http.StatusBadRequest, "order ID is required", nil, nil)
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	notes, err := o.orderUseCase.GetCreditNotes(orderID, userID.(int))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not fetch credit notes", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Credit notes", notes, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// DownloadCreditNote godoc
+// @Summary Download a credit note
+// @Description Returns the PDF of a credit note issued on one of the user's orders
+// @Tags Orders
+// @Param id query string true "Credit note ID"
+// @Produce application/pdf
+// @Success 200 {file} file
+// @Failure 400 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Router /user/order/credit-note [get]
+func (o *OrderHandler) DownloadCreditNote(c *gin.Context) {
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
 
-	err = pdf.Output(c.Writer)
+	creditNoteID := c.Query("id")
+	if creditNoteID == "" {
+		errRes := response.ClientResponse(http.StatusBadRequest, "credit note ID is required", nil, nil)
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	pdf, err := o.orderUseCase.GetCreditNotePDF(creditNoteID, userID.(int))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errorRes := response.ClientResponse(http.StatusBadRequest, "could not fetch credit note", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=credit-note.pdf")
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// TrackOrder godoc
+// @Summary Track an order
+// @Description Returns the shipment of one of the user's orders with its AWB number and tracking history
+// @Tags Orders
+// @Param order_id query string true "Order ID"
+// @Produce json
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Router /user/order/tracking [get]
+func (o *OrderHandler) TrackOrder(c *gin.Context) {
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	orderID := c.Query("order_id")
+	if orderID == "" {
+		errRes := response.ClientResponse(http.StatusBadRequest, "order ID is required", nil, nil)
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	tracking, err := o.orderUseCase.GetTracking(orderID, userID.(int))
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not fetch tracking", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Order tracking", tracking, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// @Summary Carrier tracking webhook
+// @Description Receives tracking updates from the shipping carrier. The body is authenticated with the X-Shipping-Signature HMAC; a pickup marks the order shipped and a delivery marks it delivered.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param X-Shipping-Signature header string true "HMAC-SHA256 of the body with the shipping webhook secret"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Failure 500 {object} response.Response{}
+// @Router /shipping/webhook [post]
+func (o *OrderHandler) ShippingWebhook(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "could not read webhook body", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	err = o.orderUseCase.HandleShippingEvent(body, c.GetHeader("X-Shipping-Signature"))
+	if errors.Is(err, shipping.ErrInvalidWebhookSignature) {
+		errRes := response.ClientResponse(http.StatusBadRequest, "invalid webhook signature", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusInternalServerError, "could not process webhook", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "webhook processed", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// CheckPincode godoc
+// @Summary Check a pincode
+// @Description Tells whether orders can be delivered to a pincode, in how many days, and which payment methods are available there
+// @Tags Orders
+// @Param pincode query string true "Pincode"
+// @Produce json
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Router /user/check-pincode [get]
+func (o *OrderHandler) CheckPincode(c *gin.Context) {
+	pincode := c.Query("pincode")
+	if pincode == "" {
+		errRes := response.ClientResponse(http.StatusBadRequest, "pincode is required", nil, nil)
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	serviceability, err := o.orderUseCase.CheckPincode(pincode)
+	if err != nil {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "Could not check the pincode", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
 		return
 	}
 
-	successRes := response.ClientResponse(http.StatusOK, "the invoice is generated", pdf, nil)
+	successRes := response.ClientResponse(http.StatusOK, "Pincode serviceability", serviceability, nil)
 	c.JSON(http.StatusOK, successRes)
 }
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"ecommerce_clean_arch/pkg/gateway"
 	"ecommerce_clean_arch/pkg/usecase"
 	"ecommerce_clean_arch/pkg/utils/models"
 	"ecommerce_clean_arch/pkg/utils/response"
+	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
@@ -33,6 +36,11 @@ func NewPaymentHandler(paymentUsecase usecase.PaymentUsecase) *PaymentHandler {
 func (pay *PaymentHandler) CreatePayment(c *gin.Context) {
 	orderID := c.Query("order_id")
 	orderDetail, razorID, err := pay.PaymentUsecase.CreatePayment(orderID)
+	if errors.Is(err, usecase.ErrNotPayableOnline) {
+		errorRes := response.ClientResponse(http.StatusBadRequest, "could not create payment", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errorRes)
+		return
+	}
 	if err != nil {
 		if strings.Contains(err.Error(), "Payment failed") {
 			errorRes := response.ClientResponse(http.StatusInternalServerError, "Payment failed", nil, err.Error())
@@ -47,13 +55,37 @@ func (pay *PaymentHandler) CreatePayment(c *gin.Context) {
 	log.Println("OrderDetails: ", orderDetail)
 	log.Println("OrderID is: ", orderID)
 	log.Println("razorID: ", razorID)
+
+	gatewayName, keyID := pay.PaymentUsecase.CheckoutKey()
+	if gatewayName == gateway.Fake {
+		paymentID, signature, err := pay.PaymentUsecase.SimulatePayment(razorID)
+		if err != nil {
+			errorRes := response.ClientResponse(http.StatusInternalServerError, "could not simulate payment", nil, err.Error())
+			c.JSON(http.StatusInternalServerError, errorRes)
+			return
+		}
+		c.HTML(
+			http.StatusOK, "fake_checkout.html", gin.H{
+				"razor_id":   razorID,
+				"order_id":   orderDetail.OrderId,
+				"user_name":  orderDetail.Name,
+				"total":      orderDetail.AmountDue,
+				"payment_id": paymentID,
+				"signature":  signature,
+			})
+		return
+	}
+
 	c.HTML(
 		http.StatusOK, "index.html", gin.H{
-			"final_price": orderDetail.FinalPrice * 100,
-			"razor_id":    razorID,
-			"order_id":    orderDetail.OrderId,
-			"user_name":   orderDetail.Name,
-			"total":       orderDetail.FinalPrice,
+			"final_price":  orderDetail.AmountDue.Paise(),
+			"razor_id":     razorID,
+			"order_id":     orderDetail.OrderId,
+			"user_name":    orderDetail.Name,
+			"user_email":   orderDetail.Email,
+			"user_phone":   orderDetail.Phone,
+			"total":        orderDetail.AmountDue,
+			"razorpay_key": keyID,
 		})
 }
 
@@ -85,6 +117,51 @@ func (pay *PaymentHandler) OnlinePaymentVerification(c *gin.Context) {
 	c.JSON(http.StatusOK, successRes)
 }
 
+// VerificationOwner returns the customer whose order a payment verification
+// is for, so its Idempotency-Key is scoped to them.
+func (pay *PaymentHandler) VerificationOwner(c *gin.Context, body []byte) (int, error) {
+	var details models.OnlinePaymentVerification
+	if err := json.Unmarshal(body, &details); err != nil {
+		return 0, errors.New("invalid request data")
+	}
+	return pay.PaymentUsecase.GetOrderOwner(details.OrderID)
+}
+
+// @Summary Payment gateway webhook
+// @Description Receives payment.captured, payment.failed and refund.processed events. The body is authenticated with the X-Razorpay-Signature HMAC and each event is applied once.
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param X-Razorpay-Signature header string true "HMAC-SHA256 of the body with the webhook secret"
+// @Param X-Razorpay-Event-Id header string false "Gateway event id used for de-duplication"
+// @Success 200 {object} response.Response{}
+// @Failure 400 {object} response.Response{}
+// @Failure 500 {object} response.Response{}
+// @Router /payment/webhook [post]
+func (pay *PaymentHandler) PaymentWebhook(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "could not read webhook body", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	err = pay.PaymentUsecase.HandleWebhook(body, c.GetHeader("X-Razorpay-Signature"), c.GetHeader("X-Razorpay-Event-Id"))
+	if errors.Is(err, usecase.ErrInvalidWebhookSignature) {
+		errRes := response.ClientResponse(http.StatusBadRequest, "invalid webhook signature", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusInternalServerError, "could not process webhook", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "webhook processed", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
 // @Summary Payment success page
 // @Description Renders the success page after payment
 // @Tags Payment
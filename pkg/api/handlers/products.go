@@ -138,3 +138,163 @@ func (p *ProductHandler) SearchProduct(c *gin.Context) {
 	successRes := response.ClientResponse(http.StatusOK, "Products fetched successfully", products, nil)
 	c.JSON(http.StatusOK, successRes)
 }
+
+// SearchProducts godoc
+// @Summary Full-text product search
+// @Description Keyword search over name and description with category, brand, size, price and rating facets
+// @Tags Products
+// @Param q query string false "Keyword"
+// @Param category_id query []int false "Category IDs"
+// @Param brand query []string false "Brands"
+// @Param size query []string false "Sizes, e.g. UK 9"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param min_rating query number false "Minimum average rating"
+// @Param sort_by query string false "relevance, price_L-H, price_H-L, newest, alphabetic or rating"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Produce json
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Failure 500 {object} response.ClientResponse
+// @Router /user/products/search [get]
+func (p *ProductHandler) SearchProducts(c *gin.Context) {
+	var query models.ProductSearchQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are in wrong format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	result, err := p.ProductUseCase.SearchProducts(query)
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusInternalServerError, "Failed to search products", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Products fetched successfully", result, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// AddVariant godoc
+// @Summary Add a product variant
+// @Description Adds a size/colour variant with its own SKU, stock, price override and images
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param variant body models.AddVariant true "Variant details"
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Router /admin/product/addvariant [post]
+func (p *ProductHandler) AddVariant(c *gin.Context) {
+	var addVariant models.AddVariant
+
+	if err := c.ShouldBindJSON(&addVariant); err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "the constraints are given wrong", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	variant, err := p.ProductUseCase.AddVariant(addVariant)
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "the variant cannot be added", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "the variant added successfully", variant, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// UpdateVariant godoc
+// @Summary Update a product variant
+// @Description Updates the SKU, size, colour, stock, price override and images of a variant
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id query int true "Variant ID"
+// @Param variant body models.AddVariant true "Updated variant details"
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Router /admin/product/updatevariant [put]
+func (p *ProductHandler) UpdateVariant(c *gin.Context) {
+	variantID, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "check the parameter", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	var variant models.AddVariant
+	if err := c.ShouldBindJSON(&variant); err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "the constraints are given wrong", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	updatedVariant, err := p.ProductUseCase.UpdateVariant(variant, variantID)
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "the variant cannot be updated", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the variant is updated", updatedVariant, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// DeleteVariant godoc
+// @Summary Delete a product variant
+// @Description Deletes a size/colour variant by its ID
+// @Tags Products
+// @Param id query int true "Variant ID"
+// @Produce json
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Router /admin/product/deletevariant [delete]
+func (p *ProductHandler) DeleteVariant(c *gin.Context) {
+	variantID, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "check the parameter", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	err = p.ProductUseCase.DeleteVariant(variantID)
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "the variant cannot be deleted", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "the variant is deleted", nil, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ListVariants godoc
+// @Summary List product variants
+// @Description Lists the sizes and colours available for a product
+// @Tags Products
+// @Param product_id query int true "Product ID"
+// @Produce json
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Failure 500 {object} response.ClientResponse
+// @Router /user/products/variants [get]
+func (p *ProductHandler) ListVariants(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Query("product_id"))
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "check the parameter", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	variants, err := p.ProductUseCase.GetProductVariants(productID)
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusInternalServerError, "Failed to fetch variants", nil, err.Error())
+		c.JSON(http.StatusInternalServerError, errRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Variants fetched successfully", variants, nil)
+	c.JSON(http.StatusOK, successRes)
+}
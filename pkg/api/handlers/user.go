@@ -163,10 +163,12 @@ func (h *UserHandler) UserLogin(c *gin.Context) {
 
 // GetProducts godoc
 // @Summary Get all products
-// @Description Retrieves a list of all products
+// @Description Retrieves a list of all products, with the estimated delivery window when a pincode is given
 // @Tags Users
 // @Produce json
+// @Param pincode query string false "Delivery pincode"
 // @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
 // @Failure 500 {object} response.ClientResponse
 // @Router /products [get]
 func (h *UserHandler) GetProducts(c *gin.Context) {
@@ -176,6 +178,18 @@ func (h *UserHandler) GetProducts(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errorRes)
 		return
 	}
+
+	if pincode := c.Query("pincode"); pincode != "" {
+		estimate, err := h.userUseCase.DeliveryEstimate(pincode)
+		if err != nil {
+			errorRes := response.ClientResponse(http.StatusBadRequest, "could not estimate delivery", nil, err.Error())
+			c.JSON(http.StatusBadRequest, errorRes)
+			return
+		}
+		for i := range products {
+			products[i].EstimatedDelivery = &estimate
+		}
+	}
 	successRes := response.ClientResponse(http.StatusOK, "Successfully retrieved the products", products, nil)
 	c.JSON(http.StatusOK, successRes)
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"ecommerce_clean_arch/pkg/usecase"
+	"ecommerce_clean_arch/pkg/utils/models"
 	"ecommerce_clean_arch/pkg/utils/response"
 	"net/http"
 
@@ -69,3 +70,73 @@ func (wal *WalletHandler) GetWalletTransaction(c *gin.Context) {
 	successRes := response.ClientResponse(http.StatusOK, "WallletHistory is successfully shown", walletHistory, nil)
 	c.JSON(http.StatusOK, successRes)
 }
+
+// TopUp godoc
+// @Summary Top up wallet
+// @Description Starts adding money to the authenticated user's wallet and returns the gateway order to pay it through
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Param topup body models.WalletTopUpRequest true "Amount in rupees"
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Router /user/wallet/topup [post]
+func (wal *WalletHandler) TopUp(c *gin.Context) {
+	var req models.WalletTopUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are in wrong format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	checkout, err := wal.usecase.TopUp(userID.(int), req.Amount)
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "Failed to start top-up", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "Top-up created, complete the payment to credit your wallet", checkout, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// VerifyTopUp godoc
+// @Summary Verify a wallet top-up
+// @Description Verifies the online payment of a top-up and credits the wallet
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Param payment body models.WalletTopUpPayment true "Checkout result"
+// @Success 200 {object} response.ClientResponse
+// @Failure 400 {object} response.ClientResponse
+// @Failure 401 {object} response.ClientResponse
+// @Router /user/wallet/topup/verify [post]
+func (wal *WalletHandler) VerifyTopUp(c *gin.Context) {
+	var payment models.WalletTopUpPayment
+	if err := c.ShouldBindJSON(&payment); err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "fields provided are in wrong format", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	userID, ok := c.Get("id")
+	if !ok {
+		errRes := response.ClientResponse(http.StatusUnauthorized, "User ID not found in context", nil, nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	topUp, err := wal.usecase.VerifyTopUp(userID.(int), payment)
+	if err != nil {
+		errRes := response.ClientResponse(http.StatusBadRequest, "Failed to verify top-up", nil, err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	successRes := response.ClientResponse(http.StatusOK, "Wallet topped up", topUp, nil)
+	c.JSON(http.StatusOK, successRes)
+}
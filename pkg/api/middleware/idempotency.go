@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"ecommerce_clean_arch/pkg/usecase"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const IdempotencyHeader = "Idempotency-Key"
+
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// IdempotencyOwner returns the user a request's Idempotency-Key is scoped
+// to, given the request body.
+type IdempotencyOwner func(c *gin.Context, body []byte) (int, error)
+
+// Idempotency replays the stored response when a request is retried with the
+// same Idempotency-Key. Keys are scoped per logged-in user and per endpoint,
+// and reusing a key with a different request is rejected. Only successful
+// responses are stored; after an error the key is freed so the client can
+// retry with it. Requests without the header are passed through unchanged.
+func Idempotency(idempotencyUseCase *usecase.IdempotencyUseCase, scope string) gin.HandlerFunc {
+	return IdempotencyFor(idempotencyUseCase, scope, loggedInUser)
+}
+
+func loggedInUser(c *gin.Context, _ []byte) (int, error) {
+	userID, _ := c.Get("id")
+	uid, _ := userID.(int)
+	return uid, nil
+}
+
+// IdempotencyFor is Idempotency for routes reached without logging in, such
+// as payment verification from the checkout page, with keys scoped to the
+// user owner returns.
+func IdempotencyFor(idempotencyUseCase *usecase.IdempotencyUseCase, scope string, owner IdempotencyOwner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "could not read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		uid, err := owner(c, body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			c.Abort()
+			return
+		}
+
+		record, err := idempotencyUseCase.Begin(key, uid, scope, requestHash)
+		switch {
+		case errors.Is(err, usecase.ErrIdempotencyKeyReused):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"message": err.Error()})
+			c.Abort()
+			return
+		case errors.Is(err, usecase.ErrIdempotencyInProgress):
+			c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+			c.Abort()
+			return
+		case errors.Is(err, usecase.ErrIdempotencyKeyTooLong):
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			c.Abort()
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "could not check idempotency key", "error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		if record != nil {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.StatusCode, "application/json; charset=utf-8", []byte(record.ResponseBody))
+			c.Abort()
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+
+		// Runs after a panic too, when nothing was written.
+		defer func() {
+			if recorder.Written() && recorder.Status() >= 200 && recorder.Status() < 300 {
+				err := idempotencyUseCase.Complete(key, uid, scope, recorder.Status(), recorder.body.String())
+				if err != nil {
+					log.Println("failed to store idempotent response:", err)
+				}
+				return
+			}
+			if err := idempotencyUseCase.Abandon(key, uid, scope); err != nil {
+				log.Println("failed to release idempotency key:", err)
+			}
+		}()
+
+		c.Next()
+	}
+}
@@ -34,6 +34,9 @@ func AdminRoutes(router *gin.RouterGroup, adminHandler *handlers.AdminHandler, c
 		product.POST("/addproduct", productHandler.AddProduct)
 		product.PUT("/updateproduct", productHandler.UpdateProduct)
 		product.DELETE("/deleteproduct", productHandler.DeleteProduct)
+		product.POST("/addvariant", productHandler.AddVariant)
+		product.PUT("/updatevariant", productHandler.UpdateVariant)
+		product.DELETE("/deletevariant", productHandler.DeleteVariant)
 	}
 
 	orders := router.Group("/orders")
@@ -42,6 +45,54 @@ func AdminRoutes(router *gin.RouterGroup, adminHandler *handlers.AdminHandler, c
 		orders.GET("/listorders", adminHandler.ListOrders)
 		orders.PATCH("cancelorders", adminHandler.AdminCancelOrders)
 		orders.PUT("/changeorderstatus", adminHandler.ChangeOrderStatus)
+		orders.GET("/timeline", adminHandler.OrderTimeline)
+		orders.GET("/packing-slip", adminHandler.PackingSlip)
+		orders.GET("/pick-list", adminHandler.PickList)
+		orders.GET("/shipping-label", adminHandler.ShippingLabel)
+		orders.POST("/shipments", adminHandler.BookShipment)
+		orders.GET("/shipment", adminHandler.ShipmentTracking)
+		orders.GET("/exchanges", adminHandler.ListExchanges)
+		orders.PUT("/exchanges/approve", adminHandler.ApproveExchange)
+		orders.PUT("/exchanges/reject", adminHandler.RejectExchange)
+		orders.PUT("/exchanges/complete", adminHandler.CompleteExchange)
+		orders.GET("/returns", adminHandler.ListReturnRequests)
+		orders.PUT("/returns/approve", adminHandler.ApproveReturn)
+		orders.PUT("/returns/pickup", adminHandler.MarkReturnPickedUp)
+		orders.PUT("/returns/inspect", adminHandler.InspectReturn)
+		orders.PUT("/returns/refund", adminHandler.RefundReturn)
+		orders.PUT("/returns/reject", adminHandler.RejectReturn)
+	}
+
+	pincodes := router.Group("/pincodes")
+	{
+		pincodes.Use(middleware.AdminMiddleware())
+		pincodes.GET("", adminHandler.ListPincodes)
+		pincodes.POST("/upload", adminHandler.UploadPincodes)
+	}
+
+	deliveryRules := router.Group("/delivery-rules")
+	{
+		deliveryRules.Use(middleware.AdminMiddleware())
+		deliveryRules.GET("", adminHandler.ListDeliveryRules)
+		deliveryRules.POST("", adminHandler.AddDeliveryRule)
+		deliveryRules.PUT("", adminHandler.UpdateDeliveryRule)
+		deliveryRules.DELETE("", adminHandler.DeleteDeliveryRule)
+	}
+
+	refunds := router.Group("/refunds")
+	{
+		refunds.Use(middleware.AdminMiddleware())
+		refunds.GET("", adminHandler.ListRefunds)
+		refunds.POST("/retry", adminHandler.RetryRefund)
+	}
+
+	walletAdjustments := router.Group("/wallet-adjustments")
+	{
+		walletAdjustments.Use(middleware.AdminMiddleware())
+		walletAdjustments.GET("", adminHandler.ListWalletAdjustments)
+		walletAdjustments.POST("", adminHandler.AdjustWallet)
+		walletAdjustments.PUT("/approve", adminHandler.ApproveWalletAdjustment)
+		walletAdjustments.PUT("/reject", adminHandler.RejectWalletAdjustment)
 	}
 
 	salesreportmanagement := router.Group("/salesreport")
@@ -3,13 +3,15 @@ package routes
 import (
 	"ecommerce_clean_arch/pkg/api/handlers"
 	"ecommerce_clean_arch/pkg/api/middleware"
+	"ecommerce_clean_arch/pkg/usecase"
 
 	"github.com/gin-gonic/gin"
 )
 
 func UserRoutes(router *gin.RouterGroup, userHandler *handlers.UserHandler, cartHandler *handlers.CartHandler,
 	orderHandler *handlers.OrderHandler, productHandler *handlers.ProductHandler, reviewHandler *handlers.ReviewHandler,
-	paymentHandler *handlers.PaymentHandler, walletHandler *handlers.WalletHandler, wishlistHandler *handlers.WishlistHandler) {
+	paymentHandler *handlers.PaymentHandler, walletHandler *handlers.WalletHandler, giftCardHandler *handlers.GiftCardHandler,
+	wishlistHandler *handlers.WishlistHandler, idempotencyUseCase *usecase.IdempotencyUseCase) {
 	router.Use(gin.Logger(), gin.Recovery())
 	router.POST("/usersignup", userHandler.UserSignup)
 	router.POST("/verify-otp/:email", userHandler.VerifyOTP)
@@ -17,6 +19,7 @@ func UserRoutes(router *gin.RouterGroup, userHandler *handlers.UserHandler, cart
 	router.POST("/userlogin", userHandler.UserLogin)
 	router.GET("/listproducts", userHandler.GetProducts)
 	router.GET("/listcategory", userHandler.ListCategory)
+	router.GET("/check-pincode", orderHandler.CheckPincode)
 
 	//addresses
 	address := router.Group("/addresses")
@@ -44,6 +47,8 @@ func UserRoutes(router *gin.RouterGroup, userHandler *handlers.UserHandler, cart
 	// {
 	// 	product.GET("/filtercategory", productHandler.FilterCategory)
 	product.GET("/searchproduct", productHandler.SearchProduct)
+	product.GET("/search", productHandler.SearchProducts)
+	product.GET("/variants", productHandler.ListVariants)
 	// }
 
 	//cart
@@ -59,16 +64,28 @@ func UserRoutes(router *gin.RouterGroup, userHandler *handlers.UserHandler, cart
 	order := router.Group("/order")
 	{
 		order.Use(middleware.AuthMiddleware())
-		order.POST("/placeorder", orderHandler.OrderItemsFromCart)
+		order.POST("/placeorder", middleware.Idempotency(idempotencyUseCase, "placeorder"), orderHandler.OrderItemsFromCart)
 		order.GET("/vieworders", orderHandler.ViewOrders)
 		order.PUT("/cancelorders", orderHandler.CancelOrders)
 		order.PUT("/cancelOrderItem", orderHandler.CancelOrderItem)
-		order.PUT("/returnorder", orderHandler.ReturnUserOrder)
+		order.POST("/return", orderHandler.RequestReturn)
+		order.GET("/returns", orderHandler.GetReturnRequests)
+		order.GET("/refunds", orderHandler.GetRefunds)
+		order.POST("/exchange", orderHandler.RequestExchange)
+		order.POST("/exchange/verify", orderHandler.VerifyExchangePayment)
+		order.GET("/exchanges", orderHandler.GetExchanges)
+		order.GET("/timeline", orderHandler.GetOrderTimeline)
 		order.GET("/generate", orderHandler.GenerateInvoice)
+		order.GET("/credit-notes", orderHandler.GetCreditNotes)
+		order.GET("/credit-note", orderHandler.DownloadCreditNote)
+		order.GET("/tracking", orderHandler.TrackOrder)
 	}
 
 	router.GET("/payment", paymentHandler.CreatePayment)
-	router.POST("/payment/verify", paymentHandler.OnlinePaymentVerification)
+	// Deprecated: older clients verify here; new ones use /payment/verify.
+	router.POST("/payment/verify", middleware.AuthMiddleware(),
+		middleware.IdempotencyFor(idempotencyUseCase, "payment_verify", paymentHandler.VerificationOwner),
+		paymentHandler.OnlinePaymentVerification)
 	router.GET("/payment/success", paymentHandler.PaymentSuccess)
 
 	//Wallet
@@ -77,6 +94,19 @@ func UserRoutes(router *gin.RouterGroup, userHandler *handlers.UserHandler, cart
 		wallet.Use(middleware.AuthMiddleware())
 		wallet.GET("/getwallet", walletHandler.ViewWallet)
 		wallet.GET("/wallethistory", walletHandler.GetWalletTransaction)
+		wallet.POST("/topup", middleware.Idempotency(idempotencyUseCase, "wallettopup"), walletHandler.TopUp)
+		wallet.POST("/topup/verify", walletHandler.VerifyTopUp)
+	}
+
+	//Gift cards
+	giftCard := router.Group("/giftcards")
+	{
+		giftCard.Use(middleware.AuthMiddleware())
+		giftCard.POST("/purchase", middleware.Idempotency(idempotencyUseCase, "giftcardpurchase"), giftCardHandler.Purchase)
+		giftCard.POST("/purchase/verify", giftCardHandler.VerifyPurchase)
+		giftCard.GET("/purchased", giftCardHandler.GetPurchased)
+		giftCard.POST("/resend", giftCardHandler.ResendCode)
+		giftCard.POST("/balance", giftCardHandler.Balance)
 	}
 
 	//Wishlist
@@ -2,7 +2,9 @@ package api
 
 import (
 	"ecommerce_clean_arch/pkg/api/handlers"
+	"ecommerce_clean_arch/pkg/api/middleware"
 	"ecommerce_clean_arch/pkg/api/routes"
+	"ecommerce_clean_arch/pkg/usecase"
 	"log"
 
 	"github.com/gin-gonic/gin"
@@ -18,11 +20,13 @@ type ServerHTTP struct {
 func NewServerHTTP(userHandler *handlers.UserHandler, authHandler *handlers.AuthHandler,
 	adminHandler *handlers.AdminHandler, categoryHandler *handlers.CategoryHandler, productHandler *handlers.ProductHandler,
 	reviewHandler *handlers.ReviewHandler, cartHandler *handlers.CartHandler, orderHandler *handlers.OrderHandler,
-	paymentHandler *handlers.PaymentHandler, walletHandler *handlers.WalletHandler, wishlistHandler *handlers.WishlistHandler, couponHandler *handlers.CouponHandler) *ServerHTTP {
+	paymentHandler *handlers.PaymentHandler, walletHandler *handlers.WalletHandler, giftCardHandler *handlers.GiftCardHandler,
+	wishlistHandler *handlers.WishlistHandler, couponHandler *handlers.CouponHandler,
+	idempotencyUseCase *usecase.IdempotencyUseCase) *ServerHTTP {
 
 	if userHandler == nil || authHandler == nil || adminHandler == nil || categoryHandler == nil || productHandler == nil ||
 		reviewHandler == nil || cartHandler == nil || orderHandler == nil || paymentHandler == nil || walletHandler == nil ||
-		wishlistHandler == nil || couponHandler == nil {
+		giftCardHandler == nil || wishlistHandler == nil || couponHandler == nil || idempotencyUseCase == nil {
 		log.Fatal("One or more handlers are nil")
 	}
 
@@ -39,7 +43,7 @@ func NewServerHTTP(userHandler *handlers.UserHandler, authHandler *handlers.Auth
 	// Set up user routes
 	userGroup := router.Group("/user")
 	routes.UserRoutes(userGroup, userHandler, cartHandler, orderHandler, productHandler, reviewHandler,
-		paymentHandler, walletHandler, wishlistHandler)
+		paymentHandler, walletHandler, giftCardHandler, wishlistHandler, idempotencyUseCase)
 
 	authGroup := router.Group("/auth")
 	routes.AuthRoutes(authGroup, authHandler)
@@ -49,8 +53,10 @@ func NewServerHTTP(userHandler *handlers.UserHandler, authHandler *handlers.Auth
 	routes.AdminRoutes(adminGroup, adminHandler, categoryHandler, productHandler, couponHandler)
 
 	router.GET("/payment", paymentHandler.CreatePayment)
-	router.POST("/payment/verify", paymentHandler.OnlinePaymentVerification)
+	router.POST("/payment/verify", middleware.IdempotencyFor(idempotencyUseCase, "payment_verify", paymentHandler.VerificationOwner), paymentHandler.OnlinePaymentVerification)
 	router.GET("/payment/success", paymentHandler.PaymentSuccess)
+	router.POST("/payment/webhook", paymentHandler.PaymentWebhook)
+	router.POST("/shipping/webhook", orderHandler.ShippingWebhook)
 
 	log.Println("ServerHTTP initialized successfully")
 	return &ServerHTTP{engine: router}
@@ -0,0 +1,43 @@
+package barcode_test
+
+import (
+	"ecommerce_clean_arch/pkg/barcode"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Symbols(t *testing.T) {
+	testCases := map[string]struct {
+		data      string
+		want      []int
+		expectErr bool
+	}{
+		"text uses code set b":       {data: "PJJ123C", want: []int{104, 48, 42, 42, 17, 18, 19, 35, 55, 106}},
+		"even digits use code set c": {data: "123456", want: []int{105, 12, 34, 56, 44, 106}},
+		"odd digits fall back to b":  {data: "123", want: []int{104, 17, 18, 19, 8, 106}},
+		"empty data":                 {data: "", expectErr: true},
+		"non printable character":    {data: "AB\n", expectErr: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			symbols, err := barcode.Symbols(tc.data)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, symbols)
+		})
+	}
+}
+
+func Test_Encode(t *testing.T) {
+	modules, err := barcode.Encode("123456")
+	assert.NoError(t, err)
+	// Five 11-module symbols and the 13-module stop.
+	assert.Len(t, modules, 5*11+13)
+	assert.True(t, modules[0])
+	assert.True(t, modules[len(modules)-1])
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/spf13/viper"
 )
@@ -17,6 +18,40 @@ type Config struct {
 	ClientID     string `mapstructure:"GOOGLE_CLIENT_ID" validate:"required"`
 	ClientSecret string `mapstructure:"GOOGLE_CLIENT_SECRET" validate:"required"`
 	RedirectURL  string `mapstructure:"GOOGLE_REDIRECT_URL" validate:"required"`
+
+	ReservationTTLMinutes int `mapstructure:"RESERVATION_TTL_MINUTES"`
+
+	PaymentGateway    string `mapstructure:"PAYMENT_GATEWAY"`
+	RazorpayKeyID     string `mapstructure:"RAZORPAY_KEY_ID"`
+	RazorpayKeySecret string `mapstructure:"RAZORPAY_KEY_SECRET"`
+	// RazorpayWebhookSecret is the secret set on the webhook in the Razorpay
+	// dashboard; it differs from the API key secret.
+	RazorpayWebhookSecret string `mapstructure:"RAZORPAY_WEBHOOK_SECRET"`
+
+	// SellerGSTIN is printed on invoices. SellerState decides whether a sale
+	// is taxed as CGST and SGST or as IGST; it defaults to the GSTIN's state.
+	SellerGSTIN string `mapstructure:"SELLER_GSTIN"`
+	SellerState string `mapstructure:"SELLER_STATE"`
+
+	// ShippingProvider selects the courier: "local" (the default) quotes
+	// from the built-in rate card, "fake" is an in-process stand-in.
+	// SellerPincode is where parcels ship from. With a webhook secret set
+	// the carrier's tracking events mark orders shipped and delivered.
+	ShippingProvider      string `mapstructure:"SHIPPING_PROVIDER"`
+	SellerPincode         string `mapstructure:"SELLER_PINCODE"`
+	ShippingWebhookSecret string `mapstructure:"SHIPPING_WEBHOOK_SECRET"`
+	// DispatchCutoff is the "HH:MM" (Indian time) after which orders leave
+	// the warehouse the next working day. It defaults to 14:00.
+	DispatchCutoff string `mapstructure:"DISPATCH_CUTOFF"`
+
+	// Wallet top-ups, in whole rupees: the smallest and largest single
+	// top-up and the most a customer can add in a day (Indian time).
+	WalletTopUpMin      int `mapstructure:"WALLET_TOPUP_MIN"`
+	WalletTopUpMax      int `mapstructure:"WALLET_TOPUP_MAX"`
+	WalletTopUpDailyCap int `mapstructure:"WALLET_TOPUP_DAILY_CAP"`
+	// WalletAdjustmentApprovalThreshold is the largest admin wallet
+	// adjustment, in whole rupees, applied without a second admin's approval.
+	WalletAdjustmentApprovalThreshold int `mapstructure:"WALLET_ADJUSTMENT_APPROVAL_THRESHOLD"`
 }
 
 func LoadConfig() (Config, error) {
@@ -65,7 +100,26 @@ func LoadConfig() (Config, error) {
 			ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
 			ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
 			RedirectURL:  os.Getenv("GOOGLE_REDIRECT_URL"),
+
+			PaymentGateway:    os.Getenv("PAYMENT_GATEWAY"),
+			RazorpayKeyID:     os.Getenv("RAZORPAY_KEY_ID"),
+			RazorpayKeySecret: os.Getenv("RAZORPAY_KEY_SECRET"),
+
+			RazorpayWebhookSecret: os.Getenv("RAZORPAY_WEBHOOK_SECRET"),
+
+			SellerGSTIN: os.Getenv("SELLER_GSTIN"),
+			SellerState: os.Getenv("SELLER_STATE"),
+
+			ShippingProvider:      os.Getenv("SHIPPING_PROVIDER"),
+			SellerPincode:         os.Getenv("SELLER_PINCODE"),
+			ShippingWebhookSecret: os.Getenv("SHIPPING_WEBHOOK_SECRET"),
+			DispatchCutoff:        os.Getenv("DISPATCH_CUTOFF"),
 		}
+		config.ReservationTTLMinutes, _ = strconv.Atoi(os.Getenv("RESERVATION_TTL_MINUTES"))
+		config.WalletTopUpMin, _ = strconv.Atoi(os.Getenv("WALLET_TOPUP_MIN"))
+		config.WalletTopUpMax, _ = strconv.Atoi(os.Getenv("WALLET_TOPUP_MAX"))
+		config.WalletTopUpDailyCap, _ = strconv.Atoi(os.Getenv("WALLET_TOPUP_DAILY_CAP"))
+		config.WalletAdjustmentApprovalThreshold, _ = strconv.Atoi(os.Getenv("WALLET_ADJUSTMENT_APPROVAL_THRESHOLD"))
 
 		fmt.Println(config)
 		return config, nil
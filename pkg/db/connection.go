@@ -4,6 +4,7 @@ import (
 	"ecommerce_clean_arch/pkg/config"
 	"ecommerce_clean_arch/pkg/domain"
 	"ecommerce_clean_arch/pkg/helper"
+	"ecommerce_clean_arch/pkg/money"
 	"ecommerce_clean_arch/pkg/utils/models"
 	"fmt"
 	"log"
@@ -21,19 +22,67 @@ func ConnectDatabase(cfg config.Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	err = db.AutoMigrate(
+	if err := Migrate(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrate brings the schema up to date and seeds the default delivery rules
+// and admin. It is safe to run against a database that is already migrated.
+func Migrate(db *gorm.DB) error {
+	if err := migrateMoneyToPaise(db); err != nil {
+		return err
+	}
+
+	// Cart and order lines from before variants have a NULL variant_id,
+	// which the variant_id = 0 lookups for plain products never match.
+	for _, table := range []string{"carts", "order_items"} {
+		if !db.Migrator().HasColumn(table, "variant_id") {
+			continue
+		}
+		if err := db.Exec(fmt.Sprintf(`UPDATE %q SET variant_id = 0 WHERE variant_id IS NULL`, table)).Error; err != nil {
+			return err
+		}
+	}
+
+	err := db.AutoMigrate(
 		&domain.AdminDetails{},
 		&domain.Address{},
 		&domain.Category{},
 		&domain.Products{},
+		&domain.ProductVariant{},
+		&domain.VariantImage{},
 		&domain.Review{},
 		&domain.Cart{},
 		&domain.PaymentMethod{},
 		&domain.RazorPay{},
+		&domain.PaymentEvent{},
+		&domain.Refund{},
+		&domain.Exchange{},
+		&domain.ReturnRequest{},
+		&domain.Invoice{},
+		&domain.CreditNote{},
+		&domain.CreditNoteLine{},
+		&domain.DocumentSequence{},
+		&domain.Shipment{},
+		&domain.ShipmentEvent{},
+		&domain.ServiceablePincode{},
+		&domain.DeliveryRule{},
 		&models.Order{},
 		&domain.OrderItem{},
+		&domain.StockReservation{},
+		&domain.OrderStatusHistory{},
+		&domain.IdempotencyKey{},
 		&domain.Wallet{},
 		&domain.WalletTransaction{},
+		&domain.WalletTopUp{},
+		&domain.WalletAdjustment{},
+		&domain.GiftCard{},
+		&domain.GiftCardTransaction{},
+		&domain.LedgerAccount{},
+		&domain.JournalEntry{},
+		&domain.Posting{},
 		&domain.Coupons{},
 		&domain.Wishlist{},
 		&models.User{},
@@ -41,7 +90,51 @@ func ConnectDatabase(cfg config.Config) (*gorm.DB, error) {
 		&models.TempUser{},
 	)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	// GIN index backing the keyword search in ProductRepository.SearchProducts;
+	// the expression must match productSearchVector exactly to be used.
+	err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_products_search ON products
+		USING GIN (to_tsvector('english', coalesce(name, '') || ' ' || coalesce(description, '')))`).Error
+	if err != nil {
+		return err
+	}
+
+	// Older builds wrote "return" and "Failed"; the order state machine only
+	// knows the statuses in models/const.go.
+	err = db.Exec(`UPDATE orders SET order_status = CASE order_status WHEN 'return' THEN 'returned' ELSE 'failed' END
+		WHERE order_status IN ('return', 'Failed')`).Error
+	if err != nil {
+		return err
+	}
+
+	// Order lines placed before lines had their own status follow their order.
+	err = db.Exec(`UPDATE order_items SET status = orders.order_status FROM orders
+		WHERE orders.order_id = order_items.order_id AND (order_items.status IS NULL OR order_items.status = '')`).Error
+	if err != nil {
+		return err
+	}
+
+	if err := openWalletLedger(db); err != nil {
+		return err
+	}
+
+	// Delivery used to be free from ₹1000 with no cash on delivery above it;
+	// those become the first delivery rules, which admins can then change.
+	// Cart values are compared in paise, so "above ₹1000" starts a paisa up.
+	var ruleCount int64
+	if err := db.Unscoped().Model(&domain.DeliveryRule{}).Count(&ruleCount).Error; err != nil {
+		return err
+	}
+	if ruleCount == 0 {
+		defaultRules := []domain.DeliveryRule{
+			{Name: "Free delivery from ₹1000", Priority: 100, Active: true, MinCartValue: money.Rupees(1000), Action: "free_shipping"},
+			{Name: "No cash on delivery above ₹1000", Priority: 100, Active: true, MinCartValue: money.Rupees(1000).Add(money.Paise(1)), Action: "block_cod"},
+		}
+		if err := db.Create(&defaultRules).Error; err != nil {
+			return err
+		}
 	}
 
 	log.Println("✅ Database migrated successfully!")
@@ -74,6 +167,6 @@ func ConnectDatabase(cfg config.Config) (*gorm.DB, error) {
 		log.Println("✅ Admin already exists, skipping insert.")
 	}
 
-	return db, nil
+	return nil
 }
 
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/ledger"
+	"ecommerce_clean_arch/pkg/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const walletLedgerOpening = "wallet_ledger_opening"
+
+// openWalletLedger gives every wallet that had a balance before the ledger
+// an opening entry for it, so the ledger and the cached balances agree
+// from the start.
+func openWalletLedger(db *gorm.DB) error {
+	var applied int64
+	if err := db.Model(&domain.SchemaMigration{}).Where("name = ?", walletLedgerOpening).Count(&applied).Error; err != nil {
+		return err
+	}
+	if applied > 0 {
+		return nil
+	}
+
+	ledgerRepo := repository.NewLedgerRepository(db)
+	return db.Transaction(func(tx *gorm.DB) error {
+		var wallets []domain.Wallet
+		if err := tx.Where("balance <> 0").Find(&wallets).Error; err != nil {
+			return err
+		}
+		for _, wallet := range wallets {
+			userID := int(wallet.UserID)
+			lines := ledger.Credit(userID, ledger.AccountOpeningBalances, wallet.Balance)
+			_, err := ledgerRepo.Post(tx, ledger.Source{
+				Type:        ledger.SourceOpening,
+				ID:          int(wallet.WalletID),
+				Description: "balance before the ledger",
+			}, lines)
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Create(&domain.SchemaMigration{Name: walletLedgerOpening, AppliedAt: time.Now()}).Error
+	})
+}
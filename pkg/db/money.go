@@ -0,0 +1,84 @@
+package db
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const moneyToPaise = "money_to_paise"
+
+// moneyColumns are the amounts that used to be stored in rupees, as
+// double precision or, for the wallet and coupon limits, as whole rupees.
+var moneyColumns = map[string][]string{
+	"orders":              {"raw_total", "discount_amount", "category_discount", "grand_total", "delivery_charge", "final_price", "wallet_amount"},
+	"order_items":         {"price", "total_price", "category_discount", "coupon_discount", "taxable_value", "cgst", "sgst", "igst"},
+	"carts":               {"price", "category_discount", "offer_price", "total_price"},
+	"wallets":             {"balance"},
+	"wallet_transactions": {"credit", "debit", "total_amount"},
+	"coupons":             {"minimum_required", "maximum_allowed"},
+	"refunds":             {"amount"},
+	"exchanges":           {"price_difference"},
+	"invoices":            {"taxable_value", "cgst", "sgst", "igst", "total"},
+	"credit_notes":        {"taxable_value", "cgst", "sgst", "igst", "delivery_charge", "total"},
+	"credit_note_lines":   {"taxable_value", "cgst", "sgst", "igst"},
+	"shipments":           {"freight", "cod_charge", "cod_amount"},
+	"products":            {"price", "offer_price"},
+	"product_variants":    {"price_override"},
+	"categories":          {"gst_threshold"},
+	"delivery_rules":      {"min_cart_value", "max_cart_value", "amount"},
+}
+
+// migrateMoneyToPaise converts the amounts of a database created by an
+// older build from rupees to bigint paise. It must run before AutoMigrate,
+// which would otherwise cast the rupee columns to bigint and drop the
+// paise. A new database has nothing to convert and is only marked done.
+func migrateMoneyToPaise(db *gorm.DB) error {
+	if err := db.AutoMigrate(&domain.SchemaMigration{}); err != nil {
+		return err
+	}
+	var applied int64
+	if err := db.Model(&domain.SchemaMigration{}).Where("name = ?", moneyToPaise).Count(&applied).Error; err != nil {
+		return err
+	}
+	if applied > 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := splitPercentFees(tx); err != nil {
+			return err
+		}
+		for table, columns := range moneyColumns {
+			for _, column := range columns {
+				if !tx.Migrator().HasColumn(table, column) {
+					continue
+				}
+				err := tx.Exec(fmt.Sprintf(`ALTER TABLE %q ALTER COLUMN %q TYPE bigint USING round(%q * 100)::bigint`,
+					table, column, column)).Error
+				if err != nil {
+					return fmt.Errorf("failed to convert %s.%s to paise: %w", table, column, err)
+				}
+			}
+		}
+		return tx.Create(&domain.SchemaMigration{Name: moneyToPaise, AppliedAt: time.Now()}).Error
+	})
+}
+
+// splitPercentFees moves the percentage of percent_fee delivery rules out of
+// amount, which now only holds the flat fee, into its own column.
+func splitPercentFees(tx *gorm.DB) error {
+	if !tx.Migrator().HasColumn("delivery_rules", "amount") {
+		return nil
+	}
+	if err := tx.Exec(`ALTER TABLE delivery_rules ADD COLUMN IF NOT EXISTS percent double precision NOT NULL DEFAULT 0`).Error; err != nil {
+		return err
+	}
+	err := tx.Exec(`UPDATE delivery_rules SET percent = amount, amount = 0 WHERE action = 'percent_fee'`).Error
+	if err != nil {
+		return fmt.Errorf("failed to move percent fees out of delivery_rules.amount: %w", err)
+	}
+	return nil
+}
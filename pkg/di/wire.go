@@ -5,7 +5,9 @@ import (
 	"ecommerce_clean_arch/pkg/api/handlers"
 	"ecommerce_clean_arch/pkg/config"
 	"ecommerce_clean_arch/pkg/db"
+	"ecommerce_clean_arch/pkg/gateway"
 	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/shipping"
 	"ecommerce_clean_arch/pkg/usecase"
 	"log"
 
@@ -16,6 +18,8 @@ import (
 func InitializeAPI(cfg config.Config) (*api.ServerHTTP, error) {
 	wire.Build(
 		db.ConnectDatabase,
+		gateway.New,
+		shipping.New,
 
 		// Repositories
 		repository.NewUserRepository,
@@ -29,6 +33,15 @@ func InitializeAPI(cfg config.Config) (*api.ServerHTTP, error) {
 		repository.NewOrderRepository,
 		repository.NewReviewRepository,
 		repository.NewPaymentRepository,
+		repository.NewReservationRepository,
+		repository.NewIdempotencyRepository,
+		repository.NewRefundRepository,
+		repository.NewOrderStatusRepository,
+		repository.NewExchangeRepository,
+		repository.NewReturnRepository,
+		repository.NewInvoiceRepository,
+		repository.NewShippingRepository,
+		repository.NewDeliveryRuleRepository,
 
 		// Use Cases
 		usecase.NewUserUseCase,
@@ -42,6 +55,14 @@ func InitializeAPI(cfg config.Config) (*api.ServerHTTP, error) {
 		usecase.NewOrderUseCase,
 		usecase.NewReviewUseCase,
 		usecase.NewPaymentUsecase,
+		usecase.NewReservationUseCase,
+		usecase.NewIdempotencyUseCase,
+		usecase.NewRefundUseCase,
+		usecase.NewOrderStatusUseCase,
+		usecase.NewExchangeUseCase,
+		usecase.NewInvoiceUseCase,
+		usecase.NewShippingUseCase,
+		usecase.NewDeliveryRuleUseCase,
 
 		// Handlers
 		handlers.NewUserHandler,
@@ -5,9 +5,12 @@ import (
 	"ecommerce_clean_arch/pkg/api/handlers"
 	"ecommerce_clean_arch/pkg/config"
 	"ecommerce_clean_arch/pkg/db"
+	"ecommerce_clean_arch/pkg/gateway"
 	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/shipping"
 	"ecommerce_clean_arch/pkg/usecase"
 	"os"
+	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -21,12 +24,41 @@ func InitializeServer(cfg config.Config) (*api.ServerHTTP, error) {
 
 	// Initialization
 	userRepo := repository.NewUserRepository(database)
-	userUseCase := usecase.NewUserUseCase(userRepo)
+
+	orderStatusRepo := repository.NewOrderStatusRepository(database)
+	orderStatusUseCase := usecase.NewOrderStatusUseCase(*orderStatusRepo)
+
+	paymentGateway, err := gateway.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	shippingProvider, err := shipping.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	orderRepo := repository.NewOrderRepository(database)
+	shippingRepo := repository.NewShippingRepository(database)
+	shippingUseCase := usecase.NewShippingUseCase(*shippingRepo, *orderRepo, *orderStatusUseCase, shippingProvider, cfg)
+
+	userUseCase := usecase.NewUserUseCase(userRepo, shippingUseCase)
 	userHandler := handlers.NewUserHandler(*userUseCase)
 
-	adminRepo := repository.NewAdminRepository(database)
-	adminUseCase := usecase.NewAdminUseCase(*adminRepo)
-	adminHandler := handlers.NewAdminHandler(*adminUseCase)
+	walletRepo := repository.NewWalletRepository(database)
+	walletUseCase := usecase.NewWalletUseCase(*walletRepo, paymentGateway, cfg)
+	walletHandler := handlers.NewWalletHandler(*walletUseCase)
+
+	giftCardRepo := repository.NewGiftCardRepository(database)
+	giftCardUseCase := usecase.NewGiftCardUseCase(*giftCardRepo, paymentGateway)
+	giftCardHandler := handlers.NewGiftCardHandler(*giftCardUseCase)
+
+	refundRepo := repository.NewRefundRepository(database)
+	refundUseCase := usecase.NewRefundUseCase(*refundRepo, *walletRepo, *giftCardRepo, paymentGateway)
+
+	reservationRepo := repository.NewReservationRepository(database)
+	reservationUseCase := usecase.NewReservationUseCase(*reservationRepo, *orderStatusUseCase, *refundUseCase, cfg)
+	reservationUseCase.StartSweeper(time.Minute)
 
 	categoryRepo := repository.NewCategoryRepository(database)
 	categoryUseCase := usecase.NewCategoryUseCase(*categoryRepo)
@@ -40,10 +72,6 @@ func InitializeServer(cfg config.Config) (*api.ServerHTTP, error) {
 	cartUseCase := usecase.NewCartUseCase(*cartRepo, *productRepo, *categoryRepo)
 	cartHandler := handlers.NewCartHandler(*cartUseCase)
 
-	walletRepo := repository.NewWalletRepository(database)
-	walletUseCase := usecase.NewWalletUseCase(*walletRepo)
-	walletHandler := handlers.NewWalletHandler(*walletUseCase)
-
 	couponRepo := repository.NewCouponRepository(database)
 	couponUseCase := usecase.NewCouponUseCase(*couponRepo)
 	couponHandler := handlers.NewCouponHandler(*couponUseCase)
@@ -52,18 +80,33 @@ func InitializeServer(cfg config.Config) (*api.ServerHTTP, error) {
 	wishlistUseCase := usecase.NewWishlistUseCase(*wishlistRepo)
 	wishlistHandler := handlers.NewWishlistHandler(*wishlistUseCase)
 
-	orderRepo := repository.NewOrderRepository(database)
-	orderUseCase := usecase.NewOrderUseCase(*orderRepo, *userRepo, *cartRepo, *walletRepo, *walletUseCase, *couponRepo)
+	exchangeRepo := repository.NewExchangeRepository(database)
+	exchangeUseCase := usecase.NewExchangeUseCase(*exchangeRepo, *orderRepo, *productRepo, *categoryRepo, *walletRepo, *orderStatusUseCase, *refundUseCase, paymentGateway, cfg)
+	exchangeUseCase.StartSweeper(time.Minute)
+
+	returnRepo := repository.NewReturnRepository(database)
+	invoiceRepo := repository.NewInvoiceRepository(database)
+	invoiceUseCase := usecase.NewInvoiceUseCase(*invoiceRepo, *orderRepo, *orderStatusRepo, cfg)
+	deliveryRuleRepo := repository.NewDeliveryRuleRepository(database)
+	deliveryRuleUseCase := usecase.NewDeliveryRuleUseCase(*deliveryRuleRepo)
+	orderUseCase := usecase.NewOrderUseCase(*orderRepo, *userRepo, *cartRepo, *walletRepo, *walletUseCase, *giftCardUseCase, *couponRepo, *reservationUseCase, *refundUseCase, *orderStatusUseCase, *exchangeUseCase, *returnRepo, *invoiceUseCase, *shippingUseCase, *deliveryRuleUseCase, cfg)
 	orderHandler := handlers.NewOrderHandler(*orderUseCase)
 
+	adminRepo := repository.NewAdminRepository(database)
+	adminUseCase := usecase.NewAdminUseCase(*adminRepo, *reservationUseCase, *refundUseCase, *orderStatusUseCase, *exchangeUseCase, *orderUseCase, *walletUseCase)
+	adminHandler := handlers.NewAdminHandler(*adminUseCase)
+
 	reviewRepo := repository.NewReviewRepository(database)
 	reviewUseCase := usecase.NewReviewUseCase(*reviewRepo)
 	reviewHandler := handlers.NewReviewHandler(*reviewUseCase)
 
 	paymentRepo := repository.NewPaymentRepository(database)
-	paymentUseCase := usecase.NewPaymentUsecase(*paymentRepo)
+	paymentUseCase := usecase.NewPaymentUsecase(*paymentRepo, *reservationUseCase, *refundUseCase, *orderStatusUseCase, paymentGateway, *walletUseCase, *giftCardUseCase, *exchangeUseCase)
 	paymentHandler := handlers.NewPaymentHandler(*paymentUseCase)
 
+	idempotencyRepo := repository.NewIdempotencyRepository(database)
+	idempotencyUseCase := usecase.NewIdempotencyUseCase(*idempotencyRepo)
+
 	oauthConfig := &oauth2.Config{
 		ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
 		ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
@@ -76,7 +119,7 @@ func InitializeServer(cfg config.Config) (*api.ServerHTTP, error) {
 	authHandler := handlers.NewAuthHandler(authUseCase)
 
 	server := api.NewServerHTTP(userHandler, authHandler, adminHandler, categoryHandler, productHandler, reviewHandler, cartHandler, orderHandler,
-		paymentHandler, walletHandler, wishlistHandler, couponHandler)
+		paymentHandler, walletHandler, giftCardHandler, wishlistHandler, couponHandler, idempotencyUseCase)
 
 	return server, nil
 }
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"ecommerce_clean_arch/pkg/money"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,11 +11,12 @@ type Cart struct {
 	ID               int            `json:"id"`
 	UserID           int            `json:"user_id"`
 	ProductID        int            `json:"product_id"`
+	VariantID        int            `json:"variant_id" gorm:"not null;default:0"`
 	Quantity         int            `json:"quantity"`
-	Price            float64        `json:"price"`
-	CategoryDiscount float64        `json:"category_discount"`
-	OfferPrice       float64        `json:"offer_price"`
-	TotalPrice       float64        `json:"total_price"`
+	Price            money.Money    `json:"price"`
+	CategoryDiscount money.Money    `json:"category_discount"`
+	OfferPrice       money.Money    `json:"offer_price"`
+	TotalPrice       money.Money    `json:"total_price"`
 	CreatedAt        time.Time      `json:"created_at"`
 	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
@@ -1,8 +1,19 @@
 package domain
 
+import "ecommerce_clean_arch/pkg/money"
+
+// Category carries the GST treatment of the products in it. GSTRate is a
+// percentage; when GSTThreshold and GSTHigherRate are set, units priced
+// above the threshold are taxed at the higher rate instead. WeightGrams is
+// the packed weight of one unit, used to quote shipping.
 type Category struct {
-	ID               int    `json:"id" gorm:"primarykey;not null"`
-	Category         string `json:"category"`
-	Description      string `json:"description"`
-	CategoryDiscount int    `json:"category_discount"`
+	ID               int         `json:"id" gorm:"primarykey;not null"`
+	Category         string      `json:"category"`
+	Description      string      `json:"description"`
+	CategoryDiscount int         `json:"category_discount"`
+	HSNCode          string      `json:"hsn_code"`
+	GSTRate          float64     `json:"gst_rate"`
+	GSTHigherRate    float64     `json:"gst_higher_rate"`
+	GSTThreshold     money.Money `json:"gst_threshold"`
+	WeightGrams      int         `json:"weight_grams"`
 }
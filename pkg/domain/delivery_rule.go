@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeliveryRule prices delivery or decides cash on delivery for the carts
+// that meet all of its conditions. A zero or empty condition matches every
+// cart. Rules are tried in ascending Priority.
+type DeliveryRule struct {
+	ID       int    `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name     string `json:"name" gorm:"not null"`
+	Priority int    `json:"priority" gorm:"index;not null"`
+	Active   bool   `json:"active"`
+
+	// MinCartValue and MaxCartValue bound the cart value, both inclusive;
+	// a zero MaxCartValue has no upper bound. So do MinItems and MaxItems
+	// for the number of units.
+	MinCartValue   money.Money `json:"min_cart_value"`
+	MaxCartValue   money.Money `json:"max_cart_value"`
+	MinItems       int         `json:"min_items"`
+	MaxItems       int         `json:"max_items"`
+	Zones          []string    `json:"zones" gorm:"serializer:json"`
+	UserTiers      []string    `json:"user_tiers" gorm:"serializer:json"`
+	PaymentMethods []string    `json:"payment_methods" gorm:"serializer:json"`
+
+	// Action is one of flat_fee, percent_fee, free_shipping, block_cod and
+	// allow_cod. Amount is the fee for flat_fee and Percent the percentage
+	// of the cart value charged for percent_fee.
+	Action  string      `json:"action" gorm:"not null"`
+	Amount  money.Money `json:"amount"`
+	Percent float64     `json:"percent"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"time"
+)
+
+// Exchange is a customer's request to swap a delivered order line for
+// another size of the same product. The replacement size is held from the
+// moment the request is made. PriceDifference is what the customer owes
+// for the new size, negative when they are owed money back. A difference
+// paid through the gateway must arrive by PaymentDueBy, or the hold lapses.
+type Exchange struct {
+	ID                 int         `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID            int         `json:"order_id" gorm:"index;not null"`
+	OrderItemID        int         `json:"order_item_id" gorm:"index;not null"`
+	UserID             int         `json:"user_id" gorm:"index;not null"`
+	ProductID          int         `json:"product_id" gorm:"not null"`
+	FromVariantID      int         `json:"from_variant_id" gorm:"not null"`
+	ToVariantID        int         `json:"to_variant_id" gorm:"not null"`
+	Quantity           int         `json:"quantity" gorm:"not null"`
+	PriceDifference    money.Money `json:"price_difference"`
+	SettleWith         string      `json:"settle_with"`
+	PaymentStatus      string      `json:"payment_status"`
+	GatewayOrderID     string      `json:"gateway_order_id" gorm:"index"`
+	GatewayPaymentID   string      `json:"gateway_payment_id"`
+	PaymentDueBy       *time.Time  `json:"payment_due_by" gorm:"index"`
+	RefundID           int         `json:"refund_id"`
+	ReplacementOrderID int         `json:"replacement_order_id"`
+	Status             string      `json:"status" gorm:"index;not null"`
+	Reason             string      `json:"reason"`
+	AdminNote          string      `json:"admin_note"`
+	CreatedAt          time.Time   `json:"created_at"`
+	UpdatedAt          time.Time   `json:"updated_at"`
+	ResolvedAt         *time.Time  `json:"resolved_at"`
+}
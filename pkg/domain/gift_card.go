@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"time"
+)
+
+// GiftCard is a prepaid card bought by one customer for another. Only the
+// hash of its code is kept; the code itself is emailed to the recipient
+// once the card is paid for. Balance goes down as orders are paid with the
+// card and back up when they are cancelled or returned.
+type GiftCard struct {
+	ID               int         `json:"id" gorm:"primaryKey;autoIncrement"`
+	CodeHash         string      `json:"-" gorm:"uniqueIndex:idx_gift_cards_code_hash,where:code_hash <> ''"`
+	Last4            string      `json:"last4"`
+	InitialAmount    money.Money `json:"initial_amount" gorm:"not null"`
+	Balance          money.Money `json:"balance" gorm:"not null"`
+	PurchaserID      int         `json:"purchaser_id" gorm:"index;not null"`
+	RecipientName    string      `json:"recipient_name"`
+	RecipientEmail   string      `json:"recipient_email" gorm:"not null"`
+	Message          string      `json:"message"`
+	Status           string      `json:"status" gorm:"index;not null"`
+	GatewayOrderID   string      `json:"gateway_order_id" gorm:"index"`
+	GatewayPaymentID string      `json:"gateway_payment_id"`
+	CreatedAt        time.Time   `json:"created_at"`
+	ActivatedAt      *time.Time  `json:"activated_at"`
+	ExpiresAt        *time.Time  `json:"expires_at"`
+	EmailedAt        *time.Time  `json:"emailed_at"`
+}
+
+// GiftCardTransaction is a change to a gift card's balance: its issue, a
+// redemption on an order (negative) or a refund back to it.
+type GiftCardTransaction struct {
+	ID         int         `json:"id" gorm:"primaryKey;autoIncrement"`
+	GiftCardID int         `json:"gift_card_id" gorm:"index;not null"`
+	OrderID    int         `json:"order_id" gorm:"index"`
+	RefundID   int         `json:"refund_id"`
+	Amount     money.Money `json:"amount" gorm:"not null"`
+	Balance    money.Money `json:"balance" gorm:"not null"`
+	Reason     string      `json:"reason"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
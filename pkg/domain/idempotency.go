@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// IdempotencyKey stores the first response sent for a client supplied
+// Idempotency-Key so retries of the same request can be replayed.
+type IdempotencyKey struct {
+	ID           int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Key          string    `json:"key" gorm:"column:idempotency_key;size:255;not null;uniqueIndex:idx_idempotency_key_scope"`
+	UserID       int       `json:"user_id" gorm:"not null;uniqueIndex:idx_idempotency_key_scope"`
+	Scope        string    `json:"scope" gorm:"size:64;not null;uniqueIndex:idx_idempotency_key_scope"`
+	RequestHash  string    `json:"request_hash" gorm:"not null"`
+	StatusCode   int       `json:"status_code"`
+	ResponseBody string    `json:"response_body" gorm:"type:text"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
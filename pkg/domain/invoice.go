@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"time"
+)
+
+// Invoice is the tax invoice of an order. It is issued once, numbered from
+// a gap-free financial-year series, and its PDF is kept so every later
+// download returns the same document.
+type Invoice struct {
+	ID            int         `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID       int         `json:"order_id" gorm:"uniqueIndex;not null"`
+	Number        string      `json:"number" gorm:"uniqueIndex;not null"`
+	FinancialYear string      `json:"financial_year" gorm:"not null"`
+	Sequence      int         `json:"sequence" gorm:"not null"`
+	SellerGSTIN   string      `json:"seller_gstin"`
+	BuyerName     string      `json:"buyer_name"`
+	BuyerAddress  string      `json:"buyer_address"`
+	PlaceOfSupply string      `json:"place_of_supply"`
+	TaxableValue  money.Money `json:"taxable_value"`
+	CGST          money.Money `json:"cgst"`
+	SGST          money.Money `json:"sgst"`
+	IGST          money.Money `json:"igst"`
+	Total         money.Money `json:"total"`
+	PDF           []byte      `json:"-" gorm:"type:bytea"`
+	IssuedAt      time.Time   `json:"issued_at"`
+}
+
+// CreditNote reverses the part of an invoice that was later cancelled or
+// returned. It has its own series and, like the invoice, keeps its PDF.
+type CreditNote struct {
+	ID             int              `json:"id" gorm:"primaryKey;autoIncrement"`
+	InvoiceID      int              `json:"invoice_id" gorm:"index;not null"`
+	OrderID        int              `json:"order_id" gorm:"index;not null"`
+	Number         string           `json:"number" gorm:"uniqueIndex;not null"`
+	FinancialYear  string           `json:"financial_year" gorm:"not null"`
+	Sequence       int              `json:"sequence" gorm:"not null"`
+	Reason         string           `json:"reason"`
+	TaxableValue   money.Money      `json:"taxable_value"`
+	CGST           money.Money      `json:"cgst"`
+	SGST           money.Money      `json:"sgst"`
+	IGST           money.Money      `json:"igst"`
+	DeliveryCharge money.Money      `json:"delivery_charge"`
+	Total          money.Money      `json:"total"`
+	Lines          []CreditNoteLine `json:"lines" gorm:"foreignKey:CreditNoteID"`
+	PDF            []byte           `json:"-" gorm:"type:bytea"`
+	IssuedAt       time.Time        `json:"issued_at"`
+}
+
+// CreditNoteLine is an order line credited by a credit note. An order line
+// is credited at most once.
+type CreditNoteLine struct {
+	ID           int         `json:"id" gorm:"primaryKey;autoIncrement"`
+	CreditNoteID int         `json:"credit_note_id" gorm:"index;not null"`
+	OrderItemID  int         `json:"order_item_id" gorm:"uniqueIndex;not null"`
+	Description  string      `json:"description"`
+	Quantity     int         `json:"quantity"`
+	HSNCode      string      `json:"hsn_code"`
+	GSTRate      float64     `json:"gst_rate"`
+	TaxableValue money.Money `json:"taxable_value"`
+	CGST         money.Money `json:"cgst"`
+	SGST         money.Money `json:"sgst"`
+	IGST         money.Money `json:"igst"`
+}
+
+// DocumentSequence is the last number used in a document series for a
+// financial year.
+type DocumentSequence struct {
+	Series        string `gorm:"primaryKey"`
+	FinancialYear string `gorm:"primaryKey"`
+	LastNumber    int    `gorm:"not null"`
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"time"
+)
+
+// LedgerAccount is an account of the wallet ledger: a customer's wallet
+// ("wallet:42") or one of the store's own accounts ("sales").
+type LedgerAccount struct {
+	ID        int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Code      string    `json:"code" gorm:"uniqueIndex;not null"`
+	Kind      string    `json:"kind" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// JournalEntry is one movement of money between ledger accounts. Entries
+// and their postings are only ever added, never changed.
+type JournalEntry struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	SourceType  string    `json:"source_type" gorm:"index:idx_journal_entries_source;not null"`
+	SourceID    int       `json:"source_id" gorm:"index:idx_journal_entries_source"`
+	Description string    `json:"description"`
+	Postings    []Posting `json:"postings" gorm:"foreignKey:JournalEntryID"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Posting is one side of a journal entry. Debits are positive and credits
+// negative; the postings of an entry add up to zero.
+type Posting struct {
+	ID             int         `json:"id" gorm:"primaryKey;autoIncrement"`
+	JournalEntryID int         `json:"journal_entry_id" gorm:"index;not null"`
+	AccountID      int         `json:"account_id" gorm:"index;not null"`
+	Account        string      `json:"account" gorm:"-"`
+	Amount         money.Money `json:"amount" gorm:"not null"`
+}
@@ -1,40 +1,61 @@
 package domain
 
-import "time"
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"time"
+)
 
 type Order struct {
-	OrderId          int        `gorm:"primaryKey;autoIncrement" json:"order_id"`
-	UserID           int        `json:"user_id" gorm:"not null"`
-	Users            Users      `json:"-" gorm:"foreignkey:UserID"`
-	AddressID        uint       `json:"address_id"`
-	Address          Address    `json:"-" gorm:"foreignkey:AddressID"`
-	CouponID         int        `json:"coupon_id"`
-	Coupon           Coupons    `json:"-" gorm:"foreignkey:CouponID"`
-	CouponCode       string     `json:"coupon_code"`
-	RawTotal         float64    `json:"raw_total"`
-	Discount         float64    `json:"discount"`
-	DiscountAmount   float64    `json:"discount_amount"`
-	CategoryDiscount float64    `json:"category_discount"`
-	GrandTotal       float64    `json:"grand_total"`
-	DeliveryCharge   float64    `json:"delivery_charge"`
-	PaymentStatus    string     `json:"payment_status"`
-	PaymentMethodID  uint       `json:"paymentmethod_id"`
-	OrderDate        time.Time  `json:"order_date"`
-	CreatedAt        time.Time  `json:"created_at"`
-	UpdatedAt        time.Time  `json:"updated_at"`
-	DeletedAt        *time.Time `json:"deleted_at" gorm:"index"`
-	OrderStatus      string     `json:"order_status"`
-	FinalPrice       float64    `json:"final_price"`
-	PaymentMethod    string     `json:"-" gorm:"foreignkey:PaymentMethodID"`
+	OrderId          int         `gorm:"primaryKey;autoIncrement" json:"order_id"`
+	UserID           int         `json:"user_id" gorm:"not null"`
+	Users            Users       `json:"-" gorm:"foreignkey:UserID"`
+	AddressID        uint        `json:"address_id"`
+	Address          Address     `json:"-" gorm:"foreignkey:AddressID"`
+	CouponID         int         `json:"coupon_id"`
+	Coupon           Coupons     `json:"-" gorm:"foreignkey:CouponID"`
+	CouponCode       string      `json:"coupon_code"`
+	RawTotal         money.Money `json:"raw_total"`
+	Discount         float64     `json:"discount"`
+	DiscountAmount   money.Money `json:"discount_amount"`
+	CategoryDiscount money.Money `json:"category_discount"`
+	GrandTotal       money.Money `json:"grand_total"`
+	DeliveryCharge   money.Money `json:"delivery_charge"`
+	PaymentStatus    string      `json:"payment_status"`
+	PaymentMethodID  uint        `json:"paymentmethod_id"`
+	OrderDate        time.Time   `json:"order_date"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	DeletedAt        *time.Time  `json:"deleted_at" gorm:"index"`
+	OrderStatus      string      `json:"order_status"`
+	FinalPrice       money.Money `json:"final_price"`
+	PaymentMethod    string      `json:"-" gorm:"foreignkey:PaymentMethodID"`
 }
+
+// OrderItem is one line of an order. CategoryDiscount and CouponDiscount are
+// the line's share of the order's discounts, fixed when the order is placed
+// so a line can be refunded on its own. Status follows the order until the
+// line is cancelled or returned by itself.
 type OrderItem struct {
-	ID         int      `gorm:"primaryKey;autoIncrement"`
-	OrderID    int      `json:"order_id" gorm:"column:order_id"`
-	Order      Order    `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
-	ProductID  int      `json:"product_id" gorm:"column:product_id"`
-	Product    Products `gorm:"foreignKey:ProductID"`
-	Quantity   int      `json:"quantity"`
-	TotalPrice float64  `json:"total_price"`
+	ID               int         `gorm:"primaryKey;autoIncrement"`
+	OrderID          int         `json:"order_id" gorm:"column:order_id"`
+	Order            Order       `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	ProductID        int         `json:"product_id" gorm:"column:product_id"`
+	Product          Products    `gorm:"foreignKey:ProductID"`
+	VariantID        int         `json:"variant_id" gorm:"column:variant_id;not null;default:0"`
+	Quantity         int         `json:"quantity"`
+	Price            money.Money `json:"price"`
+	TotalPrice       money.Money `json:"total_price"`
+	CategoryDiscount money.Money `json:"category_discount"`
+	CouponDiscount   money.Money `json:"coupon_discount"`
+	Status           string      `json:"status" gorm:"index"`
+	// The GST contained in what the customer paid for the line, fixed when
+	// the order is placed.
+	HSNCode      string      `json:"hsn_code"`
+	GSTRate      float64     `json:"gst_rate"`
+	TaxableValue money.Money `json:"taxable_value"`
+	CGST         money.Money `json:"cgst"`
+	SGST         money.Money `json:"sgst"`
+	IGST         money.Money `json:"igst"`
 }
 type OrderSuccessResponse struct {
 	OrderID     string `json:"order_id"`
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// OrderStatusHistory is one entry in an order's timeline. FromStatus is empty
+// for the entry written when the order is placed; OrderItemID is set when a
+// single line changed rather than the whole order.
+type OrderStatusHistory struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID     int       `json:"order_id" gorm:"index;not null"`
+	OrderItemID int       `json:"order_item_id,omitempty"`
+	FromStatus  string    `json:"from_status"`
+	ToStatus    string    `json:"to_status" gorm:"not null"`
+	Actor       string    `json:"actor" gorm:"not null"`
+	ActorID     int       `json:"actor_id"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (OrderStatusHistory) TableName() string {
+	return "order_status_history"
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "ecommerce_clean_arch/pkg/money"
+
 type PaymentMethod struct {
 	ID           uint   `gorm:"primarykey"`
 	Payment_Name string `json:"payment_name"`
@@ -11,4 +13,7 @@ type RazorPay struct {
 	Order     Order  `json:"-" gorm:"foreignkey:OrderID"`
 	RazorID   string `json:"razor_id"`
 	PaymentID string `json:"payment_id"`
+	// Amount is what the gateway order collects, so a payment that arrives
+	// after the order was cancelled can be refunded in full.
+	Amount money.Money `json:"amount"`
 }
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// PaymentEvent is a webhook delivery from the payment gateway. EventID is
+// unique so redeliveries of the same event are processed once.
+type PaymentEvent struct {
+	ID             int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	EventID        string     `json:"event_id" gorm:"size:255;not null;uniqueIndex"`
+	Event          string     `json:"event" gorm:"index;not null"`
+	GatewayOrderID string     `json:"gateway_order_id" gorm:"index"`
+	PaymentID      string     `json:"payment_id" gorm:"index"`
+	Payload        string     `json:"payload" gorm:"type:text"`
+	Status         string     `json:"status" gorm:"not null"`
+	Error          string     `json:"error"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ProcessedAt    *time.Time `json:"processed_at"`
+}
@@ -1,20 +1,44 @@
 package domain
 
 import (
+	"ecommerce_clean_arch/pkg/money"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type Products struct {
-	ID         int      `json:"id" gorm:"primaryKey;not null"`
-	CategoryID int      `json:"category_id" gorm:"column:category_id"`             
-	Category   Category `gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE"` 
-	Name       string   `json:"name" validate:"required"`
-	Stock      int      `json:"stock"`
-	Quantity   int      `json:"quantity"`
-	Price      float64  `json:"price"`
-	OfferPrice float64  `json:"offer_price"`
-	CreatedAt  time.Time
-	DeletedAt  gorm.DeletedAt `gorm:"index"`
+	ID          int         `json:"id" gorm:"primaryKey;not null"`
+	CategoryID  int         `json:"category_id" gorm:"column:category_id"`
+	Category    Category    `gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE"`
+	Name        string      `json:"name" validate:"required"`
+	Description string      `json:"description"`
+	Brand       string      `json:"brand" gorm:"index"`
+	Stock       int         `json:"stock"`
+	Quantity    int         `json:"quantity"`
+	Price       money.Money `json:"price"`
+	OfferPrice  money.Money `json:"offer_price"`
+	CreatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+type ProductVariant struct {
+	ID            int         `json:"id" gorm:"primaryKey;not null"`
+	ProductID     int         `json:"product_id" gorm:"column:product_id;index"`
+	Product       Products    `json:"-" gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	SKU           string      `json:"sku" gorm:"unique;not null"`
+	SizeSystem    string      `json:"size_system"`
+	Size          string      `json:"size"`
+	Colour        string      `json:"colour"`
+	Stock         int         `json:"stock"`
+	PriceOverride money.Money `json:"price_override"`
+	CreatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+}
+
+type VariantImage struct {
+	ID        int            `json:"id" gorm:"primaryKey;not null"`
+	VariantID int            `json:"variant_id" gorm:"column:variant_id;index"`
+	Variant   ProductVariant `json:"-" gorm:"foreignKey:VariantID;constraint:OnDelete:CASCADE"`
+	URL       string         `json:"url"`
 }
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"time"
+)
+
+// Refund tracks money going back to a customer for a cancelled or returned
+// order, or a single item of it when OrderItemID is set.
+type Refund struct {
+	ID               int         `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID          int         `json:"order_id" gorm:"index;not null"`
+	OrderItemID      int         `json:"order_item_id"`
+	UserID           int         `json:"user_id" gorm:"index;not null"`
+	Amount           money.Money `json:"amount" gorm:"not null"`
+	Destination      string      `json:"destination" gorm:"not null"`
+	Status           string      `json:"status" gorm:"index;not null"`
+	Reason           string      `json:"reason"`
+	GatewayPaymentID string      `json:"gateway_payment_id"`
+	GatewayRefundID  string      `json:"gateway_refund_id" gorm:"index"`
+	FailureReason    string      `json:"failure_reason"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	ProcessedAt      *time.Time  `json:"processed_at"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// StockReservation is a hold on stock taken when an online order is placed.
+// It is committed once payment is verified or released when the TTL expires.
+type StockReservation struct {
+	ID         int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID    int        `json:"order_id" gorm:"index;not null"`
+	ProductID  int        `json:"product_id" gorm:"not null"`
+	VariantID  int        `json:"variant_id"`
+	Quantity   int        `json:"quantity" gorm:"not null"`
+	Status     string     `json:"status" gorm:"index;not null"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"index"`
+	ReleasedAt *time.Time `json:"released_at"`
+	Reason     string     `json:"reason"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// ReturnRequest is a customer's request to send back a delivered order, or a
+// single line of it when OrderItemID is set. Nothing is refunded or
+// restocked until the parcel has been picked up and passed inspection.
+type ReturnRequest struct {
+	ID          int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID     int        `json:"order_id" gorm:"index;not null"`
+	OrderItemID int        `json:"order_item_id"`
+	UserID      int        `json:"user_id" gorm:"index;not null"`
+	ReasonCode  string     `json:"reason_code" gorm:"not null"`
+	Comments    string     `json:"comments"`
+	PhotoURL    string     `json:"photo_url"`
+	RefundTo    string     `json:"refund_to"`
+	Status      string     `json:"status" gorm:"index;not null"`
+	AdminNote   string     `json:"admin_note"`
+	RefundID    int        `json:"refund_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ApprovedAt  *time.Time `json:"approved_at"`
+	PickedUpAt  *time.Time `json:"picked_up_at"`
+	InspectedAt *time.Time `json:"inspected_at"`
+	ResolvedAt  *time.Time `json:"resolved_at"`
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+// SchemaMigration records a one-off data migration that has run, so it is
+// not run again on the next start.
+type SchemaMigration struct {
+	Name      string    `json:"name" gorm:"primaryKey"`
+	AppliedAt time.Time `json:"applied_at"`
+}
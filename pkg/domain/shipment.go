@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"time"
+)
+
+// Shipment is an order's parcel booked with a courier. Status follows the
+// carrier's tracking events.
+type Shipment struct {
+	ID          int         `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID     int         `json:"order_id" gorm:"uniqueIndex;not null"`
+	Provider    string      `json:"provider" gorm:"not null"`
+	AWB         string      `json:"awb" gorm:"uniqueIndex;not null"`
+	Zone        string      `json:"zone"`
+	WeightGrams int         `json:"weight_grams"`
+	Freight     money.Money `json:"freight"`
+	CODCharge   money.Money `json:"cod_charge"`
+	CODAmount   money.Money `json:"cod_amount"`
+	Status      string      `json:"status" gorm:"index;not null"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	DeliveredAt *time.Time  `json:"delivered_at"`
+}
+
+// ShipmentEvent is a tracking update received from the carrier. A status is
+// recorded once per shipment, so redelivered webhooks are ignored.
+type ShipmentEvent struct {
+	ID          int       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ShipmentID  int       `json:"shipment_id" gorm:"uniqueIndex:idx_shipment_event;not null"`
+	Status      string    `json:"status" gorm:"uniqueIndex:idx_shipment_event;not null"`
+	Location    string    `json:"location"`
+	Description string    `json:"description"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Tracking is a shipment with its tracking history.
+type Tracking struct {
+	Shipment Shipment        `json:"shipment"`
+	Events   []ShipmentEvent `json:"events"`
+}
+
+// ServiceablePincode says whether orders can be delivered to a pincode,
+// whether cash on delivery is accepted there and how many days delivery
+// takes.
+type ServiceablePincode struct {
+	Pincode     string    `json:"pincode" gorm:"primaryKey;size:6"`
+	Deliverable bool      `json:"deliverable"`
+	CODAllowed  bool      `json:"cod_allowed"`
+	ETADays     int       `json:"eta_days"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
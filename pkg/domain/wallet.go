@@ -1,19 +1,68 @@
 package domain
 
-import "time"
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"time"
+)
 
+// Wallet caches a customer's balance. The ledger is the record: Balance
+// changes with every posting to the wallet's account, in the same
+// transaction, and the ledger check compares the two.
 type Wallet struct {
 	WalletID uint  `gorm:"primaryKey;autoIncrement"`
 	UserID   uint  `gorm:"uniqueIndex"`
 	User     Users `gorm:"foreignkey:UserID;association_foreignkey:ID"`
-	Balance  uint
+	Balance  money.Money
 }
 
 type WalletTransaction struct {
-	TransactionID uint      `gorm:"primaryKey;autoIncrement" json:"transactionID"`
-	UserID        uint      `json:"userID"`
-	Credit        uint      `json:"credit,omitempty"`
-	Debit         uint      `json:"debit,omitempty"`
-	EventDate     time.Time `json:"eventDate"`
-	TotalAmount   uint      `json:"totalAmount"`
+	TransactionID uint        `gorm:"primaryKey;autoIncrement" json:"transactionID"`
+	UserID        uint        `json:"userID"`
+	Credit        money.Money `json:"credit,omitempty"`
+	Debit         money.Money `json:"debit,omitempty"`
+	EventDate     time.Time   `json:"eventDate"`
+	TotalAmount   money.Money `json:"totalAmount"`
+	// SourceType and SourceID name what moved the money, and JournalEntryID
+	// its entry in the ledger.
+	SourceType     string `json:"sourceType,omitempty"`
+	SourceID       int    `json:"sourceID,omitempty"`
+	JournalEntryID int    `json:"journalEntryID,omitempty" gorm:"index"`
+	// Actor and ActorID name the admin behind a manual adjustment; they are
+	// empty for money moved by the customer or the system.
+	Actor   string `json:"actor,omitempty"`
+	ActorID int    `json:"actorID,omitempty"`
+}
+
+// WalletTopUp is a customer adding money to their wallet through the
+// payment gateway. The wallet is credited once the payment is verified,
+// with a transaction whose source is the top-up.
+type WalletTopUp struct {
+	ID               int         `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID           int         `json:"user_id" gorm:"index;not null"`
+	Amount           money.Money `json:"amount" gorm:"not null"`
+	GatewayOrderID   string      `json:"gateway_order_id" gorm:"index"`
+	GatewayPaymentID string      `json:"gateway_payment_id"`
+	Status           string      `json:"status" gorm:"index;not null"`
+	CreatedAt        time.Time   `json:"created_at"`
+	CreditedAt       *time.Time  `json:"credited_at"`
+}
+
+// WalletAdjustment is an admin crediting or debiting a customer's wallet by
+// hand, such as goodwill credit or reversing a mistaken refund. One above
+// the approval threshold waits for a second admin before the money moves.
+type WalletAdjustment struct {
+	ID          int         `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      int         `json:"user_id" gorm:"index;not null"`
+	Direction   string      `json:"direction" gorm:"not null"`
+	Amount      money.Money `json:"amount" gorm:"not null"`
+	ReasonCode  string      `json:"reason_code" gorm:"not null"`
+	Note        string      `json:"note" gorm:"not null"`
+	OrderID     *int        `json:"order_id" gorm:"index"`
+	Status      string      `json:"status" gorm:"index;not null"`
+	RequestedBy int         `json:"requested_by" gorm:"not null"`
+	ReviewedBy  *int        `json:"reviewed_by"`
+	ReviewNote  string      `json:"review_note"`
+	CreatedAt   time.Time   `json:"created_at"`
+	ReviewedAt  *time.Time  `json:"reviewed_at"`
+	AppliedAt   *time.Time  `json:"applied_at"`
 }
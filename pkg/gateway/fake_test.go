@@ -0,0 +1,88 @@
+package gateway_test
+
+import (
+	"ecommerce_clean_arch/pkg/config"
+	"ecommerce_clean_arch/pkg/gateway"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FakeGatewayCheckout(t *testing.T) {
+	fake := gateway.NewFakeGateway()
+
+	orderID, err := fake.CreateOrder(150000, "INR", "order_1")
+	require.NoError(t, err)
+
+	paymentID, signature, err := fake.Pay(orderID)
+	require.NoError(t, err)
+
+	assert.True(t, fake.VerifySignature(orderID, paymentID, signature))
+	assert.False(t, fake.VerifySignature(orderID, paymentID, "tampered"))
+	assert.False(t, fake.VerifySignature("order_fake_other", paymentID, signature))
+
+	status, err := fake.FetchPaymentStatus(paymentID)
+	require.NoError(t, err)
+	assert.Equal(t, gateway.StatusCaptured, status)
+}
+
+func Test_FakeGatewayRefund(t *testing.T) {
+	fake := gateway.NewFakeGateway()
+
+	orderID, err := fake.CreateOrder(100000, "INR", "order_2")
+	require.NoError(t, err)
+	paymentID, _, err := fake.Pay(orderID)
+	require.NoError(t, err)
+
+	// Steps run in order against the same payment.
+	steps := []struct {
+		name           string
+		amount         int64
+		expectedStatus string
+		expectErr      bool
+	}{
+		{name: "partial refund", amount: 40000, expectedStatus: gateway.StatusCaptured},
+		{name: "refund over captured", amount: 70000, expectedStatus: gateway.StatusCaptured, expectErr: true},
+		{name: "refund the remainder", amount: 60000, expectedStatus: gateway.StatusRefunded},
+	}
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			result, err := fake.Refund(paymentID, step.amount)
+			if step.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, result.ID)
+				assert.Equal(t, gateway.RefundProcessed, result.Status)
+			}
+
+			status, err := fake.FetchPaymentStatus(paymentID)
+			require.NoError(t, err)
+			assert.Equal(t, step.expectedStatus, status)
+		})
+	}
+}
+
+func Test_NewGateway(t *testing.T) {
+	_, err := gateway.New(config.Config{})
+	assert.Error(t, err, "razorpay without keys must not start")
+
+	g, err := gateway.New(config.Config{PaymentGateway: gateway.Fake})
+	require.NoError(t, err)
+	assert.Equal(t, gateway.Fake, g.Name())
+
+	_, err = gateway.New(config.Config{PaymentGateway: "paypal"})
+	assert.Error(t, err)
+}
+
+func Test_FakeGatewayWebhookSignature(t *testing.T) {
+	fake := gateway.NewFakeGateway()
+	body := []byte(`{"event":"payment.captured","payload":{"payment":{"entity":{"id":"pay_fake_2","order_id":"order_fake_1"}}}}`)
+
+	signature := fake.SignWebhook(body)
+
+	assert.True(t, fake.VerifyWebhookSignature(body, signature))
+	assert.False(t, fake.VerifyWebhookSignature(append(body, ' '), signature))
+	assert.False(t, fake.VerifyWebhookSignature(body, ""))
+}
@@ -0,0 +1,70 @@
+package gateway
+
+import (
+	"ecommerce_clean_arch/pkg/config"
+	"errors"
+	"fmt"
+)
+
+const (
+	Razorpay = "razorpay"
+	Fake     = "fake"
+)
+
+// Payment states reported by FetchPaymentStatus, using Razorpay's vocabulary.
+const (
+	StatusCreated    = "created"
+	StatusAuthorized = "authorized"
+	StatusCaptured   = "captured"
+	StatusRefunded   = "refunded"
+	StatusFailed     = "failed"
+)
+
+// Refund states reported by Refund. A pending refund settles later and is
+// confirmed by a refund.processed webhook.
+const (
+	RefundPending   = "pending"
+	RefundProcessed = "processed"
+)
+
+var ErrPaymentNotFound = errors.New("payment not found at gateway")
+
+type RefundResult struct {
+	ID     string
+	Status string
+}
+
+// PaymentGateway is the boundary between checkout and an online payment
+// provider. Amounts are in the smallest currency unit (paise for INR).
+type PaymentGateway interface {
+	// Name identifies the provider, e.g. "razorpay".
+	Name() string
+	// KeyID is the public key handed to the browser checkout.
+	KeyID() string
+	// CreateOrder registers an order with the provider and returns its id.
+	CreateOrder(amount int64, currency, receipt string) (string, error)
+	// VerifySignature checks the signature returned by the checkout widget.
+	VerifySignature(gatewayOrderID, paymentID, signature string) bool
+	// FetchPaymentStatus returns one of the Status* constants.
+	FetchPaymentStatus(paymentID string) (string, error)
+	// Refund refunds amount against paymentID.
+	Refund(paymentID string, amount int64) (RefundResult, error)
+	// VerifyWebhookSignature checks the HMAC sent with a webhook body.
+	VerifyWebhookSignature(body []byte, signature string) bool
+}
+
+// New returns the gateway selected by cfg.PaymentGateway, defaulting to
+// Razorpay.
+func New(cfg config.Config) (PaymentGateway, error) {
+	switch cfg.PaymentGateway {
+	case "", Razorpay:
+		if cfg.RazorpayKeyID == "" || cfg.RazorpayKeySecret == "" {
+			return nil, errors.New("RAZORPAY_KEY_ID and RAZORPAY_KEY_SECRET must be set for the razorpay gateway")
+		}
+		return NewRazorpayGateway(cfg.RazorpayKeyID, cfg.RazorpayKeySecret, cfg.RazorpayWebhookSecret), nil
+	case Fake:
+		return NewFakeGateway(), nil
+	default:
+		return nil, fmt.Errorf("unknown payment gateway %q", cfg.PaymentGateway)
+	}
+}
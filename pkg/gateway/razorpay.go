@@ -0,0 +1,99 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/razorpay/razorpay-go"
+	"github.com/razorpay/razorpay-go/utils"
+)
+
+type RazorpayGateway struct {
+	client        *razorpay.Client
+	keyID         string
+	keySecret     string
+	webhookSecret string
+}
+
+func NewRazorpayGateway(keyID, keySecret, webhookSecret string) *RazorpayGateway {
+	return &RazorpayGateway{
+		client:        razorpay.NewClient(keyID, keySecret),
+		keyID:         keyID,
+		keySecret:     keySecret,
+		webhookSecret: webhookSecret,
+	}
+}
+
+func (r *RazorpayGateway) Name() string {
+	return Razorpay
+}
+
+func (r *RazorpayGateway) KeyID() string {
+	return r.keyID
+}
+
+func (r *RazorpayGateway) CreateOrder(amount int64, currency, receipt string) (string, error) {
+	data := map[string]interface{}{
+		"amount":   amount,
+		"currency": currency,
+		"receipt":  receipt,
+	}
+
+	body, err := r.client.Order.Create(data, nil)
+	if err != nil {
+		return "", fmt.Errorf("razorpay order creation failed: %w", err)
+	}
+
+	orderID, ok := body["id"].(string)
+	if !ok {
+		return "", errors.New("failed to retrieve Razorpay order ID")
+	}
+	return orderID, nil
+}
+
+func (r *RazorpayGateway) VerifySignature(gatewayOrderID, paymentID, signature string) bool {
+	params := map[string]interface{}{
+		"razorpay_order_id":   gatewayOrderID,
+		"razorpay_payment_id": paymentID,
+	}
+	return utils.VerifyPaymentSignature(params, signature, r.keySecret)
+}
+
+func (r *RazorpayGateway) FetchPaymentStatus(paymentID string) (string, error) {
+	body, err := r.client.Payment.Fetch(paymentID, nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("razorpay payment fetch failed: %w", err)
+	}
+
+	status, ok := body["status"].(string)
+	if !ok {
+		return "", ErrPaymentNotFound
+	}
+	return status, nil
+}
+
+func (r *RazorpayGateway) Refund(paymentID string, amount int64) (RefundResult, error) {
+	body, err := r.client.Payment.Refund(paymentID, int(amount), nil, nil)
+	if err != nil {
+		return RefundResult{}, fmt.Errorf("razorpay refund failed: %w", err)
+	}
+
+	refundID, ok := body["id"].(string)
+	if !ok {
+		return RefundResult{}, errors.New("failed to retrieve Razorpay refund ID")
+	}
+	status, _ := body["status"].(string)
+	if status != RefundProcessed {
+		status = RefundPending
+	}
+	return RefundResult{ID: refundID, Status: status}, nil
+}
+
+// VerifyWebhookSignature rejects every webhook when no webhook secret is
+// configured, rather than accepting unsigned bodies.
+func (r *RazorpayGateway) VerifyWebhookSignature(body []byte, signature string) bool {
+	if r.webhookSecret == "" {
+		return false
+	}
+	return utils.VerifyWebhookSignature(string(body), signature, r.webhookSecret)
+}
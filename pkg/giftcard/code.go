@@ -0,0 +1,62 @@
+// Package giftcard makes and recognises gift card codes. A code is shown
+// once, in the email to the recipient; the store keeps only its hash, so a
+// leaked database cannot be spent.
+package giftcard
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// alphabet leaves out 0, 1, I and O, which are easily misread.
+const alphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+const (
+	prefix     = "GIFT"
+	groups     = 4
+	groupChars = 4
+)
+
+// NewCode returns a random code such as GIFT-7KQ2-M9XD-4RTA-HP3C, carrying
+// 80 bits of randomness.
+func NewCode() (string, error) {
+	buf := make([]byte, groups*groupChars)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	parts := []string{prefix}
+	for g := 0; g < groups; g++ {
+		var part strings.Builder
+		for _, b := range buf[g*groupChars : (g+1)*groupChars] {
+			part.WriteByte(alphabet[int(b)%len(alphabet)])
+		}
+		parts = append(parts, part.String())
+	}
+	return strings.Join(parts, "-"), nil
+}
+
+// Normalize reduces a code as typed to the form that is hashed: upper case,
+// without the prefix, spaces or dashes.
+func Normalize(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer(" ", "", "-", "").Replace(code)
+	return strings.TrimPrefix(code, prefix)
+}
+
+// Hash is what is stored for a code and looked up when it is redeemed.
+func Hash(code string) string {
+	sum := sha256.Sum256([]byte(Normalize(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Last4 is the end of a code, enough for a customer to tell their cards
+// apart.
+func Last4(code string) string {
+	normalized := Normalize(code)
+	if len(normalized) <= 4 {
+		return normalized
+	}
+	return normalized[len(normalized)-4:]
+}
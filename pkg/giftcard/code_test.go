@@ -0,0 +1,37 @@
+package giftcard_test
+
+import (
+	"ecommerce_clean_arch/pkg/giftcard"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewCode(t *testing.T) {
+	code, err := giftcard.NewCode()
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^GIFT(-[2-9A-HJ-NP-Z]{4}){4}$`), code)
+
+	other, err := giftcard.NewCode()
+	assert.NoError(t, err)
+	assert.NotEqual(t, code, other)
+}
+
+func Test_Hash(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{name: "as typed", a: "GIFT-7KQ2-M9XD-4RTA-HP3C", b: "gift 7kq2 m9xd 4rta hp3c", same: true},
+		{name: "without prefix", a: "GIFT-7KQ2-M9XD-4RTA-HP3C", b: "7KQ2M9XD4RTAHP3C", same: true},
+		{name: "different code", a: "GIFT-7KQ2-M9XD-4RTA-HP3C", b: "GIFT-7KQ2-M9XD-4RTA-HP3D"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.same, giftcard.Hash(tt.a) == giftcard.Hash(tt.b))
+		})
+	}
+	assert.Equal(t, "HP3C", giftcard.Last4("GIFT-7KQ2-M9XD-4RTA-HP3C"))
+}
@@ -0,0 +1,146 @@
+// Package ledger is the double-entry bookkeeping behind customer wallets.
+// Every movement of money is a journal entry of postings to accounts that
+// add up to zero: debits are positive amounts and credits negative. A
+// wallet is a liability account, so its balance is what has been credited
+// to it less what has been debited, the negative of its postings' sum.
+package ledger
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Account kinds.
+const (
+	KindAsset     = "asset"
+	KindLiability = "liability"
+	KindEquity    = "equity"
+	KindIncome    = "income"
+	KindExpense   = "expense"
+)
+
+// The store's own accounts, which wallet money moves to and from.
+const (
+	// AccountSales takes what orders and exchanges are paid from wallets.
+	AccountSales = "sales"
+	// AccountRefunds gives what refunds and cancellations credit to wallets.
+	AccountRefunds = "refunds"
+	// AccountGateway holds money collected through the payment gateway.
+	AccountGateway = "gateway"
+	// AccountAdjustments gives and takes admin corrections to wallets.
+	AccountAdjustments = "adjustments"
+	// AccountOpeningBalances is where wallet balances from before the
+	// ledger came from.
+	AccountOpeningBalances = "opening_balances"
+)
+
+var systemKinds = map[string]string{
+	AccountSales:           KindIncome,
+	AccountRefunds:         KindExpense,
+	AccountGateway:         KindAsset,
+	AccountAdjustments:     KindExpense,
+	AccountOpeningBalances: KindEquity,
+}
+
+// Sources of journal entries. Each entry names the record that caused it.
+const (
+	SourceOrder      = "order"
+	SourceRefund     = "refund"
+	SourceExchange   = "exchange"
+	SourceTopUp      = "topup"
+	SourceAdjustment = "adjustment"
+	SourceOpening    = "opening"
+)
+
+const walletPrefix = "wallet:"
+
+// WalletAccount is the code of a customer's wallet account.
+func WalletAccount(userID int) string {
+	return walletPrefix + strconv.Itoa(userID)
+}
+
+// WalletUser reports whose wallet an account code is, if it is a wallet.
+func WalletUser(code string) (int, bool) {
+	id, found := strings.CutPrefix(code, walletPrefix)
+	if !found {
+		return 0, false
+	}
+	userID, err := strconv.Atoi(id)
+	if err != nil || userID <= 0 {
+		return 0, false
+	}
+	return userID, true
+}
+
+// Kind reports what kind of account a code is, or "" for an unknown code.
+func Kind(code string) string {
+	if _, ok := WalletUser(code); ok {
+		return KindLiability
+	}
+	return systemKinds[code]
+}
+
+// Source is the record a journal entry is for.
+type Source struct {
+	Type        string
+	ID          int
+	Description string
+}
+
+// Line is one posting of an entry before it is saved.
+type Line struct {
+	Account string
+	Amount  money.Money
+}
+
+// Transfer is the entry that debits one account and credits another by
+// amount.
+func Transfer(debit, credit string, amount money.Money) []Line {
+	return []Line{
+		{Account: debit, Amount: amount},
+		{Account: credit, Amount: -amount},
+	}
+}
+
+// Credit is the entry that puts amount into a customer's wallet from
+// account.
+func Credit(userID int, from string, amount money.Money) []Line {
+	return Transfer(from, WalletAccount(userID), amount)
+}
+
+// Debit is the entry that takes amount out of a customer's wallet to
+// account.
+func Debit(userID int, to string, amount money.Money) []Line {
+	return Transfer(WalletAccount(userID), to, amount)
+}
+
+// WalletBalance turns the sum of a wallet account's postings into its
+// balance.
+func WalletBalance(postings money.Money) money.Money {
+	return -postings
+}
+
+// Validate checks that lines make a journal entry: at least two postings
+// to known accounts, none of them zero, adding up to zero.
+func Validate(lines []Line) error {
+	if len(lines) < 2 {
+		return errors.New("a journal entry needs at least two postings")
+	}
+	var sum money.Money
+	for _, line := range lines {
+		if Kind(line.Account) == "" {
+			return fmt.Errorf("unknown account %q", line.Account)
+		}
+		if line.Amount.IsZero() {
+			return fmt.Errorf("posting to %s is zero", line.Account)
+		}
+		sum += line.Amount
+	}
+	if !sum.IsZero() {
+		return fmt.Errorf("journal entry is out of balance by %s", sum)
+	}
+	return nil
+}
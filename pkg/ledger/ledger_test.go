@@ -0,0 +1,70 @@
+package ledger_test
+
+import (
+	"ecommerce_clean_arch/pkg/ledger"
+	"ecommerce_clean_arch/pkg/money"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []ledger.Line
+		expectErr bool
+	}{
+		{name: "wallet credit", lines: ledger.Credit(7, ledger.AccountRefunds, money.Rupees(250))},
+		{name: "wallet debit", lines: ledger.Debit(7, ledger.AccountSales, money.Paise(1999))},
+		{
+			name: "split over three accounts",
+			lines: []ledger.Line{
+				{Account: ledger.WalletAccount(7), Amount: money.Rupees(100)},
+				{Account: ledger.AccountSales, Amount: money.Rupees(-60)},
+				{Account: ledger.AccountGateway, Amount: money.Rupees(-40)},
+			},
+		},
+		{name: "single posting", lines: []ledger.Line{{Account: ledger.AccountSales, Amount: money.Rupees(1)}}, expectErr: true},
+		{
+			name: "out of balance",
+			lines: []ledger.Line{
+				{Account: ledger.WalletAccount(7), Amount: money.Rupees(100)},
+				{Account: ledger.AccountSales, Amount: money.Paise(-9999)},
+			},
+			expectErr: true,
+		},
+		{name: "zero posting", lines: ledger.Credit(7, ledger.AccountRefunds, money.Zero), expectErr: true},
+		{name: "unknown account", lines: ledger.Transfer("cash", ledger.AccountSales, money.Rupees(5)), expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ledger.Validate(tt.lines)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_WalletAccount(t *testing.T) {
+	userID, ok := ledger.WalletUser(ledger.WalletAccount(42))
+	assert.True(t, ok)
+	assert.Equal(t, 42, userID)
+
+	_, ok = ledger.WalletUser(ledger.AccountSales)
+	assert.False(t, ok)
+	_, ok = ledger.WalletUser("wallet:x")
+	assert.False(t, ok)
+
+	assert.Equal(t, ledger.KindLiability, ledger.Kind(ledger.WalletAccount(42)))
+	assert.Equal(t, ledger.KindIncome, ledger.Kind(ledger.AccountSales))
+	assert.Equal(t, "", ledger.Kind("cash"))
+}
+
+func Test_WalletBalance(t *testing.T) {
+	credit := ledger.Credit(7, ledger.AccountRefunds, money.Rupees(250))
+	debit := ledger.Debit(7, ledger.AccountSales, money.Rupees(100))
+	assert.Equal(t, money.Rupees(150), ledger.WalletBalance(credit[1].Amount+debit[0].Amount))
+}
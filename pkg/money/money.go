@@ -0,0 +1,328 @@
+// Package money holds amounts of rupees as a whole number of paise, so
+// totals, discounts, refunds and splits never gain or lose a paisa to
+// floating point. Amounts are stored in the database as bigint paise and
+// read and written as rupees in JSON, so the API keeps its shape.
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in paise. It may be negative, as a price difference
+// owed back is.
+type Money int64
+
+// Zero is no money.
+const Zero Money = 0
+
+// Paise makes an amount from paise.
+func Paise(p int64) Money {
+	return Money(p)
+}
+
+// Rupees makes an amount from whole rupees.
+func Rupees(r int64) Money {
+	return Money(r * 100)
+}
+
+// FromRupees converts rupees, as typed by people and gateways, to the
+// nearest paisa.
+func FromRupees(r float64) Money {
+	return Money(math.Round(r * 100))
+}
+
+// Paise reports the amount in paise.
+func (m Money) Paise() int64 {
+	return int64(m)
+}
+
+// Rupees reports the amount in rupees, for display and for APIs that take
+// rupees. Do not calculate with it.
+func (m Money) Rupees() float64 {
+	return float64(m) / 100
+}
+
+// WholeRupees reports the amount in rupees with any paise dropped.
+func (m Money) WholeRupees() int64 {
+	return int64(m) / 100
+}
+
+func (m Money) Add(o Money) Money {
+	return m + o
+}
+
+func (m Money) Sub(o Money) Money {
+	return m - o
+}
+
+// Times multiplies a unit price by a quantity.
+func (m Money) Times(quantity int) Money {
+	return m * Money(quantity)
+}
+
+func (m Money) IsZero() bool {
+	return m == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m < 0
+}
+
+// Abs drops the sign.
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// NonNegative clamps a negative amount to zero, for totals a discount may
+// overshoot.
+func (m Money) NonNegative() Money {
+	if m < 0 {
+		return 0
+	}
+	return m
+}
+
+// Percent is pct percent of the amount, to the nearest paisa.
+func (m Money) Percent(pct float64) Money {
+	r := new(big.Rat).SetFloat64(pct)
+	if r == nil {
+		return 0
+	}
+	r.Mul(r, new(big.Rat).SetInt64(int64(m)))
+	r.Quo(r, big.NewRat(100, 1))
+	return roundRat(r)
+}
+
+// MulDiv is the amount times num over den, to the nearest paisa. It is how
+// a share of a total is worked out without overflowing or rounding twice;
+// pass paise for a share of amounts, as in a.MulDiv(part.Paise(),
+// whole.Paise()).
+func (m Money) MulDiv(num, den int64) Money {
+	if den == 0 {
+		return 0
+	}
+	r := new(big.Rat).SetFrac(
+		new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(num)),
+		big.NewInt(den),
+	)
+	return roundRat(r)
+}
+
+// Allocate splits the amount pro rata over weights. The parts always add up
+// to the amount exactly: paise left over from rounding down go to the parts
+// with the largest remainders, earlier parts first. With no weight the
+// whole amount goes to the first part.
+func (m Money) Allocate(weights ...Money) []Money {
+	parts := make([]Money, len(weights))
+	if len(weights) == 0 {
+		return parts
+	}
+	total := Sum(weights...)
+	if total <= 0 {
+		parts[0] = m
+		return parts
+	}
+
+	sign := Money(1)
+	amount := m
+	if amount < 0 {
+		sign, amount = -1, -amount
+	}
+
+	remainders := make([]*big.Int, len(weights))
+	allocated := Zero
+	for i, weight := range weights {
+		if weight <= 0 {
+			remainders[i] = big.NewInt(-1)
+			continue
+		}
+		quo, rem := new(big.Int).QuoRem(
+			new(big.Int).Mul(big.NewInt(int64(amount)), big.NewInt(int64(weight))),
+			big.NewInt(int64(total)),
+			new(big.Int),
+		)
+		parts[i] = Money(quo.Int64())
+		remainders[i] = rem
+		allocated += parts[i]
+	}
+
+	for left := amount - allocated; left > 0; left-- {
+		best := -1
+		for i, rem := range remainders {
+			if rem.Sign() < 0 {
+				continue
+			}
+			if best == -1 || rem.Cmp(remainders[best]) > 0 {
+				best = i
+			}
+		}
+		parts[best]++
+		remainders[best] = big.NewInt(-1)
+	}
+
+	for i := range parts {
+		parts[i] *= sign
+	}
+	return parts
+}
+
+// Sum adds amounts up.
+func Sum(amounts ...Money) Money {
+	var total Money
+	for _, amount := range amounts {
+		total += amount
+	}
+	return total
+}
+
+func Min(a, b Money) Money {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func Max(a, b Money) Money {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// roundRat rounds to the nearest paisa, halves away from zero.
+func roundRat(r *big.Rat) Money {
+	num := new(big.Int).Abs(r.Num())
+	quo, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if new(big.Int).Mul(rem, big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		quo.Neg(quo)
+	}
+	return Money(quo.Int64())
+}
+
+// String formats the amount as rupees with two decimals, like 1499.50.
+func (m Money) String() string {
+	sign := ""
+	p := int64(m)
+	if p < 0 {
+		sign, p = "-", -p
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, p/100, p%100)
+}
+
+// Parse reads rupees written as a decimal, like "1499.5" or "₹1,499.50".
+// More than two decimals are an error rather than rounded away.
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "₹")
+	s = strings.ReplaceAll(s, ",", "")
+	if s == "" {
+		return 0, errors.New("empty amount")
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("amount %q has more than two decimals", s)
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+	if whole == "" {
+		whole = "0"
+	}
+
+	rupees, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || rupees < 0 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	paise, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil || paise < 0 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if rupees > math.MaxInt64/100-1 {
+		return 0, fmt.Errorf("amount %q is too large", s)
+	}
+
+	m := Money(rupees*100 + paise)
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+// MarshalJSON writes the amount as a number of rupees.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON reads a number of rupees, or a string holding one.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores the amount as paise.
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+// Scan reads paise from the database.
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = Money(v)
+	case float64:
+		// Sums and averages of bigint columns come back as numeric.
+		*m = Money(math.Round(v))
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	default:
+		return fmt.Errorf("cannot scan %T into money", src)
+	}
+	return nil
+}
+
+func (m *Money) scanString(s string) error {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into money: %w", s, err)
+	}
+	*m = Money(math.Round(f))
+	return nil
+}
+
+// GormDataType keeps money columns bigint whatever the model.
+func (Money) GormDataType() string {
+	return "bigint"
+}
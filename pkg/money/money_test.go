@@ -0,0 +1,155 @@
+package money_test
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FromRupees(t *testing.T) {
+	assert.Equal(t, money.Paise(149950), money.FromRupees(1499.5))
+	assert.Equal(t, money.Paise(30), money.FromRupees(0.1+0.2))
+	assert.Equal(t, money.Paise(-1999), money.FromRupees(-19.99))
+	assert.Equal(t, 1499.5, money.Paise(149950).Rupees())
+}
+
+func Test_Percent(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount money.Money
+		pct    float64
+		want   money.Money
+	}{
+		{name: "whole", amount: money.Rupees(1000), pct: 10, want: money.Rupees(100)},
+		{name: "rounds half up", amount: money.Paise(1005), pct: 50, want: money.Paise(503)},
+		{name: "fractional percent", amount: money.Rupees(799), pct: 2.5, want: money.Paise(1998)},
+		{name: "negative rounds away from zero", amount: money.Paise(-1005), pct: 50, want: money.Paise(-503)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.amount.Percent(tt.pct))
+		})
+	}
+}
+
+func Test_MulDiv(t *testing.T) {
+	assert.Equal(t, money.Paise(33333), money.Rupees(1000).MulDiv(1, 3))
+	assert.Equal(t, money.Paise(66667), money.Rupees(1000).MulDiv(money.Rupees(2).Paise(), money.Rupees(3).Paise()))
+	assert.Equal(t, money.Paise(-66667), money.Rupees(-1000).MulDiv(2, 3))
+	assert.Equal(t, money.Zero, money.Rupees(1000).MulDiv(2, 0))
+}
+
+func Test_Allocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  money.Money
+		weights []money.Money
+		want    []money.Money
+	}{
+		{
+			name:    "even split",
+			amount:  money.Rupees(100),
+			weights: []money.Money{money.Rupees(1), money.Rupees(1)},
+			want:    []money.Money{money.Rupees(50), money.Rupees(50)},
+		},
+		{
+			name:    "leftover paise go to the largest remainders",
+			amount:  money.Rupees(100),
+			weights: []money.Money{money.Rupees(1), money.Rupees(1), money.Rupees(1)},
+			want:    []money.Money{money.Paise(3334), money.Paise(3333), money.Paise(3333)},
+		},
+		{
+			name:    "pro rata",
+			amount:  money.Paise(1001),
+			weights: []money.Money{money.Rupees(300), money.Rupees(700)},
+			want:    []money.Money{money.Paise(300), money.Paise(701)},
+		},
+		{
+			name:    "zero weights get nothing",
+			amount:  money.Rupees(10),
+			weights: []money.Money{money.Zero, money.Rupees(5)},
+			want:    []money.Money{money.Zero, money.Rupees(10)},
+		},
+		{
+			name:    "no weight puts everything first",
+			amount:  money.Rupees(10),
+			weights: []money.Money{money.Zero, money.Zero},
+			want:    []money.Money{money.Rupees(10), money.Zero},
+		},
+		{
+			name:    "negative amounts",
+			amount:  money.Paise(-100),
+			weights: []money.Money{money.Rupees(1), money.Rupees(2)},
+			want:    []money.Money{money.Paise(-33), money.Paise(-67)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := tt.amount.Allocate(tt.weights...)
+			assert.Equal(t, tt.want, parts)
+			assert.Equal(t, tt.amount, money.Sum(parts...))
+		})
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		in        string
+		want      money.Money
+		expectErr bool
+	}{
+		{in: "1499.5", want: money.Paise(149950)},
+		{in: "₹1,499.50", want: money.Paise(149950)},
+		{in: "-0.05", want: money.Paise(-5)},
+		{in: ".5", want: money.Paise(50)},
+		{in: "10", want: money.Rupees(10)},
+		{in: "1.005", expectErr: true},
+		{in: "abc", expectErr: true},
+		{in: "", expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := money.Parse(tt.in)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_JSON(t *testing.T) {
+	type order struct {
+		FinalPrice money.Money `json:"final_price"`
+	}
+
+	data, err := json.Marshal(order{FinalPrice: money.Paise(149905)})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"final_price":1499.05}`, string(data))
+
+	var decoded order
+	assert.NoError(t, json.Unmarshal([]byte(`{"final_price":1499.05}`), &decoded))
+	assert.Equal(t, money.Paise(149905), decoded.FinalPrice)
+	assert.NoError(t, json.Unmarshal([]byte(`{"final_price":"250"}`), &decoded))
+	assert.Equal(t, money.Rupees(250), decoded.FinalPrice)
+	assert.Error(t, json.Unmarshal([]byte(`{"final_price":true}`), &decoded))
+}
+
+func Test_Scan(t *testing.T) {
+	var m money.Money
+	assert.NoError(t, m.Scan(int64(149950)))
+	assert.Equal(t, money.Paise(149950), m)
+	assert.NoError(t, m.Scan([]byte("2500")))
+	assert.Equal(t, money.Paise(2500), m)
+	assert.NoError(t, m.Scan(nil))
+	assert.Equal(t, money.Zero, m)
+	assert.Error(t, m.Scan(true))
+
+	v, err := money.Paise(42).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), v)
+}
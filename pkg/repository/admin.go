@@ -89,14 +89,6 @@ func (ad *AdminRepository) CommittTransaction(tx *gorm.DB) error {
 func (ad *AdminRepository) RollbackkTransaction(tx *gorm.DB) error {
 	return tx.Rollback().Error
 }
-func (ad *AdminRepository) GetProductStockk(productID int) (int, error) {
-	var stock int
-	err := ad.DB.Raw("select stock from products where id = ?", productID).Scan(&stock).Error
-	if err != nil {
-		return 0, err
-	}
-	return stock, nil
-}
 func (ar *AdminRepository) UpdateProductStockk(tx *gorm.DB, productID, quantity int) error {
 	query := "UPDATE products SET stock = stock + ? WHERE id = ?"
 	err := tx.Exec(query, quantity, productID).Error
@@ -106,6 +98,15 @@ func (ar *AdminRepository) UpdateProductStockk(tx *gorm.DB, productID, quantity
 	return nil
 }
 
+func (ar *AdminRepository) RestoreVariantStock(tx *gorm.DB, variantID, quantity int) error {
+	query := "UPDATE product_variants SET stock = stock + ? WHERE id = ?"
+	err := tx.Exec(query, quantity, variantID).Error
+	if err != nil {
+		return fmt.Errorf("failed to update variant stock: %w", err)
+	}
+	return nil
+}
+
 func (ar *AdminRepository) AdminOrderRelationship(orderID, userID int) (int, error) {
 	var associatedUserID int
 	err := ar.DB.Raw("SELECT user_id FROM orders WHERE order_id = ?", orderID).Scan(&associatedUserID).Error
@@ -117,20 +118,13 @@ func (ar *AdminRepository) AdminOrderRelationship(orderID, userID int) (int, err
 
 func (ad *AdminRepository) GetProductDetailFromOrders(orderID int) ([]models.OrderProducts, error) {
 	var orderProductDetails []models.OrderProducts
-	err := ad.DB.Raw("SELECT product_id, quantity FROM order_items WHERE order_id = ?", orderID).Scan(&orderProductDetails).Error
+	err := ad.DB.Raw("SELECT product_id, variant_id, quantity FROM order_items WHERE order_id = ? AND status NOT IN ?",
+		orderID, models.ClosedItemStatuses).Scan(&orderProductDetails).Error
 	if err != nil {
 		return []models.OrderProducts{}, err
 	}
 	return orderProductDetails, nil
 }
-func (ad *AdminRepository) Getorderstatus(orderID int) (string, error) {
-	var orderStatus string
-	err := ad.DB.Raw("SELECT order_status FROM orders WHERE order_id = ?", orderID).Scan(&orderStatus).Error
-	if err != nil {
-		return "", err
-	}
-	return orderStatus, nil
-}
 func (ad *AdminRepository) GetOrderDetails(orderID string) (models.Order, error) {
 	var order models.Order
 	err := ad.DB.Raw("SELECT * FROM orders WHERE order_id = ?", orderID).Scan(&order).Error
@@ -159,10 +153,13 @@ func (ad *AdminRepository) GetAllOrderDetails() ([]models.FullOrderDetails, erro
 
 		err := ad.DB.Raw(`
 			SELECT 
+				order_items.id AS order_item_id, 
 				order_items.product_id, 
 				products.name AS product_name, 
+				order_items.variant_id, 
 				order_items.quantity, 
-				order_items.total_price 
+				order_items.total_price, 
+				order_items.status 
 			FROM order_items 
 			INNER JOIN products ON order_items.product_id = products.id 
 			WHERE order_items.order_id = ?`, od.OrderId).Scan(&orderProductDetails).Error
@@ -181,73 +178,20 @@ func (ad *AdminRepository) GetAllOrderDetails() ([]models.FullOrderDetails, erro
 	return fullOrderDetails, nil
 }
 
-func (ad *AdminRepository) Cancelorders(orderID int) error {
-	orderStatus := "cancelled"
-	err := ad.DB.Exec("UPDATE orders SET order_status = ? WHERE order_id = ?", orderStatus, orderID).Error
-	if err != nil {
-		return err
-	}
-	var paymentMethod int
-	err = ad.DB.Raw("SELECT payment_method_id FROM orders WHERE order_id = ?", orderID).Scan(&paymentMethod).Error
-	if err != nil {
-		return err
-	}
-	if paymentMethod == 1 || paymentMethod == 3 {
-		err = ad.DB.Exec("UPDATE orders SET payment_status = 'refunded' WHERE order_id = ?", orderID).Error
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 func (ad *AdminRepository) UpdatequantityOfproduct(orderProducts []models.OrderProducts) error {
 	for _, od := range orderProducts {
-		var quantity int
-		err := ad.DB.Raw("select quantity from products where id = ?", od.ProductID).Scan(&quantity).Error
-		if err != nil {
-			return err
-		}
-		od.Quantity += quantity
-		if err := ad.DB.Exec("update products set quantity = ? where id = ?", od.Quantity, od.ProductID).Error; err != nil {
+		if err := ad.DB.Exec("update products set quantity = quantity + ? where id = ?", od.Quantity, od.ProductID).Error; err != nil {
 			return err
 		}
 	}
 	return nil
 }
-func (ad *AdminRepository) ChangeOrderStatus(orderID string, status string) (models.Order, error) {
-	var changeOrderStatus models.Order
-	err := ad.DB.Model(&models.Order{}).Where("order_id = ?", orderID).Update("order_status", status).Error
-	if err != nil {
-		return models.Order{}, err
-	}
-
-	err = ad.DB.Where("order_id = ?", orderID).First(&changeOrderStatus).Error
-	if err != nil {
-		return models.Order{}, err
-	}
-
-	// if status == "shipped" {
-	// 	err = ad.DB.Model(&models.Order{}).Where("order_id = ?", orderID).Update("payment_status", "not paid").Error
-	// 	if err != nil {
-	// 		return models.Order{}, err
-	// 	}
-	if status == "delivered" {
-		var paymentMethod int
-		err = ad.DB.Raw("SELECT payment_method_id FROM orders WHERE order_id = ?", orderID).Scan(&paymentMethod).Error
-		if err != nil {
-			return models.Order{}, err
-		}
-
-		if paymentMethod == 1 || paymentMethod == 3 {
-			err = ad.DB.Model(&models.Order{}).Where("order_id = ?", orderID).Update("payment_status", "paid").Error
-			if err != nil {
-				return models.Order{}, err
-			}
-		}
-	}
 
-	return changeOrderStatus, nil
+// MarkCODPaid records the cash collected when a cash-on-delivery order is
+// delivered.
+func (ad *AdminRepository) MarkCODPaid(tx *gorm.DB, orderID int) error {
+	return tx.Exec("UPDATE orders SET payment_status = ? WHERE order_id = ? AND payment_method_id = ?",
+		models.PaymentPaid, orderID, models.PaymentMethodCOD).Error
 }
 
 func (ad *AdminRepository) GetTotalOrders(fromDate, toDate, orderStatus string) (models.OrderCount, models.AmountInformation, error) {
@@ -337,12 +281,13 @@ func (ad *AdminRepository) BestSellingProduct() ([]models.BestSellingProduct, er
 	FROM order_items o
 	JOIN 
 	products p ON o.product_id = p.id
+	WHERE o.status NOT IN ?
 	GROUP BY 
 	p.id, p.name 
 	ORDER BY 
 	total_sold DESC
 	LIMIT 10;
-	`).Scan(&bestSellingProduct).Error
+	`, models.ClosedItemStatuses).Scan(&bestSellingProduct).Error
 	if err != nil {
 		return []models.BestSellingProduct{}, err
 	}
@@ -357,11 +302,12 @@ func (ad *AdminRepository) BestSellingCategory() ([]models.BestSellingCategory,
 	JOIN products p ON o.product_id = p.id
 	JOIN 
 	categories c ON p.category_id = c.id
+	WHERE o.status NOT IN ?
 	GROUP BY c.id, c.category
 	ORDER BY 
 	total_sold DESC 
 	LIMIT 10;
-	`).Scan(&bestSellingCategory).Error
+	`, models.ClosedItemStatuses).Scan(&bestSellingCategory).Error
 	if err != nil {
 		return []models.BestSellingCategory{}, err
 	}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"ecommerce_clean_arch/pkg/money"
 	"ecommerce_clean_arch/pkg/utils/models"
 	"errors"
 	"log"
@@ -27,10 +28,9 @@ func (car *CartRepository) DisplayCart(userID int) ([]models.Cart, error) {
 	return cartItems, nil
 }
 
-func (r *CartRepository) GetCartItem(userID int, productID int) (*models.Cart, error) {
+func (r *CartRepository) GetCartItem(userID int, productID int, variantID int) (*models.Cart, error) {
 	var cartItem models.Cart
-	log.Println("Running query with userID=", userID, "productID=", productID)
-	if err := r.DB.Where("user_id = ? AND product_id = ?", userID, productID).First(&cartItem).Error; err != nil {
+	if err := r.DB.Where("user_id = ? AND product_id = ? AND variant_id = ?", userID, productID, variantID).First(&cartItem).Error; err != nil {
 		log.Println("Query error -", err)
 		return nil, err
 	}
@@ -40,7 +40,7 @@ func (r *CartRepository) GetCartItem(userID int, productID int) (*models.Cart, e
 func (car *CartRepository) AddToCart(cartItem models.Cart) (models.Cart, error) {
 	var existingCartItem models.Cart
 
-	err := car.DB.Where("user_id = ? AND product_id = ?", cartItem.UserID, cartItem.ProductID).
+	err := car.DB.Where("user_id = ? AND product_id = ? AND variant_id = ?", cartItem.UserID, cartItem.ProductID, cartItem.VariantID).
 		First(&existingCartItem).Error
 
 	if err == nil {
@@ -62,19 +62,19 @@ func (car *CartRepository) UpdateCart(cart models.Cart) (models.Cart, error) {
 	}
 	return cart, nil
 }
-func (cr *CartRepository) CheckProductInCart(userID int, productID int) (bool, error) {
+func (cr *CartRepository) CheckProductInCart(userID int, productID int, variantID int) (bool, error) {
 	var count int
-	err := cr.DB.Raw(`SELECT COUNT(*) FROM carts WHERE user_id = $1 AND product_id = $2`, userID, productID).Scan(&count).Error
+	err := cr.DB.Raw(`SELECT COUNT(*) FROM carts WHERE user_id = $1 AND product_id = $2 AND variant_id = $3 AND deleted_at IS NULL`, userID, productID, variantID).Scan(&count).Error
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-func (car *CartRepository) RemoveProductFromCart(userID int, productID int, price float64) error {
+func (car *CartRepository) RemoveProductFromCart(userID int, productID int, variantID int, price money.Money) error {
 	var cartItem models.Cart
 
-	err := car.DB.Where("user_id = ? AND product_id = ?", userID, productID).
+	err := car.DB.Where("user_id = ? AND product_id = ? AND variant_id = ?", userID, productID, variantID).
 		First(&cartItem).Error
 	if err != nil {
 		return errors.New("product not found in the cart")
@@ -82,15 +82,15 @@ func (car *CartRepository) RemoveProductFromCart(userID int, productID int, pric
 
 	if cartItem.Quantity > 1 {
 		cartItem.Quantity--
-		cartItem.TotalPrice -= float64(cartItem.OfferPrice)
+		cartItem.TotalPrice -= cartItem.OfferPrice
 		return car.DB.Save(&cartItem).Error
 	}
 
 	return car.DB.Delete(&cartItem).Error
 }
-func (car *CartRepository) RemoveFromCart(userID int, productID int) error {
+func (car *CartRepository) RemoveFromCart(userID int, productID int, variantID int) error {
 	var cart models.Cart
-	err := car.DB.Where("user_id = ? AND product_id = ?", userID, productID).First(&cart).Error
+	err := car.DB.Where("user_id = ? AND product_id = ? AND variant_id = ?", userID, productID, variantID).First(&cart).Error
 	if err != nil {
 		return errors.New("product not found in the cart")
 	}
@@ -16,6 +16,7 @@ func Test_GetCartItem(t *testing.T) {
 		name      string
 		userID    int
 		productID int
+		variantID int
 		setupMock func(mock sqlmock.Sqlmock)
 		expectErr bool
 	}{
@@ -25,12 +26,12 @@ func Test_GetCartItem(t *testing.T) {
 			productID: 1,
 			setupMock: func(mock sqlmock.Sqlmock) {
 
-				mock.ExpectQuery(`SELECT \* FROM "carts" WHERE \(user_id = \$1 AND product_id = \$2\) AND "carts"\."deleted_at" IS NULL ORDER BY "carts"\."id" LIMIT \$3`).
-					WithArgs(1, 1, 1).
+				mock.ExpectQuery(`SELECT \* FROM "carts" WHERE \(user_id = \$1 AND product_id = \$2 AND variant_id = \$3\) AND "carts"\."deleted_at" IS NULL ORDER BY "carts"\."id" LIMIT \$4`).
+					WithArgs(1, 1, 0, 1).
 					WillReturnRows(sqlmock.NewRows([]string{
-						"id", "user_id", "product_id", "quantity", "price", "offer_price", "category_discount", "total_price", "created_at", "deleted_at",
+						"id", "user_id", "product_id", "variant_id", "quantity", "price", "offer_price", "category_discount", "total_price", "created_at", "deleted_at",
 					}).
-						AddRow(1, 1, 1, 10, 2000, 1500, 2.0, 3000.0, time.Now(), nil))
+						AddRow(1, 1, 1, 0, 10, 2000, 1500, 2.0, 3000.0, time.Now(), nil))
 			},
 			expectErr: false,
 		},
@@ -39,8 +40,8 @@ func Test_GetCartItem(t *testing.T) {
 			userID:    1,
 			productID: 1,
 			setupMock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT \* FROM "carts" WHERE \(user_id = \$1 AND product_id = \$2\) AND "carts"\."deleted_at" IS NULL ORDER BY "carts"\."id" LIMIT \$3`).
-					WithArgs(1, 1, 1).
+				mock.ExpectQuery(`SELECT \* FROM "carts" WHERE \(user_id = \$1 AND product_id = \$2 AND variant_id = \$3\) AND "carts"\."deleted_at" IS NULL ORDER BY "carts"\."id" LIMIT \$4`).
+					WithArgs(1, 1, 0, 1).
 					WillReturnError(gorm.ErrInvalidDB)
 			},
 			expectErr: true,
@@ -60,7 +61,7 @@ func Test_GetCartItem(t *testing.T) {
 			cartRepo := repository.NewCartRepository(db)
 			tt.setupMock(mock)
 
-			cartItems, err := cartRepo.GetCartItem(tt.userID, tt.productID)
+			cartItems, err := cartRepo.GetCartItem(tt.userID, tt.productID, tt.variantID)
 
 			if tt.expectErr {
 				assert.Error(t, err)
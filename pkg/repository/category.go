@@ -20,7 +20,11 @@ func NewCategoryRepository(DB *gorm.DB) *CategoryRepository {
 func (cat *CategoryRepository) AddCategory(category domain.Category) (domain.Category, error) {
 	var categoryResponse domain.Category
 
-	err := cat.DB.Raw("INSERT INTO categories (category, description, category_discount) VALUES (?, ?, ?) RETURNING id, category, description, category_discount", category.Category, category.Description, category.CategoryDiscount).Scan(&categoryResponse).Error
+	err := cat.DB.Raw(`INSERT INTO categories (category, description, category_discount, hsn_code, gst_rate, gst_higher_rate, gst_threshold, weight_grams)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id, category, description, category_discount, hsn_code, gst_rate, gst_higher_rate, gst_threshold, weight_grams`,
+		category.Category, category.Description, category.CategoryDiscount,
+		category.HSNCode, category.GSTRate, category.GSTHigherRate, category.GSTThreshold, category.WeightGrams).Scan(&categoryResponse).Error
 	if err != nil {
 		return domain.Category{}, err
 	}
@@ -36,10 +40,12 @@ func (cat *CategoryRepository) UpdateCategory(category domain.Category, category
 
 	err := cat.DB.Raw(`
     UPDATE categories 
-    SET category = ?, description = ?, category_discount = ?
+    SET category = ?, description = ?, category_discount = ?,
+        hsn_code = ?, gst_rate = ?, gst_higher_rate = ?, gst_threshold = ?, weight_grams = ?
     WHERE id = ? 
-    RETURNING id, category, description, category_discount`,
-		category.Category, category.Description, category.CategoryDiscount, categoryID).Scan(&updatedCategory).Error
+    RETURNING id, category, description, category_discount, hsn_code, gst_rate, gst_higher_rate, gst_threshold, weight_grams`,
+		category.Category, category.Description, category.CategoryDiscount,
+		category.HSNCode, category.GSTRate, category.GSTHigherRate, category.GSTThreshold, category.WeightGrams, categoryID).Scan(&updatedCategory).Error
 
 	if err != nil {
 		return domain.Category{}, err
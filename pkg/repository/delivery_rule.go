@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type DeliveryRuleRepository struct {
+	DB *gorm.DB
+}
+
+func NewDeliveryRuleRepository(db *gorm.DB) *DeliveryRuleRepository {
+	return &DeliveryRuleRepository{DB: db}
+}
+
+func (d *DeliveryRuleRepository) GetRules() ([]domain.DeliveryRule, error) {
+	var deliveryRules []domain.DeliveryRule
+	if err := d.DB.Order("priority, id").Find(&deliveryRules).Error; err != nil {
+		return nil, err
+	}
+	return deliveryRules, nil
+}
+
+func (d *DeliveryRuleRepository) GetActiveRules() ([]domain.DeliveryRule, error) {
+	var deliveryRules []domain.DeliveryRule
+	if err := d.DB.Where("active = ?", true).Order("priority, id").Find(&deliveryRules).Error; err != nil {
+		return nil, err
+	}
+	return deliveryRules, nil
+}
+
+func (d *DeliveryRuleRepository) CreateRule(rule domain.DeliveryRule) (domain.DeliveryRule, error) {
+	rule.ID = 0
+	if err := d.DB.Create(&rule).Error; err != nil {
+		return domain.DeliveryRule{}, err
+	}
+	return rule, nil
+}
+
+// UpdateRule replaces every field of a rule.
+func (d *DeliveryRuleRepository) UpdateRule(rule domain.DeliveryRule) (domain.DeliveryRule, error) {
+	var existing domain.DeliveryRule
+	if err := d.DB.Where("id = ?", rule.ID).Limit(1).Find(&existing).Error; err != nil {
+		return domain.DeliveryRule{}, err
+	}
+	if existing.ID == 0 {
+		return domain.DeliveryRule{}, errors.New("delivery rule not found")
+	}
+
+	rule.CreatedAt = existing.CreatedAt
+	if err := d.DB.Save(&rule).Error; err != nil {
+		return domain.DeliveryRule{}, err
+	}
+	return rule, nil
+}
+
+func (d *DeliveryRuleRepository) DeleteRule(ruleID int) error {
+	result := d.DB.Delete(&domain.DeliveryRule{}, ruleID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("delivery rule not found")
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ExchangeRepository struct {
+	DB *gorm.DB
+}
+
+func NewExchangeRepository(db *gorm.DB) *ExchangeRepository {
+	return &ExchangeRepository{DB: db}
+}
+
+func (r *ExchangeRepository) CreateExchange(tx *gorm.DB, exchange domain.Exchange) (domain.Exchange, error) {
+	if err := tx.Create(&exchange).Error; err != nil {
+		return domain.Exchange{}, err
+	}
+	return exchange, nil
+}
+
+// HasPendingExchange reports whether the order line already has an exchange
+// waiting for a decision.
+func (r *ExchangeRepository) HasPendingExchange(tx *gorm.DB, orderItemID int) (bool, error) {
+	var exists bool
+	err := tx.Raw("SELECT EXISTS(SELECT 1 FROM exchanges WHERE order_item_id = ? AND status = ?)",
+		orderItemID, models.ExchangeRequested).Scan(&exists).Error
+	return exists, err
+}
+
+// LockExchange reads an exchange and holds its row until tx ends, so two
+// admins cannot decide the same request at once.
+func (r *ExchangeRepository) LockExchange(tx *gorm.DB, exchangeID int) (domain.Exchange, error) {
+	var exchange domain.Exchange
+	err := tx.Raw("SELECT * FROM exchanges WHERE id = ? FOR UPDATE", exchangeID).Scan(&exchange).Error
+	if err != nil {
+		return domain.Exchange{}, err
+	}
+	if exchange.ID == 0 {
+		return domain.Exchange{}, errors.New("exchange not found")
+	}
+	return exchange, nil
+}
+
+// GetExchangeIDByGatewayOrderID returns the exchange a gateway order was
+// created for, or 0 if it was created for something else.
+func (r *ExchangeRepository) GetExchangeIDByGatewayOrderID(gatewayOrderID string) (int, error) {
+	var exchangeID int
+	err := r.DB.Raw("SELECT id FROM exchanges WHERE gateway_order_id = ?", gatewayOrderID).Scan(&exchangeID).Error
+	return exchangeID, err
+}
+
+// SetGatewayOrderID records the gateway order created for an exchange's
+// price difference.
+func (r *ExchangeRepository) SetGatewayOrderID(exchangeID int, gatewayOrderID string) error {
+	return r.DB.Exec("UPDATE exchanges SET gateway_order_id = ? WHERE id = ?", gatewayOrderID, exchangeID).Error
+}
+
+// GetExpiredExchangeIDs returns requested exchanges whose price difference
+// was due through the gateway before now and is still unpaid.
+func (r *ExchangeRepository) GetExpiredExchangeIDs(now time.Time, limit int) ([]int, error) {
+	var exchangeIDs []int
+	err := r.DB.Raw("SELECT id FROM exchanges WHERE status = ? AND payment_status = ? AND payment_due_by <= ? ORDER BY id LIMIT ?",
+		models.ExchangeRequested, models.PaymentNotPaid, now, limit).Scan(&exchangeIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return exchangeIDs, nil
+}
+
+func (r *ExchangeRepository) SaveExchange(tx *gorm.DB, exchange domain.Exchange) error {
+	return tx.Save(&exchange).Error
+}
+
+// GetOrder returns the order an exchanged line belongs to; its address and
+// payment method carry over to the replacement shipment.
+func (r *ExchangeRepository) GetOrder(tx *gorm.DB, orderID int) (models.Order, error) {
+	var order models.Order
+	err := tx.Raw("SELECT * FROM orders WHERE order_id = ?", orderID).Scan(&order).Error
+	if err != nil {
+		return models.Order{}, err
+	}
+	if order.OrderId == 0 {
+		return models.Order{}, errors.New("order not found")
+	}
+	return order, nil
+}
+
+func (r *ExchangeRepository) GetExchangesByUser(userID int) ([]domain.Exchange, error) {
+	var exchanges []domain.Exchange
+	err := r.DB.Raw("SELECT * FROM exchanges WHERE user_id = ? ORDER BY created_at DESC", userID).Scan(&exchanges).Error
+	if err != nil {
+		return nil, err
+	}
+	return exchanges, nil
+}
+
+func (r *ExchangeRepository) GetExchangesByStatus(status string) ([]domain.Exchange, error) {
+	var exchanges []domain.Exchange
+	query := r.DB.Model(&domain.Exchange{}).Order("created_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&exchanges).Error; err != nil {
+		return nil, err
+	}
+	return exchanges, nil
+}
@@ -0,0 +1,144 @@
+package repository_test
+
+import (
+	"ecommerce_clean_arch/pkg/repository"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func Test_HasPendingExchange(t *testing.T) {
+	query := regexp.QuoteMeta("SELECT EXISTS(SELECT 1 FROM exchanges WHERE order_item_id = $1 AND status = $2)")
+
+	tests := []struct {
+		name      string
+		setupMock func(mock sqlmock.Sqlmock)
+		want      bool
+		expectErr bool
+	}{
+		{
+			name: "exchange awaiting a decision",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+				mock.ExpectQuery(query).WithArgs(5, "requested").WillReturnRows(rows)
+			},
+			want:      true,
+			expectErr: false,
+		},
+		{
+			name: "no pending exchange",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+				mock.ExpectQuery(query).WithArgs(5, "requested").WillReturnRows(rows)
+			},
+			want:      false,
+			expectErr: false,
+		},
+		{
+			name: "database error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs(5, "requested").WillReturnError(gorm.ErrInvalidDB)
+			},
+			want:      false,
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			exchangeRepo := repository.NewExchangeRepository(db)
+
+			tt.setupMock(mock)
+
+			pending, err := exchangeRepo.HasPendingExchange(db, 5)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, pending)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_GetExpiredExchangeIDs(t *testing.T) {
+	query := regexp.QuoteMeta("SELECT id FROM exchanges WHERE status = $1 AND payment_status = $2 AND payment_due_by <= $3 ORDER BY id LIMIT $4")
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		setupMock func(mock sqlmock.Sqlmock)
+		want      []int
+		expectErr bool
+	}{
+		{
+			name: "unpaid exchanges past their due time",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(8)
+				mock.ExpectQuery(query).WithArgs("requested", "not paid", now, 100).WillReturnRows(rows)
+			},
+			want:      []int{3, 8},
+			expectErr: false,
+		},
+		{
+			name: "nothing expired",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs("requested", "not paid", now, 100).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			want:      nil,
+			expectErr: false,
+		},
+		{
+			name: "database error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs("requested", "not paid", now, 100).WillReturnError(gorm.ErrInvalidDB)
+			},
+			want:      nil,
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			exchangeRepo := repository.NewExchangeRepository(db)
+
+			tt.setupMock(mock)
+
+			exchangeIDs, err := exchangeRepo.GetExpiredExchangeIDs(now, 100)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, exchangeIDs)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/money"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrInsufficientGiftCardBalance is returned for a redemption larger than
+// the card's balance.
+var ErrInsufficientGiftCardBalance = errors.New("gift card balance is too low")
+
+type GiftCardRepository struct {
+	DB *gorm.DB
+}
+
+func NewGiftCardRepository(DB *gorm.DB) *GiftCardRepository {
+	return &GiftCardRepository{DB: DB}
+}
+
+func (g *GiftCardRepository) BeginTransaction() (*gorm.DB, error) {
+	tx := g.DB.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tx, nil
+}
+
+func (g *GiftCardRepository) CreateGiftCard(tx *gorm.DB, card domain.GiftCard) (domain.GiftCard, error) {
+	if err := tx.Create(&card).Error; err != nil {
+		return domain.GiftCard{}, fmt.Errorf("failed to create gift card: %w", err)
+	}
+	return card, nil
+}
+
+func (g *GiftCardRepository) SaveGiftCard(tx *gorm.DB, card domain.GiftCard) error {
+	return tx.Save(&card).Error
+}
+
+// LockGiftCard loads a gift card and locks it until tx ends.
+func (g *GiftCardRepository) LockGiftCard(tx *gorm.DB, giftCardID int) (domain.GiftCard, error) {
+	var card domain.GiftCard
+	err := tx.Raw("SELECT * FROM gift_cards WHERE id = ? FOR UPDATE", giftCardID).Scan(&card).Error
+	if err != nil {
+		return domain.GiftCard{}, err
+	}
+	if card.ID == 0 {
+		return domain.GiftCard{}, errors.New("gift card not found")
+	}
+	return card, nil
+}
+
+// LockGiftCardByHash is LockGiftCard for the card a code belongs to.
+func (g *GiftCardRepository) LockGiftCardByHash(tx *gorm.DB, codeHash string) (domain.GiftCard, error) {
+	var card domain.GiftCard
+	err := tx.Raw("SELECT * FROM gift_cards WHERE code_hash = ? FOR UPDATE", codeHash).Scan(&card).Error
+	if err != nil {
+		return domain.GiftCard{}, err
+	}
+	if card.ID == 0 {
+		return domain.GiftCard{}, errors.New("gift card not found")
+	}
+	return card, nil
+}
+
+func (g *GiftCardRepository) GetGiftCardByHash(codeHash string) (domain.GiftCard, error) {
+	var card domain.GiftCard
+	err := g.DB.Raw("SELECT * FROM gift_cards WHERE code_hash = ?", codeHash).Scan(&card).Error
+	if err != nil {
+		return domain.GiftCard{}, err
+	}
+	if card.ID == 0 {
+		return domain.GiftCard{}, errors.New("gift card not found")
+	}
+	return card, nil
+}
+
+// GetGiftCardIDByGatewayOrderID returns the gift card a gateway order was
+// created for, or 0 if it was created for something else.
+func (g *GiftCardRepository) GetGiftCardIDByGatewayOrderID(gatewayOrderID string) (int, error) {
+	var giftCardID int
+	err := g.DB.Raw("SELECT id FROM gift_cards WHERE gateway_order_id = ?", gatewayOrderID).Scan(&giftCardID).Error
+	return giftCardID, err
+}
+
+func (g *GiftCardRepository) GetPurchasedGiftCards(userID int) ([]domain.GiftCard, error) {
+	var cards []domain.GiftCard
+	err := g.DB.Raw("SELECT * FROM gift_cards WHERE purchaser_id = ? ORDER BY id DESC", userID).Scan(&cards).Error
+	return cards, err
+}
+
+// GetPurchaserName is the name the gift card email says the card is from.
+func (g *GiftCardRepository) GetPurchaserName(userID int) (string, error) {
+	var name string
+	err := g.DB.Raw("SELECT first_name FROM users WHERE id = ?", userID).Scan(&name).Error
+	return name, err
+}
+
+func (g *GiftCardRepository) MarkEmailed(giftCardID int) error {
+	return g.DB.Exec("UPDATE gift_cards SET emailed_at = NOW() WHERE id = ?", giftCardID).Error
+}
+
+func (g *GiftCardRepository) CreateTransaction(tx *gorm.DB, transaction domain.GiftCardTransaction) error {
+	if err := tx.Create(&transaction).Error; err != nil {
+		return fmt.Errorf("failed to record gift card transaction: %w", err)
+	}
+	return nil
+}
+
+// Redeem takes amount off a gift card to pay orderID, inside tx. It fails
+// with ErrInsufficientGiftCardBalance rather than overdraw the card.
+func (g *GiftCardRepository) Redeem(tx *gorm.DB, giftCardID int, amount money.Money, orderID int) (money.Money, error) {
+	return g.move(tx, domain.GiftCardTransaction{
+		GiftCardID: giftCardID,
+		OrderID:    orderID,
+		Amount:     -amount,
+		Reason:     "order payment",
+	})
+}
+
+// Restore puts amount back on a gift card for a refund of orderID, inside
+// tx.
+func (g *GiftCardRepository) Restore(tx *gorm.DB, giftCardID int, amount money.Money, orderID, refundID int, reason string) (money.Money, error) {
+	return g.move(tx, domain.GiftCardTransaction{
+		GiftCardID: giftCardID,
+		OrderID:    orderID,
+		RefundID:   refundID,
+		Amount:     amount,
+		Reason:     reason,
+	})
+}
+
+func (g *GiftCardRepository) move(tx *gorm.DB, transaction domain.GiftCardTransaction) (money.Money, error) {
+	var balance money.Money
+	err := tx.Raw("SELECT balance FROM gift_cards WHERE id = ? FOR UPDATE", transaction.GiftCardID).Scan(&balance).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to query gift card: %w", err)
+	}
+	balance += transaction.Amount
+	if balance.IsNegative() {
+		return 0, ErrInsufficientGiftCardBalance
+	}
+	err = tx.Exec("UPDATE gift_cards SET balance = ? WHERE id = ?", balance, transaction.GiftCardID).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to update gift card balance: %w", err)
+	}
+	transaction.Balance = balance
+	if err := g.CreateTransaction(tx, transaction); err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/repository"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func Test_GiftCardRedeem(t *testing.T) {
+	lockCard := regexp.QuoteMeta("SELECT balance FROM gift_cards WHERE id = $1 FOR UPDATE")
+	updateBalance := regexp.QuoteMeta("UPDATE gift_cards SET balance = $1 WHERE id = $2")
+
+	tests := []struct {
+		name      string
+		amount    money.Money
+		setupMock func(mock sqlmock.Sqlmock)
+		want      money.Money
+		expectErr error
+	}{
+		{
+			name:   "part of the balance pays the order",
+			amount: money.Rupees(300),
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(lockCard).WithArgs(4).
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(50000))
+				mock.ExpectExec(updateBalance).WithArgs(20000, 4).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "gift_card_transactions"`)).
+					WithArgs(4, 11, 0, -30000, 20000, "order payment", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+			},
+			want: money.Rupees(200),
+		},
+		{
+			name:   "card cannot be overdrawn",
+			amount: money.Rupees(300),
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(lockCard).WithArgs(4).
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(29999))
+			},
+			expectErr: repository.ErrInsufficientGiftCardBalance,
+		},
+		{
+			name:   "database error",
+			amount: money.Rupees(300),
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(lockCard).WithArgs(4).WillReturnError(gorm.ErrInvalidDB)
+			},
+			expectErr: gorm.ErrInvalidDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger:                 logger.Default.LogMode(logger.Silent),
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+
+			giftCardRepo := repository.NewGiftCardRepository(db)
+
+			tt.setupMock(mock)
+
+			balance, err := giftCardRepo.Redeem(db, 4, tt.amount, 11)
+
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, balance)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
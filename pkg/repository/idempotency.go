@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IdempotencyRepository struct {
+	DB *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{DB: db}
+}
+
+// CreateKey inserts the key unless it already exists for the user and scope,
+// and reports whether this call created it.
+func (i *IdempotencyRepository) CreateKey(key domain.IdempotencyKey) (bool, error) {
+	result := i.DB.Exec(`INSERT INTO idempotency_keys (idempotency_key, user_id, scope, request_hash, status_code, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 0, ?, ?)
+		ON CONFLICT (idempotency_key, user_id, scope) DO NOTHING`,
+		key.Key, key.UserID, key.Scope, key.RequestHash, time.Now(), time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (i *IdempotencyRepository) GetKey(key string, userID int, scope string) (domain.IdempotencyKey, error) {
+	var record domain.IdempotencyKey
+	err := i.DB.Raw("SELECT * FROM idempotency_keys WHERE idempotency_key = ? AND user_id = ? AND scope = ?", key, userID, scope).
+		Scan(&record).Error
+	if err != nil {
+		return domain.IdempotencyKey{}, err
+	}
+	return record, nil
+}
+
+func (i *IdempotencyRepository) SaveResponse(key string, userID int, scope string, statusCode int, body string) error {
+	return i.DB.Exec("UPDATE idempotency_keys SET status_code = ?, response_body = ?, updated_at = ? WHERE idempotency_key = ? AND user_id = ? AND scope = ?",
+		statusCode, body, time.Now(), key, userID, scope).Error
+}
+
+func (i *IdempotencyRepository) DeleteKey(key string, userID int, scope string) error {
+	return i.DB.Exec("DELETE FROM idempotency_keys WHERE idempotency_key = ? AND user_id = ? AND scope = ?", key, userID, scope).Error
+}
@@ -0,0 +1,90 @@
+package repository_test
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/repository"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func Test_CreateIdempotencyKey(t *testing.T) {
+	query := regexp.QuoteMeta(`INSERT INTO idempotency_keys (idempotency_key, user_id, scope, request_hash, status_code, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 0, $5, $6)
+		ON CONFLICT (idempotency_key, user_id, scope) DO NOTHING`)
+
+	tests := []struct {
+		name        string
+		input       domain.IdempotencyKey
+		setupMock   func(mock sqlmock.Sqlmock)
+		wantCreated bool
+		expectErr   bool
+	}{
+		{
+			name:  "new key is claimed",
+			input: domain.IdempotencyKey{Key: "abc-123", UserID: 1, Scope: "placeorder", RequestHash: "hash"},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("abc-123", 1, "placeorder", "hash", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantCreated: true,
+			expectErr:   false,
+		},
+		{
+			name:  "key already exists",
+			input: domain.IdempotencyKey{Key: "abc-123", UserID: 1, Scope: "placeorder", RequestHash: "hash"},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("abc-123", 1, "placeorder", "hash", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantCreated: false,
+			expectErr:   false,
+		},
+		{
+			name:  "database error",
+			input: domain.IdempotencyKey{Key: "abc-123", UserID: 1, Scope: "placeorder", RequestHash: "hash"},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("abc-123", 1, "placeorder", "hash", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnError(gorm.ErrInvalidDB)
+			},
+			wantCreated: false,
+			expectErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			idempotencyRepo := repository.NewIdempotencyRepository(db)
+
+			tt.setupMock(mock)
+
+			created, err := idempotencyRepo.CreateKey(tt.input)
+
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCreated, created)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
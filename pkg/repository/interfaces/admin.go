@@ -18,8 +18,8 @@ type AdminRepository interface {
 	CommittTransaction(tx *gorm.DB) error
 	RollbackkTransaction(tx *gorm.DB) error
 	GetAllOrderDetails() ([]models.FullOrderDetails, error)
-	GetProductStockk(ProductID int) (int, error)
-	UpdateProductStockk(tx *gorm.DB, productID int, newStock int) error
+	UpdateProductStockk(tx *gorm.DB, productID int, quantity int) error
+	RestoreVariantStock(tx *gorm.DB, variantID, quantity int) error
 	AdminOrderRelationship(orderID string, userID int) (int, error)
 	GetProductDetailFromOrders(orderID string) ([]models.OrderProducts, error)
 	Cancelorders(orderID string) error
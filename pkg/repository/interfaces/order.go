@@ -14,7 +14,10 @@ type OrderRepository interface {
 	DoesCartExist(orderBody models.OrderFromCart, userID int) (bool, error)
 	AddressExist(orderBody models.OrderIncoming) (bool, error)
 	GetProductStock(ProductID int) (int, error)
-	UpdateProductStock(tx *gorm.DB, productID int, newStock int) error
+	DecrementProductStock(tx *gorm.DB, productID int, quantity int) error
+	DecrementVariantStock(tx *gorm.DB, variantID int, quantity int) error
+	IncrementProductStock(tx *gorm.DB, productID int, quantity int) error
+	IncrementVariantStock(tx *gorm.DB, variantID int, quantity int) error
 	CreateOrder(tx *gorm.DB, orderDetails models.OrderFromCart) error
 	CreateOrderItems(tx *gorm.DB, orderItems []domain.OrderItem) error
 	GetBriefOrderDetails(orderID string) (domain.OrderSuccessResponse, error)
@@ -31,7 +34,7 @@ type OrderRepository interface {
 	CancelOrderItem(orderItemID string, userID int) (domain.OrderItem, error)
 	ReturnUserOrder(orderID string, userID int) error
 	GetOrderItemPrice(tx *gorm.DB, orderItemID int) (float64, error)
-	GetOrderItemDetails(tx *gorm.DB, orderItemID int) (int, int, error)
+	GetOrderItemDetails(tx *gorm.DB, orderItemID int) (int, int, int, error)
 
 	FetchOrderDetailsFromDB(orderID string) (models.CombinedOrderDetails, error)
 
@@ -13,4 +13,12 @@ type ProductRepository interface {
 	UpdateStock(productID, qty int) error
 	GetAllProducts(showOutOfStock bool) ([]models.ProductResponse, error)
 	GetProductsByCategory(categoryID string, sortBy string) ([]domain.Products, error)
+	SearchProducts(query models.ProductSearchQuery) ([]models.ProductSearchResult, int64, error)
+	GetSearchFacets(query models.ProductSearchQuery) (models.ProductFacets, error)
+
+	AddVariant(variant models.AddVariant) (models.VariantResponse, error)
+	UpdateVariant(variant models.AddVariant, variantID int) (models.VariantResponse, error)
+	DeleteVariant(variantID int) error
+	GetVariantByID(variantID int) (models.VariantResponse, error)
+	GetVariantsByProductID(productID int) ([]models.VariantResponse, error)
 }
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type InvoiceRepository struct {
+	DB *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) *InvoiceRepository {
+	return &InvoiceRepository{DB: db}
+}
+
+// NextNumber takes the next number of a series for a financial year. The
+// counter row stays locked until tx ends, so numbers are handed out one at
+// a time and a rolled back document gives its number back.
+func (i *InvoiceRepository) NextNumber(tx *gorm.DB, series, financialYear string) (int, error) {
+	var number int
+	err := tx.Raw(`INSERT INTO document_sequences (series, financial_year, last_number) VALUES (?, ?, 1)
+		ON CONFLICT (series, financial_year) DO UPDATE SET last_number = document_sequences.last_number + 1
+		RETURNING last_number`, series, financialYear).Scan(&number).Error
+	if err != nil {
+		return 0, err
+	}
+	if number == 0 {
+		return 0, errors.New("failed to take the next document number")
+	}
+	return number, nil
+}
+
+// GetInvoice returns the invoice issued for an order, with a zero ID when
+// none has been issued yet.
+func (i *InvoiceRepository) GetInvoice(tx *gorm.DB, orderID int) (domain.Invoice, error) {
+	var invoice domain.Invoice
+	err := tx.Raw("SELECT * FROM invoices WHERE order_id = ?", orderID).Scan(&invoice).Error
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+	return invoice, nil
+}
+
+func (i *InvoiceRepository) CreateInvoice(tx *gorm.DB, invoice domain.Invoice) (domain.Invoice, error) {
+	if err := tx.Create(&invoice).Error; err != nil {
+		return domain.Invoice{}, err
+	}
+	return invoice, nil
+}
+
+// GetUncreditedLines returns the cancelled and returned lines of an order
+// that no credit note covers yet.
+func (i *InvoiceRepository) GetUncreditedLines(tx *gorm.DB, orderID int) ([]domain.CreditNoteLine, error) {
+	var rows []struct {
+		OrderItemID  int
+		Name         string
+		Size         string
+		SizeSystem   string
+		Colour       string
+		Quantity     int
+		HSNCode      string
+		GSTRate      float64
+		TaxableValue money.Money
+		CGST         money.Money
+		SGST         money.Money
+		IGST         money.Money
+	}
+	err := tx.Raw(`SELECT order_items.id AS order_item_id, products.name,
+			product_variants.size, product_variants.size_system, product_variants.colour,
+			order_items.quantity, order_items.hsn_code, order_items.gst_rate,
+			order_items.taxable_value, order_items.cgst, order_items.sgst, order_items.igst
+		FROM order_items
+		JOIN products ON products.id = order_items.product_id
+		LEFT JOIN product_variants ON product_variants.id = order_items.variant_id
+		WHERE order_items.order_id = ? AND order_items.status IN ?
+			AND NOT EXISTS (SELECT 1 FROM credit_note_lines WHERE credit_note_lines.order_item_id = order_items.id)
+		ORDER BY order_items.id`,
+		orderID, []string{models.Cancelled, models.Return}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]domain.CreditNoteLine, 0, len(rows))
+	for _, row := range rows {
+		description := row.Name
+		if row.Size != "" {
+			description = fmt.Sprintf("%s (Size %s %s, %s)", row.Name, row.Size, row.SizeSystem, row.Colour)
+		}
+		lines = append(lines, domain.CreditNoteLine{
+			OrderItemID:  row.OrderItemID,
+			Description:  description,
+			Quantity:     row.Quantity,
+			HSNCode:      row.HSNCode,
+			GSTRate:      row.GSTRate,
+			TaxableValue: row.TaxableValue,
+			CGST:         row.CGST,
+			SGST:         row.SGST,
+			IGST:         row.IGST,
+		})
+	}
+	return lines, nil
+}
+
+// GetUncreditedDeliveryCharge returns the delivery charge of an order that
+// has been cancelled or returned as a whole, unless a credit note already
+// covers it. It is zero while the order is still open.
+func (i *InvoiceRepository) GetUncreditedDeliveryCharge(tx *gorm.DB, orderID int) (money.Money, error) {
+	var charge money.Money
+	err := tx.Raw(`SELECT delivery_charge FROM orders WHERE order_id = ? AND order_status IN ?
+			AND NOT EXISTS (SELECT 1 FROM credit_notes WHERE credit_notes.order_id = orders.order_id AND credit_notes.delivery_charge > 0)`,
+		orderID, []string{models.Cancelled, models.Return}).Scan(&charge).Error
+	if err != nil {
+		return 0, err
+	}
+	return charge, nil
+}
+
+// CreateCreditNote saves a credit note together with its lines.
+func (i *InvoiceRepository) CreateCreditNote(tx *gorm.DB, note domain.CreditNote) (domain.CreditNote, error) {
+	if err := tx.Create(&note).Error; err != nil {
+		return domain.CreditNote{}, err
+	}
+	return note, nil
+}
+
+func (i *InvoiceRepository) GetCreditNotes(orderID int) ([]domain.CreditNote, error) {
+	var notes []domain.CreditNote
+	err := i.DB.Preload("Lines").Omit("PDF").Where("order_id = ?", orderID).Order("id").Find(&notes).Error
+	if err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+func (i *InvoiceRepository) GetCreditNote(creditNoteID int) (domain.CreditNote, error) {
+	var note domain.CreditNote
+	err := i.DB.Raw("SELECT * FROM credit_notes WHERE id = ?", creditNoteID).Scan(&note).Error
+	if err != nil {
+		return domain.CreditNote{}, err
+	}
+	if note.ID == 0 {
+		return domain.CreditNote{}, errors.New("credit note not found")
+	}
+	return note, nil
+}
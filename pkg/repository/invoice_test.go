@@ -0,0 +1,80 @@
+package repository_test
+
+import (
+	"ecommerce_clean_arch/pkg/repository"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func Test_NextNumber(t *testing.T) {
+	query := regexp.QuoteMeta(`INSERT INTO document_sequences (series, financial_year, last_number) VALUES ($1, $2, 1)
+		ON CONFLICT (series, financial_year) DO UPDATE SET last_number = document_sequences.last_number + 1
+		RETURNING last_number`)
+
+	tests := []struct {
+		name      string
+		setupMock func(mock sqlmock.Sqlmock)
+		want      int
+		expectErr bool
+	}{
+		{
+			name: "first invoice of the year",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"last_number"}).AddRow(1)
+				mock.ExpectQuery(query).WithArgs("SS", "2026-27").WillReturnRows(rows)
+			},
+			want:      1,
+			expectErr: false,
+		},
+		{
+			name: "next invoice in the series",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"last_number"}).AddRow(124)
+				mock.ExpectQuery(query).WithArgs("SS", "2026-27").WillReturnRows(rows)
+			},
+			want:      124,
+			expectErr: false,
+		},
+		{
+			name: "database error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs("SS", "2026-27").WillReturnError(gorm.ErrInvalidDB)
+			},
+			want:      0,
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			invoiceRepo := repository.NewInvoiceRepository(db)
+
+			tt.setupMock(mock)
+
+			number, err := invoiceRepo.NextNumber(db, "SS", "2026-27")
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, number)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/ledger"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type LedgerRepository struct {
+	DB *gorm.DB
+}
+
+func NewLedgerRepository(DB *gorm.DB) *LedgerRepository {
+	return &LedgerRepository{DB: DB}
+}
+
+// Post records a balanced journal entry for source inside tx. Wallet
+// movements go through WalletRepository.Credit and Debit instead, which
+// also keep the cached balance in step.
+func (l *LedgerRepository) Post(tx *gorm.DB, source ledger.Source, lines []ledger.Line) (domain.JournalEntry, error) {
+	return postJournal(tx, source, lines)
+}
+
+func postJournal(tx *gorm.DB, source ledger.Source, lines []ledger.Line) (domain.JournalEntry, error) {
+	if err := ledger.Validate(lines); err != nil {
+		return domain.JournalEntry{}, err
+	}
+
+	entry := domain.JournalEntry{
+		SourceType:  source.Type,
+		SourceID:    source.ID,
+		Description: source.Description,
+	}
+	for _, line := range lines {
+		accountID, err := ledgerAccountID(tx, line.Account)
+		if err != nil {
+			return domain.JournalEntry{}, err
+		}
+		entry.Postings = append(entry.Postings, domain.Posting{
+			AccountID: accountID,
+			Account:   line.Account,
+			Amount:    line.Amount,
+		})
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return domain.JournalEntry{}, fmt.Errorf("failed to record journal entry: %w", err)
+	}
+	return entry, nil
+}
+
+// ledgerAccountID opens an account the first time money moves through it.
+func ledgerAccountID(tx *gorm.DB, code string) (int, error) {
+	err := tx.Exec(`INSERT INTO ledger_accounts (code, kind, created_at) VALUES (?, ?, NOW())
+		ON CONFLICT (code) DO NOTHING`, code, ledger.Kind(code)).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to open ledger account %s: %w", code, err)
+	}
+	var id int
+	if err := tx.Raw("SELECT id FROM ledger_accounts WHERE code = ?", code).Scan(&id).Error; err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetUnbalancedEntries lists journal entries whose postings do not add up
+// to zero, or that have fewer than two.
+func (l *LedgerRepository) GetUnbalancedEntries() ([]models.UnbalancedEntry, error) {
+	var entries []models.UnbalancedEntry
+	err := l.DB.Raw(`SELECT journal_entries.id AS journal_entry_id, journal_entries.source_type, journal_entries.source_id,
+			COALESCE(SUM(postings.amount), 0) AS imbalance, COUNT(postings.id) AS postings
+		FROM journal_entries LEFT JOIN postings ON postings.journal_entry_id = journal_entries.id
+		GROUP BY journal_entries.id
+		HAVING COALESCE(SUM(postings.amount), 0) <> 0 OR COUNT(postings.id) < 2
+		ORDER BY journal_entries.id`).Scan(&entries).Error
+	return entries, err
+}
+
+// GetWalletDrift lists wallets whose cached balance differs from the
+// balance of their ledger account, including ledger accounts without a
+// wallet row and wallets that were never posted to.
+func (l *LedgerRepository) GetWalletDrift() ([]models.WalletDrift, error) {
+	var drift []models.WalletDrift
+	err := l.DB.Raw(`SELECT COALESCE(wallets.user_id, accounts.user_id) AS user_id,
+			COALESCE(wallets.balance, 0) AS wallet_balance, COALESCE(accounts.balance, 0) AS ledger_balance
+		FROM wallets FULL OUTER JOIN (
+			SELECT CAST(SUBSTRING(ledger_accounts.code FROM 8) AS bigint) AS user_id, -SUM(postings.amount) AS balance
+			FROM ledger_accounts JOIN postings ON postings.account_id = ledger_accounts.id
+			WHERE ledger_accounts.code LIKE 'wallet:%'
+			GROUP BY ledger_accounts.code
+		) accounts ON accounts.user_id = wallets.user_id
+		WHERE COALESCE(wallets.balance, 0) <> COALESCE(accounts.balance, 0)
+		ORDER BY 1`).Scan(&drift).Error
+	return drift, err
+}
+
+// ResetWalletBalances sets drifted wallets back to the balance of their
+// ledger account, creating wallets that are missing.
+func (l *LedgerRepository) ResetWalletBalances(drift []models.WalletDrift) error {
+	return l.DB.Transaction(func(tx *gorm.DB) error {
+		for _, wallet := range drift {
+			err := tx.Exec(`INSERT INTO wallets (user_id, balance) VALUES (?, ?)
+				ON CONFLICT (user_id) DO UPDATE SET balance = EXCLUDED.balance`, wallet.UserID, wallet.LedgerBalance).Error
+			if err != nil {
+				return fmt.Errorf("failed to reset wallet of user %d: %w", wallet.UserID, err)
+			}
+		}
+		return nil
+	})
+}
@@ -2,13 +2,17 @@ package repository
 
 import (
 	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/money"
 	"ecommerce_clean_arch/pkg/utils/models"
 	"errors"
+	"fmt"
 	"log"
 
 	"gorm.io/gorm"
 )
 
+var ErrInsufficientStock = errors.New("insufficient stock")
+
 type OrderRepository struct {
 	DB *gorm.DB
 }
@@ -53,6 +57,64 @@ func (o *OrderRepository) AddressExist(AddressID int) (bool, error) {
 	return count > 0, nil
 }
 
+// GetAddressState returns the state of a delivery address.
+func (o *OrderRepository) GetAddressState(tx *gorm.DB, addressID int) (string, error) {
+	var state string
+	err := tx.Raw("SELECT state FROM addresses WHERE id = ?", addressID).Scan(&state).Error
+	if err != nil {
+		return "", err
+	}
+	return state, nil
+}
+
+// GetAddressPin returns the pincode of a delivery address.
+func (o *OrderRepository) GetAddressPin(tx *gorm.DB, addressID int) (string, error) {
+	var pin string
+	err := tx.Raw("SELECT pin FROM addresses WHERE id = ?", addressID).Scan(&pin).Error
+	if err != nil {
+		return "", err
+	}
+	return pin, nil
+}
+
+// GetCartWeight returns the shipping weight of a user's cart in grams,
+// counting defaultWeight for items whose category has none set.
+func (o *OrderRepository) GetCartWeight(tx *gorm.DB, userID int, defaultWeight int) (int, error) {
+	var weight int
+	err := tx.Raw(`SELECT COALESCE(SUM(carts.quantity * COALESCE(NULLIF(categories.weight_grams, 0), ?)), 0)
+		FROM carts
+		JOIN products ON products.id = carts.product_id
+		JOIN categories ON categories.id = products.category_id
+		WHERE carts.user_id = ? AND carts.deleted_at IS NULL`, defaultWeight, userID).Scan(&weight).Error
+	if err != nil {
+		return 0, err
+	}
+	return weight, nil
+}
+
+// CountDeliveredOrders returns how many of a user's orders have been
+// delivered.
+func (o *OrderRepository) CountDeliveredOrders(tx *gorm.DB, userID int) (int, error) {
+	var count int
+	err := tx.Raw("SELECT COUNT(*) FROM orders WHERE user_id = ? AND order_status = ?", userID, models.Delivered).Scan(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetProductTax returns the HSN code and GST rates of a product's category.
+func (o *OrderRepository) GetProductTax(tx *gorm.DB, productID int) (models.ProductTax, error) {
+	var productTax models.ProductTax
+	err := tx.Raw(`SELECT categories.hsn_code, categories.gst_rate, categories.gst_higher_rate, categories.gst_threshold
+		FROM products JOIN categories ON categories.id = products.category_id
+		WHERE products.id = ?`, productID).Scan(&productTax).Error
+	if err != nil {
+		return models.ProductTax{}, err
+	}
+	return productTax, nil
+}
+
 func (o *OrderRepository) GetProductStock(tx *gorm.DB, productID int) (int, error) {
 	var stock int
 	err := tx.Raw("select stock from products where id = ?", productID).Scan(&stock).Error
@@ -61,8 +123,37 @@ func (o *OrderRepository) GetProductStock(tx *gorm.DB, productID int) (int, erro
 	}
 	return stock, nil
 }
-func (o *OrderRepository) UpdateProductStock(tx *gorm.DB, productID int, newStock int) error {
-	return tx.Model(&domain.Products{}).Where("id = ?", productID).Update("stock", newStock).Error
+
+// DecrementProductStock takes quantity off the product's stock in a single
+// conditional UPDATE, so concurrent checkouts cannot oversell it.
+func (o *OrderRepository) DecrementProductStock(tx *gorm.DB, productID int, quantity int) error {
+	result := tx.Exec("UPDATE products SET stock = stock - ? WHERE id = ? AND stock >= ?", quantity, productID, quantity)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+func (o *OrderRepository) DecrementVariantStock(tx *gorm.DB, variantID int, quantity int) error {
+	result := tx.Exec("UPDATE product_variants SET stock = stock - ? WHERE id = ? AND stock >= ?", quantity, variantID, quantity)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+func (o *OrderRepository) IncrementProductStock(tx *gorm.DB, productID int, quantity int) error {
+	return tx.Exec("UPDATE products SET stock = stock + ? WHERE id = ?", quantity, productID).Error
+}
+
+func (o *OrderRepository) IncrementVariantStock(tx *gorm.DB, variantID int, quantity int) error {
+	return tx.Exec("UPDATE product_variants SET stock = stock + ? WHERE id = ?", quantity, variantID).Error
 }
 
 func (o *OrderRepository) CreateOrder(tx *gorm.DB, orderDetails models.Order) (int, error) {
@@ -116,44 +207,39 @@ func (o *OrderRepository) UserOrderRelationship(orderID int, userID int) (int, e
 	return testUserID, nil
 }
 
+// GetProductDetailsFromOrders returns the order's lines that are still live,
+// with FinalPrice set to what the customer paid for each.
 func (o *OrderRepository) GetProductDetailsFromOrders(tx *gorm.DB, orderID int) ([]models.OrderProducts, error) {
 	var orderProductDetails []models.OrderProducts
-	err := tx.Raw("select * from order_items where order_id = ?", orderID).Scan(&orderProductDetails).Error
+	err := tx.Raw(`SELECT product_id, variant_id, quantity, total_price - category_discount - coupon_discount AS final_price
+		FROM order_items WHERE order_id = ? AND status NOT IN ?`, orderID, models.ClosedItemStatuses).Scan(&orderProductDetails).Error
 	if err != nil {
 		return nil, err
 	}
-	var FinalPrice []float64
-	err = tx.Raw("select final_price from orders where order_id = ?", orderID).Scan(&FinalPrice).Error
-	if err != nil {
-		return []models.OrderProducts{}, err
-	}
-	for i, val := range orderProductDetails {
-		orderProductDetails[i].FinalPrice = FinalPrice[i]
-		log.Println("HASHIM________________________", val.FinalPrice, FinalPrice[i])
-	}
-	log.Println("final____________-price", FinalPrice, orderProductDetails)
 	return orderProductDetails, nil
 }
 
-func (o *OrderRepository) GetOrderItemPrice(tx *gorm.DB, orderItemID int) (float64, error) {
-	var price float64
-	err := tx.Raw("select total_price from order_items where id = ?", orderItemID).Scan(&price).Error
+func (o *OrderRepository) GetOrderItem(tx *gorm.DB, orderItemID int) (domain.OrderItem, error) {
+	var orderItem domain.OrderItem
+	err := tx.Raw("SELECT * FROM order_items WHERE id = ?", orderItemID).Scan(&orderItem).Error
 	if err != nil {
-		return 0.0, err
+		return domain.OrderItem{}, err
+	}
+	if orderItem.ID == 0 {
+		return domain.OrderItem{}, errors.New("order item does not exist")
 	}
-	return price, nil
+	return orderItem, nil
 }
 
-func (o *OrderRepository) GetOrderItemDetails(tx *gorm.DB, orderItemID int) (int, int, error) {
-	var orderItem struct {
-		productID int
-		quantity  int
-	}
-	err := tx.Raw("SELECT product_id, quantity FROM order_items WHERE id = ?", orderItemID).Scan(&orderItem).Error
-	if err != nil {
-		return 0, 0, err
-	}
-	return orderItem.productID, orderItem.quantity, nil
+// ReduceOrderTotals takes a cancelled or returned line off the order's
+// totals. The delivery charge stays with the order.
+func (o *OrderRepository) ReduceOrderTotals(tx *gorm.DB, item domain.OrderItem) error {
+	return tx.Exec(`UPDATE orders SET raw_total = raw_total - ?, grand_total = grand_total - ?,
+			category_discount = category_discount - ?, discount_amount = discount_amount - ?,
+			final_price = GREATEST(final_price - ?, 0)
+		WHERE order_id = ?`,
+		item.Price.Times(item.Quantity), item.TotalPrice, item.CategoryDiscount, item.CouponDiscount,
+		item.TotalPrice-item.CategoryDiscount-item.CouponDiscount, item.OrderID).Error
 }
 
 func (o *OrderRepository) GetOrderStatus(tx *gorm.DB, orderID int) (string, error) {
@@ -164,23 +250,11 @@ func (o *OrderRepository) GetOrderStatus(tx *gorm.DB, orderID int) (string, erro
 	}
 	return OrderStatus, nil
 }
-func (o *OrderRepository) GetPaymentStatus(tx *gorm.DB, orderID string) (string, error) {
-	var paymentstatus string
-	err := tx.Raw("select payment_status from orders where order_id=?", orderID).Scan(&paymentstatus).Error
-	if err != nil {
-		return "", err
-	}
-	return paymentstatus, nil
-}
-func (o *OrderRepository) UpdatePaymentStatus(tx *gorm.DB, orderID int, paymentStatus string) error {
-	return tx.Exec("UPDATE orders SET payment_status = ? WHERE order_id = ?", paymentStatus, orderID).Error
-}
-
-func (o *OrderRepository) GetPriceoftheproduct(tx *gorm.DB, orderID string) (float64, error) {
-	var a float64
+func (o *OrderRepository) GetPriceoftheproduct(tx *gorm.DB, orderID string) (money.Money, error) {
+	var a money.Money
 	err := tx.Raw("select final_price from orders where order_id=?", orderID).Scan(&a).Error
 	if err != nil {
-		return 0.0, err
+		return 0, err
 	}
 	return a, nil
 }
@@ -201,11 +275,11 @@ func (o *OrderRepository) FetchOrderDetailsFromDB(orderID string) (models.Orders
 	}
 
 	var orderItems []domain.OrderItem
-	if err := o.DB.Where("order_id = ?", orderID).Find(&orderItems).Error; err != nil {
+	if err := o.DB.Where("order_id = ? AND status NOT IN ?", orderID, models.ClosedItemStatuses).Find(&orderItems).Error; err != nil {
 		return models.OrdersDetails{}, err
 	}
 	var products []domain.Products
-	var RawTotal float64
+	var RawTotal money.Money
 	for _, item := range orderItems {
 		var product domain.Products
 		if err := o.DB.Model(&product).Where("id = ?", item.ProductID).First(&product).Error; err != nil {
@@ -215,7 +289,7 @@ func (o *OrderRepository) FetchOrderDetailsFromDB(orderID string) (models.Orders
 
 		for _, product := range products {
 			if item.Product.ID == product.ID {
-				RawTotal += product.Price * float64(item.Quantity)
+				RawTotal += product.Price.Times(item.Quantity)
 			}
 		}
 	}
@@ -226,10 +300,29 @@ func (o *OrderRepository) FetchOrderDetailsFromDB(orderID string) (models.Orders
 		if err := o.DB.Model(&product).Where("id = ?", orderItem.ProductID).First(&product).Error; err != nil {
 			return models.OrdersDetails{}, err
 		}
+		var variantLabel string
+		price := product.Price
+		if orderItem.VariantID != 0 {
+			var variant domain.ProductVariant
+			if err := o.DB.Unscoped().Where("id = ?", orderItem.VariantID).First(&variant).Error; err != nil {
+				return models.OrdersDetails{}, err
+			}
+			variantLabel = fmt.Sprintf("Size %s %s, %s", variant.Size, variant.SizeSystem, variant.Colour)
+			if variant.PriceOverride > 0 {
+				price = variant.PriceOverride
+			}
+		}
 		items = append(items, models.InvoiceItem{
-			Name:     product.Name,
-			Quantity: uint(orderItem.Quantity),
-			Price:    product.Price,
+			Name:         product.Name,
+			Variant:      variantLabel,
+			Quantity:     uint(orderItem.Quantity),
+			Price:        price,
+			HSNCode:      orderItem.HSNCode,
+			GSTRate:      orderItem.GSTRate,
+			TaxableValue: orderItem.TaxableValue,
+			CGST:         orderItem.CGST,
+			SGST:         orderItem.SGST,
+			IGST:         orderItem.IGST,
 		})
 	}
 
@@ -269,10 +362,13 @@ func (o *OrderRepository) GetOrderDetails(userID int) ([]models.FullOrderDetails
 		var orderProductDetails []models.OrderProductDetails
 		o.DB.Raw(`
 			SELECT 
+				order_items.id AS order_item_id, 
 				order_items.product_id, 
 				products.name AS product_name, 
+				order_items.variant_id, 
 				order_items.quantity, 
-				order_items.total_price 
+				order_items.total_price, 
+				order_items.status 
 			FROM order_items 
 			INNER JOIN products ON order_items.product_id = products.id 
 			WHERE order_items.order_id = ?`, od.OrderId).Scan(&orderProductDetails)
@@ -284,74 +380,24 @@ func (o *OrderRepository) GetOrderDetails(userID int) ([]models.FullOrderDetails
 	return fullOrderDetails, nil
 }
 
-func (o *OrderRepository) GetWalletAmount(tx *gorm.DB, userID int) (float64, error) {
-	var walletAvailable float64
+func (o *OrderRepository) GetWalletAmount(tx *gorm.DB, userID int) (money.Money, error) {
+	var walletAvailable money.Money
 	err := tx.Raw("select balance from wallets where user_id = ?", userID).Scan(&walletAvailable).Error
 	if err != nil {
-		return 0.0, err
+		return 0, err
 	}
 	return walletAvailable, nil
 }
 
-func (o *OrderRepository) UpdateWalletAmount(tx *gorm.DB, walletAmount float64, UserID int) error {
-	return tx.Exec("UPDATE wallets SET balance = ? WHERE user_id = ?", walletAmount, UserID).Error
-}
-
-func (o *OrderRepository) CancelOrders(tx *gorm.DB, orderID int) error {
-	OrderStatus := "cancelled"
-	err := tx.Exec("UPDATE orders SET order_status = ? WHERE order_id = ?", OrderStatus, orderID).Error
-	if err != nil {
-		return err
-	}
-	var paymentMethod int
-	err = tx.Raw("SELECT payment_method_id FROM orders WHERE order_id = ?", orderID).Scan(&paymentMethod).Error
-	if err != nil {
-		return err
-	}
-	if paymentMethod == 1 || paymentMethod == 3 {
-		err = tx.Exec("UPDATE orders SET payment_status = 'refunded' WHERE order_id = ?", orderID).Error
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 func (o *OrderRepository) UpdateQuantityOfProduct(tx *gorm.DB, orderProducts []models.OrderProducts) error {
 	for _, od := range orderProducts {
-		var quantity int
-		err := tx.Raw("select quantity from products where id = ?", od.ProductID).Scan(&quantity).Error
-		if err != nil {
-			return err
-		}
-		od.Quantity += quantity
-		if err := tx.Exec("update products set quantity = ? where id = ?", od.Quantity, od.ProductID).Error; err != nil {
+		if err := tx.Exec("update products set quantity = quantity + ? where id = ?", od.Quantity, od.ProductID).Error; err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (o *OrderRepository) CancelOrderItem(tx *gorm.DB, orderItemID int) error {
-	result := tx.Exec("DELETE FROM order_items WHERE id = ?", orderItemID)
-	if result.Error != nil {
-		return errors.New("error cancelling order item")
-	}
-	return nil
-}
-
-func (o *OrderRepository) UpdateUserOrderReturn(tx *gorm.DB, orderID int, userID int) error {
-	query := "UPDATE orders SET order_status = 'returned', payment_status = 'refunded' WHERE order_id = ? AND user_id = ?"
-	result := tx.Exec(query, orderID, userID)
-	if result.Error != nil {
-		return errors.New("error updating order return status")
-	}
-	if result.RowsAffected == 0 {
-		return errors.New("no rows updated, check if order ID and user ID are correct")
-	}
-	return nil
-}
-
 func (o *OrderRepository) GetCouponDetails(couponCode string) (models.Coupon, error) {
 	var coupon models.Coupon
 	query := "SELECT id, coupon_code, discount, minimum_required, maximum_allowed, maximum_usage, expire_date FROM coupons WHERE coupon_code = $1"
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OrderStatusRepository struct {
+	DB *gorm.DB
+}
+
+func NewOrderStatusRepository(db *gorm.DB) *OrderStatusRepository {
+	return &OrderStatusRepository{DB: db}
+}
+
+// LockOrderStatus reads the order's status and holds the row until tx ends so
+// concurrent transitions are applied one after the other.
+func (r *OrderStatusRepository) LockOrderStatus(tx *gorm.DB, orderID int) (string, error) {
+	var status string
+	err := tx.Raw("SELECT order_status FROM orders WHERE order_id = ? FOR UPDATE", orderID).Scan(&status).Error
+	if err != nil {
+		return "", err
+	}
+	if status == "" {
+		return "", errors.New("order not found")
+	}
+	return status, nil
+}
+
+func (r *OrderStatusRepository) UpdateOrderStatus(tx *gorm.DB, orderID int, status string) error {
+	return tx.Exec("UPDATE orders SET order_status = ?, updated_at = ? WHERE order_id = ?", status, time.Now(), orderID).Error
+}
+
+// UpdateOpenItemStatus moves every line of the order that has not been
+// cancelled or returned on its own along with the order.
+func (r *OrderStatusRepository) UpdateOpenItemStatus(tx *gorm.DB, orderID int, status string) error {
+	return tx.Exec("UPDATE order_items SET status = ? WHERE order_id = ? AND status NOT IN ?",
+		status, orderID, models.ClosedItemStatuses).Error
+}
+
+// LockOrderItemStatus reads an order line's status and order, holding the
+// row until tx ends.
+func (r *OrderStatusRepository) LockOrderItemStatus(tx *gorm.DB, orderItemID int) (int, string, error) {
+	var item struct {
+		OrderID int
+		Status  string
+	}
+	err := tx.Raw("SELECT order_id, status FROM order_items WHERE id = ? FOR UPDATE", orderItemID).Scan(&item).Error
+	if err != nil {
+		return 0, "", err
+	}
+	if item.OrderID == 0 {
+		return 0, "", errors.New("order item does not exist")
+	}
+	return item.OrderID, item.Status, nil
+}
+
+func (r *OrderStatusRepository) UpdateOrderItemStatus(tx *gorm.DB, orderItemID int, status string) error {
+	return tx.Exec("UPDATE order_items SET status = ? WHERE id = ?", status, orderItemID).Error
+}
+
+func (r *OrderStatusRepository) GetItemStatuses(tx *gorm.DB, orderID int) ([]string, error) {
+	var statuses []string
+	err := tx.Raw("SELECT status FROM order_items WHERE order_id = ?", orderID).Scan(&statuses).Error
+	if err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+func (r *OrderStatusRepository) CreateHistory(tx *gorm.DB, history domain.OrderStatusHistory) error {
+	return tx.Create(&history).Error
+}
+
+func (r *OrderStatusRepository) GetHistory(orderID int) ([]domain.OrderStatusHistory, error) {
+	var history []domain.OrderStatusHistory
+	err := r.DB.Raw("SELECT * FROM order_status_history WHERE order_id = ? ORDER BY created_at, id", orderID).Scan(&history).Error
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
@@ -2,6 +2,7 @@ package repository_test
 
 import (
 	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/money"
 	"ecommerce_clean_arch/pkg/repository"
 	"regexp"
 	"testing"
@@ -24,17 +25,22 @@ func Test_CreateOrderItems(t *testing.T) {
 			name: "Succcessfully created OrderItems",
 			input: []domain.OrderItem{
 				{
-					OrderID:    1,
-					ProductID:  1,
-					Quantity:   15,
-					TotalPrice: 2000,
+					OrderID:      1,
+					ProductID:    1,
+					Quantity:     15,
+					TotalPrice:   money.Rupees(2000),
+					HSNCode:      "6403",
+					GSTRate:      18,
+					TaxableValue: money.Paise(169492),
+					CGST:         money.Paise(15254),
+					SGST:         money.Paise(15254),
 				},
 			},
 			setupMock: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
 				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "order_items" 
-				("order_id","product_id","quantity","total_price") VALUES ($1,$2,$3,$4) RETURNING "id"`)).
-					WithArgs(1, 1, 15, 2000.0).
+				("order_id","product_id","variant_id","quantity","price","total_price","category_discount","coupon_discount","status","hsn_code","gst_rate","taxable_value","cgst","sgst","igst") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15) RETURNING "id"`)).
+					WithArgs(1, 1, 0, 15, 0, 200000, 0, 0, "", "6403", 18.0, 169492, 15254, 15254, 0).
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 				mock.ExpectCommit()
 			},
@@ -47,14 +53,14 @@ func Test_CreateOrderItems(t *testing.T) {
 					OrderID:    2,
 					ProductID:  2,
 					Quantity:   15,
-					TotalPrice: 3000,
+					TotalPrice: money.Rupees(3000),
 				},
 			},
 			setupMock: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
 				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "order_items" 
-				("order_id","product_id","quantity","total_price") VALUES ($1,$2,$3,$4) RETURNING "id"`)).
-					WithArgs(2, 2, 15, 3000.0).
+				("order_id","product_id","variant_id","quantity","price","total_price","category_discount","coupon_discount","status","hsn_code","gst_rate","taxable_value","cgst","sgst","igst") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15) RETURNING "id"`)).
+					WithArgs(2, 2, 0, 15, 0, 300000, 0, 0, "", "", 0.0, 0, 0, 0, 0).
 					WillReturnError(gorm.ErrInvalidDB)
 				mock.ExpectRollback()
 			},
@@ -155,6 +161,145 @@ func Test_GetProductStock(t *testing.T) {
 	}
 }
 
+func Test_DecrementProductStock(t *testing.T) {
+	tests := []struct {
+		name      string
+		productID int
+		quantity  int
+		setupMock func(mock sqlmock.Sqlmock)
+		expectErr error
+	}{
+		{
+			name:      "Successfully decremented the stock",
+			productID: 1,
+			quantity:  2,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE products SET stock = stock - $1 WHERE id = $2 AND stock >= $3`)).
+					WithArgs(2, 1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectErr: nil,
+		},
+		{
+			name:      "Not enough stock left",
+			productID: 1,
+			quantity:  5,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE products SET stock = stock - $1 WHERE id = $2 AND stock >= $3`)).
+					WithArgs(5, 1, 5).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			expectErr: repository.ErrInsufficientStock,
+		},
+		{
+			name:      "Failed to decrement the stock",
+			productID: 2,
+			quantity:  1,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE products SET stock = stock - $1 WHERE id = $2 AND stock >= $3`)).
+					WithArgs(1, 2, 1).
+					WillReturnError(gorm.ErrInvalidDB)
+			},
+			expectErr: gorm.ErrInvalidDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			orderRepo := repository.NewOrderRepository(db)
+
+			tt.setupMock(mock)
+
+			err = orderRepo.DecrementProductStock(db, tt.productID, tt.quantity)
+
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_ReduceOrderTotals(t *testing.T) {
+	query := regexp.QuoteMeta(`UPDATE orders SET raw_total = raw_total - $1, grand_total = grand_total - $2,
+			category_discount = category_discount - $3, discount_amount = discount_amount - $4,
+			final_price = GREATEST(final_price - $5, 0)
+		WHERE order_id = $6`)
+	item := domain.OrderItem{
+		ID:               4,
+		OrderID:          9,
+		Quantity:         2,
+		Price:            money.Rupees(1200),
+		TotalPrice:       money.Rupees(2000),
+		CategoryDiscount: money.Rupees(100),
+		CouponDiscount:   money.Rupees(150),
+	}
+
+	tests := []struct {
+		name      string
+		setupMock func(mock sqlmock.Sqlmock)
+		expectErr bool
+	}{
+		{
+			name: "line is taken off the order",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(240000, 200000, 10000, 15000, 175000, 9).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			expectErr: false,
+		},
+		{
+			name: "database error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs(240000, 200000, 10000, 15000, 175000, 9).
+					WillReturnError(gorm.ErrInvalidDB)
+			},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			orderRepo := repository.NewOrderRepository(db)
+
+			tt.setupMock(mock)
+
+			err = orderRepo.ReduceOrderTotals(db, item)
+
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func Te()  {
 	
 }
@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/money"
 	"ecommerce_clean_arch/pkg/utils/models"
 	"errors"
+	"strconv"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -14,9 +18,18 @@ type PaymentRepository struct {
 func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
 	return &PaymentRepository{DB: db}
 }
-func (pay *PaymentRepository) AddRazorPayDetails(orderID string, razorPayOrderID string) error {
 
-	razorPay := models.RazorPay{OrderID: orderID, RazorID: razorPayOrderID}
+func (pay *PaymentRepository) BeginTransaction() (*gorm.DB, error) {
+	tx := pay.DB.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tx, nil
+}
+
+func (pay *PaymentRepository) AddRazorPayDetails(orderID string, razorPayOrderID string, amount money.Money) error {
+
+	razorPay := models.RazorPay{OrderID: orderID, RazorID: razorPayOrderID, Amount: amount}
 	err := pay.DB.Create(&razorPay).Error
 	if err != nil {
 		return err
@@ -27,7 +40,7 @@ func (pay *PaymentRepository) GetOrderDetailsByOrderId(orderID string) (models.C
 	var orderDetails models.CombinedOrderDetails
 	err := pay.DB.Raw(`
 	SELECT 
-		orders.order_id, orders.final_price, orders.order_status, 
+		orders.order_id, orders.final_price, orders.wallet_amount, orders.gift_card_amount, orders.payment_method_id, orders.order_status, 
 		orders.payment_status, users.first_name, users.email, users.phone,
 		addresses.house_name, addresses.street, addresses.city, 
 		addresses.district, addresses.state, addresses.pin 
@@ -44,6 +57,18 @@ func (pay *PaymentRepository) GetOrderDetailsByOrderId(orderID string) (models.C
 	}
 	return orderDetails, nil
 }
+func (pay *PaymentRepository) GetOrderUserID(orderID int) (int, error) {
+	var userID int
+	err := pay.DB.Raw("SELECT user_id FROM orders WHERE order_id = ?", orderID).Scan(&userID).Error
+	if err != nil {
+		return 0, err
+	}
+	if userID == 0 {
+		return 0, errors.New("order not found")
+	}
+	return userID, nil
+}
+
 func (pay *PaymentRepository) CheckPaymentStatus(orderID int) (string, error) {
 	var paymentStatus string
 	err := pay.DB.Raw("select payment_status from orders where order_id = ?", orderID).Scan(&paymentStatus).Error
@@ -53,20 +78,125 @@ func (pay *PaymentRepository) CheckPaymentStatus(orderID int) (string, error) {
 	return paymentStatus, nil
 }
 
-func (pay *PaymentRepository) UpdateOnlinePaymentSucess(orderID int) (*[]models.CombinedOrderDetails, error) {
+func (pay *PaymentRepository) UpdateOnlinePaymentSucess(tx *gorm.DB, orderID int) (*[]models.CombinedOrderDetails, error) {
 	var orders []models.CombinedOrderDetails
-	err := pay.DB.Raw("UPDATE orders set payment_status = 'paid',order_status = 'success' where order_id = ?", orderID).Scan(&orders).Error
+	err := tx.Raw("UPDATE orders set payment_status = 'paid' where order_id = ?", orderID).Scan(&orders).Error
 	if err != nil {
 		return nil, err
 	}
 	return &orders, nil
 }
-func (pay *PaymentRepository) UpdatePaymentDetails(orderID int, paymentID string) error {
 
-	err := pay.DB.Model(&models.RazorPay{}).Where("order_id = ?", orderID).Update("payment_id", paymentID).Error
+// HasGatewayOrder reports whether razorID is one of the gateway orders
+// created to pay for orderID.
+func (pay *PaymentRepository) HasGatewayOrder(orderID int, razorID string) (bool, error) {
+	var count int64
+	err := pay.DB.Model(&models.RazorPay{}).Where("order_id = ? AND razor_id = ?", strconv.Itoa(orderID), razorID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// UpdatePaymentDetails records the payment that paid the gateway order
+// razorID of orderID.
+func (pay *PaymentRepository) UpdatePaymentDetails(orderID int, razorID, paymentID string) error {
+	err := pay.DB.Model(&models.RazorPay{}).Where("order_id = ? AND razor_id = ?", strconv.Itoa(orderID), razorID).
+		Update("payment_id", paymentID).Error
 	if err != nil {
 		return err
 	}
 	return nil
+}
+
+// GetGatewayOrderAmount returns what the gateway order razorID was created
+// to collect.
+func (pay *PaymentRepository) GetGatewayOrderAmount(razorID string) (money.Money, error) {
+	var amount money.Money
+	err := pay.DB.Raw("SELECT COALESCE(amount, 0) FROM razor_pays WHERE razor_id = ? ORDER BY id DESC LIMIT 1", razorID).
+		Scan(&amount).Error
+	return amount, err
+}
+
+// ClaimLatePayment marks an unpaid order's payment as being refunded and
+// returns its customer, or 0 if the payment was already dealt with, so a
+// payment captured after the order was cancelled is refunded once.
+func (pay *PaymentRepository) ClaimLatePayment(tx *gorm.DB, orderID int) (int, error) {
+	var userID int
+	err := tx.Raw("UPDATE orders SET payment_status = ? WHERE order_id = ? AND payment_status IN ? RETURNING user_id",
+		models.PaymentRefundInitiated, orderID, []string{models.PaymentNotPaid, models.PaymentFailed}).Scan(&userID).Error
+	if err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// CreatePaymentEvent stores a webhook event and reports whether it was new.
+func (pay *PaymentRepository) CreatePaymentEvent(event domain.PaymentEvent) (bool, error) {
+	result := pay.DB.Exec(`INSERT INTO payment_events (event_id, event, gateway_order_id, payment_id, payload, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (event_id) DO NOTHING`,
+		event.EventID, event.Event, event.GatewayOrderID, event.PaymentID, event.Payload, event.Status, time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (pay *PaymentRepository) GetPaymentEvent(eventID string) (domain.PaymentEvent, error) {
+	var event domain.PaymentEvent
+	err := pay.DB.Raw("SELECT * FROM payment_events WHERE event_id = ?", eventID).Scan(&event).Error
+	if err != nil {
+		return domain.PaymentEvent{}, err
+	}
+	return event, nil
+}
+
+func (pay *PaymentRepository) UpdatePaymentEventStatus(eventID, status, errMsg string) error {
+	return pay.DB.Exec("UPDATE payment_events SET status = ?, error = ?, processed_at = ? WHERE event_id = ?",
+		status, errMsg, time.Now(), eventID).Error
+}
+
+func (pay *PaymentRepository) GetOrderIDByRazorID(razorID string) (int, error) {
+	var orderID int
+	err := pay.DB.Raw("SELECT CAST(order_id AS INTEGER) FROM razor_pays WHERE razor_id = ? ORDER BY id DESC LIMIT 1", razorID).
+		Scan(&orderID).Error
+	if err != nil {
+		return 0, err
+	}
+	if orderID == 0 {
+		return 0, errors.New("no order found for gateway order " + razorID)
+	}
+	return orderID, nil
+}
+
+func (pay *PaymentRepository) GetOrderIDByPaymentID(paymentID string) (int, error) {
+	var orderID int
+	err := pay.DB.Raw("SELECT CAST(order_id AS INTEGER) FROM razor_pays WHERE payment_id = ? ORDER BY id DESC LIMIT 1", paymentID).
+		Scan(&orderID).Error
+	if err != nil {
+		return 0, err
+	}
+	if orderID == 0 {
+		return 0, errors.New("no order found for payment " + paymentID)
+	}
+	return orderID, nil
+}
+
+// MarkPaymentFailed flags a failed attempt on an order that has not been paid
+// yet. The customer may still retry against the same gateway order.
+func (pay *PaymentRepository) MarkPaymentFailed(orderID int) error {
+	return pay.DB.Exec("UPDATE orders SET payment_status = ? WHERE order_id = ? AND payment_status = ?",
+		models.PaymentFailed, orderID, models.PaymentNotPaid).Error
+}
+
+func (pay *PaymentRepository) GetPaymentMethodID(orderID int) (uint, error) {
+	var methodID uint
+	err := pay.DB.Raw("SELECT payment_method_id FROM orders WHERE order_id = ?", orderID).Scan(&methodID).Error
+	return methodID, err
+}
 
+func (pay *PaymentRepository) UpdatePaymentStatus(orderID int, status string) error {
+	return pay.DB.Exec("UPDATE orders SET payment_status = ? WHERE order_id = ?", status, orderID).Error
 }
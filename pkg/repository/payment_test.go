@@ -0,0 +1,148 @@
+package repository_test
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/repository"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func Test_CreatePaymentEvent(t *testing.T) {
+	query := regexp.QuoteMeta(`INSERT INTO payment_events (event_id, event, gateway_order_id, payment_id, payload, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (event_id) DO NOTHING`)
+	event := domain.PaymentEvent{
+		EventID:        "evt_1",
+		Event:          "payment.captured",
+		GatewayOrderID: "order_1",
+		PaymentID:      "pay_1",
+		Payload:        "{}",
+		Status:         "received",
+	}
+
+	tests := []struct {
+		name        string
+		setupMock   func(mock sqlmock.Sqlmock)
+		wantCreated bool
+		expectErr   bool
+	}{
+		{
+			name: "first delivery is stored",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("evt_1", "payment.captured", "order_1", "pay_1", "{}", "received", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantCreated: true,
+			expectErr:   false,
+		},
+		{
+			name: "redelivery is deduplicated",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("evt_1", "payment.captured", "order_1", "pay_1", "{}", "received", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantCreated: false,
+			expectErr:   false,
+		},
+		{
+			name: "database error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(query).
+					WithArgs("evt_1", "payment.captured", "order_1", "pay_1", "{}", "received", sqlmock.AnyArg()).
+					WillReturnError(gorm.ErrInvalidDB)
+			},
+			wantCreated: false,
+			expectErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			paymentRepo := repository.NewPaymentRepository(db)
+
+			tt.setupMock(mock)
+
+			created, err := paymentRepo.CreatePaymentEvent(event)
+
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCreated, created)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_HasGatewayOrder(t *testing.T) {
+	query := regexp.QuoteMeta(`SELECT count(*) FROM "razor_pays" WHERE order_id = $1 AND razor_id = $2`)
+
+	tests := []struct {
+		name      string
+		razorID   string
+		setupMock func(mock sqlmock.Sqlmock)
+		want      bool
+	}{
+		{
+			name:    "gateway order created for the order",
+			razorID: "order_1",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs("5", "order_1").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			},
+			want: true,
+		},
+		{
+			name:    "gateway order of something else",
+			razorID: "order_topup",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs("5", "order_topup").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			paymentRepo := repository.NewPaymentRepository(db)
+
+			tt.setupMock(mock)
+
+			got, err := paymentRepo.HasGatewayOrder(5, tt.razorID)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
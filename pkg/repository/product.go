@@ -2,9 +2,11 @@ package repository
 
 import (
 	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/money"
 	"ecommerce_clean_arch/pkg/utils/models"
 	"errors"
 	"fmt"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -23,10 +25,10 @@ func (p *ProductRepository) AddProduct(product models.AddProduct) (models.Produc
 	var productResponse models.ProductResponse
 
 	err := p.DB.Raw(
-		`INSERT INTO products (category_id, name, stock, quantity, price, offer_price) 
-        VALUES (?, ?, ?, ?, ?, ?) 
-        RETURNING id, category_id, name, stock, quantity, price, offer_price`,
-		product.CategoryID, product.Name, product.Stock, product.Quantity, product.Price, product.OfferPrice).Scan(&productResponse).Error
+		`INSERT INTO products (category_id, name, stock, quantity, price, offer_price, description, brand) 
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?) 
+        RETURNING id, category_id, name, stock, quantity, price, offer_price, description, brand`,
+		product.CategoryID, product.Name, product.Stock, product.Quantity, product.Price, product.OfferPrice, product.Description, product.Brand).Scan(&productResponse).Error
 
 	if err != nil {
 		return models.ProductResponse{}, err
@@ -43,15 +45,19 @@ func (p *ProductRepository) UpdateProduct(product models.ProductResponse, produc
             stock = $3, 
             quantity = $4, 
             price = $5, 
-            offer_price = $6 
-        WHERE id = $7 
-        RETURNING id, category_id, name, stock, quantity, price, offer_price`,
+            offer_price = $6, 
+            description = $7, 
+            brand = $8 
+        WHERE id = $9 
+        RETURNING id, category_id, name, stock, quantity, price, offer_price, description, brand`,
 		product.Category_Id,
 		product.Name,
 		product.Stock,
 		product.Quantity,
 		product.Price,
 		product.OfferPrice,
+		product.Description,
+		product.Brand,
 		productID,
 	).Scan(&updatedProduct).Error
 
@@ -82,9 +88,14 @@ func (p *ProductRepository) GetProductByID(productID int) (models.ProductRespons
 }
 
 func (p *ProductRepository) UpdateStock(productID, qty int) error {
-	return p.DB.Model(&models.ProductResponse{}).
-		Where("id = ?", productID).
-		UpdateColumn("stock", gorm.Expr("stock - ?", qty)).Error
+	result := p.DB.Exec("UPDATE products SET stock = stock - ? WHERE id = ? AND stock >= ?", qty, productID, qty)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInsufficientStock
+	}
+	return nil
 }
 
 func (p *ProductRepository) GetAllProducts(showOutOfStock bool) ([]models.ProductResponse, error) {
@@ -119,3 +130,300 @@ func (p *ProductRepository) GetProductsByCategory(categoryID string, sortBy stri
 	}
 	return products, nil
 }
+
+func (p *ProductRepository) AddVariant(variant models.AddVariant) (models.VariantResponse, error) {
+	var variantResponse models.VariantResponse
+
+	err := p.DB.Raw(
+		`INSERT INTO product_variants (product_id, sku, size_system, size, colour, stock, price_override, created_at) 
+        VALUES (?, ?, ?, ?, ?, ?, ?, now()) 
+        RETURNING id, product_id, sku, size_system, size, colour, stock, price_override`,
+		variant.ProductID, variant.SKU, variant.SizeSystem, variant.Size, variant.Colour, variant.Stock, variant.PriceOverride).Scan(&variantResponse).Error
+	if err != nil {
+		return models.VariantResponse{}, err
+	}
+
+	if err := p.addVariantImages(variantResponse.ID, variant.Images); err != nil {
+		return models.VariantResponse{}, err
+	}
+	variantResponse.Images = variant.Images
+	return variantResponse, nil
+}
+
+func (p *ProductRepository) UpdateVariant(variant models.AddVariant, variantID int) (models.VariantResponse, error) {
+	var updatedVariant models.VariantResponse
+	err := p.DB.Raw(
+		`UPDATE product_variants SET 
+            sku = $1, 
+            size_system = $2, 
+            size = $3, 
+            colour = $4, 
+            stock = $5, 
+            price_override = $6 
+        WHERE id = $7 AND deleted_at IS NULL 
+        RETURNING id, product_id, sku, size_system, size, colour, stock, price_override`,
+		variant.SKU,
+		variant.SizeSystem,
+		variant.Size,
+		variant.Colour,
+		variant.Stock,
+		variant.PriceOverride,
+		variantID,
+	).Scan(&updatedVariant).Error
+	if err != nil {
+		return models.VariantResponse{}, fmt.Errorf("error updating variant: %w", err)
+	}
+	if updatedVariant.ID == 0 {
+		return models.VariantResponse{}, errors.New("the variant id is not existing")
+	}
+
+	if variant.Images != nil {
+		if err := p.DB.Where("variant_id = ?", variantID).Delete(&domain.VariantImage{}).Error; err != nil {
+			return models.VariantResponse{}, err
+		}
+		if err := p.addVariantImages(variantID, variant.Images); err != nil {
+			return models.VariantResponse{}, err
+		}
+	}
+
+	updatedVariant.Images, err = p.getVariantImages(variantID)
+	if err != nil {
+		return models.VariantResponse{}, err
+	}
+	return updatedVariant, nil
+}
+
+func (p *ProductRepository) DeleteVariant(variantID int) error {
+	var variant domain.ProductVariant
+	err := p.DB.Where("id = ?", variantID).Delete(&variant)
+	if err.RowsAffected < 1 {
+		return errors.New("the variant id is not existing")
+	}
+	return nil
+}
+
+func (p *ProductRepository) GetVariantByID(variantID int) (models.VariantResponse, error) {
+	var variant models.VariantResponse
+	err := p.DB.Raw("SELECT * FROM product_variants WHERE id = ? AND deleted_at IS NULL", variantID).Scan(&variant).Error
+	if err != nil {
+		return models.VariantResponse{}, err
+	}
+	if variant.ID == 0 {
+		return models.VariantResponse{}, errors.New("variant not found")
+	}
+
+	variant.Images, err = p.getVariantImages(variantID)
+	if err != nil {
+		return models.VariantResponse{}, err
+	}
+	return variant, nil
+}
+
+func (p *ProductRepository) GetVariantsByProductID(productID int) ([]models.VariantResponse, error) {
+	var variants []models.VariantResponse
+	err := p.DB.Raw("SELECT * FROM product_variants WHERE product_id = ? AND deleted_at IS NULL ORDER BY size_system, size, colour", productID).Scan(&variants).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range variants {
+		variants[i].Images, err = p.getVariantImages(variants[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return variants, nil
+}
+
+func (p *ProductRepository) addVariantImages(variantID int, urls []string) error {
+	for _, url := range urls {
+		if err := p.DB.Create(&domain.VariantImage{VariantID: variantID, URL: url}).Error; err != nil {
+			return fmt.Errorf("failed to save variant image: %w", err)
+		}
+	}
+	return nil
+}
+
+func (p *ProductRepository) getVariantImages(variantID int) ([]string, error) {
+	var urls []string
+	err := p.DB.Raw("SELECT url FROM variant_images WHERE variant_id = ? ORDER BY id", variantID).Scan(&urls).Error
+	if err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// productSearchVector mirrors the idx_products_search expression index created
+// in db.ConnectDatabase so keyword searches can use it.
+const productSearchVector = `to_tsvector('english', coalesce(products.name, '') || ' ' || coalesce(products.description, ''))`
+
+// productInStock keeps products that can still be bought, either off their
+// own stock or, for products sold only by size, off any variant's.
+const productInStock = `(products.stock > 0 OR EXISTS (SELECT 1 FROM product_variants v
+	WHERE v.product_id = products.id AND v.deleted_at IS NULL AND v.stock > 0))`
+
+const productSearchFrom = ` FROM products
+	LEFT JOIN categories ON categories.id = products.category_id
+	LEFT JOIN (SELECT product_id, AVG(rating) AS rating, COUNT(*) AS review_count
+		FROM reviews GROUP BY product_id) r ON r.product_id = products.id`
+
+// buildSearchFilters returns the WHERE clause for a search. The facet named
+// in skip is left out so its counts reflect the other active filters only.
+func buildSearchFilters(query models.ProductSearchQuery, skip string) (string, []interface{}) {
+	conditions := []string{"products.deleted_at IS NULL", productInStock}
+	var args []interface{}
+
+	if query.Keyword != "" {
+		conditions = append(conditions, productSearchVector+" @@ plainto_tsquery('english', ?)")
+		args = append(args, query.Keyword)
+	}
+	if len(query.CategoryIDs) > 0 && skip != "category" {
+		conditions = append(conditions, "products.category_id IN ?")
+		args = append(args, query.CategoryIDs)
+	}
+	if len(query.Brands) > 0 && skip != "brand" {
+		conditions = append(conditions, "products.brand IN ?")
+		args = append(args, query.Brands)
+	}
+	if len(query.Sizes) > 0 && skip != "size" {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM product_variants v
+			WHERE v.product_id = products.id AND v.deleted_at IS NULL AND v.stock > 0
+			AND v.size_system || ' ' || v.size IN ?)`)
+		args = append(args, query.Sizes)
+	}
+	if skip != "price" {
+		if query.MinPrice > 0 {
+			conditions = append(conditions, "products.offer_price >= ?")
+			args = append(args, money.FromRupees(query.MinPrice))
+		}
+		if query.MaxPrice > 0 {
+			conditions = append(conditions, "products.offer_price <= ?")
+			args = append(args, money.FromRupees(query.MaxPrice))
+		}
+	}
+	if query.MinRating > 0 && skip != "rating" {
+		conditions = append(conditions, "COALESCE(r.rating, 0) >= ?")
+		args = append(args, query.MinRating)
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (p *ProductRepository) SearchProducts(query models.ProductSearchQuery) ([]models.ProductSearchResult, int64, error) {
+	where, args := buildSearchFilters(query, "")
+
+	var total int64
+	if err := p.DB.Raw("SELECT COUNT(*)"+productSearchFrom+where, args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var orderBy string
+	switch query.SortBy {
+	case "price_H-L":
+		orderBy = "products.offer_price DESC"
+	case "price_L-H":
+		orderBy = "products.offer_price ASC"
+	case "newest":
+		orderBy = "products.created_at DESC"
+	case "alphabetic":
+		orderBy = "LOWER(products.name) ASC"
+	case "rating":
+		orderBy = "COALESCE(r.rating, 0) DESC, products.id"
+	default:
+		if query.Keyword != "" {
+			orderBy = "ts_rank(" + productSearchVector + ", plainto_tsquery('english', ?)) DESC, products.id"
+			args = append(args, query.Keyword)
+		} else {
+			orderBy = "products.created_at DESC"
+		}
+	}
+	args = append(args, query.Limit, (query.Page-1)*query.Limit)
+
+	var products []models.ProductSearchResult
+	err := p.DB.Raw(`SELECT products.id, products.category_id, categories.category, products.name,
+		products.description, products.brand, products.stock, products.price, products.offer_price,
+		COALESCE(r.rating, 0) AS rating, COALESCE(r.review_count, 0) AS review_count`+
+		productSearchFrom+where+" ORDER BY "+orderBy+" LIMIT ? OFFSET ?", args...).Scan(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+func (p *ProductRepository) GetSearchFacets(query models.ProductSearchQuery) (models.ProductFacets, error) {
+	var facets models.ProductFacets
+
+	where, args := buildSearchFilters(query, "category")
+	err := p.DB.Raw(`SELECT CAST(products.category_id AS TEXT) AS value, categories.category AS label, COUNT(*) AS count`+
+		productSearchFrom+where+` GROUP BY products.category_id, categories.category ORDER BY count DESC`, args...).
+		Scan(&facets.Categories).Error
+	if err != nil {
+		return models.ProductFacets{}, err
+	}
+
+	where, args = buildSearchFilters(query, "brand")
+	err = p.DB.Raw(`SELECT products.brand AS value, COUNT(*) AS count`+
+		productSearchFrom+where+` AND products.brand <> '' GROUP BY products.brand ORDER BY count DESC`, args...).
+		Scan(&facets.Brands).Error
+	if err != nil {
+		return models.ProductFacets{}, err
+	}
+
+	where, args = buildSearchFilters(query, "size")
+	err = p.DB.Raw(`SELECT v.size_system || ' ' || v.size AS value, COUNT(DISTINCT products.id) AS count`+
+		productSearchFrom+` JOIN product_variants v ON v.product_id = products.id AND v.deleted_at IS NULL AND v.stock > 0`+
+		where+` GROUP BY v.size_system, v.size ORDER BY v.size_system, v.size`, args...).
+		Scan(&facets.Sizes).Error
+	if err != nil {
+		return models.ProductFacets{}, err
+	}
+
+	where, args = buildSearchFilters(query, "price")
+	var prices struct {
+		Under1000 int64
+		Under2500 int64
+		Under5000 int64
+		Above5000 int64
+	}
+	// Prices are stored in paise.
+	err = p.DB.Raw(`SELECT
+		COUNT(*) FILTER (WHERE products.offer_price < 100000) AS under1000,
+		COUNT(*) FILTER (WHERE products.offer_price >= 100000 AND products.offer_price < 250000) AS under2500,
+		COUNT(*) FILTER (WHERE products.offer_price >= 250000 AND products.offer_price < 500000) AS under5000,
+		COUNT(*) FILTER (WHERE products.offer_price >= 500000) AS above5000`+
+		productSearchFrom+where, args...).Scan(&prices).Error
+	if err != nil {
+		return models.ProductFacets{}, err
+	}
+	facets.PriceRanges = []models.FacetCount{
+		{Value: "0-1000", Count: prices.Under1000},
+		{Value: "1000-2500", Count: prices.Under2500},
+		{Value: "2500-5000", Count: prices.Under5000},
+		{Value: "5000-", Count: prices.Above5000},
+	}
+
+	where, args = buildSearchFilters(query, "rating")
+	var ratings struct {
+		Four  int64
+		Three int64
+		Two   int64
+		One   int64
+	}
+	err = p.DB.Raw(`SELECT
+		COUNT(*) FILTER (WHERE r.rating >= 4) AS four,
+		COUNT(*) FILTER (WHERE r.rating >= 3) AS three,
+		COUNT(*) FILTER (WHERE r.rating >= 2) AS two,
+		COUNT(*) FILTER (WHERE r.rating >= 1) AS one`+
+		productSearchFrom+where, args...).Scan(&ratings).Error
+	if err != nil {
+		return models.ProductFacets{}, err
+	}
+	facets.Ratings = []models.FacetCount{
+		{Value: "4", Label: "4 & up", Count: ratings.Four},
+		{Value: "3", Label: "3 & up", Count: ratings.Three},
+		{Value: "2", Label: "2 & up", Count: ratings.Two},
+		{Value: "1", Label: "1 & up", Count: ratings.One},
+	}
+
+	return facets, nil
+}
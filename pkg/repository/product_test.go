@@ -1,6 +1,7 @@
 package repository_test
 
 import (
+	"ecommerce_clean_arch/pkg/money"
 	"ecommerce_clean_arch/pkg/repository"
 	"ecommerce_clean_arch/pkg/utils/models"
 	"regexp"
@@ -27,20 +28,20 @@ func Test_AddProduct(t *testing.T) {
 				Name:       "Adidas",
 				Quantity:   20,
 				Stock:      20,
-				Price:      3000,
-				OfferPrice: 2300,
+				Price:      money.Rupees(3000),
+				OfferPrice: money.Rupees(2300),
 			},
 			setupMock: func(mock sqlmock.Sqlmock) {
 				query := regexp.QuoteMeta(
-					`INSERT INTO products (category_id, name, stock, quantity, price, offer_price) 
-					VALUES ($1, $2, $3, $4, $5, $6) 
-					RETURNING id, category_id, name, stock, quantity, price, offer_price`,
+					`INSERT INTO products (category_id, name, stock, quantity, price, offer_price, description, brand) 
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8) 
+					RETURNING id, category_id, name, stock, quantity, price, offer_price, description, brand`,
 				)
 				mock.ExpectQuery(query).
-					WithArgs(1, "Adidas", 20, 20, 3000.0, 2300.0).
+					WithArgs(1, "Adidas", 20, 20, money.Rupees(3000), money.Rupees(2300), "", "").
 					WillReturnRows(sqlmock.NewRows([]string{
 						"id", "category_id", "name", "stock", "quantity", "price", "offer_price",
-					}).AddRow(1, 1, "Adidas", 20, 20, 3000.0, 2300.0))
+					}).AddRow(1, 1, "Adidas", 20, 20, 300000, 230000))
 			},
 			expectErr: false,
 		},
@@ -51,17 +52,17 @@ func Test_AddProduct(t *testing.T) {
 				Name:       "Puma",
 				Quantity:   15,
 				Stock:      15,
-				Price:      2800,
-				OfferPrice: 2400,
+				Price:      money.Rupees(2800),
+				OfferPrice: money.Rupees(2400),
 			},
 			setupMock: func(mock sqlmock.Sqlmock) {
 				query := regexp.QuoteMeta(
-					`INSERT INTO products (category_id, name, stock, quantity, price, offer_price) 
-					VALUES ($1, $2, $3, $4, $5, $6) 
-					RETURNING id, category_id, name, stock, quantity, price, offer_price`,
+					`INSERT INTO products (category_id, name, stock, quantity, price, offer_price, description, brand) 
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8) 
+					RETURNING id, category_id, name, stock, quantity, price, offer_price, description, brand`,
 				)
 				mock.ExpectQuery(query).
-					WithArgs(2, "Puma", 15, 15, 2800.0, 2400.0).
+					WithArgs(2, "Puma", 15, 15, money.Rupees(2800), money.Rupees(2400), "", "").
 					WillReturnError(gorm.ErrInvalidDB)
 			},
 			expectErr: true,
@@ -112,20 +113,20 @@ func Test_UpdateProduct(t *testing.T) {
 				Name:        "Convacs",
 				Quantity:    20,
 				Stock:       20,
-				Price:       3000,
-				OfferPrice:  2500,
+				Price:       money.Rupees(3000),
+				OfferPrice:  money.Rupees(2500),
 			},
 			productID: 1,
 			setupMock: func(mock sqlmock.Sqlmock) {
 				query := regexp.QuoteMeta(
-					`UPDATE products SET category_id = $1, name = $2, stock = $3, quantity = $4, price = $5, offer_price = $6 WHERE id = $7
-				RETURNING id, category_id, name, stock, quantity, price, offer_price`,
+					`UPDATE products SET category_id = $1, name = $2, stock = $3, quantity = $4, price = $5, offer_price = $6, description = $7, brand = $8 WHERE id = $9
+				RETURNING id, category_id, name, stock, quantity, price, offer_price, description, brand`,
 				)
 				mock.ExpectQuery(query).
-					WithArgs(1, "Convacs", 20, 20, 3000.0, 2500.0, 1).
+					WithArgs(1, "Convacs", 20, 20, money.Rupees(3000), money.Rupees(2500), "", "", 1).
 					WillReturnRows(sqlmock.NewRows([]string{
 						"id", "category_id", "name", "stock", "quantity", "price", "offer_price",
-					}).AddRow(1, 1, "Convacs", 20, 20, 3000.0, 2500.0))
+					}).AddRow(1, 1, "Convacs", 20, 20, 300000, 250000))
 			},
 			expectErr: false,
 		},
@@ -136,17 +137,17 @@ func Test_UpdateProduct(t *testing.T) {
 				Name:        "Puma",
 				Quantity:    15,
 				Stock:       15,
-				Price:       2800,
-				OfferPrice:  2400,
+				Price:       money.Rupees(2800),
+				OfferPrice:  money.Rupees(2400),
 			},
 			productID: 1,
 			setupMock: func(mock sqlmock.Sqlmock) {
 				query := regexp.QuoteMeta(
-					`UPDATE products SET category_id = $1, name = $2, stock = $3, quantity = $4, price = $5, offer_price = $6 WHERE id = $7
-				RETURNING id, category_id, name, stock, quantity, price, offer_price`,
+					`UPDATE products SET category_id = $1, name = $2, stock = $3, quantity = $4, price = $5, offer_price = $6, description = $7, brand = $8 WHERE id = $9
+				RETURNING id, category_id, name, stock, quantity, price, offer_price, description, brand`,
 				)
 				mock.ExpectQuery(query).
-					WithArgs(2, "Puma", 15, 15, 2800.0, 2400.0, 1).
+					WithArgs(2, "Puma", 15, 15, money.Rupees(2800), money.Rupees(2400), "", "", 1).
 					WillReturnError(gorm.ErrInvalidDB)
 			},
 			expectErr: true,
@@ -180,3 +181,74 @@ func Test_UpdateProduct(t *testing.T) {
 		})
 	}
 }
+
+func Test_SearchProducts(t *testing.T) {
+	// A product sold only by size keeps no stock of its own.
+	inStock := regexp.QuoteMeta("WHERE products.deleted_at IS NULL AND (products.stock > 0 OR EXISTS (SELECT 1 FROM product_variants v") +
+		`\s+` + regexp.QuoteMeta("WHERE v.product_id = products.id AND v.deleted_at IS NULL AND v.stock > 0))")
+	countQuery := `(?s)SELECT COUNT\(\*\) FROM products.*` + inStock
+	selectQuery := `(?s)SELECT products\.id, .*` + inStock + regexp.QuoteMeta(" ORDER BY products.created_at DESC LIMIT $1 OFFSET $2")
+
+	tests := []struct {
+		name      string
+		setupMock func(mock sqlmock.Sqlmock)
+		want      []models.ProductSearchResult
+		wantTotal int64
+		expectErr bool
+	}{
+		{
+			name: "variant-only product with sizes in stock is listed",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(countQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				rows := sqlmock.NewRows([]string{"id", "category_id", "category", "name", "stock", "price", "offer_price"}).
+					AddRow(4, 1, "Running", "Pegasus", 0, 900000, 850000)
+				mock.ExpectQuery(selectQuery).WithArgs(10, 0).WillReturnRows(rows)
+			},
+			want: []models.ProductSearchResult{{
+				ID: 4, CategoryID: 1, Category: "Running", Name: "Pegasus", Stock: 0,
+				Price: money.Rupees(9000), OfferPrice: money.Rupees(8500),
+			}},
+			wantTotal: 1,
+			expectErr: false,
+		},
+		{
+			name: "database error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(countQuery).WillReturnError(gorm.ErrInvalidDB)
+			},
+			want:      nil,
+			wantTotal: 0,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			productRepo := repository.NewProductRepository(db)
+
+			tt.setupMock(mock)
+
+			products, total, err := productRepo.SearchProducts(models.ProductSearchQuery{Page: 1, Limit: 10})
+
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, products)
+			assert.Equal(t, tt.wantTotal, total)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
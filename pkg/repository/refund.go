@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RefundRepository struct {
+	DB *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) *RefundRepository {
+	return &RefundRepository{DB: db}
+}
+
+func (r *RefundRepository) GetOrderPaymentInfo(tx *gorm.DB, orderID int) (models.OrderPaymentInfo, error) {
+	var info models.OrderPaymentInfo
+	err := tx.Raw(`SELECT orders.user_id, orders.payment_method_id, orders.payment_status, orders.final_price, orders.wallet_amount,
+			orders.gift_card_id, orders.gift_card_amount,
+			COALESCE((SELECT payment_id FROM razor_pays WHERE razor_pays.order_id = CAST(orders.order_id AS TEXT)
+				AND payment_id <> '' ORDER BY id DESC LIMIT 1), '') AS gateway_payment_id
+		FROM orders WHERE order_id = ?`, orderID).Scan(&info).Error
+	if err != nil {
+		return models.OrderPaymentInfo{}, err
+	}
+	if info.UserID == 0 {
+		return models.OrderPaymentInfo{}, errors.New("order not found")
+	}
+	return info, nil
+}
+
+func (r *RefundRepository) CreateRefund(tx *gorm.DB, refund domain.Refund) (domain.Refund, error) {
+	if err := tx.Create(&refund).Error; err != nil {
+		return domain.Refund{}, err
+	}
+	return refund, nil
+}
+
+func (r *RefundRepository) GetRefundByID(refundID int) (domain.Refund, error) {
+	var refund domain.Refund
+	err := r.DB.Raw("SELECT * FROM refunds WHERE id = ?", refundID).Scan(&refund).Error
+	if err != nil {
+		return domain.Refund{}, err
+	}
+	if refund.ID == 0 {
+		return domain.Refund{}, errors.New("refund not found")
+	}
+	return refund, nil
+}
+
+func (r *RefundRepository) GetRefundByGatewayRefundID(gatewayRefundID string) (domain.Refund, error) {
+	var refund domain.Refund
+	err := r.DB.Raw("SELECT * FROM refunds WHERE gateway_refund_id = ?", gatewayRefundID).Scan(&refund).Error
+	if err != nil {
+		return domain.Refund{}, err
+	}
+	if refund.ID == 0 {
+		return domain.Refund{}, errors.New("refund not found")
+	}
+	return refund, nil
+}
+
+func (r *RefundRepository) SetGatewayRefundID(refundID int, gatewayRefundID string) error {
+	return r.DB.Exec("UPDATE refunds SET gateway_refund_id = ?, failure_reason = '', status = ?, updated_at = ? WHERE id = ?",
+		gatewayRefundID, models.RefundInitiated, time.Now(), refundID).Error
+}
+
+func (r *RefundRepository) MarkRefundProcessed(tx *gorm.DB, refundID int) error {
+	now := time.Now()
+	return tx.Exec("UPDATE refunds SET status = ?, processed_at = ?, updated_at = ? WHERE id = ?",
+		models.RefundProcessed, now, now, refundID).Error
+}
+
+func (r *RefundRepository) MarkRefundFailed(refundID int, reason string) error {
+	return r.DB.Exec("UPDATE refunds SET status = ?, failure_reason = ?, updated_at = ? WHERE id = ?",
+		models.RefundFailed, reason, time.Now(), refundID).Error
+}
+
+// HasOpenRefunds reports whether any refund on the order is still waiting on
+// the gateway.
+func (r *RefundRepository) HasOpenRefunds(orderID int) (bool, error) {
+	var exists bool
+	err := r.DB.Raw("SELECT EXISTS(SELECT 1 FROM refunds WHERE order_id = ? AND status <> ?)", orderID, models.RefundProcessed).
+		Scan(&exists).Error
+	return exists, err
+}
+
+func (r *RefundRepository) UpdatePaymentStatus(tx *gorm.DB, orderID int, status string) error {
+	return tx.Exec("UPDATE orders SET payment_status = ? WHERE order_id = ?", status, orderID).Error
+}
+
+// ReduceGiftCardAmount takes the gift card share of a refund off what the
+// order records the card as having paid.
+func (r *RefundRepository) ReduceGiftCardAmount(tx *gorm.DB, orderID int, amount money.Money) error {
+	return tx.Exec("UPDATE orders SET gift_card_amount = GREATEST(gift_card_amount - ?, 0) WHERE order_id = ?", amount, orderID).Error
+}
+
+// ReduceWalletAmount takes the wallet share of a refund off what the order
+// holds from the wallet.
+func (r *RefundRepository) ReduceWalletAmount(tx *gorm.DB, orderID int, amount money.Money) error {
+	return tx.Exec("UPDATE orders SET wallet_amount = GREATEST(wallet_amount - ?, 0) WHERE order_id = ?", amount, orderID).Error
+}
+
+func (r *RefundRepository) GetRefundsByUser(userID int) ([]domain.Refund, error) {
+	var refunds []domain.Refund
+	err := r.DB.Raw("SELECT * FROM refunds WHERE user_id = ? ORDER BY created_at DESC", userID).Scan(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+func (r *RefundRepository) GetRefundsByStatus(status string) ([]domain.Refund, error) {
+	var refunds []domain.Refund
+	query := r.DB.Model(&domain.Refund{}).Order("created_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// MarkGatewayRefundProcessed settles a refund confirmed by the gateway and,
+// for whole-order refunds, marks the order refunded.
+func (r *RefundRepository) MarkGatewayRefundProcessed(refund domain.Refund) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := r.MarkRefundProcessed(tx, refund.ID); err != nil {
+			return err
+		}
+		if refund.OrderItemID == 0 {
+			return r.UpdatePaymentStatus(tx, refund.OrderID, models.PaymentRefunded)
+		}
+		return nil
+	})
+}
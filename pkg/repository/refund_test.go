@@ -0,0 +1,125 @@
+package repository_test
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func Test_GetOrderPaymentInfo(t *testing.T) {
+	query := regexp.QuoteMeta(`SELECT orders.user_id, orders.payment_method_id, orders.payment_status, orders.final_price, orders.wallet_amount,
+			orders.gift_card_id, orders.gift_card_amount,
+			COALESCE((SELECT payment_id FROM razor_pays WHERE razor_pays.order_id = CAST(orders.order_id AS TEXT)
+				AND payment_id <> '' ORDER BY id DESC LIMIT 1), '') AS gateway_payment_id
+		FROM orders WHERE order_id = $1`)
+
+	tests := []struct {
+		name      string
+		setupMock func(mock sqlmock.Sqlmock)
+		want      models.OrderPaymentInfo
+		expectErr bool
+	}{
+		{
+			name: "paid online order",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"user_id", "payment_method_id", "payment_status", "final_price", "wallet_amount", "gift_card_id", "gift_card_amount", "gateway_payment_id"}).
+					AddRow(7, 2, "paid", 149950, 0, 0, 0, "pay_1")
+				mock.ExpectQuery(query).WithArgs(3).WillReturnRows(rows)
+			},
+			want: models.OrderPaymentInfo{
+				UserID:           7,
+				PaymentMethodID:  2,
+				PaymentStatus:    "paid",
+				FinalPrice:       money.Paise(149950),
+				GatewayPaymentID: "pay_1",
+			},
+			expectErr: false,
+		},
+		{
+			name: "split payment order",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"user_id", "payment_method_id", "payment_status", "final_price", "wallet_amount", "gift_card_id", "gift_card_amount", "gateway_payment_id"}).
+					AddRow(7, 4, "not paid", 120000, 40000, 0, 0, "")
+				mock.ExpectQuery(query).WithArgs(3).WillReturnRows(rows)
+			},
+			want: models.OrderPaymentInfo{
+				UserID:          7,
+				PaymentMethodID: 4,
+				PaymentStatus:   "not paid",
+				FinalPrice:      money.Rupees(1200),
+				WalletAmount:    money.Rupees(400),
+			},
+			expectErr: false,
+		},
+		{
+			name: "order part paid by gift card",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"user_id", "payment_method_id", "payment_status", "final_price", "wallet_amount", "gift_card_id", "gift_card_amount", "gateway_payment_id"}).
+					AddRow(7, 1, "not paid", 120000, 0, 5, 50000, "")
+				mock.ExpectQuery(query).WithArgs(3).WillReturnRows(rows)
+			},
+			want: models.OrderPaymentInfo{
+				UserID:          7,
+				PaymentMethodID: 1,
+				PaymentStatus:   "not paid",
+				FinalPrice:      money.Rupees(1200),
+				GiftCardID:      5,
+				GiftCardAmount:  money.Rupees(500),
+			},
+			expectErr: false,
+		},
+		{
+			name: "order not found",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"user_id", "payment_method_id", "payment_status", "final_price", "wallet_amount", "gift_card_id", "gift_card_amount", "gateway_payment_id"})
+				mock.ExpectQuery(query).WithArgs(3).WillReturnRows(rows)
+			},
+			want:      models.OrderPaymentInfo{},
+			expectErr: true,
+		},
+		{
+			name: "database error",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs(3).WillReturnError(gorm.ErrInvalidDB)
+			},
+			want:      models.OrderPaymentInfo{},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			refundRepo := repository.NewRefundRepository(db)
+
+			tt.setupMock(mock)
+
+			info, err := refundRepo.GetOrderPaymentInfo(db, 3)
+
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, info)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
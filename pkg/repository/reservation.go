@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReservationRepository struct {
+	DB *gorm.DB
+}
+
+func NewReservationRepository(db *gorm.DB) *ReservationRepository {
+	return &ReservationRepository{DB: db}
+}
+
+func (r *ReservationRepository) BeginTransaction() (*gorm.DB, error) {
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tx, nil
+}
+
+func (r *ReservationRepository) CreateReservations(tx *gorm.DB, reservations []domain.StockReservation) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+	return tx.Create(&reservations).Error
+}
+
+// LockReservations returns every reservation of the order and holds row locks
+// on them until tx ends, so payment verification and the sweeper cannot both
+// act on the same hold.
+func (r *ReservationRepository) LockReservations(tx *gorm.DB, orderID int) ([]domain.StockReservation, error) {
+	var reservations []domain.StockReservation
+	err := tx.Raw("SELECT * FROM stock_reservations WHERE order_id = ? ORDER BY id FOR UPDATE", orderID).
+		Scan(&reservations).Error
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+func (r *ReservationRepository) CommitReservations(tx *gorm.DB, orderID int) error {
+	return tx.Exec("UPDATE stock_reservations SET status = ?, updated_at = ? WHERE order_id = ? AND status = ?",
+		models.ReservationCommitted, time.Now(), orderID, models.ReservationHeld).Error
+}
+
+func (r *ReservationRepository) ReleaseReservations(tx *gorm.DB, orderID int, reason string) error {
+	now := time.Now()
+	return tx.Exec("UPDATE stock_reservations SET status = ?, released_at = ?, reason = ?, updated_at = ? WHERE order_id = ? AND status = ?",
+		models.ReservationReleased, now, reason, now, orderID, models.ReservationHeld).Error
+}
+
+func (r *ReservationRepository) ReleaseItemReservation(tx *gorm.DB, orderID, productID, variantID int, reason string) error {
+	now := time.Now()
+	return tx.Exec(`UPDATE stock_reservations SET status = ?, released_at = ?, reason = ?, updated_at = ?
+		WHERE order_id = ? AND product_id = ? AND variant_id = ? AND status = ?`,
+		models.ReservationReleased, now, reason, now, orderID, productID, variantID, models.ReservationHeld).Error
+}
+
+func (r *ReservationRepository) GetExpiredOrderIDs(now time.Time, limit int) ([]int, error) {
+	var orderIDs []int
+	err := r.DB.Raw("SELECT DISTINCT order_id FROM stock_reservations WHERE status = ? AND expires_at <= ? ORDER BY order_id LIMIT ?",
+		models.ReservationHeld, now, limit).Scan(&orderIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return orderIDs, nil
+}
+
+func (r *ReservationRepository) RestoreStock(tx *gorm.DB, productID, variantID, quantity int) error {
+	if variantID != 0 {
+		return tx.Exec("UPDATE product_variants SET stock = stock + ? WHERE id = ?", quantity, variantID).Error
+	}
+	return tx.Exec("UPDATE products SET stock = stock + ? WHERE id = ?", quantity, productID).Error
+}
+
+// LockOrderPayment locks the order and returns its order and payment
+// status, so a payment cannot land while the order is being released.
+func (r *ReservationRepository) LockOrderPayment(tx *gorm.DB, orderID int) (string, string, error) {
+	var order struct {
+		OrderStatus   string
+		PaymentStatus string
+	}
+	err := tx.Raw("SELECT order_status, payment_status FROM orders WHERE order_id = ? FOR UPDATE", orderID).Scan(&order).Error
+	if err != nil {
+		return "", "", err
+	}
+	return order.OrderStatus, order.PaymentStatus, nil
+}
@@ -0,0 +1,89 @@
+package repository_test
+
+import (
+	"ecommerce_clean_arch/pkg/repository"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func Test_LockOrderPayment(t *testing.T) {
+	tests := []struct {
+		name              string
+		orderID           int
+		setupMock         func(mock sqlmock.Sqlmock)
+		wantOrderStatus   string
+		wantPaymentStatus string
+		expectErr         bool
+	}{
+		{
+			name:    "unpaid pending order",
+			orderID: 1,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT order_status, payment_status FROM orders WHERE order_id = $1 FOR UPDATE`)).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"order_status", "payment_status"}).AddRow("pending", "not paid"))
+			},
+			wantOrderStatus:   "pending",
+			wantPaymentStatus: "not paid",
+			expectErr:         false,
+		},
+		{
+			name:    "order paid in the meantime",
+			orderID: 2,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT order_status, payment_status FROM orders WHERE order_id = $1 FOR UPDATE`)).
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"order_status", "payment_status"}).AddRow("success", "paid"))
+			},
+			wantOrderStatus:   "success",
+			wantPaymentStatus: "paid",
+			expectErr:         false,
+		},
+		{
+			name:    "database error",
+			orderID: 3,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT order_status, payment_status FROM orders WHERE order_id = $1 FOR UPDATE`)).
+					WithArgs(3).
+					WillReturnError(errors.New("database error"))
+			},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			reservationRepo := repository.NewReservationRepository(db)
+
+			tt.setupMock(mock)
+
+			orderStatus, paymentStatus, err := reservationRepo.LockOrderPayment(db, tt.orderID)
+
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOrderStatus, orderStatus)
+			assert.Equal(t, tt.wantPaymentStatus, paymentStatus)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type ReturnRepository struct {
+	DB *gorm.DB
+}
+
+func NewReturnRepository(db *gorm.DB) *ReturnRepository {
+	return &ReturnRepository{DB: db}
+}
+
+func (r *ReturnRepository) CreateReturnRequest(tx *gorm.DB, request domain.ReturnRequest) (domain.ReturnRequest, error) {
+	if err := tx.Create(&request).Error; err != nil {
+		return domain.ReturnRequest{}, err
+	}
+	return request, nil
+}
+
+// HasOpenReturn reports whether a return that would overlap the new one is
+// still in progress: any open return on the order for a whole-order
+// request, or an open return of the order or of that line for a line.
+func (r *ReturnRepository) HasOpenReturn(tx *gorm.DB, orderID, orderItemID int) (bool, error) {
+	var exists bool
+	err := tx.Raw(`SELECT EXISTS(SELECT 1 FROM return_requests WHERE order_id = ? AND status NOT IN ?
+			AND (? = 0 OR order_item_id = 0 OR order_item_id = ?))`,
+		orderID, []string{models.ReturnRefunded, models.ReturnRejected}, orderItemID, orderItemID).Scan(&exists).Error
+	return exists, err
+}
+
+// LockReturnRequest reads a return request and holds its row until tx ends.
+func (r *ReturnRepository) LockReturnRequest(tx *gorm.DB, returnID int) (domain.ReturnRequest, error) {
+	var request domain.ReturnRequest
+	err := tx.Raw("SELECT * FROM return_requests WHERE id = ? FOR UPDATE", returnID).Scan(&request).Error
+	if err != nil {
+		return domain.ReturnRequest{}, err
+	}
+	if request.ID == 0 {
+		return domain.ReturnRequest{}, errors.New("return request not found")
+	}
+	return request, nil
+}
+
+func (r *ReturnRepository) SaveReturnRequest(tx *gorm.DB, request domain.ReturnRequest) error {
+	return tx.Save(&request).Error
+}
+
+func (r *ReturnRepository) GetReturnRequestsByUser(userID int) ([]domain.ReturnRequest, error) {
+	var requests []domain.ReturnRequest
+	err := r.DB.Raw("SELECT * FROM return_requests WHERE user_id = ? ORDER BY created_at DESC", userID).Scan(&requests).Error
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+func (r *ReturnRepository) GetReturnRequestsByStatus(status string) ([]domain.ReturnRequest, error) {
+	var requests []domain.ReturnRequest
+	query := r.DB.Model(&domain.ReturnRequest{}).Order("created_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
@@ -0,0 +1,83 @@
+package repository_test
+
+import (
+	"ecommerce_clean_arch/pkg/repository"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func Test_HasOpenReturn(t *testing.T) {
+	query := regexp.QuoteMeta(`SELECT EXISTS(SELECT 1 FROM return_requests WHERE order_id = $1 AND status NOT IN ($2,$3)
+			AND ($4 = 0 OR order_item_id = 0 OR order_item_id = $5))`)
+
+	tests := []struct {
+		name        string
+		orderItemID int
+		setupMock   func(mock sqlmock.Sqlmock)
+		want        bool
+		expectErr   bool
+	}{
+		{
+			name:        "whole order already being returned",
+			orderItemID: 0,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+				mock.ExpectQuery(query).WithArgs(4, "refunded", "rejected", 0, 0).WillReturnRows(rows)
+			},
+			want:      true,
+			expectErr: false,
+		},
+		{
+			name:        "no open return for the item",
+			orderItemID: 9,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+				mock.ExpectQuery(query).WithArgs(4, "refunded", "rejected", 9, 9).WillReturnRows(rows)
+			},
+			want:      false,
+			expectErr: false,
+		},
+		{
+			name:        "database error",
+			orderItemID: 9,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(query).WithArgs(4, "refunded", "rejected", 9, 9).WillReturnError(gorm.ErrInvalidDB)
+			},
+			want:      false,
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			returnRepo := repository.NewReturnRepository(db)
+
+			tt.setupMock(mock)
+
+			open, err := returnRepo.HasOpenReturn(db, 4, tt.orderItemID)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, open)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ShippingRepository struct {
+	DB *gorm.DB
+}
+
+func NewShippingRepository(db *gorm.DB) *ShippingRepository {
+	return &ShippingRepository{DB: db}
+}
+
+// GetShipmentDetails returns what booking a courier needs to know about an
+// order: where it goes, what it weighs and what is to be collected.
+func (s *ShippingRepository) GetShipmentDetails(tx *gorm.DB, orderID int, defaultWeight int) (models.ShipmentDetails, error) {
+	var details models.ShipmentDetails
+	err := tx.Raw(`SELECT orders.order_id, orders.order_status, orders.payment_status, orders.payment_method_id,
+			orders.final_price, orders.gift_card_amount, users.first_name AS name, users.phone,
+			addresses.house_name, addresses.street, addresses.city, addresses.district, addresses.state, addresses.pin,
+			(SELECT COALESCE(SUM(order_items.quantity * COALESCE(NULLIF(categories.weight_grams, 0), ?)), 0)
+				FROM order_items
+				JOIN products ON products.id = order_items.product_id
+				JOIN categories ON categories.id = products.category_id
+				WHERE order_items.order_id = orders.order_id AND order_items.status NOT IN ?) AS weight_grams
+		FROM orders
+		JOIN users ON users.id = orders.user_id
+		JOIN addresses ON addresses.id = orders.address_id
+		WHERE orders.order_id = ?`, defaultWeight, models.ClosedItemStatuses, orderID).Scan(&details).Error
+	if err != nil {
+		return models.ShipmentDetails{}, err
+	}
+	if details.OrderID == 0 {
+		return models.ShipmentDetails{}, errors.New("order not found")
+	}
+	return details, nil
+}
+
+func (s *ShippingRepository) CreateShipment(tx *gorm.DB, shipment domain.Shipment) (domain.Shipment, error) {
+	if err := tx.Create(&shipment).Error; err != nil {
+		return domain.Shipment{}, err
+	}
+	return shipment, nil
+}
+
+// GetShipment returns an order's shipment, with a zero ID when it has not
+// been booked.
+func (s *ShippingRepository) GetShipment(orderID int) (domain.Shipment, error) {
+	var shipment domain.Shipment
+	err := s.DB.Raw("SELECT * FROM shipments WHERE order_id = ?", orderID).Scan(&shipment).Error
+	if err != nil {
+		return domain.Shipment{}, err
+	}
+	return shipment, nil
+}
+
+// LockShipmentByAWB reads a shipment and holds its row until tx ends.
+func (s *ShippingRepository) LockShipmentByAWB(tx *gorm.DB, awb string) (domain.Shipment, error) {
+	var shipment domain.Shipment
+	err := tx.Raw("SELECT * FROM shipments WHERE awb = ? FOR UPDATE", awb).Scan(&shipment).Error
+	if err != nil {
+		return domain.Shipment{}, err
+	}
+	if shipment.ID == 0 {
+		return domain.Shipment{}, errors.New("shipment not found")
+	}
+	return shipment, nil
+}
+
+func (s *ShippingRepository) SaveShipment(tx *gorm.DB, shipment domain.Shipment) error {
+	return tx.Save(&shipment).Error
+}
+
+// CreateShipmentEvent records a tracking event and reports whether it was
+// new; a status already recorded for the shipment is left alone.
+func (s *ShippingRepository) CreateShipmentEvent(tx *gorm.DB, event domain.ShipmentEvent) (bool, error) {
+	result := tx.Exec(`INSERT INTO shipment_events (shipment_id, status, location, description, occurred_at, created_at)
+		VALUES (?, ?, ?, ?, ?, NOW()) ON CONFLICT (shipment_id, status) DO NOTHING`,
+		event.ShipmentID, event.Status, event.Location, event.Description, event.OccurredAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (s *ShippingRepository) GetShipmentEvents(shipmentID int) ([]domain.ShipmentEvent, error) {
+	var events []domain.ShipmentEvent
+	err := s.DB.Raw("SELECT * FROM shipment_events WHERE shipment_id = ? ORDER BY occurred_at, id", shipmentID).Scan(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkCODPaid records the cash the courier collected on delivery of a
+// cash-on-delivery order.
+func (s *ShippingRepository) MarkCODPaid(tx *gorm.DB, orderID int) error {
+	return tx.Exec("UPDATE orders SET payment_status = ? WHERE order_id = ? AND payment_method_id = ?",
+		models.PaymentPaid, orderID, models.PaymentMethodCOD).Error
+}
+
+// SavePincode adds a pincode to the serviceability table or replaces its
+// entry.
+func (s *ShippingRepository) SavePincode(tx *gorm.DB, pincode domain.ServiceablePincode) error {
+	return tx.Exec(`INSERT INTO serviceable_pincodes (pincode, deliverable, cod_allowed, eta_days, updated_at)
+		VALUES (?, ?, ?, ?, NOW())
+		ON CONFLICT (pincode) DO UPDATE SET deliverable = EXCLUDED.deliverable, cod_allowed = EXCLUDED.cod_allowed,
+			eta_days = EXCLUDED.eta_days, updated_at = EXCLUDED.updated_at`,
+		pincode.Pincode, pincode.Deliverable, pincode.CODAllowed, pincode.ETADays).Error
+}
+
+// GetPincode returns a pincode's serviceability, with an empty Pincode when
+// it is not in the table.
+func (s *ShippingRepository) GetPincode(pincode string) (domain.ServiceablePincode, error) {
+	var serviceable domain.ServiceablePincode
+	err := s.DB.Raw("SELECT * FROM serviceable_pincodes WHERE pincode = ?", pincode).Scan(&serviceable).Error
+	if err != nil {
+		return domain.ServiceablePincode{}, err
+	}
+	return serviceable, nil
+}
+
+// GetPincodes lists the serviceability table, optionally only the pincodes
+// starting with prefix.
+func (s *ShippingRepository) GetPincodes(prefix string) ([]domain.ServiceablePincode, error) {
+	var pincodes []domain.ServiceablePincode
+	err := s.DB.Raw("SELECT * FROM serviceable_pincodes WHERE pincode LIKE ? ORDER BY pincode", prefix+"%").Scan(&pincodes).Error
+	if err != nil {
+		return nil, err
+	}
+	return pincodes, nil
+}
+
+func (s *ShippingRepository) CountPincodes() (int64, error) {
+	var count int64
+	err := s.DB.Raw("SELECT COUNT(*) FROM serviceable_pincodes").Scan(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetOrderPin returns the pincode an order is delivered to.
+func (s *ShippingRepository) GetOrderPin(tx *gorm.DB, orderID int) (string, error) {
+	var pin string
+	err := tx.Raw(`SELECT addresses.pin FROM orders JOIN addresses ON addresses.id = orders.address_id
+		WHERE orders.order_id = ?`, orderID).Scan(&pin).Error
+	if err != nil {
+		return "", err
+	}
+	if pin == "" {
+		return "", errors.New("order not found")
+	}
+	return pin, nil
+}
+
+func (s *ShippingRepository) UpdateDeliveryEstimate(tx *gorm.DB, orderID int, from, to time.Time) error {
+	return tx.Exec("UPDATE orders SET estimated_delivery_from = ?, estimated_delivery_to = ? WHERE order_id = ?",
+		from, to, orderID).Error
+}
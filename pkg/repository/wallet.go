@@ -2,13 +2,20 @@ package repository
 
 import (
 	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/ledger"
+	"ecommerce_clean_arch/pkg/money"
 	"ecommerce_clean_arch/pkg/utils/models"
 	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrInsufficientWalletBalance is returned for a debit larger than the
+// wallet's balance.
+var ErrInsufficientWalletBalance = errors.New("wallet balance is too low")
+
 type WalletRepository struct {
 	DB *gorm.DB
 }
@@ -17,32 +24,110 @@ func NewWalletRepository(DB *gorm.DB) *WalletRepository {
 	return &WalletRepository{DB: DB}
 }
 
-func (wal *WalletRepository) CreateOrUpdateWallet(tx *gorm.DB, userID int, creditAmount uint) (uint, error) {
-	var wallet domain.Wallet
-	err := tx.Where("user_id = ?", userID).First(&wallet).Error
+func (wal *WalletRepository) BeginTransaction() (*gorm.DB, error) {
+	tx := wal.DB.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tx, nil
+}
+
+// Credit puts amount into userID's wallet from the ledger account from,
+// inside tx. It posts the journal entry for source, moves the cached
+// balance and records the transaction the customer sees, and returns the
+// new balance.
+func (wal *WalletRepository) Credit(tx *gorm.DB, userID int, amount money.Money, from string, source ledger.Source) (money.Money, error) {
+	return wal.move(tx, userID, amount, ledger.Credit(userID, from, amount), source, walletActor{})
+}
+
+// Debit takes amount out of userID's wallet to the ledger account to,
+// inside tx, like Credit. It fails with ErrInsufficientWalletBalance
+// rather than overdraw the wallet.
+func (wal *WalletRepository) Debit(tx *gorm.DB, userID int, amount money.Money, to string, source ledger.Source) (money.Money, error) {
+	return wal.move(tx, userID, -amount, ledger.Debit(userID, to, amount), source, walletActor{})
+}
+
+// Adjust applies an admin's adjustment to its customer's wallet against the
+// adjustments account, inside tx, recording adminID on the transaction.
+// A debit fails with ErrInsufficientWalletBalance like Debit.
+func (wal *WalletRepository) Adjust(tx *gorm.DB, adjustment domain.WalletAdjustment, adminID int) (money.Money, error) {
+	source := ledger.Source{
+		Type:        ledger.SourceAdjustment,
+		ID:          adjustment.ID,
+		Description: "wallet adjustment: " + adjustment.ReasonCode,
+	}
+	actor := walletActor{kind: models.ActorAdmin, id: adminID}
+	if adjustment.Direction == models.AdjustmentDebit {
+		lines := ledger.Debit(adjustment.UserID, ledger.AccountAdjustments, adjustment.Amount)
+		return wal.move(tx, adjustment.UserID, -adjustment.Amount, lines, source, actor)
+	}
+	lines := ledger.Credit(adjustment.UserID, ledger.AccountAdjustments, adjustment.Amount)
+	return wal.move(tx, adjustment.UserID, adjustment.Amount, lines, source, actor)
+}
+
+// walletActor is who moved money by hand; it is empty for the customer and
+// the system.
+type walletActor struct {
+	kind string
+	id   int
+}
+
+func (wal *WalletRepository) move(tx *gorm.DB, userID int, change money.Money, lines []ledger.Line, source ledger.Source, actor walletActor) (money.Money, error) {
+	balance, err := wal.LockWallet(tx, userID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			wallet = domain.Wallet{
-				UserID:  uint(userID),
-				Balance: creditAmount,
-			}
-			if err := tx.Create(&wallet).Error; err != nil {
-				return 0, fmt.Errorf("failed to create wallet: %w", err)
-			}
-		} else {
-			return 0, fmt.Errorf("failed to query wallet: %w", err)
-		}
+		return 0, err
+	}
+	balance += change
+	if balance.IsNegative() {
+		return 0, ErrInsufficientWalletBalance
+	}
+
+	entry, err := postJournal(tx, source, lines)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Exec("UPDATE wallets SET balance = ? WHERE user_id = ?", balance, userID).Error; err != nil {
+		return 0, fmt.Errorf("failed to update wallet balance: %w", err)
+	}
+
+	transaction := domain.WalletTransaction{
+		UserID:         uint(userID),
+		EventDate:      time.Now(),
+		TotalAmount:    balance,
+		SourceType:     source.Type,
+		SourceID:       source.ID,
+		JournalEntryID: entry.ID,
+		Actor:          actor.kind,
+		ActorID:        actor.id,
+	}
+	if change.IsPositive() {
+		transaction.Credit = change
 	} else {
-		wallet.Balance += creditAmount
-		if err := tx.Model(&wallet).Update("balance", wallet.Balance).Error; err != nil {
-			return 0, fmt.Errorf("failed to update wallet balance: %w", err)
-		}
+		transaction.Debit = change.Abs()
 	}
-	return wallet.Balance, nil
+	if err := tx.Create(&transaction).Error; err != nil {
+		return 0, fmt.Errorf("failed to record wallet transaction: %w", err)
+	}
+	return balance, nil
 }
 
-func (wal *WalletRepository) GetWalletbalance(tx *gorm.DB, userID int) (uint, error) {
-	var currentBalance uint
+// LockWallet locks userID's wallet row until tx ends, creating the wallet
+// if the user has none, and returns its balance.
+func (wal *WalletRepository) LockWallet(tx *gorm.DB, userID int) (money.Money, error) {
+	err := tx.Exec("INSERT INTO wallets (user_id, balance) VALUES (?, 0) ON CONFLICT (user_id) DO NOTHING", userID).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to create wallet: %w", err)
+	}
+	var balance money.Money
+	err = tx.Raw("SELECT balance FROM wallets WHERE user_id = ? FOR UPDATE", userID).Scan(&balance).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to query wallet: %w", err)
+	}
+	return balance, nil
+}
+
+func (wal *WalletRepository) GetWalletbalance(tx *gorm.DB, userID int) (money.Money, error) {
+	var currentBalance money.Money
 	err := tx.Raw("SELECT balance FROM wallets WHERE user_id = ?", userID).Scan(&currentBalance).Error
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch wallet balance: %w", err)
@@ -52,11 +137,6 @@ func (wal *WalletRepository) GetWalletbalance(tx *gorm.DB, userID int) (uint, er
 	// }
 	return currentBalance, nil
 }
-func (wal *WalletRepository) WalletTransaction(tx *gorm.DB, transaction models.WalletTransaction) error {
-	result := tx.Omit("TransactionID").Create(&transaction)
-	return result.Error
-}
-
 func (wal *WalletRepository) GetWalletTransaction(userID int) (*[]models.WalletTransaction, error) {
 	var transaction *[]models.WalletTransaction
 	query := "SELECT * FROM wallet_transactions WHERE user_id = ?"
@@ -70,8 +150,8 @@ func (wal *WalletRepository) GetWalletTransaction(userID int) (*[]models.WalletT
 	return transaction, nil
 }
 
-func (wal *WalletRepository) GetFinalPriceByOrderID(orderID string) (uint, error) {
-	var finalPrice uint
+func (wal *WalletRepository) GetFinalPriceByOrderID(orderID string) (money.Money, error) {
+	var finalPrice money.Money
 	query := "SELECT SUM(final_price) FROM orders WHERE order_id = ?"
 	result := wal.DB.Raw(query, orderID).Scan(&finalPrice)
 	if result.Error != nil {
@@ -93,14 +173,99 @@ func (wal *WalletRepository) GetWallet(userID int) (*models.UserWallet, error) {
 	return &userWallet, nil
 }
 
-func (wal *WalletRepository) UpdateWalletReduceBalance(userID string, amount uint) error {
-	query := "UPDATE wallets SET balance = ? WHERE user_id = ?"
-	result := wal.DB.Raw(query, amount, userID)
-	if result.Error != nil {
-		return fmt.Errorf("face some issue while update wallet balance %w", result.Error)
+func (wal *WalletRepository) CreateTopUp(tx *gorm.DB, topUp domain.WalletTopUp) (domain.WalletTopUp, error) {
+	if err := tx.Create(&topUp).Error; err != nil {
+		return domain.WalletTopUp{}, fmt.Errorf("failed to create top-up: %w", err)
 	}
-	if result.RowsAffected == 0 {
-		return errors.New("No rows affected while updating the wallet balance")
+	return topUp, nil
+}
+
+// LockTopUp loads a top-up and locks it until tx ends, so a payment is
+// credited once however many times it is verified.
+func (wal *WalletRepository) LockTopUp(tx *gorm.DB, topUpID int) (domain.WalletTopUp, error) {
+	var topUp domain.WalletTopUp
+	err := tx.Raw("SELECT * FROM wallet_top_ups WHERE id = ? FOR UPDATE", topUpID).Scan(&topUp).Error
+	if err != nil {
+		return domain.WalletTopUp{}, err
+	}
+	if topUp.ID == 0 {
+		return domain.WalletTopUp{}, errors.New("top-up not found")
+	}
+	return topUp, nil
+}
+
+// GetTopUpIDByGatewayOrderID returns the top-up a gateway order was created
+// for, or 0 if it was created for something else.
+func (wal *WalletRepository) GetTopUpIDByGatewayOrderID(gatewayOrderID string) (int, error) {
+	var topUpID int
+	err := wal.DB.Raw("SELECT id FROM wallet_top_ups WHERE gateway_order_id = ?", gatewayOrderID).Scan(&topUpID).Error
+	return topUpID, err
+}
+
+func (wal *WalletRepository) SaveTopUp(tx *gorm.DB, topUp domain.WalletTopUp) error {
+	return tx.Save(&topUp).Error
+}
+
+// GetTopUpTotal adds up userID's top-ups that count against the daily
+// cap: those credited since since, and those still awaiting payment that
+// were started since pendingSince.
+func (wal *WalletRepository) GetTopUpTotal(tx *gorm.DB, userID int, since, pendingSince time.Time) (money.Money, error) {
+	var total money.Money
+	err := tx.Raw(`SELECT COALESCE(SUM(amount), 0) FROM wallet_top_ups
+		WHERE user_id = ? AND ((status = ? AND credited_at >= ?) OR (status = ? AND created_at >= ?))`,
+		userID, models.TopUpCredited, since, models.TopUpPending, pendingSince).Scan(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to add up top-ups: %w", err)
 	}
-	return nil
+	return total, nil
+}
+
+func (wal *WalletRepository) CreateAdjustment(tx *gorm.DB, adjustment domain.WalletAdjustment) (domain.WalletAdjustment, error) {
+	if err := tx.Create(&adjustment).Error; err != nil {
+		return domain.WalletAdjustment{}, fmt.Errorf("failed to create wallet adjustment: %w", err)
+	}
+	return adjustment, nil
+}
+
+// LockAdjustment loads an adjustment and locks it until tx ends, so it is
+// approved or rejected once.
+func (wal *WalletRepository) LockAdjustment(tx *gorm.DB, adjustmentID int) (domain.WalletAdjustment, error) {
+	var adjustment domain.WalletAdjustment
+	err := tx.Raw("SELECT * FROM wallet_adjustments WHERE id = ? FOR UPDATE", adjustmentID).Scan(&adjustment).Error
+	if err != nil {
+		return domain.WalletAdjustment{}, err
+	}
+	if adjustment.ID == 0 {
+		return domain.WalletAdjustment{}, errors.New("wallet adjustment not found")
+	}
+	return adjustment, nil
+}
+
+func (wal *WalletRepository) SaveAdjustment(tx *gorm.DB, adjustment domain.WalletAdjustment) error {
+	return tx.Save(&adjustment).Error
+}
+
+// GetAdjustments lists wallet adjustments, newest first, optionally only
+// those with status or for userID.
+func (wal *WalletRepository) GetAdjustments(status string, userID int) ([]domain.WalletAdjustment, error) {
+	var adjustments []domain.WalletAdjustment
+	query := wal.DB.Model(&domain.WalletAdjustment{}).Order("created_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if err := query.Find(&adjustments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list wallet adjustments: %w", err)
+	}
+	return adjustments, nil
+}
+
+// GetOrderUserID returns who placed an order, or 0 if there is no such
+// order.
+func (wal *WalletRepository) GetOrderUserID(orderID int) (int, error) {
+	var userID int
+	err := wal.DB.Raw("SELECT user_id FROM orders WHERE order_id = ?", orderID).Scan(&userID).Error
+	return userID, err
 }
@@ -0,0 +1,154 @@
+package repository_test
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/ledger"
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/repository"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func Test_WalletDebit(t *testing.T) {
+	createWallet := regexp.QuoteMeta("INSERT INTO wallets (user_id, balance) VALUES ($1, 0) ON CONFLICT (user_id) DO NOTHING")
+	lockWallet := regexp.QuoteMeta("SELECT balance FROM wallets WHERE user_id = $1 FOR UPDATE")
+	openAccount := regexp.QuoteMeta("INSERT INTO ledger_accounts (code, kind, created_at) VALUES ($1, $2, NOW())")
+	accountID := regexp.QuoteMeta("SELECT id FROM ledger_accounts WHERE code = $1")
+	source := ledger.Source{Type: ledger.SourceOrder, ID: 11, Description: "order payment"}
+
+	tests := []struct {
+		name      string
+		amount    money.Money
+		setupMock func(mock sqlmock.Sqlmock)
+		want      money.Money
+		expectErr error
+	}{
+		{
+			name:   "debit is posted to the ledger and the wallet",
+			amount: money.Rupees(300),
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(createWallet).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(lockWallet).WithArgs(7).
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(50000))
+				mock.ExpectExec(openAccount).WithArgs("wallet:7", "liability").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(accountID).WithArgs("wallet:7").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectExec(openAccount).WithArgs("sales", "income").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(accountID).WithArgs("sales").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "journal_entries"`)).
+					WithArgs("order", 11, "order payment", sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(21))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "postings"`)).
+					WithArgs(21, 3, 30000, 21, 1, -30000).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(41).AddRow(42))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = $1 WHERE user_id = $2")).
+					WithArgs(20000, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "wallet_transactions"`)).
+					WithArgs(7, 0, 30000, sqlmock.AnyArg(), 20000, "order", 11, 21, "", 0).
+					WillReturnRows(sqlmock.NewRows([]string{"transaction_id"}).AddRow(5))
+			},
+			want: money.Rupees(200),
+		},
+		{
+			name:   "wallet cannot be overdrawn",
+			amount: money.Rupees(300),
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(createWallet).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(lockWallet).WithArgs(7).
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(29999))
+			},
+			expectErr: repository.ErrInsufficientWalletBalance,
+		},
+		{
+			name:   "database error",
+			amount: money.Rupees(300),
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(createWallet).WithArgs(7).WillReturnError(gorm.ErrInvalidDB)
+			},
+			expectErr: gorm.ErrInvalidDB,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{
+				Conn: mockSQL,
+			}), &gorm.Config{
+				Logger:                 logger.Default.LogMode(logger.Silent),
+				SkipDefaultTransaction: true,
+			})
+			assert.NoError(t, err)
+
+			walletRepo := repository.NewWalletRepository(db)
+
+			tt.setupMock(mock)
+
+			balance, err := walletRepo.Debit(db, 7, tt.amount, ledger.AccountSales, source)
+
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, balance)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_WalletAdjust(t *testing.T) {
+	mockSQL, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer mockSQL.Close()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: mockSQL,
+	}), &gorm.Config{
+		Logger:                 logger.Default.LogMode(logger.Silent),
+		SkipDefaultTransaction: true,
+	})
+	assert.NoError(t, err)
+
+	openAccount := regexp.QuoteMeta("INSERT INTO ledger_accounts (code, kind, created_at) VALUES ($1, $2, NOW())")
+	accountID := regexp.QuoteMeta("SELECT id FROM ledger_accounts WHERE code = $1")
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO wallets (user_id, balance) VALUES ($1, 0) ON CONFLICT (user_id) DO NOTHING")).
+		WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT balance FROM wallets WHERE user_id = $1 FOR UPDATE")).WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(10000))
+	mock.ExpectExec(openAccount).WithArgs("adjustments", "expense").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(accountID).WithArgs("adjustments").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectExec(openAccount).WithArgs("wallet:7", "liability").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(accountID).WithArgs("wallet:7").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "journal_entries"`)).
+		WithArgs("adjustment", 9, "wallet adjustment: goodwill", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(21))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "postings"`)).
+		WithArgs(21, 5, 25000, 21, 3, -25000).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(41).AddRow(42))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE wallets SET balance = $1 WHERE user_id = $2")).
+		WithArgs(35000, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "wallet_transactions"`)).
+		WithArgs(7, 25000, 0, sqlmock.AnyArg(), 35000, "adjustment", 9, 21, "admin", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"transaction_id"}).AddRow(5))
+
+	walletRepo := repository.NewWalletRepository(db)
+	balance, err := walletRepo.Adjust(db, domain.WalletAdjustment{
+		ID:         9,
+		UserID:     7,
+		Direction:  "credit",
+		Amount:     money.Rupees(250),
+		ReasonCode: "goodwill",
+	}, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.Rupees(350), balance)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
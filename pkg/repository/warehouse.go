@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+)
+
+// GetShippingDetails returns the order's customer and delivery address.
+func (ad *AdminRepository) GetShippingDetails(orderID int) (models.CombinedOrderDetails, error) {
+	var orderDetails models.CombinedOrderDetails
+	err := ad.DB.Raw(`
+	SELECT 
+		orders.order_id, orders.final_price, orders.gift_card_amount, orders.order_status, 
+		orders.payment_status, users.first_name, users.email, users.phone,
+		addresses.house_name, addresses.street, addresses.city, 
+		addresses.district, addresses.state, addresses.pin 
+	FROM orders 
+	INNER JOIN users ON orders.user_id = users.id 
+	INNER JOIN addresses ON orders.address_id = addresses.id 
+	WHERE orders.order_id = ? 
+`, orderID).Scan(&orderDetails).Error
+	if err != nil {
+		return models.CombinedOrderDetails{}, err
+	}
+	if orderDetails.OrderId == "" {
+		return models.CombinedOrderDetails{}, errors.New("order not found")
+	}
+	return orderDetails, nil
+}
+
+// GetPackingLines returns the lines of an order still to be shipped.
+func (ad *AdminRepository) GetPackingLines(orderID int) ([]models.PackingLine, error) {
+	var lines []models.PackingLine
+	err := ad.DB.Raw(`SELECT order_items.id AS order_item_id, order_items.product_id, products.name AS product_name,
+			product_variants.sku, product_variants.size, product_variants.size_system, product_variants.colour,
+			order_items.quantity
+		FROM order_items
+		JOIN products ON products.id = order_items.product_id
+		LEFT JOIN product_variants ON product_variants.id = order_items.variant_id
+		WHERE order_items.order_id = ? AND order_items.status NOT IN ?
+		ORDER BY order_items.id`, orderID, models.ClosedItemStatuses).Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// GetPickLines totals the open lines of every order in status by product
+// and variant, in product name and size order.
+func (ad *AdminRepository) GetPickLines(status string) ([]models.PickLine, error) {
+	var lines []models.PickLine
+	err := ad.DB.Raw(`SELECT order_items.product_id, products.name AS product_name,
+			product_variants.sku, product_variants.size, product_variants.size_system, product_variants.colour,
+			SUM(order_items.quantity) AS quantity, COUNT(DISTINCT order_items.order_id) AS orders
+		FROM order_items
+		JOIN orders ON orders.order_id = order_items.order_id
+		JOIN products ON products.id = order_items.product_id
+		LEFT JOIN product_variants ON product_variants.id = order_items.variant_id
+		WHERE orders.order_status = ? AND order_items.status NOT IN ?
+		GROUP BY order_items.product_id, products.name, order_items.variant_id,
+			product_variants.sku, product_variants.size, product_variants.size_system, product_variants.colour
+		ORDER BY products.name, product_variants.size, product_variants.colour`,
+		status, models.ClosedItemStatuses).Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
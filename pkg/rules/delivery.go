@@ -0,0 +1,172 @@
+// Package rules decides what a cart pays for delivery and whether it may
+// be paid for in cash on delivery, from the delivery rules admins set. It
+// works on rules already loaded, so it can be tested without a database.
+package rules
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/money"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Actions a delivery rule can take. The fee actions decide the delivery
+// charge and the COD actions whether cash on delivery is offered.
+const (
+	ActionFlatFee      = "flat_fee"
+	ActionPercentFee   = "percent_fee"
+	ActionFreeShipping = "free_shipping"
+	ActionBlockCOD     = "block_cod"
+	ActionAllowCOD     = "allow_cod"
+)
+
+var (
+	feeActions = []string{ActionFlatFee, ActionPercentFee, ActionFreeShipping}
+	codActions = []string{ActionBlockCOD, ActionAllowCOD}
+)
+
+// Customer tiers, from how many of their orders have been delivered.
+const (
+	TierNew     = "new"
+	TierRegular = "regular"
+	TierLoyal   = "loyal"
+)
+
+// loyalOrders is how many delivered orders make a customer loyal.
+const loyalOrders = 5
+
+var tiers = []string{TierNew, TierRegular, TierLoyal}
+
+// Tier places a customer by the number of their orders delivered so far.
+func Tier(deliveredOrders int) string {
+	switch {
+	case deliveredOrders >= loyalOrders:
+		return TierLoyal
+	case deliveredOrders > 0:
+		return TierRegular
+	}
+	return TierNew
+}
+
+// Cart is what the rules are matched against.
+type Cart struct {
+	// Value is what the items cost after discounts.
+	Value         money.Money
+	Items         int
+	Zone          string
+	UserTier      string
+	PaymentMethod string
+	// CarrierFee is the carrier's quote, charged when no fee rule matches.
+	CarrierFee money.Money
+}
+
+// Outcome is what the rules decided for a cart, and which rules decided it.
+type Outcome struct {
+	DeliveryCharge money.Money
+	CODAllowed     bool
+	FeeRule        string
+	CODRule        string
+}
+
+// Evaluate tries the active rules in priority order. The first matching
+// fee rule sets the delivery charge and the first matching COD rule decides
+// cash on delivery; without one the carrier's fee is charged and cash on
+// delivery is allowed.
+func Evaluate(deliveryRules []domain.DeliveryRule, cart Cart) Outcome {
+	ordered := slices.Clone(deliveryRules)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Priority != ordered[j].Priority {
+			return ordered[i].Priority < ordered[j].Priority
+		}
+		return ordered[i].ID < ordered[j].ID
+	})
+
+	outcome := Outcome{DeliveryCharge: cart.CarrierFee, CODAllowed: true}
+	var feeDecided, codDecided bool
+	for _, rule := range ordered {
+		if !rule.Active || !Matches(rule, cart) {
+			continue
+		}
+		switch {
+		case !feeDecided && slices.Contains(feeActions, rule.Action):
+			feeDecided = true
+			outcome.FeeRule = rule.Name
+			outcome.DeliveryCharge = fee(rule, cart)
+		case !codDecided && slices.Contains(codActions, rule.Action):
+			codDecided = true
+			outcome.CODRule = rule.Name
+			outcome.CODAllowed = rule.Action == ActionAllowCOD
+		}
+	}
+	return outcome
+}
+
+// Matches reports whether cart meets every condition of rule.
+func Matches(rule domain.DeliveryRule, cart Cart) bool {
+	switch {
+	case cart.Value < rule.MinCartValue:
+		return false
+	case rule.MaxCartValue.IsPositive() && cart.Value > rule.MaxCartValue:
+		return false
+	case cart.Items < rule.MinItems:
+		return false
+	case rule.MaxItems > 0 && cart.Items > rule.MaxItems:
+		return false
+	case len(rule.Zones) > 0 && !slices.Contains(rule.Zones, cart.Zone):
+		return false
+	case len(rule.UserTiers) > 0 && !slices.Contains(rule.UserTiers, cart.UserTier):
+		return false
+	case len(rule.PaymentMethods) > 0 && !slices.Contains(rule.PaymentMethods, cart.PaymentMethod):
+		return false
+	}
+	return true
+}
+
+func fee(rule domain.DeliveryRule, cart Cart) money.Money {
+	switch rule.Action {
+	case ActionFlatFee:
+		return rule.Amount
+	case ActionPercentFee:
+		return cart.Value.Percent(rule.Percent)
+	}
+	return 0
+}
+
+// Validate checks that a rule can be evaluated.
+func Validate(rule domain.DeliveryRule) error {
+	if rule.Name == "" {
+		return errors.New("rule needs a name")
+	}
+	if !slices.Contains(feeActions, rule.Action) && !slices.Contains(codActions, rule.Action) {
+		return fmt.Errorf("unknown action %q", rule.Action)
+	}
+	if rule.Amount.IsNegative() || rule.Percent < 0 {
+		return errors.New("amount cannot be negative")
+	}
+	if rule.Percent > 100 {
+		return errors.New("percent fee cannot be above 100")
+	}
+	if rule.Action == ActionFlatFee && rule.Amount.IsZero() {
+		return fmt.Errorf("%s needs an amount", rule.Action)
+	}
+	if rule.Action == ActionPercentFee && rule.Percent == 0 {
+		return fmt.Errorf("%s needs a percent", rule.Action)
+	}
+	if rule.MinCartValue.IsNegative() || rule.MaxCartValue.IsNegative() || rule.MinItems < 0 || rule.MaxItems < 0 {
+		return errors.New("conditions cannot be negative")
+	}
+	if rule.MaxCartValue.IsPositive() && rule.MaxCartValue < rule.MinCartValue {
+		return errors.New("max cart value is below min cart value")
+	}
+	if rule.MaxItems > 0 && rule.MaxItems < rule.MinItems {
+		return errors.New("max items is below min items")
+	}
+	for _, tier := range rule.UserTiers {
+		if !slices.Contains(tiers, tier) {
+			return fmt.Errorf("unknown user tier %q", tier)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package rules_test
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/rules"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var defaultRules = []domain.DeliveryRule{
+	{ID: 1, Name: "free from 1000", Priority: 100, Active: true, MinCartValue: money.Rupees(1000), Action: rules.ActionFreeShipping},
+	{ID: 2, Name: "no cod above 1000", Priority: 100, Active: true, MinCartValue: money.Paise(100001), Action: rules.ActionBlockCOD},
+}
+
+func Test_Evaluate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []domain.DeliveryRule
+		cart  rules.Cart
+		want  rules.Outcome
+	}{
+		{
+			name:  "no rules charges the carrier fee",
+			rules: nil,
+			cart:  rules.Cart{Value: money.Rupees(500), CarrierFee: money.Rupees(50)},
+			want:  rules.Outcome{DeliveryCharge: money.Rupees(50), CODAllowed: true},
+		},
+		{
+			name:  "below the threshold",
+			rules: defaultRules,
+			cart:  rules.Cart{Value: money.Rupees(999), CarrierFee: money.Rupees(50)},
+			want:  rules.Outcome{DeliveryCharge: money.Rupees(50), CODAllowed: true},
+		},
+		{
+			name:  "at the threshold ships free and keeps cod",
+			rules: defaultRules,
+			cart:  rules.Cart{Value: money.Rupees(1000), CarrierFee: money.Rupees(50)},
+			want:  rules.Outcome{DeliveryCharge: money.Rupees(0), CODAllowed: true, FeeRule: "free from 1000"},
+		},
+		{
+			name:  "a paisa above the threshold blocks cod",
+			rules: defaultRules,
+			cart:  rules.Cart{Value: money.Paise(100001), CarrierFee: money.Rupees(50)},
+			want:  rules.Outcome{DeliveryCharge: money.Rupees(0), CODAllowed: false, FeeRule: "free from 1000", CODRule: "no cod above 1000"},
+		},
+		{
+			name:  "above the threshold blocks cod",
+			rules: defaultRules,
+			cart:  rules.Cart{Value: money.Rupees(1500), CarrierFee: money.Rupees(50)},
+			want:  rules.Outcome{DeliveryCharge: money.Rupees(0), CODAllowed: false, FeeRule: "free from 1000", CODRule: "no cod above 1000"},
+		},
+		{
+			name: "higher priority wins",
+			rules: append([]domain.DeliveryRule{
+				{ID: 3, Name: "special zone", Priority: 10, Active: true, Zones: []string{"special"}, Action: rules.ActionFlatFee, Amount: money.Rupees(150)},
+			}, defaultRules...),
+			cart: rules.Cart{Value: money.Rupees(1200), Zone: "special", CarrierFee: money.Rupees(120)},
+			want: rules.Outcome{DeliveryCharge: money.Rupees(150), CODAllowed: false, FeeRule: "special zone", CODRule: "no cod above 1000"},
+		},
+		{
+			name: "loyal customers keep cod",
+			rules: append([]domain.DeliveryRule{
+				{ID: 3, Name: "loyal cod", Priority: 10, Active: true, UserTiers: []string{rules.TierLoyal}, Action: rules.ActionAllowCOD},
+			}, defaultRules...),
+			cart: rules.Cart{Value: money.Rupees(1500), UserTier: rules.TierLoyal},
+			want: rules.Outcome{DeliveryCharge: money.Rupees(0), CODAllowed: true, FeeRule: "free from 1000", CODRule: "loyal cod"},
+		},
+		{
+			name: "percent fee on cod orders",
+			rules: []domain.DeliveryRule{
+				{ID: 1, Name: "cod handling", Priority: 1, Active: true, PaymentMethods: []string{"COD"}, Action: rules.ActionPercentFee, Percent: 2.5},
+			},
+			cart: rules.Cart{Value: money.Rupees(799), PaymentMethod: "COD", CarrierFee: money.Rupees(50)},
+			want: rules.Outcome{DeliveryCharge: money.Paise(1998), CODAllowed: true, FeeRule: "cod handling"},
+		},
+		{
+			name: "item count bounds",
+			rules: []domain.DeliveryRule{
+				{ID: 1, Name: "bulk", Priority: 1, Active: true, MinItems: 3, MaxItems: 5, Action: rules.ActionFlatFee, Amount: money.Rupees(99)},
+			},
+			cart: rules.Cart{Value: money.Rupees(500), Items: 6, CarrierFee: money.Rupees(50)},
+			want: rules.Outcome{DeliveryCharge: money.Rupees(50), CODAllowed: true},
+		},
+		{
+			name: "inactive rules are skipped",
+			rules: []domain.DeliveryRule{
+				{ID: 1, Name: "paused", Priority: 1, Active: false, Action: rules.ActionFreeShipping},
+			},
+			cart: rules.Cart{Value: money.Rupees(500), CarrierFee: money.Rupees(50)},
+			want: rules.Outcome{DeliveryCharge: money.Rupees(50), CODAllowed: true},
+		},
+		{
+			name: "first order ships free",
+			rules: []domain.DeliveryRule{
+				{ID: 1, Name: "welcome", Priority: 1, Active: true, UserTiers: []string{rules.TierNew}, MaxCartValue: money.Paise(99999), Action: rules.ActionFreeShipping},
+			},
+			cart: rules.Cart{Value: money.Rupees(400), UserTier: rules.Tier(0), CarrierFee: money.Rupees(50)},
+			want: rules.Outcome{DeliveryCharge: money.Rupees(0), CODAllowed: true, FeeRule: "welcome"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rules.Evaluate(tt.rules, tt.cart))
+		})
+	}
+}
+
+func Test_Tier(t *testing.T) {
+	assert.Equal(t, rules.TierNew, rules.Tier(0))
+	assert.Equal(t, rules.TierRegular, rules.Tier(1))
+	assert.Equal(t, rules.TierRegular, rules.Tier(4))
+	assert.Equal(t, rules.TierLoyal, rules.Tier(5))
+}
+
+func Test_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      domain.DeliveryRule
+		expectErr bool
+	}{
+		{name: "valid", rule: domain.DeliveryRule{Name: "free", Action: rules.ActionFreeShipping, MinCartValue: money.Rupees(1000)}},
+		{name: "no name", rule: domain.DeliveryRule{Action: rules.ActionFreeShipping}, expectErr: true},
+		{name: "unknown action", rule: domain.DeliveryRule{Name: "x", Action: "discount"}, expectErr: true},
+		{name: "flat fee without amount", rule: domain.DeliveryRule{Name: "x", Action: rules.ActionFlatFee}, expectErr: true},
+		{name: "percent fee without percent", rule: domain.DeliveryRule{Name: "x", Action: rules.ActionPercentFee, Amount: money.Rupees(5)}, expectErr: true},
+		{name: "percent over 100", rule: domain.DeliveryRule{Name: "x", Action: rules.ActionPercentFee, Percent: 101}, expectErr: true},
+		{name: "inverted cart bounds", rule: domain.DeliveryRule{Name: "x", Action: rules.ActionBlockCOD, MinCartValue: money.Rupees(500), MaxCartValue: money.Rupees(100)}, expectErr: true},
+		{name: "inverted item bounds", rule: domain.DeliveryRule{Name: "x", Action: rules.ActionBlockCOD, MinItems: 5, MaxItems: 2}, expectErr: true},
+		{name: "unknown tier", rule: domain.DeliveryRule{Name: "x", Action: rules.ActionBlockCOD, UserTiers: []string{"platinum"}}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rules.Validate(tt.rule)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
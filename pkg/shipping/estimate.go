@@ -0,0 +1,71 @@
+package shipping
+
+import (
+	"fmt"
+	"time"
+)
+
+// IST is the warehouse's clock: the dispatch cutoff and delivery dates are
+// in Indian time whatever zone the server runs in.
+var IST = time.FixedZone("IST", 5*60*60+30*60)
+
+// DefaultDispatchCutoff is when the warehouse stops dispatching for the
+// day if DISPATCH_CUTOFF is not set.
+const DefaultDispatchCutoff = 14 * time.Hour
+
+// DefaultTransitDays is assumed for pincodes without an ETA in the
+// serviceability table.
+const DefaultTransitDays = 5
+
+// estimateSpreadDays widens the estimate into a window, since couriers
+// rarely promise a single day.
+const estimateSpreadDays = 2
+
+// ParseCutoff reads a dispatch cutoff written as a 24-hour "HH:MM".
+func ParseCutoff(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid dispatch cutoff %q, want HH:MM", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// EstimateDelivery returns the window an order placed at placedAt should
+// arrive in. Orders placed before cutoff leave the warehouse that day and
+// later ones the next working day; nothing moves on Sundays.
+func EstimateDelivery(placedAt time.Time, cutoff time.Duration, transitDays int) (time.Time, time.Time) {
+	placedAt = placedAt.In(IST)
+	dispatch := midnight(placedAt)
+	if placedAt.Sub(dispatch) >= cutoff {
+		dispatch = dispatch.AddDate(0, 0, 1)
+	}
+	for dispatch.Weekday() == time.Sunday {
+		dispatch = dispatch.AddDate(0, 0, 1)
+	}
+	return EstimateFromDispatch(dispatch, transitDays)
+}
+
+// EstimateFromDispatch returns the delivery window of a parcel that left
+// the warehouse at dispatchedAt.
+func EstimateFromDispatch(dispatchedAt time.Time, transitDays int) (time.Time, time.Time) {
+	if transitDays <= 0 {
+		transitDays = DefaultTransitDays
+	}
+	from := addWorkingDays(midnight(dispatchedAt.In(IST)), transitDays)
+	return from, addWorkingDays(from, estimateSpreadDays)
+}
+
+func addWorkingDays(day time.Time, n int) time.Time {
+	for n > 0 {
+		day = day.AddDate(0, 0, 1)
+		if day.Weekday() != time.Sunday {
+			n--
+		}
+	}
+	return day
+}
+
+func midnight(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
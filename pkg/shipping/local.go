@@ -0,0 +1,151 @@
+package shipping
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Zones of the local rate card, from the seller's own sorting district out.
+const (
+	ZoneLocal    = "local"
+	ZoneRegional = "regional"
+	ZoneZonal    = "zonal"
+	ZoneNational = "national"
+	ZoneSpecial  = "special"
+)
+
+// slabGrams is the weight step the rate card charges in.
+const slabGrams = 500
+
+// ZoneRate prices a zone: FirstSlab covers the first 500 g and every 500 g
+// or part of it after that adds AdditionalSlab. Amounts are in paise.
+type ZoneRate struct {
+	Zone           string
+	FirstSlab      int64
+	AdditionalSlab int64
+}
+
+// RateCard is the table the local provider quotes from.
+type RateCard struct {
+	Zones []ZoneRate
+	// CODFlat and CODPercent price collecting cash: whichever is higher of
+	// the flat fee and the percentage of the order value.
+	CODFlat    int64
+	CODPercent float64
+	// SpecialPrefixes are destination pincode prefixes that always take the
+	// special zone: the north east, Jammu and Kashmir and the islands.
+	SpecialPrefixes []string
+}
+
+var DefaultRateCard = RateCard{
+	Zones: []ZoneRate{
+		{Zone: ZoneLocal, FirstSlab: 5000, AdditionalSlab: 2000},
+		{Zone: ZoneRegional, FirstSlab: 6000, AdditionalSlab: 2500},
+		{Zone: ZoneZonal, FirstSlab: 7000, AdditionalSlab: 3000},
+		{Zone: ZoneNational, FirstSlab: 9000, AdditionalSlab: 4000},
+		{Zone: ZoneSpecial, FirstSlab: 12000, AdditionalSlab: 5000},
+	},
+	CODFlat:         3000,
+	CODPercent:      2,
+	SpecialPrefixes: []string{"18", "19", "78", "79", "744", "68255"},
+}
+
+// LocalProvider prices shipments from a rate card by zone and weight slab
+// and books them with the store's own delivery partners. It receives
+// tracking events only when a webhook secret is configured.
+type LocalProvider struct {
+	origin        string
+	card          RateCard
+	webhookSecret string
+}
+
+func NewLocalProvider(originPincode string, card RateCard, webhookSecret string) *LocalProvider {
+	return &LocalProvider{origin: originPincode, card: card, webhookSecret: webhookSecret}
+}
+
+func (l *LocalProvider) Name() string {
+	return Local
+}
+
+// Zone places a destination pincode relative to the origin: the same
+// sorting district is local, the same postal circle regional, the same
+// postal region zonal and anything else national.
+func (l *LocalProvider) Zone(destination string) (string, error) {
+	if !ValidPincode(destination) {
+		return "", ErrInvalidPincode
+	}
+	for _, prefix := range l.card.SpecialPrefixes {
+		if strings.HasPrefix(destination, prefix) {
+			return ZoneSpecial, nil
+		}
+	}
+	switch {
+	case l.origin == "":
+		return ZoneNational, nil
+	case destination[:3] == l.origin[:3]:
+		return ZoneLocal, nil
+	case destination[:2] == l.origin[:2]:
+		return ZoneRegional, nil
+	case destination[:1] == l.origin[:1]:
+		return ZoneZonal, nil
+	}
+	return ZoneNational, nil
+}
+
+func (l *LocalProvider) Rate(req RateRequest) (Quote, error) {
+	zone, err := l.Zone(req.DestinationPincode)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	var rate *ZoneRate
+	for i := range l.card.Zones {
+		if l.card.Zones[i].Zone == zone {
+			rate = &l.card.Zones[i]
+		}
+	}
+	if rate == nil {
+		return Quote{}, fmt.Errorf("no rate for zone %s", zone)
+	}
+
+	quote := Quote{Provider: Local, Zone: zone, Freight: rate.FirstSlab}
+	if req.WeightGrams > slabGrams {
+		extraSlabs := (req.WeightGrams - slabGrams + slabGrams - 1) / slabGrams
+		quote.Freight += int64(extraSlabs) * rate.AdditionalSlab
+	}
+	if req.COD {
+		quote.CODCharge = l.card.CODFlat
+		if percent := int64(float64(req.OrderValue) * l.card.CODPercent / 100); percent > quote.CODCharge {
+			quote.CODCharge = percent
+		}
+	}
+	quote.Total = quote.Freight + quote.CODCharge
+	return quote, nil
+}
+
+// CreateShipment books the parcel under an AWB derived from the order, so
+// booking the same order again returns the same number.
+func (l *LocalProvider) CreateShipment(req ShipmentRequest) (Shipment, error) {
+	if req.OrderID == 0 {
+		return Shipment{}, errors.New("shipment has no order")
+	}
+	quote, err := l.Rate(RateRequest{
+		DestinationPincode: req.DestinationPincode,
+		WeightGrams:        req.WeightGrams,
+		COD:                req.COD,
+		OrderValue:         req.CODAmount,
+	})
+	if err != nil {
+		return Shipment{}, err
+	}
+	return Shipment{AWB: fmt.Sprintf("SSL%010d", req.OrderID), Quote: quote}, nil
+}
+
+func (l *LocalProvider) SendsEvents() bool {
+	return l.webhookSecret != ""
+}
+
+func (l *LocalProvider) ParseEvent(body []byte, signature string) (Event, error) {
+	return parseSignedEvent(body, signature, l.webhookSecret)
+}
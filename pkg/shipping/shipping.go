@@ -0,0 +1,159 @@
+// Package shipping quotes, books and tracks parcels with a courier. Amounts
+// are in paise, like the payment gateway's.
+package shipping
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"ecommerce_clean_arch/pkg/config"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	Local = "local"
+	Fake  = "fake"
+)
+
+// Tracking statuses a carrier reports for a shipment.
+const (
+	StatusCreated        = "created"
+	StatusPickedUp       = "picked_up"
+	StatusInTransit      = "in_transit"
+	StatusOutForDelivery = "out_for_delivery"
+	StatusDelivered      = "delivered"
+	StatusFailedAttempt  = "failed_attempt"
+	StatusReturned       = "returned_to_origin"
+)
+
+var trackingStatuses = []string{
+	StatusPickedUp, StatusInTransit, StatusOutForDelivery, StatusDelivered, StatusFailedAttempt, StatusReturned,
+}
+
+// DefaultItemWeightGrams is the packed weight assumed for a unit whose
+// category does not set one: a pair of shoes in its box.
+const DefaultItemWeightGrams = 1000
+
+var (
+	ErrInvalidPincode          = errors.New("invalid pincode")
+	ErrInvalidWebhookSignature = errors.New("invalid tracking webhook signature")
+)
+
+type RateRequest struct {
+	DestinationPincode string
+	WeightGrams        int
+	COD                bool
+	// OrderValue is what the courier collects on a COD shipment.
+	OrderValue int64
+}
+
+// Quote is a carrier's price for a shipment. Amounts are in paise.
+type Quote struct {
+	Provider  string
+	Zone      string
+	Freight   int64
+	CODCharge int64
+	Total     int64
+}
+
+type ShipmentRequest struct {
+	OrderID            int
+	DestinationPincode string
+	WeightGrams        int
+	COD                bool
+	CODAmount          int64
+	Name               string
+	Phone              string
+	Address            string
+}
+
+type Shipment struct {
+	AWB   string
+	Quote Quote
+}
+
+// Event is a tracking update pushed by a carrier.
+type Event struct {
+	AWB         string    `json:"awb"`
+	Status      string    `json:"status"`
+	Location    string    `json:"location"`
+	Description string    `json:"description"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// ShippingProvider is the boundary between order fulfilment and a courier.
+type ShippingProvider interface {
+	// Name identifies the provider, e.g. "local".
+	Name() string
+	// Rate quotes delivery to a pincode for a parcel of the given weight.
+	Rate(req RateRequest) (Quote, error)
+	// CreateShipment books a pickup and returns the air waybill number.
+	CreateShipment(req ShipmentRequest) (Shipment, error)
+	// SendsEvents reports whether the carrier pushes tracking events. When
+	// it does, orders are only marked shipped and delivered from them.
+	SendsEvents() bool
+	// ParseEvent authenticates and decodes a tracking webhook body.
+	ParseEvent(body []byte, signature string) (Event, error)
+}
+
+// New returns the provider selected by cfg.ShippingProvider, defaulting to
+// the local rate card.
+func New(cfg config.Config) (ShippingProvider, error) {
+	switch cfg.ShippingProvider {
+	case "", Local:
+		if cfg.SellerPincode != "" && !ValidPincode(cfg.SellerPincode) {
+			return nil, fmt.Errorf("SELLER_PINCODE %q is not a valid pincode", cfg.SellerPincode)
+		}
+		return NewLocalProvider(cfg.SellerPincode, DefaultRateCard, cfg.ShippingWebhookSecret), nil
+	case Fake:
+		return NewFakeProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown shipping provider %q", cfg.ShippingProvider)
+	}
+}
+
+// ValidPincode reports whether pincode looks like an Indian PIN code.
+func ValidPincode(pincode string) bool {
+	if len(pincode) != 6 || pincode[0] < '1' || pincode[0] > '9' {
+		return false
+	}
+	for i := 1; i < len(pincode); i++ {
+		if pincode[i] < '0' || pincode[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// SignEvent returns the HMAC-SHA256 of body with secret, hex encoded, as
+// sent in the X-Shipping-Signature header.
+func SignEvent(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func parseSignedEvent(body []byte, signature, secret string) (Event, error) {
+	if secret == "" || !hmac.Equal([]byte(SignEvent(body, secret)), []byte(signature)) {
+		return Event{}, ErrInvalidWebhookSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return Event{}, fmt.Errorf("invalid tracking event: %w", err)
+	}
+	if event.AWB == "" {
+		return Event{}, errors.New("tracking event has no AWB")
+	}
+	if !slices.Contains(trackingStatuses, event.Status) {
+		return Event{}, fmt.Errorf("unknown tracking status %q", event.Status)
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	return event, nil
+}
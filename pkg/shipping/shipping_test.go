@@ -0,0 +1,159 @@
+package shipping_test
+
+import (
+	"ecommerce_clean_arch/pkg/config"
+	"ecommerce_clean_arch/pkg/shipping"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LocalRate(t *testing.T) {
+	local := shipping.NewLocalProvider("560001", shipping.DefaultRateCard, "")
+
+	tests := []struct {
+		name        string
+		pincode     string
+		weightGrams int
+		cod         bool
+		orderValue  int64
+		wantZone    string
+		wantFreight int64
+		wantCOD     int64
+	}{
+		{name: "same district", pincode: "560034", weightGrams: 500, wantZone: shipping.ZoneLocal, wantFreight: 5000},
+		{name: "same circle", pincode: "562101", weightGrams: 500, wantZone: shipping.ZoneRegional, wantFreight: 6000},
+		{name: "same region", pincode: "500001", weightGrams: 500, wantZone: shipping.ZoneZonal, wantFreight: 7000},
+		{name: "elsewhere", pincode: "110001", weightGrams: 500, wantZone: shipping.ZoneNational, wantFreight: 9000},
+		{name: "north east", pincode: "781001", weightGrams: 500, wantZone: shipping.ZoneSpecial, wantFreight: 12000},
+		{name: "part slab rounds up", pincode: "560034", weightGrams: 501, wantZone: shipping.ZoneLocal, wantFreight: 7000},
+		{name: "two extra slabs", pincode: "110001", weightGrams: 1500, wantZone: shipping.ZoneNational, wantFreight: 17000},
+		{name: "cod flat fee", pincode: "560034", weightGrams: 500, cod: true, orderValue: 50000, wantZone: shipping.ZoneLocal, wantFreight: 5000, wantCOD: 3000},
+		{name: "cod percentage", pincode: "560034", weightGrams: 500, cod: true, orderValue: 500000, wantZone: shipping.ZoneLocal, wantFreight: 5000, wantCOD: 10000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := local.Rate(shipping.RateRequest{
+				DestinationPincode: tt.pincode,
+				WeightGrams:        tt.weightGrams,
+				COD:                tt.cod,
+				OrderValue:         tt.orderValue,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantZone, quote.Zone)
+			assert.Equal(t, tt.wantFreight, quote.Freight)
+			assert.Equal(t, tt.wantCOD, quote.CODCharge)
+			assert.Equal(t, tt.wantFreight+tt.wantCOD, quote.Total)
+		})
+	}
+}
+
+func Test_LocalRateInvalidPincode(t *testing.T) {
+	local := shipping.NewLocalProvider("560001", shipping.DefaultRateCard, "")
+
+	for _, pincode := range []string{"", "56000", "056001", "56000a", "5600011"} {
+		_, err := local.Rate(shipping.RateRequest{DestinationPincode: pincode, WeightGrams: 500})
+		assert.ErrorIs(t, err, shipping.ErrInvalidPincode, pincode)
+	}
+}
+
+func Test_LocalEvents(t *testing.T) {
+	assert.False(t, shipping.NewLocalProvider("560001", shipping.DefaultRateCard, "").SendsEvents())
+
+	local := shipping.NewLocalProvider("560001", shipping.DefaultRateCard, "secret")
+	require.True(t, local.SendsEvents())
+
+	body := []byte(`{"awb":"SSL0000000042","status":"delivered","location":"Bengaluru"}`)
+	event, err := local.ParseEvent(body, shipping.SignEvent(body, "secret"))
+	require.NoError(t, err)
+	assert.Equal(t, "SSL0000000042", event.AWB)
+	assert.Equal(t, shipping.StatusDelivered, event.Status)
+	assert.False(t, event.OccurredAt.IsZero())
+
+	_, err = local.ParseEvent(body, shipping.SignEvent(body, "other"))
+	assert.ErrorIs(t, err, shipping.ErrInvalidWebhookSignature)
+
+	unknown := []byte(`{"awb":"SSL0000000042","status":"lost"}`)
+	_, err = local.ParseEvent(unknown, shipping.SignEvent(unknown, "secret"))
+	assert.Error(t, err)
+}
+
+func Test_FakeShipmentTracking(t *testing.T) {
+	fake := shipping.NewFakeProvider()
+
+	shipment, err := fake.CreateShipment(shipping.ShipmentRequest{OrderID: 7, DestinationPincode: "110001", WeightGrams: 1000, COD: true})
+	require.NoError(t, err)
+	assert.Equal(t, "FAKE00000001", shipment.AWB)
+	assert.Equal(t, int64(7500), shipment.Quote.Total)
+
+	body, signature, err := fake.Track(shipment.AWB, shipping.StatusPickedUp)
+	require.NoError(t, err)
+	event, err := fake.ParseEvent(body, signature)
+	require.NoError(t, err)
+	assert.Equal(t, shipment.AWB, event.AWB)
+	assert.Equal(t, shipping.StatusPickedUp, event.Status)
+
+	_, err = fake.ParseEvent(body, "tampered")
+	assert.ErrorIs(t, err, shipping.ErrInvalidWebhookSignature)
+
+	_, _, err = fake.Track("FAKE99999999", shipping.StatusDelivered)
+	assert.Error(t, err)
+}
+
+func Test_New(t *testing.T) {
+	provider, err := shipping.New(config.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, shipping.Local, provider.Name())
+
+	provider, err = shipping.New(config.Config{ShippingProvider: shipping.Fake})
+	require.NoError(t, err)
+	assert.Equal(t, shipping.Fake, provider.Name())
+
+	_, err = shipping.New(config.Config{SellerPincode: "12345"})
+	assert.Error(t, err)
+
+	_, err = shipping.New(config.Config{ShippingProvider: "carrier-pigeon"})
+	assert.Error(t, err)
+}
+
+func Test_EstimateDelivery(t *testing.T) {
+	date := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.October, day, hour, minute, 0, 0, shipping.IST)
+	}
+
+	tests := []struct {
+		name        string
+		placedAt    time.Time
+		transitDays int
+		wantFrom    time.Time
+		wantTo      time.Time
+	}{
+		// 14 October 2026 is a Wednesday.
+		{name: "before cutoff ships today", placedAt: date(14, 10, 0), transitDays: 2, wantFrom: date(16, 0, 0), wantTo: date(19, 0, 0)},
+		{name: "after cutoff ships tomorrow", placedAt: date(14, 14, 0), transitDays: 2, wantFrom: date(17, 0, 0), wantTo: date(20, 0, 0)},
+		{name: "saturday evening ships monday", placedAt: date(17, 18, 0), transitDays: 1, wantFrom: date(20, 0, 0), wantTo: date(22, 0, 0)},
+		{name: "sunday ships monday", placedAt: date(18, 9, 0), transitDays: 1, wantFrom: date(20, 0, 0), wantTo: date(22, 0, 0)},
+		{name: "unknown transit uses default", placedAt: date(14, 10, 0), transitDays: 0, wantFrom: date(20, 0, 0), wantTo: date(22, 0, 0)},
+		{name: "server clock in utc", placedAt: time.Date(2026, time.October, 14, 9, 0, 0, 0, time.UTC), transitDays: 2, wantFrom: date(17, 0, 0), wantTo: date(20, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := shipping.EstimateDelivery(tt.placedAt, shipping.DefaultDispatchCutoff, tt.transitDays)
+			assert.True(t, tt.wantFrom.Equal(from), "from: want %s, got %s", tt.wantFrom, from)
+			assert.True(t, tt.wantTo.Equal(to), "to: want %s, got %s", tt.wantTo, to)
+		})
+	}
+}
+
+func Test_ParseCutoff(t *testing.T) {
+	cutoff, err := shipping.ParseCutoff("15:30")
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Hour+30*time.Minute, cutoff)
+
+	_, err = shipping.ParseCutoff("3pm")
+	assert.Error(t, err)
+}
@@ -0,0 +1,125 @@
+// Package tax works out Indian GST on sale prices. Prices in the store are
+// inclusive of GST, as retail prices in India must be, so tax is carved out
+// of the amount the customer pays rather than added on top.
+package tax
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"math"
+	"strings"
+)
+
+// Breakup is the GST contained in a tax-inclusive amount. A sale within the
+// seller's state is taxed as CGST plus SGST, one across states as IGST.
+type Breakup struct {
+	TaxableValue money.Money
+	CGST         money.Money
+	SGST         money.Money
+	IGST         money.Money
+}
+
+func (b Breakup) Total() money.Money {
+	return money.Sum(b.CGST, b.SGST, b.IGST)
+}
+
+// Rate picks the GST rate for a unit sold at unitValue. Goods taxed on a
+// price slab, like footwear, set threshold and higherRate: units priced
+// above threshold take higherRate. Otherwise rate applies.
+func Rate(rate, higherRate float64, threshold, unitValue money.Money) float64 {
+	if threshold > 0 && higherRate > 0 && unitValue > threshold {
+		return higherRate
+	}
+	return rate
+}
+
+// Split carves GST at rate percent out of amount, which includes it.
+func Split(amount money.Money, rate float64, intraState bool) Breakup {
+	if amount <= 0 {
+		return Breakup{}
+	}
+	// Rates are whole or half percents, so hundredths of a percent are exact.
+	taxable := amount.MulDiv(10000, int64(math.Round((100+rate)*100)))
+	gst := amount.Sub(taxable)
+	if !intraState {
+		return Breakup{TaxableValue: taxable, IGST: gst}
+	}
+	cgst := gst.MulDiv(1, 2)
+	return Breakup{TaxableValue: taxable, CGST: cgst, SGST: gst.Sub(cgst)}
+}
+
+// stateCodes maps state and union territory names, and their usual short
+// forms, to the two-digit codes GST uses, which also open every GSTIN.
+var stateCodes = map[string]string{
+	"jammu and kashmir": "01", "jk": "01",
+	"himachal pradesh": "02", "hp": "02",
+	"punjab": "03", "pb": "03",
+	"chandigarh": "04", "ch": "04",
+	"uttarakhand": "05", "uk": "05",
+	"haryana": "06", "hr": "06",
+	"delhi": "07", "dl": "07",
+	"rajasthan": "08", "rj": "08",
+	"uttar pradesh": "09", "up": "09",
+	"bihar": "10", "br": "10",
+	"sikkim": "11", "sk": "11",
+	"arunachal pradesh": "12", "ar": "12",
+	"nagaland": "13", "nl": "13",
+	"manipur": "14", "mn": "14",
+	"mizoram": "15", "mz": "15",
+	"tripura": "16", "tr": "16",
+	"meghalaya": "17", "ml": "17",
+	"assam": "18", "as": "18",
+	"west bengal": "19", "wb": "19",
+	"jharkhand": "20", "jh": "20",
+	"odisha": "21", "od": "21",
+	"chhattisgarh": "22", "cg": "22",
+	"madhya pradesh": "23", "mp": "23",
+	"gujarat": "24", "gj": "24",
+	"dadra and nagar haveli and daman and diu": "26", "dd": "26", "dn": "26",
+	"maharashtra": "27", "mh": "27",
+	"karnataka": "29", "ka": "29",
+	"goa": "30", "ga": "30",
+	"lakshadweep": "31", "ld": "31",
+	"kerala": "32", "kl": "32",
+	"tamil nadu": "33", "tn": "33",
+	"puducherry": "34", "py": "34",
+	"andaman and nicobar islands": "35", "an": "35",
+	"telangana": "36", "ts": "36", "tg": "36",
+	"andhra pradesh": "37", "ap": "37",
+	"ladakh": "38", "la": "38",
+}
+
+// StateCode returns the GST code of a state given by name, short form
+// (optionally ISO style, as in "IN-KL") or code, or "" when it is not
+// recognised.
+func StateCode(state string) string {
+	key := strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(state, "&", "and")), " "))
+	key = strings.TrimPrefix(key, "in-")
+	if code, ok := stateCodes[key]; ok {
+		return code
+	}
+	for _, code := range stateCodes {
+		if key == code {
+			return code
+		}
+	}
+	return ""
+}
+
+// SellerStateCode is the seller's GST state code: from the configured state
+// when set, otherwise from the first two digits of the GSTIN.
+func SellerStateCode(state, gstin string) string {
+	if code := StateCode(state); code != "" {
+		return code
+	}
+	if len(gstin) >= 2 {
+		return StateCode(gstin[:2])
+	}
+	return ""
+}
+
+// IntraState reports whether shipping to buyerState keeps the sale within
+// the seller's state. An address whose state is not recognised is treated
+// as inter-state.
+func IntraState(sellerStateCode, buyerState string) bool {
+	return sellerStateCode != "" && StateCode(buyerState) == sellerStateCode
+}
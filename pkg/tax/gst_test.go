@@ -0,0 +1,62 @@
+package tax_test
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/tax"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Split(t *testing.T) {
+	testCases := map[string]struct {
+		amount     money.Money
+		rate       float64
+		intraState bool
+		want       tax.Breakup
+	}{
+		"intra-state sale splits into cgst and sgst": {amount: money.Rupees(1050), rate: 5, intraState: true,
+			want: tax.Breakup{TaxableValue: money.Rupees(1000), CGST: money.Rupees(25), SGST: money.Rupees(25)}},
+		"inter-state sale is igst": {amount: money.Rupees(1180), rate: 18, intraState: false,
+			want: tax.Breakup{TaxableValue: money.Rupees(1000), IGST: money.Rupees(180)}},
+		"odd paisa rounds onto cgst": {amount: money.Rupees(999), rate: 5, intraState: true,
+			want: tax.Breakup{TaxableValue: money.Paise(95143), CGST: money.Paise(2379), SGST: money.Paise(2378)}},
+		"half percent rate": {amount: money.Rupees(1000), rate: 2.5, intraState: false,
+			want: tax.Breakup{TaxableValue: money.Paise(97561), IGST: money.Paise(2439)}},
+		"zero rated": {amount: money.Rupees(500), rate: 0, intraState: true,
+			want: tax.Breakup{TaxableValue: money.Rupees(500)}},
+		"nothing paid": {amount: 0, rate: 18, intraState: false,
+			want: tax.Breakup{}},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := tax.Split(tc.amount, tc.rate, tc.intraState)
+			assert.Equal(t, tc.want, got)
+			if tc.amount > 0 {
+				assert.Equal(t, tc.amount, got.TaxableValue+got.Total())
+			}
+		})
+	}
+}
+
+func Test_Rate(t *testing.T) {
+	assert.Equal(t, 5.0, tax.Rate(5, 18, money.Rupees(2500), money.Rupees(1999)))
+	assert.Equal(t, 5.0, tax.Rate(5, 18, money.Rupees(2500), money.Rupees(2500)))
+	assert.Equal(t, 18.0, tax.Rate(5, 18, money.Rupees(2500), money.Paise(250001)))
+	assert.Equal(t, 12.0, tax.Rate(12, 0, 0, money.Rupees(9000)))
+}
+
+func Test_IntraState(t *testing.T) {
+	seller := tax.SellerStateCode("", "32AAACS1234A1Z5")
+	assert.Equal(t, "32", seller)
+
+	assert.True(t, tax.IntraState(seller, "Kerala"))
+	assert.True(t, tax.IntraState(seller, " KL "))
+	assert.True(t, tax.IntraState(seller, "32"))
+	assert.False(t, tax.IntraState(seller, "Tamil Nadu"))
+	assert.False(t, tax.IntraState(seller, "Atlantis"))
+	assert.False(t, tax.IntraState("", "Kerala"))
+	assert.Equal(t, "35", tax.StateCode("Andaman & Nicobar Islands"))
+	assert.Equal(t, "27", tax.SellerStateCode("Maharashtra", "32AAACS1234A1Z5"))
+}
@@ -19,12 +19,24 @@ import (
 )
 
 type AdminUseCase struct {
-	adminrepository repository.AdminRepository
+	adminrepository    repository.AdminRepository
+	ReservationUseCase ReservationUseCase
+	RefundUseCase      RefundUseCase
+	OrderStatusUseCase OrderStatusUseCase
+	ExchangeUseCase    ExchangeUseCase
+	OrderUseCase       OrderUseCase
+	WalletUseCase      WalletUseCase
 }
 
-func NewAdminUseCase(adminrepository repository.AdminRepository) *AdminUseCase {
+func NewAdminUseCase(adminrepository repository.AdminRepository, reservationUseCase ReservationUseCase, refundUseCase RefundUseCase, orderStatusUseCase OrderStatusUseCase, exchangeUseCase ExchangeUseCase, orderUseCase OrderUseCase, walletUseCase WalletUseCase) *AdminUseCase {
 	return &AdminUseCase{
-		adminrepository: adminrepository,
+		adminrepository:    adminrepository,
+		ReservationUseCase: reservationUseCase,
+		RefundUseCase:      refundUseCase,
+		OrderStatusUseCase: orderStatusUseCase,
+		ExchangeUseCase:    exchangeUseCase,
+		OrderUseCase:       orderUseCase,
+		WalletUseCase:      walletUseCase,
 	}
 }
 
@@ -134,44 +146,58 @@ func (ad *AdminUseCase) CancelOrders(orderID string, userID int) error {
 		log.Printf("Warning: User %d attempting to cancel order %d belonging to user %d", userID, orderIDInt, userTest)
 	}
 
+	return ad.settleOrder(orderIDInt, models.Cancelled, userID, "cancelled by admin", "order cancelled by admin")
+}
+
+// settleOrder moves an order that will not be fulfilled to status,
+// cancelled or failed, and settles it: the hold is released, the stock put
+// back, a credit note issued and whatever was paid refunded.
+func (ad *AdminUseCase) settleOrder(orderID int, status string, adminID int, reason, refundReason string) error {
 	tx, err := ad.adminrepository.BeginnTransaction()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer ad.adminrepository.RollbackkTransaction(tx)
 
-	orderProductDetails, err := ad.adminrepository.GetProductDetailFromOrders(orderIDInt)
+	orderProductDetails, err := ad.adminrepository.GetProductDetailFromOrders(orderID)
 	if err != nil {
 		return err
 	}
-	orderStatus, err := ad.adminrepository.Getorderstatus(orderIDInt)
+	err = ad.OrderStatusUseCase.Transition(tx, orderID, status, models.StatusChange{
+		Actor:   models.ActorAdmin,
+		ActorID: adminID,
+		Reason:  reason,
+	})
 	if err != nil {
 		return err
 	}
-	if orderStatus == "delivered" {
-		return errors.New("items already delivered, cannot cancel")
+	if err := ad.OrderUseCase.InvoiceUseCase.CreditNote(tx, orderID, refundReason); err != nil {
+		return fmt.Errorf("failed to issue credit note: %w", err)
 	}
 
-	if orderStatus == "returned" || orderStatus == "Failed" {
-		return fmt.Errorf("the order is in %s, so no point in cancelling", orderStatus)
-	}
-	if orderStatus == "cancelled" {
-		return errors.New("the order is already cancelled, so no point in cancelling")
+	err = ad.ReservationUseCase.Release(tx, orderID, reason)
+	if err != nil {
+		return err
 	}
 
-	err = ad.adminrepository.Cancelorders(orderIDInt)
+	refund, err := ad.RefundUseCase.Initiate(tx, models.RefundRequest{
+		OrderID: orderID,
+		Reason:  refundReason,
+	})
 	if err != nil {
 		return err
 	}
 
 	for _, product := range orderProductDetails {
-		availableStock, err := ad.adminrepository.GetProductStockk(product.ProductID)
-		if err != nil {
-			return err
+		if product.VariantID != 0 {
+			err = ad.adminrepository.RestoreVariantStock(tx, product.VariantID, product.Quantity)
+			if err != nil {
+				return errors.New("failed to restore variant stock")
+			}
+			continue
 		}
 
-		newStock := availableStock + product.Quantity
-		err = ad.adminrepository.UpdateProductStockk(tx, product.ProductID, newStock)
+		err = ad.adminrepository.UpdateProductStockk(tx, product.ProductID, product.Quantity)
 		if err != nil {
 			return errors.New("failed to restore product stock")
 		}
@@ -187,73 +213,173 @@ func (ad *AdminUseCase) CancelOrders(orderID string, userID int) error {
 		return err
 	}
 
+	if err := ad.RefundUseCase.Dispatch(refund); err != nil {
+		log.Println("refund will need to be retried:", err)
+	}
 	return nil
 }
 
-func (ad *AdminUseCase) ChangeOrderStatus(orderID string, status string) (models.Order, error) {
+func (ad *AdminUseCase) GetRefunds(status string) ([]domain.Refund, error) {
+	return ad.RefundUseCase.GetRefunds(status)
+}
 
-	validStatuses := map[string]bool{
-		"pending":   true,
-		"shipped":   true,
-		"cancelled": true,
-		"return":    true,
-		"delivered": true,
-		"failed":    true,
-	}
-	if !validStatuses[status] {
-		return models.Order{}, fmt.Errorf("invalid order status")
+func (ad *AdminUseCase) RetryRefund(refundID string) (domain.Refund, error) {
+	id, err := strconv.Atoi(refundID)
+	if err != nil {
+		return domain.Refund{}, fmt.Errorf("invalid refund ID format: %w", err)
 	}
-	order, err := ad.adminrepository.GetOrderDetails(orderID)
+	return ad.RefundUseCase.Retry(id)
+}
+
+// ChangeOrderStatus moves an order along its fulfilment. Cancelling and
+// failing an order settle its stock and payment too; returns and exchanges
+// are started by the customer and go through their own requests.
+func (ad *AdminUseCase) ChangeOrderStatus(orderID string, status string, adminID int, reason string) (models.Order, error) {
+	orderIDInt, err := strconv.Atoi(orderID)
 	if err != nil {
-		return models.Order{}, fmt.Errorf("order ID does not exist")
+		return models.Order{}, fmt.Errorf("invalid order ID format: %w", err)
 	}
 
 	switch status {
-	case "shipped":
-		if order.OrderStatus == "cancelled" {
-			return models.Order{}, fmt.Errorf("cannot ship an order that has already been cancelled")
+	case models.Cancelled:
+		if err := ad.CancelOrders(orderID, adminID); err != nil {
+			return models.Order{}, err
 		}
-
-	case "cancelled":
-		if order.OrderStatus == "cancelled" {
-			return models.Order{}, fmt.Errorf("order has already been cancelled by user or Admin")
-		}
-		if order.OrderStatus == "delivered" {
-			return models.Order{}, fmt.Errorf("cannot cancel the order as it is already delivered")
+		return ad.adminrepository.GetOrderDetails(orderID)
+	case models.Failed:
+		if reason == "" {
+			reason = "failed by admin"
 		}
-
-	case "delivered":
-		if order.OrderStatus == "delivered" {
-			return models.Order{}, fmt.Errorf("order has already been delivered")
+		if err := ad.settleOrder(orderIDInt, models.Failed, adminID, reason, "order failed: "+reason); err != nil {
+			return models.Order{}, err
 		}
-		if order.OrderStatus != "shipped" {
-			return models.Order{}, fmt.Errorf("cannot mark the order as delivered unless it is shipped")
+		return ad.adminrepository.GetOrderDetails(orderID)
+	case models.Return:
+		return models.Order{}, errors.New("returns are started by the customer")
+	case models.Exchanged:
+		return models.Order{}, errors.New("exchanges are started by the customer")
+	case models.Shipped, models.Delivered:
+		if ad.OrderUseCase.ShippingUseCase.CarrierManaged() {
+			return models.Order{}, ErrCarrierManagedStatus
 		}
+	}
 
-	case "failed":
-		if order.OrderStatus == "failed" {
-			return models.Order{}, fmt.Errorf("order has already failed")
-		}
-		if order.OrderStatus == "delivered" {
-			return models.Order{}, fmt.Errorf("cannot mark an already delivered order as failed")
-		}
+	if reason == "" {
+		reason = "updated by admin"
+	}
 
-	case "return":
-		if order.OrderStatus == "return" {
-			return models.Order{}, fmt.Errorf("order has already been marked as return")
+	tx, err := ad.adminrepository.BeginnTransaction()
+	if err != nil {
+		return models.Order{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer ad.adminrepository.RollbackkTransaction(tx)
+
+	err = ad.OrderStatusUseCase.Transition(tx, orderIDInt, status, models.StatusChange{
+		Actor:   models.ActorAdmin,
+		ActorID: adminID,
+		Reason:  reason,
+	})
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	if status == models.Shipped {
+		err = ad.OrderUseCase.ShippingUseCase.ReestimateDelivery(tx, orderIDInt, time.Now())
+		if err != nil {
+			return models.Order{}, err
 		}
-		if order.OrderStatus != "delivered" {
-			return models.Order{}, fmt.Errorf("only delivered orders can be returned")
+	}
+
+	if status == models.Delivered {
+		err = ad.adminrepository.MarkCODPaid(tx, orderIDInt)
+		if err != nil {
+			return models.Order{}, err
 		}
 	}
 
-	updateOrder, err := ad.adminrepository.ChangeOrderStatus(orderID, status)
+	err = ad.adminrepository.CommittTransaction(tx)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	updateOrder, err := ad.adminrepository.GetOrderDetails(orderID)
 	if err != nil {
 		return models.Order{}, fmt.Errorf("could not get updated order status: %w", err)
 	}
 	return updateOrder, nil
 }
 
+func (ad *AdminUseCase) GetExchanges(status string) ([]domain.Exchange, error) {
+	return ad.ExchangeUseCase.GetExchanges(status)
+}
+
+func (ad *AdminUseCase) ApproveExchange(decision models.ExchangeDecision, adminID int) (domain.Exchange, error) {
+	return ad.ExchangeUseCase.Approve(decision.ExchangeID, adminID, decision.Note)
+}
+
+func (ad *AdminUseCase) RejectExchange(decision models.ExchangeDecision, adminID int) (domain.Exchange, error) {
+	return ad.ExchangeUseCase.Reject(decision.ExchangeID, adminID, decision.Note)
+}
+
+// CompleteExchange records that the old pair of an approved exchange is
+// back in the warehouse.
+func (ad *AdminUseCase) CompleteExchange(exchangeID string) (domain.Exchange, error) {
+	id, err := strconv.Atoi(exchangeID)
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("invalid exchange ID format: %w", err)
+	}
+	return ad.ExchangeUseCase.Complete(id)
+}
+
+func (ad *AdminUseCase) GetReturnRequests(status string) ([]domain.ReturnRequest, error) {
+	return ad.OrderUseCase.GetAllReturnRequests(status)
+}
+
+func (ad *AdminUseCase) UpdateReturnStatus(decision models.ReturnDecision, status string, adminID int) (domain.ReturnRequest, error) {
+	return ad.OrderUseCase.UpdateReturnStatus(decision.ReturnID, status, adminID, decision.Note)
+}
+
+// InspectReturn records the outcome of checking a returned parcel. A pass
+// leaves the return ready to refund; a failure rejects it.
+func (ad *AdminUseCase) InspectReturn(inspection models.ReturnInspection, adminID int) (domain.ReturnRequest, error) {
+	status := models.ReturnRejected
+	if inspection.Passed != nil && *inspection.Passed {
+		status = models.ReturnInspected
+	}
+	return ad.OrderUseCase.UpdateReturnStatus(inspection.ReturnID, status, adminID, inspection.Note)
+}
+
+func (ad *AdminUseCase) GetWalletAdjustments(status, userID string) ([]domain.WalletAdjustment, error) {
+	var id int
+	if userID != "" {
+		var err error
+		if id, err = strconv.Atoi(userID); err != nil {
+			return nil, fmt.Errorf("invalid user ID format: %w", err)
+		}
+	}
+	return ad.WalletUseCase.GetAdjustments(status, id)
+}
+
+func (ad *AdminUseCase) AdjustWallet(req models.WalletAdjustmentRequest, adminID int) (domain.WalletAdjustment, error) {
+	return ad.WalletUseCase.RequestAdjustment(adminID, req)
+}
+
+func (ad *AdminUseCase) ApproveWalletAdjustment(decision models.WalletAdjustmentDecision, adminID int) (domain.WalletAdjustment, error) {
+	return ad.WalletUseCase.ApproveAdjustment(decision, adminID)
+}
+
+func (ad *AdminUseCase) RejectWalletAdjustment(decision models.WalletAdjustmentDecision, adminID int) (domain.WalletAdjustment, error) {
+	return ad.WalletUseCase.RejectAdjustment(decision, adminID)
+}
+
+func (ad *AdminUseCase) GetOrderTimeline(orderID string) ([]domain.OrderStatusHistory, error) {
+	orderIDInt, err := strconv.Atoi(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID format: %w", err)
+	}
+	return ad.OrderStatusUseCase.GetTimeline(orderIDInt)
+}
+
 func (ad *AdminUseCase) GetDateRange(startDate, endDate, limit string) (string, string) {
 	today := time.Now()
 	switch limit {
@@ -313,10 +439,10 @@ func (ad *AdminUseCase) GenerateSalesReportPDF(orderCount models.OrderCount, amo
 	pdf.SetFont("Arial", "", 12)
 	summaryData := map[string]string{
 		"Total Orders":                  strconv.Itoa(int(orderCount.TotalOrder)),
-		"Total Amount Before Deduction": fmt.Sprintf("%.2f", amountInfo.TotalAmountBeforeDeduction),
-		"Total Coupon Deduction":        fmt.Sprintf("%.2f", amountInfo.TotalCouponDeduction),
-		"Total Product Offer Deduction": fmt.Sprintf("%.2f", amountInfo.TotalProuctOfferDeduction),
-		"Total Amount After Deduction":  fmt.Sprintf("%.2f", amountInfo.TotalAmountAfterDeduction),
+		"Total Amount Before Deduction": amountInfo.TotalAmountBeforeDeduction.String(),
+		"Total Coupon Deduction":        amountInfo.TotalCouponDeduction.String(),
+		"Total Product Offer Deduction": amountInfo.TotalProuctOfferDeduction.String(),
+		"Total Amount After Deduction":  amountInfo.TotalAmountAfterDeduction.String(),
 	}
 
 	for desc, amount := range summaryData {
@@ -432,10 +558,10 @@ func (ad *AdminUseCase) GenerateSalesReportPDF(orderCount models.OrderCount, amo
 
 	// Pie Chart
 	pieChartData := []chart.Value{
-		{Value: amountInfo.TotalAmountBeforeDeduction, Label: "Total Amount Before Deduction"},
-		{Value: amountInfo.TotalCouponDeduction, Label: "Total Coupon Deduction"},
-		{Value: amountInfo.TotalProuctOfferDeduction, Label: "Total Product Offer Deduction"},
-		{Value: amountInfo.TotalAmountAfterDeduction, Label: "Total Amount After Deduction"},
+		{Value: amountInfo.TotalAmountBeforeDeduction.Rupees(), Label: "Total Amount Before Deduction"},
+		{Value: amountInfo.TotalCouponDeduction.Rupees(), Label: "Total Coupon Deduction"},
+		{Value: amountInfo.TotalProuctOfferDeduction.Rupees(), Label: "Total Product Offer Deduction"},
+		{Value: amountInfo.TotalAmountAfterDeduction.Rupees(), Label: "Total Amount After Deduction"},
 	}
 
 	if hasValidData(pieChartData) {
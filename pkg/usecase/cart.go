@@ -1,11 +1,11 @@
 package usecase
 
 import (
+	"ecommerce_clean_arch/pkg/money"
 	"ecommerce_clean_arch/pkg/repository"
 	"ecommerce_clean_arch/pkg/repository/interfaces"
 	"ecommerce_clean_arch/pkg/utils/models"
 	"errors"
-	"math"
 )
 
 type CartUseCase struct {
@@ -22,7 +22,7 @@ func NewCartUseCase(cartRepository repository.CartRepository, productrepository
 	}
 }
 
-func (uc *CartUseCase) ValidateAddToCart(userID int, productID int, requestQty int) error {
+func (uc *CartUseCase) ValidateAddToCart(userID int, productID int, variantID int, requestQty int) error {
 	product, err := uc.productRepository.GetProductByID(productID)
 	if err != nil {
 		return errors.New("product not found")
@@ -32,11 +32,20 @@ func (uc *CartUseCase) ValidateAddToCart(userID int, productID int, requestQty i
 		return errors.New("invalid quantity")
 	}
 
-	if requestQty > product.Stock {
+	variant, err := uc.resolveVariant(productID, variantID)
+	if err != nil {
+		return err
+	}
+
+	availableStock := product.Stock
+	if variant != nil {
+		availableStock = variant.Stock
+	}
+	if requestQty > availableStock {
 		return errors.New("requested quantity exceeds available stock")
 	}
 
-	cartItem, err := uc.cartRepository.GetCartItem(userID, productID)
+	cartItem, err := uc.cartRepository.GetCartItem(userID, productID, variantID)
 	if err != nil && err.Error() != "record not found" {
 		return errors.New("error fetching cart details")
 	}
@@ -65,7 +74,7 @@ func (cu *CartUseCase) DisplayCart(userID int) ([]models.Cart, error) {
 	}
 	return cart, nil
 }
-func (cu *CartUseCase) AddToCart(userID int, productID int, quantity int) (models.CartResponse, error) {
+func (cu *CartUseCase) AddToCart(userID int, productID int, variantID int, quantity int) (models.CartResponse, error) {
 	if quantity <= 0 {
 		return models.CartResponse{}, errors.New("invalid quantity")
 	}
@@ -79,7 +88,21 @@ func (cu *CartUseCase) AddToCart(userID int, productID int, quantity int) (model
 		return models.CartResponse{}, errors.New("invalid product quantity")
 	}
 
-	if quantity > product.Quantity {
+	variant, err := cu.resolveVariant(productID, variantID)
+	if err != nil {
+		return models.CartResponse{}, err
+	}
+
+	availableQuantity := product.Quantity
+	unitPrice := product.OfferPrice
+	if variant != nil {
+		availableQuantity = variant.Stock
+		if variant.PriceOverride > 0 {
+			unitPrice = variant.PriceOverride
+		}
+	}
+
+	if quantity > availableQuantity {
 		return models.CartResponse{}, errors.New("insufficient quantity available")
 	}
 
@@ -92,13 +115,14 @@ func (cu *CartUseCase) AddToCart(userID int, productID int, quantity int) (model
 		return models.CartResponse{}, errors.New("category not found")
 	}
 
-	discountedPrice := math.Round(float64(product.OfferPrice)*(1-(float64(category.CategoryDiscount)/100))*100) / 100
-	totalPrice := math.Round(float64(quantity)*discountedPrice*100) / 100
+	unitDiscount := unitPrice.Percent(float64(category.CategoryDiscount))
+	discountedPrice := unitPrice.Sub(unitDiscount)
+	totalPrice := discountedPrice.Times(quantity)
 
-	existingCartItem, _ := cu.cartRepository.GetCartItem(userID, productID)
+	existingCartItem, _ := cu.cartRepository.GetCartItem(userID, productID, variantID)
 	if existingCartItem != nil {
 		existingCartItem.Quantity += quantity
-		existingCartItem.TotalPrice = math.Round(float64(existingCartItem.Quantity)*discountedPrice*100) / 100
+		existingCartItem.TotalPrice = discountedPrice.Times(existingCartItem.Quantity)
 		updatedCart, err := cu.cartRepository.UpdateCart(*existingCartItem)
 		if err != nil {
 			return models.CartResponse{}, err
@@ -112,10 +136,11 @@ func (cu *CartUseCase) AddToCart(userID int, productID int, quantity int) (model
 	newCartItem := models.Cart{
 		UserID:           userID,
 		ProductID:        productID,
+		VariantID:        variantID,
 		Quantity:         quantity,
-		Price:            int(product.Price),
-		OfferPrice:       int(product.OfferPrice),
-		CategoryDiscount: math.Round((float64(product.OfferPrice)-discountedPrice)*float64(quantity)*100) / 100,
+		Price:            product.Price,
+		OfferPrice:       unitPrice,
+		CategoryDiscount: unitDiscount.Times(quantity),
 		TotalPrice:       totalPrice,
 	}
 
@@ -130,13 +155,13 @@ func (cu *CartUseCase) AddToCart(userID int, productID int, quantity int) (model
 	}, nil
 }
 
-func (cu *CartUseCase) RemoveProductFromCart(userID int, productID int) (models.CartResponse, error) {
+func (cu *CartUseCase) RemoveProductFromCart(userID int, productID int, variantID int) (models.CartResponse, error) {
 	product, err := cu.productRepository.GetProductByID(productID)
 	if err != nil {
 		return models.CartResponse{}, errors.New("product not found")
 	}
 
-	exists, err := cu.cartRepository.CheckProductInCart(userID, productID)
+	exists, err := cu.cartRepository.CheckProductInCart(userID, productID, variantID)
 	if err != nil {
 		return models.CartResponse{}, err
 	}
@@ -144,7 +169,7 @@ func (cu *CartUseCase) RemoveProductFromCart(userID int, productID int) (models.
 		return models.CartResponse{}, errors.New("product not found in the cart")
 	}
 
-	err = cu.cartRepository.RemoveProductFromCart(userID, productID, product.Price)
+	err = cu.cartRepository.RemoveProductFromCart(userID, productID, variantID, product.Price)
 	if err != nil {
 		return models.CartResponse{}, err
 	}
@@ -154,7 +179,7 @@ func (cu *CartUseCase) RemoveProductFromCart(userID int, productID int) (models.
 		return models.CartResponse{}, err
 	}
 
-	var totalPrice float64
+	var totalPrice money.Money
 	for _, item := range updatedCart {
 		totalPrice += item.TotalPrice
 	}
@@ -164,3 +189,28 @@ func (cu *CartUseCase) RemoveProductFromCart(userID int, productID int) (models.
 		TotalPrice: totalPrice,
 	}, nil
 }
+
+// resolveVariant returns the size/colour variant a cart line refers to.
+// Products that have variants must be added with one; products without
+// variants return nil and keep using the product's own stock and price.
+func (cu *CartUseCase) resolveVariant(productID int, variantID int) (*models.VariantResponse, error) {
+	if variantID == 0 {
+		variants, err := cu.productRepository.GetVariantsByProductID(productID)
+		if err != nil {
+			return nil, errors.New("error fetching product variants")
+		}
+		if len(variants) > 0 {
+			return nil, errors.New("please select a size and colour for this product")
+		}
+		return nil, nil
+	}
+
+	variant, err := cu.productRepository.GetVariantByID(variantID)
+	if err != nil {
+		return nil, errors.New("variant not found")
+	}
+	if variant.ProductID != productID {
+		return nil, errors.New("variant does not belong to this product")
+	}
+	return &variant, nil
+}
@@ -3,8 +3,31 @@ package usecase
 import (
 	"ecommerce_clean_arch/pkg/domain"
 	"ecommerce_clean_arch/pkg/repository"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
 )
 
+// gstRates are the GST slabs a category can be taxed at, in percent.
+var gstRates = []float64{0, 0.25, 3, 5, 12, 18, 28, 40}
+
+var hsnCodePattern = regexp.MustCompile(`^[0-9]{4}([0-9]{2}){0,2}$`)
+
+// validateTax checks a category's HSN code and GST slabs.
+func validateTax(category domain.Category) error {
+	if category.HSNCode != "" && !hsnCodePattern.MatchString(category.HSNCode) {
+		return errors.New("HSN code must be 4, 6 or 8 digits")
+	}
+	if !slices.Contains(gstRates, category.GSTRate) || !slices.Contains(gstRates, category.GSTHigherRate) {
+		return fmt.Errorf("GST rates must be one of %v", gstRates)
+	}
+	if (category.GSTThreshold > 0) != (category.GSTHigherRate > 0) {
+		return errors.New("a GST threshold and a higher rate must be set together")
+	}
+	return nil
+}
+
 type CategoryUseCase struct {
 	CategoryRepository repository.CategoryRepository
 }
@@ -16,6 +39,12 @@ func NewCategoryUseCase(usecase repository.CategoryRepository) *CategoryUseCase
 }
 
 func (cat *CategoryUseCase) AddCategory(category domain.Category) (domain.Category, error) {
+	if err := validateTax(category); err != nil {
+		return domain.Category{}, err
+	}
+	if category.WeightGrams < 0 {
+		return domain.Category{}, errors.New("weight cannot be negative")
+	}
 	categoryResponse, err := cat.CategoryRepository.AddCategory(category)
 	if err != nil {
 		return domain.Category{}, err
@@ -24,6 +53,12 @@ func (cat *CategoryUseCase) AddCategory(category domain.Category) (domain.Catego
 }
 
 func (cat *CategoryUseCase) UpdateCategory(category domain.Category, categoryID int) (domain.Category, error) {
+	if err := validateTax(category); err != nil {
+		return domain.Category{}, err
+	}
+	if category.WeightGrams < 0 {
+		return domain.Category{}, errors.New("weight cannot be negative")
+	}
 	updateCategory, err := cat.CategoryRepository.UpdateCategory(category, categoryID)
 	if err != nil {
 		return domain.Category{}, err
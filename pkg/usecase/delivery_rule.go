@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/rules"
+	"ecommerce_clean_arch/pkg/shipping"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"fmt"
+	"slices"
+)
+
+var (
+	rulePaymentMethods = []string{models.COD, models.Online, models.Wallet, models.Split}
+	ruleZones          = []string{shipping.ZoneLocal, shipping.ZoneRegional, shipping.ZoneZonal, shipping.ZoneNational, shipping.ZoneSpecial}
+)
+
+type DeliveryRuleUseCase struct {
+	deliveryRuleRepository repository.DeliveryRuleRepository
+}
+
+func NewDeliveryRuleUseCase(deliveryRuleRepository repository.DeliveryRuleRepository) *DeliveryRuleUseCase {
+	return &DeliveryRuleUseCase{deliveryRuleRepository: deliveryRuleRepository}
+}
+
+// Evaluate runs the active delivery rules over a cart.
+func (d *DeliveryRuleUseCase) Evaluate(cart rules.Cart) (rules.Outcome, error) {
+	deliveryRules, err := d.deliveryRuleRepository.GetActiveRules()
+	if err != nil {
+		return rules.Outcome{}, fmt.Errorf("failed to load delivery rules: %w", err)
+	}
+	return rules.Evaluate(deliveryRules, cart), nil
+}
+
+func (d *DeliveryRuleUseCase) GetRules() ([]domain.DeliveryRule, error) {
+	return d.deliveryRuleRepository.GetRules()
+}
+
+func (d *DeliveryRuleUseCase) AddRule(rule domain.DeliveryRule) (domain.DeliveryRule, error) {
+	if err := validateDeliveryRule(rule); err != nil {
+		return domain.DeliveryRule{}, err
+	}
+	return d.deliveryRuleRepository.CreateRule(rule)
+}
+
+func (d *DeliveryRuleUseCase) UpdateRule(rule domain.DeliveryRule, ruleID int) (domain.DeliveryRule, error) {
+	if err := validateDeliveryRule(rule); err != nil {
+		return domain.DeliveryRule{}, err
+	}
+	rule.ID = ruleID
+	return d.deliveryRuleRepository.UpdateRule(rule)
+}
+
+func (d *DeliveryRuleUseCase) DeleteRule(ruleID int) error {
+	return d.deliveryRuleRepository.DeleteRule(ruleID)
+}
+
+func validateDeliveryRule(rule domain.DeliveryRule) error {
+	if err := rules.Validate(rule); err != nil {
+		return err
+	}
+	for _, method := range rule.PaymentMethods {
+		if !slices.Contains(rulePaymentMethods, method) {
+			return fmt.Errorf("unknown payment method %q", method)
+		}
+	}
+	for _, zone := range rule.Zones {
+		if !slices.Contains(ruleZones, zone) {
+			return fmt.Errorf("unknown zone %q", zone)
+		}
+	}
+	return nil
+}
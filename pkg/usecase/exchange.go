@@ -0,0 +1,599 @@
+package usecase
+
+import (
+	"ecommerce_clean_arch/pkg/config"
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/gateway"
+	"ecommerce_clean_arch/pkg/ledger"
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidExchangeSettlement = errors.New("settle_with must be wallet or source")
+
+type ExchangeUseCase struct {
+	exchangeRepository repository.ExchangeRepository
+	orderRepository    repository.OrderRepository
+	productRepository  repository.ProductRepository
+	categoryRepository repository.CategoryRepository
+	walletRepository   repository.WalletRepository
+	OrderStatusUseCase OrderStatusUseCase
+	RefundUseCase      RefundUseCase
+	gateway            gateway.PaymentGateway
+	paymentWindow      time.Duration
+}
+
+func NewExchangeUseCase(exchangeRepository repository.ExchangeRepository, orderRepository repository.OrderRepository,
+	productRepository repository.ProductRepository, categoryRepository repository.CategoryRepository,
+	walletRepository repository.WalletRepository, orderStatusUseCase OrderStatusUseCase, refundUseCase RefundUseCase,
+	paymentGateway gateway.PaymentGateway, cfg config.Config) *ExchangeUseCase {
+	return &ExchangeUseCase{
+		exchangeRepository: exchangeRepository,
+		orderRepository:    orderRepository,
+		productRepository:  productRepository,
+		categoryRepository: categoryRepository,
+		walletRepository:   walletRepository,
+		OrderStatusUseCase: orderStatusUseCase,
+		RefundUseCase:      refundUseCase,
+		gateway:            paymentGateway,
+		paymentWindow:      reservationTTL(cfg),
+	}
+}
+
+// Request opens an exchange of a delivered line for another size of the same
+// product. The new size is taken off stock straight away so it cannot sell
+// out while the request waits for an admin. A higher price is collected now,
+// from the wallet or through the gateway; a lower one is refunded once the
+// exchange is approved. A gateway payment that does not arrive within the
+// reservation TTL lets the held size go again.
+func (e *ExchangeUseCase) Request(userID int, req models.ExchangeRequest) (domain.Exchange, error) {
+	if req.SettleWith != "" && req.SettleWith != models.RefundToWallet && req.SettleWith != models.RefundToSource {
+		return domain.Exchange{}, ErrInvalidExchangeSettlement
+	}
+
+	tx, err := e.orderRepository.BeginTransaction()
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = e.orderRepository.RollbackTransaction(tx)
+	}()
+
+	item, err := e.orderRepository.GetOrderItem(tx, req.OrderItemID)
+	if err != nil {
+		return domain.Exchange{}, err
+	}
+	orderUserID, err := e.orderRepository.UserOrderRelationship(item.OrderID, userID)
+	if err != nil {
+		return domain.Exchange{}, errors.New("order item does not exist")
+	}
+	if orderUserID != userID {
+		return domain.Exchange{}, errors.New("you are not authorized to exchange this item!")
+	}
+	if item.Status != models.Delivered {
+		return domain.Exchange{}, fmt.Errorf("only delivered items can be exchanged, this one is %s", item.Status)
+	}
+	if item.VariantID == 0 {
+		return domain.Exchange{}, errors.New("this item has no other sizes to exchange for")
+	}
+
+	pending, err := e.exchangeRepository.HasPendingExchange(tx, item.ID)
+	if err != nil {
+		return domain.Exchange{}, err
+	}
+	if pending {
+		return domain.Exchange{}, errors.New("an exchange is already pending for this item")
+	}
+
+	difference, err := e.priceDifference(item, req.VariantID)
+	if err != nil {
+		return domain.Exchange{}, err
+	}
+
+	order, err := e.exchangeRepository.GetOrder(tx, item.OrderID)
+	if err != nil {
+		return domain.Exchange{}, err
+	}
+	if difference < 0 && req.SettleWith == models.RefundToSource && order.PaymentMethodID != models.PaymentMethodOnline &&
+		order.PaymentMethodID != models.PaymentMethodSplit {
+		return domain.Exchange{}, ErrRefundToSourceNotAllowed
+	}
+
+	err = e.orderRepository.DecrementVariantStock(tx, req.VariantID, item.Quantity)
+	if errors.Is(err, repository.ErrInsufficientStock) {
+		return domain.Exchange{}, errors.New("the requested size is out of stock")
+	}
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to reserve variant ID %d: %w", req.VariantID, err)
+	}
+
+	exchange := domain.Exchange{
+		OrderID:         item.OrderID,
+		OrderItemID:     item.ID,
+		UserID:          userID,
+		ProductID:       item.ProductID,
+		FromVariantID:   item.VariantID,
+		ToVariantID:     req.VariantID,
+		Quantity:        item.Quantity,
+		PriceDifference: difference,
+		SettleWith:      req.SettleWith,
+		Status:          models.ExchangeRequested,
+		Reason:          req.Reason,
+	}
+	if difference > 0 {
+		if exchange.SettleWith == "" {
+			exchange.SettleWith = models.RefundToSource
+		}
+		exchange.PaymentStatus = models.PaymentNotPaid
+		if exchange.SettleWith == models.RefundToWallet {
+			exchange.PaymentStatus = models.PaymentPaid
+		} else {
+			dueBy := time.Now().Add(e.paymentWindow)
+			exchange.PaymentDueBy = &dueBy
+		}
+	}
+
+	exchange, err = e.exchangeRepository.CreateExchange(tx, exchange)
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to record exchange: %w", err)
+	}
+	if difference > 0 && exchange.SettleWith == models.RefundToWallet {
+		if err := e.debitWallet(tx, exchange); err != nil {
+			return domain.Exchange{}, err
+		}
+	}
+
+	err = e.orderRepository.CommitTransaction(tx)
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// The gateway is only called once the stock and wallet locks are gone.
+	if exchange.PaymentStatus == models.PaymentNotPaid {
+		exchange.GatewayOrderID, err = e.gateway.CreateOrder(difference.Paise(), "INR",
+			"exchange_"+strconv.Itoa(exchange.ID))
+		if err == nil {
+			err = e.exchangeRepository.SetGatewayOrderID(exchange.ID, exchange.GatewayOrderID)
+		}
+		if err != nil {
+			if _, releaseErr := e.expire(exchange.ID, "payment could not be started"); releaseErr != nil {
+				log.Printf("exchange %d will be released when its payment window ends: %v", exchange.ID, releaseErr)
+			}
+			return domain.Exchange{}, fmt.Errorf("failed to create payment for the price difference: %w", err)
+		}
+	}
+	return exchange, nil
+}
+
+// ReleaseExpired puts back the held size of every exchange whose price
+// difference was not paid through the gateway in time. It returns the
+// number of exchanges expired.
+func (e *ExchangeUseCase) ReleaseExpired() (int, error) {
+	exchangeIDs, err := e.exchangeRepository.GetExpiredExchangeIDs(time.Now(), reservationSweepBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, exchangeID := range exchangeIDs {
+		ok, err := e.expire(exchangeID, reservationExpiredMsg)
+		if err != nil {
+			log.Printf("failed to release exchange %d: %v", exchangeID, err)
+			continue
+		}
+		if ok {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// StartSweeper runs ReleaseExpired every interval in the background.
+func (e *ExchangeUseCase) StartSweeper(interval time.Duration) {
+	startSweeper("exchange sweeper", "exchanges", interval, e.ReleaseExpired)
+}
+
+// expire restocks the held size of a requested exchange that is still
+// unpaid and closes it. A payment captured afterwards is refunded by
+// CapturePayment. It reports whether the exchange was expired.
+func (e *ExchangeUseCase) expire(exchangeID int, reason string) (bool, error) {
+	tx, err := e.orderRepository.BeginTransaction()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = e.orderRepository.RollbackTransaction(tx)
+	}()
+
+	exchange, err := e.exchangeRepository.LockExchange(tx, exchangeID)
+	if err != nil {
+		return false, err
+	}
+	if exchange.Status != models.ExchangeRequested || exchange.PaymentStatus != models.PaymentNotPaid {
+		return false, nil
+	}
+
+	if err := e.orderRepository.IncrementVariantStock(tx, exchange.ToVariantID, exchange.Quantity); err != nil {
+		return false, errors.New("failed to restore variant stock")
+	}
+
+	now := time.Now()
+	exchange.Status = models.ExchangeExpired
+	exchange.AdminNote = reason
+	exchange.ResolvedAt = &now
+	if err := e.exchangeRepository.SaveExchange(tx, exchange); err != nil {
+		return false, err
+	}
+
+	err = e.orderRepository.CommitTransaction(tx)
+	if err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// priceDifference is what the customer owes for moving the line to another
+// variant of the same product, after the category discount. It is negative
+// when the new size is cheaper.
+func (e *ExchangeUseCase) priceDifference(item domain.OrderItem, variantID int) (money.Money, error) {
+	if variantID == item.VariantID {
+		return 0, errors.New("choose a different size to exchange for")
+	}
+	newVariant, err := e.productRepository.GetVariantByID(variantID)
+	if err != nil {
+		return 0, err
+	}
+	if newVariant.ProductID != item.ProductID {
+		return 0, errors.New("an item can only be exchanged for another size of the same product")
+	}
+	oldVariant, err := e.productRepository.GetVariantByID(item.VariantID)
+	if err != nil {
+		return 0, err
+	}
+	product, err := e.productRepository.GetProductByID(item.ProductID)
+	if err != nil {
+		return 0, errors.New("product not found")
+	}
+	category, err := e.categoryRepository.GetCategoryByID(product.Category_Id)
+	if err != nil {
+		return 0, errors.New("category not found")
+	}
+
+	perUnit := variantPrice(product, newVariant).Sub(variantPrice(product, oldVariant))
+	perUnit = perUnit.Sub(perUnit.Percent(float64(category.CategoryDiscount)))
+	return perUnit.Times(item.Quantity), nil
+}
+
+func variantPrice(product models.ProductResponse, variant models.VariantResponse) money.Money {
+	if variant.PriceOverride > 0 {
+		return variant.PriceOverride
+	}
+	return product.OfferPrice
+}
+
+func (e *ExchangeUseCase) debitWallet(tx *gorm.DB, exchange domain.Exchange) error {
+	_, err := e.walletRepository.Debit(tx, exchange.UserID, exchange.PriceDifference, ledger.AccountSales, ledger.Source{
+		Type:        ledger.SourceExchange,
+		ID:          exchange.ID,
+		Description: "exchange price difference",
+	})
+	if errors.Is(err, repository.ErrInsufficientWalletBalance) {
+		return errors.New("wallet amount is less than the price difference")
+	}
+	return err
+}
+
+// VerifyPayment records the gateway payment of an exchange's price
+// difference once the checkout signature checks out.
+func (e *ExchangeUseCase) VerifyPayment(userID int, payment models.ExchangePayment) (domain.Exchange, error) {
+	tx, err := e.orderRepository.BeginTransaction()
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = e.orderRepository.RollbackTransaction(tx)
+	}()
+
+	exchange, err := e.exchangeRepository.LockExchange(tx, payment.ExchangeID)
+	if err != nil {
+		return domain.Exchange{}, err
+	}
+	if exchange.UserID != userID {
+		return domain.Exchange{}, errors.New("exchange not found")
+	}
+	if exchange.PaymentStatus != models.PaymentNotPaid || exchange.Status != models.ExchangeRequested {
+		return domain.Exchange{}, errors.New("this exchange has nothing left to pay")
+	}
+	if exchange.GatewayOrderID != payment.GatewayOrderID ||
+		!e.gateway.VerifySignature(payment.GatewayOrderID, payment.PaymentID, payment.Signature) {
+		return domain.Exchange{}, errors.New("payment is unsuccessful")
+	}
+
+	exchange.GatewayPaymentID = payment.PaymentID
+	exchange.PaymentStatus = models.PaymentPaid
+	if err := e.exchangeRepository.SaveExchange(tx, exchange); err != nil {
+		return domain.Exchange{}, err
+	}
+
+	err = e.orderRepository.CommitTransaction(tx)
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return exchange, nil
+}
+
+// CapturePayment records the captured gateway payment of an exchange's
+// price difference, when the customer closed the page before it was
+// verified. A payment for an exchange that was rejected in the meantime is
+// refunded. It reports false for payments that are not exchanges.
+func (e *ExchangeUseCase) CapturePayment(gatewayOrderID, paymentID string) (bool, error) {
+	exchangeID, err := e.exchangeRepository.GetExchangeIDByGatewayOrderID(gatewayOrderID)
+	if err != nil || exchangeID == 0 {
+		return false, err
+	}
+
+	tx, err := e.orderRepository.BeginTransaction()
+	if err != nil {
+		return true, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = e.orderRepository.RollbackTransaction(tx)
+	}()
+
+	exchange, err := e.exchangeRepository.LockExchange(tx, exchangeID)
+	if err != nil {
+		return true, err
+	}
+	if exchange.PaymentStatus != models.PaymentNotPaid {
+		return true, nil
+	}
+
+	exchange.GatewayPaymentID = paymentID
+	exchange.PaymentStatus = models.PaymentPaid
+	var refund domain.Refund
+	if exchange.Status != models.ExchangeRequested {
+		refund, err = e.RefundUseCase.RefundPayment(tx, models.RefundRequest{
+			OrderID:     exchange.OrderID,
+			OrderItemID: exchange.OrderItemID,
+			UserID:      exchange.UserID,
+			Amount:      exchange.PriceDifference,
+			Reason:      "payment received after the exchange was " + exchange.Status,
+		}, paymentID)
+		if err != nil {
+			return true, err
+		}
+		exchange.RefundID = refund.ID
+		exchange.PaymentStatus = models.PaymentRefunded
+	}
+	if err := e.exchangeRepository.SaveExchange(tx, exchange); err != nil {
+		return true, err
+	}
+
+	err = e.orderRepository.CommitTransaction(tx)
+	if err != nil {
+		return true, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if err := e.RefundUseCase.Dispatch(refund); err != nil {
+		log.Println("refund will need to be retried:", err)
+	}
+	return true, nil
+}
+
+// IsExchangePayment reports whether a gateway order was created for an
+// exchange's price difference.
+func (e *ExchangeUseCase) IsExchangePayment(gatewayOrderID string) (bool, error) {
+	exchangeID, err := e.exchangeRepository.GetExchangeIDByGatewayOrderID(gatewayOrderID)
+	return exchangeID != 0, err
+}
+
+// Approve accepts an exchange. The old line is marked exchanged and the new
+// size ships free of charge on a replacement order, whose courier collects
+// the old pair. A price difference in the customer's favour is refunded.
+func (e *ExchangeUseCase) Approve(exchangeID, adminID int, note string) (domain.Exchange, error) {
+	tx, err := e.orderRepository.BeginTransaction()
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = e.orderRepository.RollbackTransaction(tx)
+	}()
+
+	exchange, err := e.exchangeRepository.LockExchange(tx, exchangeID)
+	if err != nil {
+		return domain.Exchange{}, err
+	}
+	if exchange.Status != models.ExchangeRequested {
+		return domain.Exchange{}, fmt.Errorf("only requested exchanges can be approved, this one is %s", exchange.Status)
+	}
+	if exchange.PaymentStatus == models.PaymentNotPaid {
+		return domain.Exchange{}, errors.New("the price difference has not been paid yet")
+	}
+
+	change := models.StatusChange{
+		Actor:   models.ActorAdmin,
+		ActorID: adminID,
+		Reason:  fmt.Sprintf("exchanged for variant %d", exchange.ToVariantID),
+	}
+	_, err = e.OrderStatusUseCase.TransitionItem(tx, exchange.OrderItemID, models.Exchanged, change)
+	if err != nil {
+		return domain.Exchange{}, err
+	}
+
+	original, err := e.exchangeRepository.GetOrder(tx, exchange.OrderID)
+	if err != nil {
+		return domain.Exchange{}, err
+	}
+	replacementID, err := e.orderRepository.CreateOrder(tx, models.Order{
+		UserID:          exchange.UserID,
+		AddressID:       original.AddressID,
+		PaymentMethodID: original.PaymentMethodID,
+		PaymentStatus:   models.PaymentPaid,
+		OrderStatus:     models.Confirm,
+		OrderDate:       time.Now(),
+	})
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to create replacement order: %w", err)
+	}
+
+	change.Reason = fmt.Sprintf("replacement for exchange %d of order %d", exchange.ID, exchange.OrderID)
+	err = e.OrderStatusUseCase.Record(tx, replacementID, "", models.Confirm, change)
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to record order status: %w", err)
+	}
+	err = e.orderRepository.CreateOrderItems(tx, []domain.OrderItem{{
+		OrderID:   replacementID,
+		ProductID: exchange.ProductID,
+		VariantID: exchange.ToVariantID,
+		Quantity:  exchange.Quantity,
+		Status:    models.Confirm,
+	}})
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to create replacement order items: %w", err)
+	}
+
+	var refund domain.Refund
+	if exchange.PriceDifference < 0 {
+		refund, err = e.RefundUseCase.Initiate(tx, models.RefundRequest{
+			OrderID:     exchange.OrderID,
+			OrderItemID: exchange.OrderItemID,
+			UserID:      exchange.UserID,
+			Amount:      -exchange.PriceDifference,
+			Destination: exchange.SettleWith,
+			Reason:      "exchange price difference",
+		})
+		if err != nil {
+			return domain.Exchange{}, err
+		}
+		exchange.RefundID = refund.ID
+	}
+
+	exchange.Status = models.ExchangeApproved
+	exchange.ReplacementOrderID = replacementID
+	exchange.AdminNote = note
+	if err := e.exchangeRepository.SaveExchange(tx, exchange); err != nil {
+		return domain.Exchange{}, err
+	}
+
+	err = e.orderRepository.CommitTransaction(tx)
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if err := e.RefundUseCase.Dispatch(refund); err != nil {
+		log.Println("refund will need to be retried:", err)
+	}
+	return exchange, nil
+}
+
+// Reject turns an exchange down, puts the held size back on stock and
+// returns any price difference already paid.
+func (e *ExchangeUseCase) Reject(exchangeID, adminID int, note string) (domain.Exchange, error) {
+	tx, err := e.orderRepository.BeginTransaction()
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = e.orderRepository.RollbackTransaction(tx)
+	}()
+
+	exchange, err := e.exchangeRepository.LockExchange(tx, exchangeID)
+	if err != nil {
+		return domain.Exchange{}, err
+	}
+	if exchange.Status != models.ExchangeRequested {
+		return domain.Exchange{}, fmt.Errorf("only requested exchanges can be rejected, this one is %s", exchange.Status)
+	}
+
+	if err := e.orderRepository.IncrementVariantStock(tx, exchange.ToVariantID, exchange.Quantity); err != nil {
+		return domain.Exchange{}, errors.New("failed to restore variant stock")
+	}
+
+	var refund domain.Refund
+	if exchange.PaymentStatus == models.PaymentPaid {
+		refund, err = e.RefundUseCase.RefundPayment(tx, models.RefundRequest{
+			OrderID:     exchange.OrderID,
+			OrderItemID: exchange.OrderItemID,
+			UserID:      exchange.UserID,
+			Amount:      exchange.PriceDifference,
+			Destination: exchange.SettleWith,
+			Reason:      "exchange rejected",
+		}, exchange.GatewayPaymentID)
+		if err != nil {
+			return domain.Exchange{}, err
+		}
+		exchange.RefundID = refund.ID
+		exchange.PaymentStatus = models.PaymentRefunded
+	}
+
+	now := time.Now()
+	exchange.Status = models.ExchangeRejected
+	exchange.AdminNote = note
+	exchange.ResolvedAt = &now
+	if err := e.exchangeRepository.SaveExchange(tx, exchange); err != nil {
+		return domain.Exchange{}, err
+	}
+
+	err = e.orderRepository.CommitTransaction(tx)
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if err := e.RefundUseCase.Dispatch(refund); err != nil {
+		log.Println("refund will need to be retried:", err)
+	}
+	return exchange, nil
+}
+
+// Complete records that the old pair of an approved exchange has been
+// collected and puts it back on stock.
+func (e *ExchangeUseCase) Complete(exchangeID int) (domain.Exchange, error) {
+	tx, err := e.orderRepository.BeginTransaction()
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = e.orderRepository.RollbackTransaction(tx)
+	}()
+
+	exchange, err := e.exchangeRepository.LockExchange(tx, exchangeID)
+	if err != nil {
+		return domain.Exchange{}, err
+	}
+	if exchange.Status != models.ExchangeApproved {
+		return domain.Exchange{}, fmt.Errorf("only approved exchanges can be completed, this one is %s", exchange.Status)
+	}
+
+	if err := e.orderRepository.IncrementVariantStock(tx, exchange.FromVariantID, exchange.Quantity); err != nil {
+		return domain.Exchange{}, errors.New("failed to restore variant stock")
+	}
+
+	now := time.Now()
+	exchange.Status = models.ExchangeCompleted
+	exchange.ResolvedAt = &now
+	if err := e.exchangeRepository.SaveExchange(tx, exchange); err != nil {
+		return domain.Exchange{}, err
+	}
+
+	err = e.orderRepository.CommitTransaction(tx)
+	if err != nil {
+		return domain.Exchange{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return exchange, nil
+}
+
+func (e *ExchangeUseCase) GetUserExchanges(userID int) ([]domain.Exchange, error) {
+	return e.exchangeRepository.GetExchangesByUser(userID)
+}
+
+func (e *ExchangeUseCase) GetExchanges(status string) ([]domain.Exchange, error) {
+	return e.exchangeRepository.GetExchangesByStatus(status)
+}
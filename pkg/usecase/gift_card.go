@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/gateway"
+	"ecommerce_clean_arch/pkg/giftcard"
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/shipping"
+	"ecommerce_clean_arch/pkg/utils"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	giftCardMinAmount = 100
+	giftCardMaxAmount = 10000
+	// giftCardValidityYears is how long a card can be spent from once it is
+	// paid for.
+	giftCardValidityYears = 1
+)
+
+var ErrGiftCardNotUsable = errors.New("gift card is not valid")
+
+type GiftCardUseCase struct {
+	giftCardRepository repository.GiftCardRepository
+	gateway            gateway.PaymentGateway
+}
+
+func NewGiftCardUseCase(giftCardRepository repository.GiftCardRepository, paymentGateway gateway.PaymentGateway) *GiftCardUseCase {
+	return &GiftCardUseCase{
+		giftCardRepository: giftCardRepository,
+		gateway:            paymentGateway,
+	}
+}
+
+// Purchase starts buying a gift card for someone and creates the gateway
+// order the checkout widget collects it through. The card has no code
+// until it is paid for.
+func (g *GiftCardUseCase) Purchase(userID int, req models.GiftCardPurchase) (models.GiftCardCheckout, error) {
+	if req.Amount < money.Rupees(giftCardMinAmount) || req.Amount > money.Rupees(giftCardMaxAmount) {
+		return models.GiftCardCheckout{}, fmt.Errorf("gift cards are sold from Rs. %d to Rs. %d", giftCardMinAmount, giftCardMaxAmount)
+	}
+
+	tx, err := g.giftCardRepository.BeginTransaction()
+	if err != nil {
+		return models.GiftCardCheckout{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	card, err := g.giftCardRepository.CreateGiftCard(tx, domain.GiftCard{
+		InitialAmount:  req.Amount,
+		PurchaserID:    userID,
+		RecipientName:  strings.TrimSpace(req.RecipientName),
+		RecipientEmail: strings.TrimSpace(req.RecipientEmail),
+		Message:        strings.TrimSpace(req.Message),
+		Status:         models.GiftCardPending,
+	})
+	if err != nil {
+		return models.GiftCardCheckout{}, err
+	}
+	card.GatewayOrderID, err = g.gateway.CreateOrder(req.Amount.Paise(), "INR", "giftcard_"+strconv.Itoa(card.ID))
+	if err != nil {
+		return models.GiftCardCheckout{}, fmt.Errorf("failed to create payment for the gift card: %w", err)
+	}
+	if err := g.giftCardRepository.SaveGiftCard(tx, card); err != nil {
+		return models.GiftCardCheckout{}, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return models.GiftCardCheckout{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return models.GiftCardCheckout{
+		GiftCardID:     card.ID,
+		Amount:         card.InitialAmount,
+		GatewayOrderID: card.GatewayOrderID,
+		Gateway:        g.gateway.Name(),
+		KeyID:          g.gateway.KeyID(),
+	}, nil
+}
+
+// VerifyPurchase checks the checkout widget's signature for a gift card,
+// activates the card and emails its code to the recipient.
+func (g *GiftCardUseCase) VerifyPurchase(userID int, payment models.GiftCardPayment) (domain.GiftCard, error) {
+	tx, err := g.giftCardRepository.BeginTransaction()
+	if err != nil {
+		return domain.GiftCard{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	card, err := g.giftCardRepository.LockGiftCard(tx, payment.GiftCardID)
+	if err != nil {
+		return domain.GiftCard{}, err
+	}
+	if card.PurchaserID != userID {
+		return domain.GiftCard{}, errors.New("gift card not found")
+	}
+	if card.Status != models.GiftCardPending {
+		return domain.GiftCard{}, errors.New("this gift card has already been paid for")
+	}
+	if card.GatewayOrderID != payment.GatewayOrderID ||
+		!g.gateway.VerifySignature(payment.GatewayOrderID, payment.PaymentID, payment.Signature) {
+		return domain.GiftCard{}, errors.New("payment is unsuccessful")
+	}
+
+	card, code, err := g.activate(tx, card, payment.PaymentID)
+	if err != nil {
+		return domain.GiftCard{}, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return domain.GiftCard{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	g.emailCode(&card, code)
+	return card, nil
+}
+
+// CaptureGiftCard activates the gift card a captured gateway payment was
+// for, when the purchaser closed the page before it was verified. It
+// reports false for payments that are not gift cards.
+func (g *GiftCardUseCase) CaptureGiftCard(gatewayOrderID, paymentID string) (bool, error) {
+	giftCardID, err := g.giftCardRepository.GetGiftCardIDByGatewayOrderID(gatewayOrderID)
+	if err != nil || giftCardID == 0 {
+		return false, err
+	}
+
+	tx, err := g.giftCardRepository.BeginTransaction()
+	if err != nil {
+		return true, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	card, err := g.giftCardRepository.LockGiftCard(tx, giftCardID)
+	if err != nil {
+		return true, err
+	}
+	if card.Status != models.GiftCardPending {
+		return true, nil
+	}
+	card, code, err := g.activate(tx, card, paymentID)
+	if err != nil {
+		return true, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return true, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	g.emailCode(&card, code)
+	return true, nil
+}
+
+// IsGiftCardPayment reports whether a gateway order was created for a gift
+// card.
+func (g *GiftCardUseCase) IsGiftCardPayment(gatewayOrderID string) (bool, error) {
+	giftCardID, err := g.giftCardRepository.GetGiftCardIDByGatewayOrderID(gatewayOrderID)
+	return giftCardID != 0, err
+}
+
+// ResendCode replaces the code of an active gift card the user bought and
+// emails the new one to the recipient, for when the first email did not
+// arrive. The old code stops working.
+func (g *GiftCardUseCase) ResendCode(userID, giftCardID int) (domain.GiftCard, error) {
+	tx, err := g.giftCardRepository.BeginTransaction()
+	if err != nil {
+		return domain.GiftCard{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	card, err := g.giftCardRepository.LockGiftCard(tx, giftCardID)
+	if err != nil {
+		return domain.GiftCard{}, err
+	}
+	if card.PurchaserID != userID {
+		return domain.GiftCard{}, errors.New("gift card not found")
+	}
+	if card.Status != models.GiftCardActive {
+		return domain.GiftCard{}, errors.New("this gift card has not been paid for")
+	}
+
+	code, err := giftcard.NewCode()
+	if err != nil {
+		return domain.GiftCard{}, fmt.Errorf("failed to generate gift card code: %w", err)
+	}
+	card.CodeHash = giftcard.Hash(code)
+	card.Last4 = giftcard.Last4(code)
+	card.EmailedAt = nil
+	if err := g.giftCardRepository.SaveGiftCard(tx, card); err != nil {
+		return domain.GiftCard{}, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return domain.GiftCard{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	g.emailCode(&card, code)
+	return card, nil
+}
+
+func (g *GiftCardUseCase) GetPurchased(userID int) ([]domain.GiftCard, error) {
+	return g.giftCardRepository.GetPurchasedGiftCards(userID)
+}
+
+// Balance looks up a gift card by its code.
+func (g *GiftCardUseCase) Balance(code string) (models.GiftCardBalance, error) {
+	card, err := g.giftCardRepository.GetGiftCardByHash(giftcard.Hash(code))
+	if err != nil {
+		return models.GiftCardBalance{}, err
+	}
+	return models.GiftCardBalance{
+		Last4:     card.Last4,
+		Balance:   card.Balance,
+		Status:    card.Status,
+		Expired:   giftCardExpired(card, time.Now()),
+		ExpiresAt: card.ExpiresAt,
+	}, nil
+}
+
+// Lock finds the gift card a code belongs to at checkout and locks it until
+// tx ends, so two orders cannot spend the same balance.
+func (g *GiftCardUseCase) Lock(tx *gorm.DB, code string) (domain.GiftCard, error) {
+	card, err := g.giftCardRepository.LockGiftCardByHash(tx, giftcard.Hash(code))
+	if err != nil {
+		return domain.GiftCard{}, ErrGiftCardNotUsable
+	}
+	if card.Status != models.GiftCardActive {
+		return domain.GiftCard{}, ErrGiftCardNotUsable
+	}
+	if giftCardExpired(card, time.Now()) {
+		return domain.GiftCard{}, errors.New("gift card has expired")
+	}
+	if !card.Balance.IsPositive() {
+		return domain.GiftCard{}, errors.New("gift card has no balance left")
+	}
+	return card, nil
+}
+
+// Redeem takes amount off a gift card locked by Lock to pay orderID.
+func (g *GiftCardUseCase) Redeem(tx *gorm.DB, giftCardID int, amount money.Money, orderID int) error {
+	_, err := g.giftCardRepository.Redeem(tx, giftCardID, amount, orderID)
+	return err
+}
+
+func (g *GiftCardUseCase) activate(tx *gorm.DB, card domain.GiftCard, paymentID string) (domain.GiftCard, string, error) {
+	code, err := giftcard.NewCode()
+	if err != nil {
+		return domain.GiftCard{}, "", fmt.Errorf("failed to generate gift card code: %w", err)
+	}
+	now := time.Now()
+	expiresAt := giftCardExpiry(now)
+	card.CodeHash = giftcard.Hash(code)
+	card.Last4 = giftcard.Last4(code)
+	card.Balance = card.InitialAmount
+	card.Status = models.GiftCardActive
+	card.GatewayPaymentID = paymentID
+	card.ActivatedAt = &now
+	card.ExpiresAt = &expiresAt
+	if err := g.giftCardRepository.SaveGiftCard(tx, card); err != nil {
+		return domain.GiftCard{}, "", err
+	}
+	err = g.giftCardRepository.CreateTransaction(tx, domain.GiftCardTransaction{
+		GiftCardID: card.ID,
+		Amount:     card.InitialAmount,
+		Balance:    card.Balance,
+		Reason:     "gift card issued",
+	})
+	if err != nil {
+		return domain.GiftCard{}, "", err
+	}
+	return card, code, nil
+}
+
+// emailCode sends the code to the recipient. The card is paid for either
+// way, so a failure is only logged; the purchaser can have it resent.
+func (g *GiftCardUseCase) emailCode(card *domain.GiftCard, code string) {
+	from, err := g.giftCardRepository.GetPurchaserName(card.PurchaserID)
+	if err != nil || from == "" {
+		from = "Someone"
+	}
+	if err := utils.SendEmail(card.RecipientEmail, "You have received a gift card", giftCardEmail(*card, from, code)); err != nil {
+		log.Printf("failed to email gift card %d: %v", card.ID, err)
+		return
+	}
+	if err := g.giftCardRepository.MarkEmailed(card.ID); err != nil {
+		log.Printf("failed to record gift card %d as emailed: %v", card.ID, err)
+		return
+	}
+	now := time.Now()
+	card.EmailedAt = &now
+}
+
+func giftCardEmail(card domain.GiftCard, from, code string) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hi %s,\n\n%s has sent you a gift card worth Rs. %s.\n\n", card.RecipientName, from, card.InitialAmount)
+	if card.Message != "" {
+		fmt.Fprintf(&body, "%s\n\n", card.Message)
+	}
+	fmt.Fprintf(&body, "Your code: %s\n", code)
+	if card.ExpiresAt != nil {
+		fmt.Fprintf(&body, "Valid until: %s\n", card.ExpiresAt.In(shipping.IST).Format("02 Jan 2006"))
+	}
+	body.WriteString("\nEnter the code at checkout. Whatever you do not spend stays on the card for your next order.\n")
+	return body.String()
+}
+
+// giftCardExpiry is the end of the day, in India, a year after now.
+func giftCardExpiry(now time.Time) time.Time {
+	local := now.In(shipping.IST).AddDate(giftCardValidityYears, 0, 0)
+	return time.Date(local.Year(), local.Month(), local.Day(), 23, 59, 59, 0, shipping.IST)
+}
+
+func giftCardExpired(card domain.GiftCard, now time.Time) bool {
+	return card.ExpiresAt != nil && now.After(*card.ExpiresAt)
+}
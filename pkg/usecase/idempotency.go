@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/repository"
+	"errors"
+)
+
+const maxIdempotencyKeyLength = 255
+
+var (
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyInProgress = errors.New("a request with this idempotency key is still being processed")
+	ErrIdempotencyKeyTooLong = errors.New("idempotency key must be at most 255 characters")
+)
+
+type IdempotencyUseCase struct {
+	idempotencyRepository repository.IdempotencyRepository
+}
+
+func NewIdempotencyUseCase(idempotencyRepository repository.IdempotencyRepository) *IdempotencyUseCase {
+	return &IdempotencyUseCase{idempotencyRepository: idempotencyRepository}
+}
+
+// Begin claims the key for a new request. When the key has been seen before
+// the stored record is returned so the caller can replay its response; a nil
+// record means the caller owns the key and must call Complete or Abandon.
+func (i *IdempotencyUseCase) Begin(key string, userID int, scope, requestHash string) (*domain.IdempotencyKey, error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, ErrIdempotencyKeyTooLong
+	}
+
+	created, err := i.idempotencyRepository.CreateKey(domain.IdempotencyKey{
+		Key:         key,
+		UserID:      userID,
+		Scope:       scope,
+		RequestHash: requestHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		return nil, nil
+	}
+
+	record, err := i.idempotencyRepository.GetKey(key, userID, scope)
+	if err != nil {
+		return nil, err
+	}
+	if record.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if record.StatusCode == 0 {
+		return nil, ErrIdempotencyInProgress
+	}
+	return &record, nil
+}
+
+func (i *IdempotencyUseCase) Complete(key string, userID int, scope string, statusCode int, body string) error {
+	return i.idempotencyRepository.SaveResponse(key, userID, scope, statusCode, body)
+}
+
+// Abandon frees a claimed key when no successful response was produced, so
+// the client can retry with it.
+func (i *IdempotencyUseCase) Abandon(key string, userID int, scope string) error {
+	return i.idempotencyRepository.DeleteKey(key, userID, scope)
+}
@@ -3,6 +3,7 @@ package interfaces
 import (
 	"ecommerce_clean_arch/pkg/domain"
 	"ecommerce_clean_arch/pkg/utils/models"
+	"io"
 )
 
 type AdminUseCase interface {
@@ -13,7 +14,28 @@ type AdminUseCase interface {
 	UnBlockUsers(userID int) error
 	GetAllOrderDetails() ([]models.FullOrderDetails, error)
 	CancelOrders(orderID string, userID int) error
-	ChangeOrderStatus(orderID string, Status string) (models.Order, error)
+	ChangeOrderStatus(orderID string, status string, adminID int, reason string) (models.Order, error)
+	GetOrderTimeline(orderID string) ([]domain.OrderStatusHistory, error)
+	GeneratePackingSlip(orderID string) ([]byte, error)
+	GeneratePickList() ([]byte, error)
+	GenerateShippingLabel(orderID string) ([]byte, error)
+	BookShipment(orderID int) (domain.Shipment, error)
+	GetShipmentTracking(orderID string) (domain.Tracking, error)
+	UploadPincodes(r io.Reader) (int, error)
+	GetPincodes(prefix string) ([]domain.ServiceablePincode, error)
+	GetDeliveryRules() ([]domain.DeliveryRule, error)
+	AddDeliveryRule(rule domain.DeliveryRule) (domain.DeliveryRule, error)
+	UpdateDeliveryRule(rule domain.DeliveryRule, ruleID int) (domain.DeliveryRule, error)
+	DeleteDeliveryRule(ruleID int) error
+	GetRefunds(status string) ([]domain.Refund, error)
+	RetryRefund(refundID string) (domain.Refund, error)
+	GetExchanges(status string) ([]domain.Exchange, error)
+	ApproveExchange(decision models.ExchangeDecision, adminID int) (domain.Exchange, error)
+	RejectExchange(decision models.ExchangeDecision, adminID int) (domain.Exchange, error)
+	CompleteExchange(exchangeID string) (domain.Exchange, error)
+	GetReturnRequests(status string) ([]domain.ReturnRequest, error)
+	UpdateReturnStatus(decision models.ReturnDecision, status string, adminID int) (domain.ReturnRequest, error)
+	InspectReturn(inspection models.ReturnInspection, adminID int) (domain.ReturnRequest, error)
 
 	GetDateRange(startDate, endDate, limit string) (string, string)
 	TotalOrders(fromDate, toDate, PaymentStatus string) (models.OrderCount, models.AmountInformation, error)
@@ -3,15 +3,26 @@ package interfaces
 import (
 	"ecommerce_clean_arch/pkg/domain"
 	"ecommerce_clean_arch/pkg/utils/models"
-
-	"github.com/jung-kurt/gofpdf"
 )
 
 type OrderUseCase interface {
 	OrderItemsFromCart(orderFromCart models.OrderFromCart, userID int) (domain.OrderSuccessResponse, error)
 	GetOrderDetails(userID int, page int, count int) ([]models.FullOrderDetails, error)
-	CancelOrders(orderID string, userID int) error
-	CancelOrderItem(orderItemID string, userID int) (domain.OrderItem, error)
-	ReturnUserOrder(orderID string, userID int) error
-	GenerateInvoice(orderID string, userID int) (*gofpdf.Fpdf, error)
+	CancelOrders(orderID string, userID int, refundTo string) error
+	CancelOrderItem(orderItemID string, userID int, refundTo string) (domain.OrderItem, error)
+	RequestReturn(userID int, req models.ReturnRequest) (domain.ReturnRequest, error)
+	GetReturnRequests(userID int) ([]domain.ReturnRequest, error)
+	GetAllReturnRequests(status string) ([]domain.ReturnRequest, error)
+	UpdateReturnStatus(returnID int, to string, adminID int, note string) (domain.ReturnRequest, error)
+	GetRefunds(userID int) ([]domain.Refund, error)
+	RequestExchange(userID int, req models.ExchangeRequest) (domain.Exchange, error)
+	VerifyExchangePayment(userID int, payment models.ExchangePayment) (domain.Exchange, error)
+	GetExchanges(userID int) ([]domain.Exchange, error)
+	GetOrderTimeline(orderID string, userID int) ([]domain.OrderStatusHistory, error)
+	GenerateInvoice(orderID string, userID int) ([]byte, error)
+	GetCreditNotes(orderID string, userID int) ([]domain.CreditNote, error)
+	GetTracking(orderID string, userID int) (domain.Tracking, error)
+	HandleShippingEvent(body []byte, signature string) error
+	CheckPincode(pincode string) (models.PincodeServiceability, error)
+	GetCreditNotePDF(creditNoteID string, userID int) ([]byte, error)
 }
@@ -10,4 +10,10 @@ type ProductUseCase interface {
 	UpdateProduct(products models.ProductResponse, productID int) (models.ProductResponse, error)
 	DeleteProduct(productID int) error
 	SearchProduct(categoryID string, sortBy string) ([]domain.Products, error)
+	SearchProducts(query models.ProductSearchQuery) (models.ProductSearchResponse, error)
+
+	AddVariant(variant models.AddVariant) (models.VariantResponse, error)
+	UpdateVariant(variant models.AddVariant, variantID int) (models.VariantResponse, error)
+	DeleteVariant(variantID int) error
+	GetProductVariants(productID int) ([]models.VariantResponse, error)
 }
@@ -18,6 +18,7 @@ type UserUseCaseInterface interface {
 	ResendOTP(string) error
 	UserLogin(user models.User, input models.User) (models.TokenUsers, models.User, error)
 	GetProducts() ([]models.ProductResponse, error)
+	DeliveryEstimate(pincode string) (models.DeliveryEstimate, error)
 	ListCategory() ([]domain.Category, error)
 	UserProfile(userID string) (*models.User, error)
 	UpdateProfile(editProfile models.User) (*models.User, error)
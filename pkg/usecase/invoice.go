@@ -0,0 +1,393 @@
+package usecase
+
+import (
+	"ecommerce_clean_arch/pkg/config"
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"gorm.io/gorm"
+)
+
+type InvoiceUseCase struct {
+	invoiceRepository     repository.InvoiceRepository
+	orderRepository       repository.OrderRepository
+	orderStatusRepository repository.OrderStatusRepository
+	sellerGSTIN           string
+}
+
+func NewInvoiceUseCase(invoiceRepository repository.InvoiceRepository, orderRepository repository.OrderRepository, orderStatusRepository repository.OrderStatusRepository, cfg config.Config) *InvoiceUseCase {
+	return &InvoiceUseCase{
+		invoiceRepository:     invoiceRepository,
+		orderRepository:       orderRepository,
+		orderStatusRepository: orderStatusRepository,
+		sellerGSTIN:           cfg.SellerGSTIN,
+	}
+}
+
+// FinancialYear names the Indian financial year, April to March, that t
+// falls in, as in "2026-27".
+func FinancialYear(t time.Time) string {
+	start := t.Year()
+	if t.Month() < time.April {
+		start--
+	}
+	return fmt.Sprintf("%d-%02d", start, (start+1)%100)
+}
+
+// DocumentNumber formats the number of an invoice or credit note, as in
+// "SS/2026-27/000123".
+func DocumentNumber(series, financialYear string, sequence int) string {
+	return fmt.Sprintf("%s/%s/%06d", series, financialYear, sequence)
+}
+
+// Invoice returns the PDF of an order's tax invoice. The first download of
+// a confirmed order issues the invoice and takes the next number in the
+// series; every later one returns the stored document unchanged.
+func (i *InvoiceUseCase) Invoice(orderID, userID int) ([]byte, error) {
+	if err := i.checkOwner(orderID, userID); err != nil {
+		return nil, err
+	}
+
+	invoice, err := i.invoiceRepository.GetInvoice(i.invoiceRepository.DB, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.ID != 0 {
+		return invoice.PDF, nil
+	}
+
+	orderDetails, err := i.orderRepository.FetchOrderDetailsFromDB(strconv.Itoa(orderID))
+	if err != nil {
+		return nil, errors.New("Unable to fetch order Details")
+	}
+
+	tx, err := i.orderRepository.BeginTransaction()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = i.orderRepository.RollbackTransaction(tx)
+	}()
+
+	status, err := i.orderStatusRepository.LockOrderStatus(tx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	invoice, err = i.invoiceRepository.GetInvoice(tx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.ID != 0 {
+		return invoice.PDF, nil
+	}
+	if !slices.Contains(models.InvoiceableStatuses, status) {
+		return nil, fmt.Errorf("an invoice cannot be issued for a %s order", status)
+	}
+
+	now := time.Now()
+	invoice = domain.Invoice{
+		OrderID:       orderID,
+		FinancialYear: FinancialYear(now),
+		SellerGSTIN:   i.sellerGSTIN,
+		BuyerName:     orderDetails.CustomerName,
+		BuyerAddress: fmt.Sprintf("phone: %v\n%s %s\n%s, %s %s",
+			orderDetails.CustomerPhoneNumber,
+			orderDetails.CustomerAddress.HouseName,
+			orderDetails.CustomerAddress.State,
+			orderDetails.CustomerAddress.Street,
+			orderDetails.CustomerAddress.City,
+			orderDetails.CustomerAddress.Pin,
+		),
+		PlaceOfSupply: orderDetails.CustomerAddress.State,
+		Total:         orderDetails.FinalPrice,
+		IssuedAt:      now,
+	}
+	for _, item := range orderDetails.Items {
+		invoice.TaxableValue += item.TaxableValue
+		invoice.CGST += item.CGST
+		invoice.SGST += item.SGST
+		invoice.IGST += item.IGST
+	}
+
+	invoice.Sequence, err = i.invoiceRepository.NextNumber(tx, models.InvoiceSeries, invoice.FinancialYear)
+	if err != nil {
+		return nil, fmt.Errorf("failed to number invoice: %w", err)
+	}
+	invoice.Number = DocumentNumber(models.InvoiceSeries, invoice.FinancialYear, invoice.Sequence)
+
+	invoice.PDF, err = renderInvoice(invoice, orderDetails)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render invoice: %w", err)
+	}
+	invoice, err = i.invoiceRepository.CreateInvoice(tx, invoice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save invoice: %w", err)
+	}
+
+	err = i.orderRepository.CommitTransaction(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return invoice.PDF, nil
+}
+
+// CreditNote issues a credit note inside tx for the lines of an invoiced
+// order that were cancelled or returned since its last one, and for the
+// delivery charge once the whole order is. It does nothing when the order
+// has no invoice or nothing is left to credit.
+func (i *InvoiceUseCase) CreditNote(tx *gorm.DB, orderID int, reason string) error {
+	invoice, err := i.invoiceRepository.GetInvoice(tx, orderID)
+	if err != nil {
+		return err
+	}
+	if invoice.ID == 0 {
+		return nil
+	}
+
+	lines, err := i.invoiceRepository.GetUncreditedLines(tx, orderID)
+	if err != nil {
+		return err
+	}
+	deliveryCharge, err := i.invoiceRepository.GetUncreditedDeliveryCharge(tx, orderID)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 && deliveryCharge == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	note := domain.CreditNote{
+		InvoiceID:      invoice.ID,
+		OrderID:        orderID,
+		FinancialYear:  FinancialYear(now),
+		Reason:         reason,
+		DeliveryCharge: deliveryCharge,
+		Lines:          lines,
+		IssuedAt:       now,
+	}
+	for _, line := range lines {
+		note.TaxableValue += line.TaxableValue
+		note.CGST += line.CGST
+		note.SGST += line.SGST
+		note.IGST += line.IGST
+	}
+	note.Total = money.Sum(note.TaxableValue, note.CGST, note.SGST, note.IGST, deliveryCharge)
+
+	note.Sequence, err = i.invoiceRepository.NextNumber(tx, models.CreditNoteSeries, note.FinancialYear)
+	if err != nil {
+		return fmt.Errorf("failed to number credit note: %w", err)
+	}
+	note.Number = DocumentNumber(models.CreditNoteSeries, note.FinancialYear, note.Sequence)
+
+	note.PDF, err = renderCreditNote(note, invoice)
+	if err != nil {
+		return fmt.Errorf("failed to render credit note: %w", err)
+	}
+	if _, err := i.invoiceRepository.CreateCreditNote(tx, note); err != nil {
+		return fmt.Errorf("failed to save credit note: %w", err)
+	}
+	return nil
+}
+
+func (i *InvoiceUseCase) GetCreditNotes(orderID, userID int) ([]domain.CreditNote, error) {
+	if err := i.checkOwner(orderID, userID); err != nil {
+		return nil, err
+	}
+	return i.invoiceRepository.GetCreditNotes(orderID)
+}
+
+func (i *InvoiceUseCase) GetCreditNotePDF(creditNoteID, userID int) ([]byte, error) {
+	note, err := i.invoiceRepository.GetCreditNote(creditNoteID)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.checkOwner(note.OrderID, userID); err != nil {
+		return nil, err
+	}
+	return note.PDF, nil
+}
+
+func (i *InvoiceUseCase) checkOwner(orderID, userID int) error {
+	orderUserID, err := i.orderRepository.UserOrderRelationship(orderID, userID)
+	if err != nil || orderUserID == 0 {
+		return errors.New("order does not exist")
+	}
+	if orderUserID != userID {
+		return errors.New("you are not authorized to view this order!")
+	}
+	return nil
+}
+
+func renderInvoice(invoice domain.Invoice, orderDetails models.OrdersDetails) ([]byte, error) {
+	date := invoice.IssuedAt.Format("02 Jan 2006")
+	dueDate := invoice.IssuedAt.AddDate(0, 0, 15).Format("02 Jan 2006")
+
+	// Seller Info
+	sellerInfo := fmt.Sprintf("Axis Bank\nAccount Name: Sole-Spot\nAccount No.: 123-456-7890\nPay by: %v", orderDetails.OrderDate)
+
+	// Initialize PDF
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+
+	// Title
+	pdf.SetFont("Arial", "B", 20)
+	pdf.CellFormat(0, 15, "Tax Invoice", "", 1, "C", false, 0, "")
+	pdf.Ln(10)
+
+	// Invoice and Date
+	pdf.SetFont("Arial", "", 12)
+	pdf.SetXY(130, 20)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Invoice No: %s", invoice.Number), "", 1, "R", false, 0, "")
+	pdf.SetXY(130, 26)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Date: %s", date), "", 1, "R", false, 0, "")
+	pdf.SetXY(130, 32)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Due Date: %s", dueDate), "", 1, "R", false, 0, "")
+	if invoice.SellerGSTIN != "" {
+		pdf.SetXY(130, 38)
+		pdf.CellFormat(0, 6, fmt.Sprintf("GSTIN: %s", invoice.SellerGSTIN), "", 1, "R", false, 0, "")
+	}
+	pdf.SetXY(130, 44)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Place of Supply: %s", invoice.PlaceOfSupply), "", 1, "R", false, 0, "")
+
+	// Billing and Shipping
+	pdf.Ln(15)
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Billed To:", "", 1, "", false, 0, "")
+	pdf.SetFont("Arial", "", 12)
+	pdf.MultiCell(0, 10, sellerInfo, "", "L", false)
+	pdf.Ln(5)
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Shipped To:", "", 1, "", false, 0, "")
+	pdf.SetFont("Arial", "", 12)
+	pdf.MultiCell(0, 10, invoice.BuyerName+"\n"+invoice.BuyerAddress, "", "L", false)
+
+	// Items Table
+	pdf.Ln(10)
+	taxTableHeader(pdf)
+
+	var subtotal money.Money
+	for _, item := range orderDetails.Items {
+		total := item.Price.Times(int(item.Quantity))
+		subtotal += total
+		itemName := item.Name
+		if item.Variant != "" {
+			itemName = fmt.Sprintf("%s (%s)", item.Name, item.Variant)
+		}
+		taxTableRow(pdf, itemName, item.HSNCode, int(item.Quantity), item.TaxableValue, item.GSTRate, item.CGST, item.SGST, item.IGST, total)
+	}
+
+	// Additional Charges and Final Total
+	totalOfferAmount := orderDetails.RawAmount - orderDetails.GrandTotal
+	totalDiscountAmount := orderDetails.Discount
+	categoryDiscount := orderDetails.CategoryDiscount
+	totalDeliveryCharge := orderDetails.DeliveryCharge
+
+	// Totals Section
+	pdf.Ln(5)
+	pdf.SetFont("Arial", "B", 12)
+	totalRow(pdf, "Subtotal", subtotal)
+	totalRow(pdf, "Total Offer Amount", totalOfferAmount)
+	totalRow(pdf, "Total Discount Amount", totalDiscountAmount)
+	totalRow(pdf, "Total Category Discount Amount", categoryDiscount)
+	totalRow(pdf, "Total Delivery Charge", totalDeliveryCharge)
+	totalRow(pdf, "Taxable Value", invoice.TaxableValue)
+	totalRow(pdf, "CGST", invoice.CGST)
+	totalRow(pdf, "SGST", invoice.SGST)
+	totalRow(pdf, "IGST", invoice.IGST)
+	totalRow(pdf, "Grand Total (inclusive of GST)", invoice.Total)
+
+	return outputPDF(pdf)
+}
+
+func renderCreditNote(note domain.CreditNote, invoice domain.Invoice) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 20)
+	pdf.CellFormat(0, 15, "Credit Note", "", 1, "C", false, 0, "")
+	pdf.Ln(10)
+
+	pdf.SetFont("Arial", "", 12)
+	pdf.SetXY(110, 20)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Credit Note No: %s", note.Number), "", 1, "R", false, 0, "")
+	pdf.SetXY(110, 26)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Date: %s", note.IssuedAt.Format("02 Jan 2006")), "", 1, "R", false, 0, "")
+	pdf.SetXY(110, 32)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Against Invoice: %s dated %s", invoice.Number, invoice.IssuedAt.Format("02 Jan 2006")), "", 1, "R", false, 0, "")
+	if invoice.SellerGSTIN != "" {
+		pdf.SetXY(110, 38)
+		pdf.CellFormat(0, 6, fmt.Sprintf("GSTIN: %s", invoice.SellerGSTIN), "", 1, "R", false, 0, "")
+	}
+	pdf.SetXY(110, 44)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Place of Supply: %s", invoice.PlaceOfSupply), "", 1, "R", false, 0, "")
+
+	pdf.Ln(15)
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Issued To:", "", 1, "", false, 0, "")
+	pdf.SetFont("Arial", "", 12)
+	pdf.MultiCell(0, 10, invoice.BuyerName+"\n"+invoice.BuyerAddress, "", "L", false)
+	if note.Reason != "" {
+		pdf.MultiCell(0, 10, fmt.Sprintf("Reason: %s", note.Reason), "", "L", false)
+	}
+
+	pdf.Ln(10)
+	taxTableHeader(pdf)
+	for _, line := range note.Lines {
+		amount := line.TaxableValue + line.CGST + line.SGST + line.IGST
+		taxTableRow(pdf, line.Description, line.HSNCode, line.Quantity, line.TaxableValue, line.GSTRate, line.CGST, line.SGST, line.IGST, amount)
+	}
+
+	pdf.Ln(5)
+	pdf.SetFont("Arial", "B", 12)
+	totalRow(pdf, "Taxable Value", note.TaxableValue)
+	totalRow(pdf, "CGST", note.CGST)
+	totalRow(pdf, "SGST", note.SGST)
+	totalRow(pdf, "IGST", note.IGST)
+	if note.DeliveryCharge > 0 {
+		totalRow(pdf, "Delivery Charge", note.DeliveryCharge)
+	}
+	totalRow(pdf, "Total Credited", note.Total)
+
+	return outputPDF(pdf)
+}
+
+func taxTableHeader(pdf *gofpdf.Fpdf) {
+	pdf.SetFont("Arial", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	pdf.CellFormat(46, 10, "Item", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(18, 10, "HSN", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(10, 10, "Qty", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(24, 10, "Taxable", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(14, 10, "GST %", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(18, 10, "CGST", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(18, 10, "SGST", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(18, 10, "IGST", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(24, 10, "Total", "1", 1, "C", true, 0, "")
+	pdf.SetFont("Arial", "", 9)
+}
+
+func taxTableRow(pdf *gofpdf.Fpdf, name, hsnCode string, quantity int, taxable money.Money, rate float64, cgst, sgst, igst, total money.Money) {
+	pdf.CellFormat(46, 10, name, "1", 0, "", false, 0, "")
+	pdf.CellFormat(18, 10, hsnCode, "1", 0, "C", false, 0, "")
+	pdf.CellFormat(10, 10, fmt.Sprintf("%d", quantity), "1", 0, "C", false, 0, "")
+	pdf.CellFormat(24, 10, taxable.String(), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(14, 10, fmt.Sprintf("%g", rate), "1", 0, "C", false, 0, "")
+	pdf.CellFormat(18, 10, cgst.String(), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(18, 10, sgst.String(), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(18, 10, igst.String(), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(24, 10, total.String(), "1", 1, "R", false, 0, "")
+}
+
+func totalRow(pdf *gofpdf.Fpdf, label string, amount money.Money) {
+	pdf.CellFormat(150, 10, label, "1", 0, "R", false, 0, "")
+	pdf.CellFormat(40, 10, amount.String(), "1", 1, "R", false, 0, "")
+}
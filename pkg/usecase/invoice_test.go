@@ -0,0 +1,34 @@
+package usecase_test
+
+import (
+	"ecommerce_clean_arch/pkg/usecase"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FinancialYear(t *testing.T) {
+	testCases := map[string]struct {
+		date time.Time
+		want string
+	}{
+		"first day of the year":  {date: time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC), want: "2026-27"},
+		"mid year":               {date: time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC), want: "2026-27"},
+		"last day of the year":   {date: time.Date(2027, time.March, 31, 23, 59, 0, 0, time.UTC), want: "2026-27"},
+		"january is the old one": {date: time.Date(2027, time.January, 5, 0, 0, 0, 0, time.UTC), want: "2026-27"},
+		"turn of the century":    {date: time.Date(2099, time.May, 1, 0, 0, 0, 0, time.UTC), want: "2099-00"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, usecase.FinancialYear(tc.date))
+		})
+	}
+}
+
+func Test_DocumentNumber(t *testing.T) {
+	assert.Equal(t, "SS/2026-27/000123", usecase.DocumentNumber(models.InvoiceSeries, "2026-27", 123))
+	assert.Equal(t, "SS/CN/2026-27/000012", usecase.DocumentNumber(models.CreditNoteSeries, "2026-27", 12))
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/utils/models"
+)
+
+type LedgerUseCase struct {
+	ledgerRepository repository.LedgerRepository
+}
+
+func NewLedgerUseCase(ledgerRepository repository.LedgerRepository) *LedgerUseCase {
+	return &LedgerUseCase{ledgerRepository: ledgerRepository}
+}
+
+// Check looks for journal entries that do not balance and for wallets
+// whose cached balance differs from their ledger account. With repair set
+// the drifted wallets are reset to the ledger's balance; broken entries
+// are only reported, since the ledger is never edited.
+func (l *LedgerUseCase) Check(repair bool) (models.LedgerReport, error) {
+	var report models.LedgerReport
+	var err error
+
+	report.UnbalancedEntries, err = l.ledgerRepository.GetUnbalancedEntries()
+	if err != nil {
+		return models.LedgerReport{}, err
+	}
+	report.WalletDrift, err = l.ledgerRepository.GetWalletDrift()
+	if err != nil {
+		return models.LedgerReport{}, err
+	}
+
+	if repair && len(report.WalletDrift) > 0 {
+		if err := l.ledgerRepository.ResetWalletBalances(report.WalletDrift); err != nil {
+			return report, err
+		}
+		report.Repaired = len(report.WalletDrift)
+	}
+	return report, nil
+}
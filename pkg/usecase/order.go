@@ -1,9 +1,14 @@
 package usecase
 
 import (
+	"ecommerce_clean_arch/pkg/config"
 	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/ledger"
+	"ecommerce_clean_arch/pkg/money"
 	"ecommerce_clean_arch/pkg/repository"
-	"ecommerce_clean_arch/pkg/utils"
+	"ecommerce_clean_arch/pkg/rules"
+	"ecommerce_clean_arch/pkg/shipping"
+	"ecommerce_clean_arch/pkg/tax"
 	"ecommerce_clean_arch/pkg/utils/models"
 	"errors"
 	"fmt"
@@ -11,26 +16,46 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/jung-kurt/gofpdf"
+	"gorm.io/gorm"
 )
 
 type OrderUseCase struct {
-	orderRepository  repository.OrderRepository
-	userRepository   repository.UserRepository
-	cartRepository   repository.CartRepository
-	walletRepository repository.WalletRepository
-	WalletUseCase    WalletUseCase
-	CouponRepo       repository.CouponRepository
+	orderRepository     repository.OrderRepository
+	userRepository      repository.UserRepository
+	cartRepository      repository.CartRepository
+	walletRepository    repository.WalletRepository
+	WalletUseCase       WalletUseCase
+	GiftCardUseCase     GiftCardUseCase
+	CouponRepo          repository.CouponRepository
+	ReservationUseCase  ReservationUseCase
+	RefundUseCase       RefundUseCase
+	OrderStatusUseCase  OrderStatusUseCase
+	ExchangeUseCase     ExchangeUseCase
+	returnRepository    repository.ReturnRepository
+	InvoiceUseCase      InvoiceUseCase
+	ShippingUseCase     ShippingUseCase
+	DeliveryRuleUseCase DeliveryRuleUseCase
+	sellerStateCode     string
 }
 
-func NewOrderUseCase(orderRepository repository.OrderRepository, userRepository repository.UserRepository, cartRepository repository.CartRepository, walletRepository repository.WalletRepository, walletUseCase WalletUseCase, couponRepository repository.CouponRepository) *OrderUseCase {
+func NewOrderUseCase(orderRepository repository.OrderRepository, userRepository repository.UserRepository, cartRepository repository.CartRepository, walletRepository repository.WalletRepository, walletUseCase WalletUseCase, giftCardUseCase GiftCardUseCase, couponRepository repository.CouponRepository, reservationUseCase ReservationUseCase, refundUseCase RefundUseCase, orderStatusUseCase OrderStatusUseCase, exchangeUseCase ExchangeUseCase, returnRepository repository.ReturnRepository, invoiceUseCase InvoiceUseCase, shippingUseCase ShippingUseCase, deliveryRuleUseCase DeliveryRuleUseCase, cfg config.Config) *OrderUseCase {
 	return &OrderUseCase{
-		orderRepository:  orderRepository,
-		userRepository:   userRepository,
-		cartRepository:   cartRepository,
-		walletRepository: walletRepository,
-		WalletUseCase:    walletUseCase,
-		CouponRepo:       couponRepository,
+		orderRepository:     orderRepository,
+		userRepository:      userRepository,
+		cartRepository:      cartRepository,
+		walletRepository:    walletRepository,
+		WalletUseCase:       walletUseCase,
+		GiftCardUseCase:     giftCardUseCase,
+		CouponRepo:          couponRepository,
+		ReservationUseCase:  reservationUseCase,
+		RefundUseCase:       refundUseCase,
+		OrderStatusUseCase:  orderStatusUseCase,
+		ExchangeUseCase:     exchangeUseCase,
+		returnRepository:    returnRepository,
+		InvoiceUseCase:      invoiceUseCase,
+		ShippingUseCase:     shippingUseCase,
+		DeliveryRuleUseCase: deliveryRuleUseCase,
+		sellerStateCode:     tax.SellerStateCode(cfg.SellerState, cfg.SellerGSTIN),
 	}
 }
 func (o *OrderUseCase) OrderItemsFromCart(order models.Order) (models.Order, error) {
@@ -49,8 +74,11 @@ func (o *OrderUseCase) OrderItemsFromCart(order models.Order) (models.Order, err
 		return models.Order{}, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
+	// Roll back on every way out but a commit, so a refused checkout never
+	// keeps the stock, wallet or gift card rows it locked.
+	committed := false
 	defer func() {
-		if err != nil {
+		if !committed {
 			_ = o.orderRepository.RollbackTransaction(tx)
 		}
 	}()
@@ -60,71 +88,110 @@ func (o *OrderUseCase) OrderItemsFromCart(order models.Order) (models.Order, err
 		return models.Order{}, err
 	}
 
-	var grandTotal float64
-	var rawTotal float64
-	var categoryDiscount float64
+	pin, err := o.orderRepository.GetAddressPin(tx, int(order.AddressID))
+	if err != nil {
+		return models.Order{}, err
+	}
+	serviceability, err := o.ShippingUseCase.Serviceability(pin)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("cannot deliver to pincode %s: %w", pin, err)
+	}
+	if !serviceability.Deliverable {
+		err = fmt.Errorf("we do not deliver to pincode %s yet", pin)
+		return models.Order{}, err
+	}
+
+	var grandTotal money.Money
+	var rawTotal money.Money
+	var categoryDiscount money.Money
+	var units int
 
 	for _, item := range cartItems {
+		units += item.Quantity
 		grandTotal += item.TotalPrice
-		rawTotal += item.Price * float64(item.Quantity)
-		categoryDiscount += float64(item.CategoryDiscount)
+		rawTotal += item.Price.Times(item.Quantity)
+		categoryDiscount += item.CategoryDiscount
 	}
 
 	order.GrandTotal = grandTotal
 	order.RawTotal = rawTotal
 
-	order.FinalPrice = utils.RoundToTwoDecimalPlaces(grandTotal - categoryDiscount)
-	if order.FinalPrice < 0 {
-		order.FinalPrice = 0
-	}
+	order.FinalPrice = grandTotal.Sub(categoryDiscount).NonNegative()
 	order.CategoryDiscount = categoryDiscount
 	order.OrderDate = time.Now()
+	estimatedFrom, estimatedTo := o.ShippingUseCase.EstimateDelivery(order.OrderDate, serviceability.ETADays)
+	order.EstimatedDeliveryFrom = &estimatedFrom
+	order.EstimatedDeliveryTo = &estimatedTo
 
-	deliveryCharge := 0.0
-	if order.FinalPrice < 1000 {
-		deliveryCharge = 50.0
+	delivery, err := o.deliveryRules(tx, order, pin, units)
+	if err != nil {
+		return models.Order{}, err
 	}
-	order.DeliveryCharge = deliveryCharge
-	order.FinalPrice += deliveryCharge
+	order.DeliveryCharge = delivery.DeliveryCharge
+	order.FinalPrice += delivery.DeliveryCharge
 
 	if order.CouponCode != "" {
-		couponData, err := o.CouponRepo.CheckCouponExpired(tx, order.CouponCode)
+		var couponData models.CouponResponse
+		couponData, err = o.CouponRepo.CheckCouponExpired(tx, order.CouponCode)
 		if err != nil {
 			return models.Order{}, fmt.Errorf("failed to fetch coupon details: %w", err)
 		}
 
-		if order.FinalPrice < float64(couponData.MinimumRequired) {
-			return models.Order{}, fmt.Errorf("order price does not meet coupon requirements (Total Price: %f, Coupon: %s, Minimum Required: %d)", order.FinalPrice, order.CouponCode, couponData.MinimumRequired)
+		if order.FinalPrice < couponData.MinimumRequired {
+			err = fmt.Errorf("order price does not meet coupon requirements (Total Price: %s, Coupon: %s, Minimum Required: %s)", order.FinalPrice, order.CouponCode, couponData.MinimumRequired)
+			return models.Order{}, err
 		}
 
 		if couponData.EndDate.Before(time.Now()) {
-			return models.Order{}, errors.New("Coupon has expired")
+			err = errors.New("Coupon has expired")
+			return models.Order{}, err
 		}
 
 		if exist := o.orderRepository.CheckCouponAppliedOrNot(tx, order.UserID, order.CouponCode); exist >= couponData.MaximumUsage {
-			return models.Order{}, fmt.Errorf("coupon %s already applied %d times", order.CouponCode, exist)
+			err = fmt.Errorf("coupon %s already applied %d times", order.CouponCode, exist)
+			return models.Order{}, err
 		}
 
 		order.CouponID = &couponData.ID
 		order.Discount = float64(couponData.Discount)
 
-		discount := (order.GrandTotal * float64(couponData.Discount)) / 100
-		if discount > float64(couponData.MaximumAllowed) {
-			discount = float64(couponData.MaximumAllowed)
-		}
+		discount := money.Min(order.GrandTotal.Percent(float64(couponData.Discount)), couponData.MaximumAllowed)
+		order.FinalPrice = order.FinalPrice.Sub(discount).NonNegative()
+		order.DiscountAmount = discount
+	}
 
-		order.FinalPrice = utils.RoundToTwoDecimalPlaces(order.FinalPrice - discount)
-		if order.FinalPrice < 0 {
-			order.FinalPrice = 0
+	// A gift card pays what it can before the payment method does. The
+	// card stays locked until the order is placed and is redeemed once the
+	// order exists; whatever it does not cover is due by the payment method,
+	// and an order it covers in full is paid by the card alone.
+	if order.GiftCardCode != "" {
+		var giftCard domain.GiftCard
+		giftCard, err = o.GiftCardUseCase.Lock(tx, order.GiftCardCode)
+		if err != nil {
+			return models.Order{}, err
 		}
-
-		order.DiscountAmount = utils.RoundToTwoDecimalPlaces(discount)
+		order.GiftCardAmount = money.Min(giftCard.Balance, order.FinalPrice)
+		if order.GiftCardAmount.IsPositive() {
+			order.GiftCardID = giftCard.ID
+		}
+	}
+	due := order.FinalPrice.Sub(order.GiftCardAmount)
+	if due.IsZero() && order.GiftCardAmount.IsPositive() {
+		order.PaymentMethod = models.GiftCard
 	}
 
+	// Wallet payments are taken once the order exists, so the ledger entry
+	// can name it.
+	var walletDebit money.Money
 	switch order.PaymentMethod {
 	case "COD":
-		if order.FinalPrice > 1000 {
-			return models.Order{}, errors.New("cash on delivery is not allowed for orders above 1000")
+		if !serviceability.CODAllowed {
+			err = fmt.Errorf("cash on delivery is not available for pincode %s", pin)
+			return models.Order{}, err
+		}
+		if !delivery.CODAllowed {
+			err = fmt.Errorf("cash on delivery is not available for this order (%s)", delivery.CODRule)
+			return models.Order{}, err
 		}
 		order.PaymentMethodID = 1
 		order.OrderStatus = "pending"
@@ -136,58 +203,83 @@ func (o *OrderUseCase) OrderItemsFromCart(order models.Order) (models.Order, err
 		order.PaymentStatus = "not paid"
 
 	case "WALLET":
-		userWallet, err := o.orderRepository.GetWalletAmount(tx, order.UserID)
+		var userWallet money.Money
+		userWallet, err = o.orderRepository.GetWalletAmount(tx, order.UserID)
 		if err != nil {
 			return models.Order{}, err
 		}
 
-		if userWallet < order.FinalPrice {
-			return models.Order{}, errors.New("wallet amount is less than total amount")
+		if userWallet < due {
+			err = errors.New("wallet amount is less than total amount")
+			return models.Order{}, err
 		}
 
 		if userWallet < 0 {
-			return models.Order{}, errors.New("wallet amount is invalid")
+			err = errors.New("wallet amount is invalid")
+			return models.Order{}, err
 		}
 
-		neweBalance := userWallet - order.FinalPrice
-		err = o.orderRepository.UpdateWalletAmount(tx, neweBalance, order.UserID)
+		walletDebit = due
+		order.PaymentMethodID = 3
+		order.PaymentStatus = "paid"
+		order.OrderStatus = "success"
+
+	case models.Split:
+		// The wallet pays what it holds now, inside this transaction; the
+		// rest is collected online. If that never happens the reservation
+		// sweeper cancels the order and the wallet share is refunded.
+		var userWallet money.Money
+		userWallet, err = o.orderRepository.GetWalletAmount(tx, order.UserID)
 		if err != nil {
-			return models.Order{}, fmt.Errorf("failed to update wallet: %w", err)
+			return models.Order{}, err
 		}
-
-		walletTxn := models.WalletTransaction{
-			UserID:      order.UserID,
-			Debit:       uint(order.FinalPrice),
-			EventDate:   time.Now(),
-			TotalAmount: uint(neweBalance),
+		if userWallet <= 0 {
+			err = errors.New("wallet is empty; pay online instead")
+			return models.Order{}, err
 		}
-		fmt.Println("TotalAmount", walletTxn.TotalAmount)
-		if err := o.walletRepository.WalletTransaction(tx, walletTxn); err != nil {
-			return models.Order{}, fmt.Errorf("failed to record wallet transaction: %w", err)
+		if userWallet >= due {
+			err = errors.New("wallet covers the whole order; pay with the wallet instead")
+			return models.Order{}, err
 		}
 
-		order.PaymentMethodID = 3
-		order.PaymentStatus = "paid"
-		order.OrderStatus = "success"
+		walletDebit = userWallet
+		order.WalletAmount = userWallet
+		order.PaymentMethodID = models.PaymentMethodSplit
+		order.OrderStatus = models.Pending
+		order.PaymentStatus = models.PaymentNotPaid
+
+	case models.GiftCard:
+		if order.GiftCardAmount.IsZero() || due.IsPositive() {
+			err = errors.New("gift card does not cover the order; choose how to pay the rest")
+			return models.Order{}, err
+		}
+		order.PaymentMethodID = models.PaymentMethodGiftCard
+		order.PaymentStatus = models.PaymentPaid
+		order.OrderStatus = models.Confirm
 
 	default:
-		return models.Order{}, errors.New("unsupported payment method")
+		err = errors.New("unsupported payment method")
+		return models.Order{}, err
 	}
 
 	for _, item := range cartItems {
-		availableStock, err := o.orderRepository.GetProductStock(tx, item.ProductID)
-		if err != nil {
-			return models.Order{}, fmt.Errorf("failed to fetch stock for product ID %d: %w", item.ProductID, err)
+		if item.VariantID != 0 {
+			err = o.orderRepository.DecrementVariantStock(tx, item.VariantID, item.Quantity)
+			if errors.Is(err, repository.ErrInsufficientStock) {
+				return models.Order{}, fmt.Errorf("insufficient stock for variant ID %d", item.VariantID)
+			}
+			if err != nil {
+				return models.Order{}, fmt.Errorf("failed to update stock for variant ID %d: %w", item.VariantID, err)
+			}
+			continue
 		}
 
-		if item.Quantity > availableStock {
+		err = o.orderRepository.DecrementProductStock(tx, item.ProductID, item.Quantity)
+		if errors.Is(err, repository.ErrInsufficientStock) {
 			return models.Order{}, fmt.Errorf("insufficient stock for product ID %d", item.ProductID)
 		}
-
-		newStock := availableStock - item.Quantity
-		err = o.orderRepository.UpdateProductStock(tx, item.ProductID, newStock)
 		if err != nil {
-			return models.Order{}, fmt.Errorf("failed to update stock for product ID %d", item.ProductID)
+			return models.Order{}, fmt.Errorf("failed to update stock for product ID %d: %w", item.ProductID, err)
 		}
 	}
 
@@ -195,24 +287,64 @@ func (o *OrderUseCase) OrderItemsFromCart(order models.Order) (models.Order, err
 	if err != nil {
 		return models.Order{}, err
 	}
+	if order.GiftCardAmount.IsPositive() {
+		err = o.GiftCardUseCase.Redeem(tx, order.GiftCardID, order.GiftCardAmount, orderID)
+		if err != nil {
+			return models.Order{}, fmt.Errorf("failed to redeem gift card: %w", err)
+		}
+	}
+	if walletDebit.IsPositive() {
+		_, err = o.walletRepository.Debit(tx, order.UserID, walletDebit, ledger.AccountSales, ledger.Source{
+			Type:        ledger.SourceOrder,
+			ID:          orderID,
+			Description: "order payment",
+		})
+		if err != nil {
+			return models.Order{}, fmt.Errorf("failed to debit wallet: %w", err)
+		}
+	}
+
+	err = o.OrderStatusUseCase.Record(tx, orderID, "", order.OrderStatus, models.StatusChange{
+		Actor:   models.ActorUser,
+		ActorID: order.UserID,
+		Reason:  "order placed",
+	})
+	if err != nil {
+		return models.Order{}, fmt.Errorf("failed to record order status: %w", err)
+	}
 
 	var orderItems []domain.OrderItem
 	for _, item := range cartItems {
 		orderItems = append(orderItems, domain.OrderItem{
-			OrderID:    orderID,
-			ProductID:  item.ProductID,
-			Quantity:   item.Quantity,
-			TotalPrice: item.TotalPrice,
+			OrderID:          orderID,
+			ProductID:        item.ProductID,
+			VariantID:        item.VariantID,
+			Quantity:         item.Quantity,
+			Price:            item.Price,
+			TotalPrice:       item.TotalPrice,
+			CategoryDiscount: item.CategoryDiscount,
+			Status:           order.OrderStatus,
 		})
 	}
+	allocateCouponDiscount(orderItems, order.DiscountAmount)
+	if err = o.applyGST(tx, orderItems, int(order.AddressID)); err != nil {
+		return models.Order{}, err
+	}
 
 	err = o.orderRepository.CreateOrderItems(tx, orderItems)
 	if err != nil {
 		return models.Order{}, fmt.Errorf("failed to create order items: %w", err)
 	}
 
+	if order.PaymentMethod == models.Online || order.PaymentMethod == models.Split {
+		err = o.ReservationUseCase.Hold(tx, orderID, orderItems)
+		if err != nil {
+			return models.Order{}, fmt.Errorf("failed to reserve stock: %w", err)
+		}
+	}
+
 	for _, item := range cartItems {
-		err := o.cartRepository.RemoveFromCart(item.UserID, item.ProductID)
+		err = o.cartRepository.RemoveFromCart(item.UserID, item.ProductID, item.VariantID)
 		if err != nil {
 			return models.Order{}, err
 		}
@@ -222,6 +354,7 @@ func (o *OrderUseCase) OrderItemsFromCart(order models.Order) (models.Order, err
 	if err != nil {
 		return models.Order{}, fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	committed = true
 
 	orderSuccessResponse, err := o.orderRepository.GetBriefOrderDetails(orderID)
 	if err != nil {
@@ -240,7 +373,7 @@ func (o *OrderUseCase) GetOrderDetails(userID int) ([]models.FullOrderDetails, e
 	return fullOrderDetails, nil
 }
 
-func (o *OrderUseCase) CancelOrders(orderID string, userID int) error {
+func (o *OrderUseCase) CancelOrders(orderID string, userID int, refundTo string) error {
 	orderIDInt, err := strconv.Atoi(orderID)
 	if err != nil {
 		log.Println("1------------", err)
@@ -253,7 +386,7 @@ func (o *OrderUseCase) CancelOrders(orderID string, userID int) error {
 		return err
 	}
 	if userTest != userID {
-		log.Printf("Warning: User %d attempting to cancel order %d belonging to user %d", userID, orderIDInt, userTest)
+		return errors.New("you are not authorized to cancel this order!")
 	}
 
 	tx, err := o.orderRepository.BeginTransaction()
@@ -271,85 +404,40 @@ func (o *OrderUseCase) CancelOrders(orderID string, userID int) error {
 		return err
 	}
 
-	paymentStatus, err := o.orderRepository.GetPaymentStatus(tx, orderID)
-	if err != nil {
-		log.Println("5------------", err)
-		return errors.New("cannot show the payment status")
-	}
-	log.Println("paymentStatus: ", paymentStatus)
-
-	orderStatus, err := o.orderRepository.GetOrderStatus(tx, orderIDInt)
+	err = o.OrderStatusUseCase.Transition(tx, orderIDInt, models.Cancelled, models.StatusChange{
+		Actor:   models.ActorUser,
+		ActorID: userID,
+		Reason:  "cancelled by user",
+	})
 	if err != nil {
-		log.Println("6------------", err)
 		return err
 	}
-	if orderStatus == "delivered" {
-		return errors.New("items already delivered, cannot cancel")
+	if err := o.InvoiceUseCase.CreditNote(tx, orderIDInt, "order cancelled"); err != nil {
+		return fmt.Errorf("failed to issue credit note: %w", err)
 	}
-	if orderStatus == "returned" || orderStatus == "Failed" {
-		return fmt.Errorf("the order is in %s, so no point in cancelling", orderStatus)
-	}
-	if orderStatus == "cancelled" {
-		return errors.New("the order is already cancelled, so no point in cancelling")
-	}
-
-	log.Println("Proceeding with cancellation...")
-
-	err = o.orderRepository.CancelOrders(tx, orderIDInt)
-	if err != nil {
-		log.Println("7------------", err)
-		return err
-	}
-	log.Println("Order status updated to cancelled.")
 
-	err = o.orderRepository.UpdatePaymentStatus(tx, orderIDInt, "refunded")
+	err = o.ReservationUseCase.Release(tx, orderIDInt, "cancelled by user")
 	if err != nil {
-		log.Println("8------------", err)
 		return err
 	}
-	var totalRefundAmount float64
-	log.Println("OrderProductDetails:", orderProductDetails)
-	for _, product := range orderProductDetails {
-		totalRefundAmount += product.FinalPrice
-	}
-	log.Println("totalrefundamount", totalRefundAmount)
 
-	// if totalRefundAmount <= 0 {
-	// 	return errors.New("refund amount is zero; cannot update wallet")
-	// }
-
-	newBalance, err := o.walletRepository.CreateOrUpdateWallet(tx, userID, uint(totalRefundAmount))
+	refund, err := o.RefundUseCase.Initiate(tx, models.RefundRequest{
+		OrderID:     orderIDInt,
+		UserID:      userID,
+		Destination: refundTo,
+		Reason:      "order cancelled",
+	})
 	if err != nil {
 		log.Println("9------------", err)
 		return err
 	}
-	log.Println("New wallet balance after refund:", newBalance)
 
-	walletTxn := models.WalletTransaction{
-		UserID:      userID,
-		Credit:      uint(totalRefundAmount),
-		Debit:       0,
-		EventDate:   time.Now(),
-		TotalAmount: newBalance,
-	}
-
-	err = o.walletRepository.WalletTransaction(tx, walletTxn)
-	if err != nil {
-		log.Println("wallet transaction error:", err)
-		return err
-	}
 	for _, product := range orderProductDetails {
-		availableStock, err := o.orderRepository.GetProductStock(tx, product.ProductID)
+		err = o.restoreStock(tx, product.ProductID, product.VariantID, product.Quantity)
 		if err != nil {
 			log.Println("11------------", err)
 			return err
 		}
-		newStock := availableStock + product.Quantity
-		err = o.orderRepository.UpdateProductStock(tx, product.ProductID, newStock)
-		if err != nil {
-			log.Println("12------------", err)
-			return errors.New("failed to restore product stock")
-		}
 	}
 
 	err = o.orderRepository.UpdateQuantityOfProduct(tx, orderProductDetails)
@@ -364,23 +452,19 @@ func (o *OrderUseCase) CancelOrders(orderID string, userID int) error {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	if err := o.RefundUseCase.Dispatch(refund); err != nil {
+		log.Println("refund will need to be retried:", err)
+	}
 	return nil
 }
 
-func (o *OrderUseCase) CancelOrderItem(orderItemID string, userID int) (domain.OrderItem, error) {
+// CancelOrderItem cancels one line of an order before it is delivered.
+func (o *OrderUseCase) CancelOrderItem(orderItemID string, userID int, refundTo string) (domain.OrderItem, error) {
 	orderItemIDInt, err := strconv.Atoi(orderItemID)
 	if err != nil {
 		return domain.OrderItem{}, fmt.Errorf("invalid order item ID format: %w", err)
 	}
 
-	orderUserID, err := o.orderRepository.UserOrderRelationship(orderItemIDInt, userID)
-	if err != nil {
-		return domain.OrderItem{}, errors.New("order item does not exist")
-	}
-	if orderUserID != userID {
-		return domain.OrderItem{}, errors.New("you are not authorized to return this order!")
-	}
-
 	tx, err := o.orderRepository.BeginTransaction()
 	if err != nil {
 		return domain.OrderItem{}, fmt.Errorf("failed to begin transaction: %w", err)
@@ -389,270 +473,310 @@ func (o *OrderUseCase) CancelOrderItem(orderItemID string, userID int) (domain.O
 		_ = o.orderRepository.RollbackTransaction(tx)
 	}()
 
-	orderItemStatus, err := o.orderRepository.GetOrderStatus(tx, orderItemIDInt)
+	item, err := o.orderRepository.GetOrderItem(tx, orderItemIDInt)
 	if err != nil {
 		return domain.OrderItem{}, err
 	}
-	if orderItemStatus == "cancelled" {
-		return domain.OrderItem{}, errors.New("order item was already cancelled!")
+
+	orderUserID, err := o.orderRepository.UserOrderRelationship(item.OrderID, userID)
+	if err != nil {
+		return domain.OrderItem{}, errors.New("order item does not exist")
 	}
-	if orderItemStatus == "returned" {
-		return domain.OrderItem{}, errors.New("order item already returned!")
+	if orderUserID != userID {
+		return domain.OrderItem{}, errors.New("you are not authorized to change this order!")
 	}
-	if orderItemStatus != "delivered" {
-		return domain.OrderItem{}, errors.New("order item not delivered, cannot cancel!")
+
+	change := models.StatusChange{Actor: models.ActorUser, ActorID: userID, Reason: "order item cancelled"}
+	refund, err := o.closeOrderItem(tx, item, userID, models.Cancelled, refundTo, change)
+	if err != nil {
+		return domain.OrderItem{}, err
 	}
 
-	err = o.orderRepository.CancelOrderItem(tx, orderItemIDInt)
+	err = o.orderRepository.CommitTransaction(tx)
 	if err != nil {
-		return domain.OrderItem{}, errors.New("failed to cancel order item!")
+		return domain.OrderItem{}, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	refundAmount, err := o.orderRepository.GetOrderItemPrice(tx, orderItemIDInt)
+	if err := o.RefundUseCase.Dispatch(refund); err != nil {
+		log.Println("refund will need to be retried:", err)
+	}
+
+	item.Status = models.Cancelled
+	return item, nil
+}
+
+// closeOrderItem cancels or returns a single line inside tx. The line's
+// stock goes back and its share of the amount paid, discounts included, is
+// refunded to userID. While other lines stay open the order's totals shrink
+// by the line; when it was the last one the order follows it and the rest of
+// the payment, delivery charge included, is refunded. The refund is
+// returned for Dispatch once tx commits.
+func (o *OrderUseCase) closeOrderItem(tx *gorm.DB, item domain.OrderItem, userID int, to, refundTo string, change models.StatusChange) (domain.Refund, error) {
+	_, err := o.OrderStatusUseCase.TransitionItem(tx, item.ID, to, change)
 	if err != nil {
-		return domain.OrderItem{}, err
+		return domain.Refund{}, err
 	}
 
-	newBalance, err := o.walletRepository.CreateOrUpdateWallet(tx, userID, uint(refundAmount))
+	err = o.restoreStock(tx, item.ProductID, item.VariantID, item.Quantity)
 	if err != nil {
-		return domain.OrderItem{}, err
+		return domain.Refund{}, err
+	}
+	err = o.ReservationUseCase.ReleaseItem(tx, item.OrderID, item.ProductID, item.VariantID, change.Reason)
+	if err != nil {
+		return domain.Refund{}, err
 	}
-	walletTxn := models.WalletTransaction{
+
+	orderStatus, err := o.OrderStatusUseCase.SyncOrderStatus(tx, item.OrderID, change)
+	if err != nil {
+		return domain.Refund{}, err
+	}
+
+	if err := o.InvoiceUseCase.CreditNote(tx, item.OrderID, change.Reason); err != nil {
+		return domain.Refund{}, fmt.Errorf("failed to issue credit note: %w", err)
+	}
+
+	refundRequest := models.RefundRequest{
+		OrderID:     item.OrderID,
 		UserID:      userID,
-		Credit:      uint(refundAmount),
-		Debit:       0,
-		EventDate:   time.Now(),
-		TotalAmount: newBalance,
+		Destination: refundTo,
+		Reason:      change.Reason,
+	}
+	if orderStatus != "" {
+		return o.RefundUseCase.Initiate(tx, refundRequest)
 	}
 
-	err = o.walletRepository.WalletTransaction(tx, walletTxn)
+	// The refund is split against the order's totals before the line comes
+	// off them.
+	refundRequest.OrderItemID = item.ID
+	refundRequest.Amount = itemRefundAmount(item)
+	refund, err := o.RefundUseCase.Initiate(tx, refundRequest)
 	if err != nil {
-		return domain.OrderItem{}, err
+		return domain.Refund{}, err
+	}
+	err = o.orderRepository.ReduceOrderTotals(tx, item)
+	if err != nil {
+		return domain.Refund{}, fmt.Errorf("failed to update order totals: %w", err)
 	}
+	return refund, nil
+}
+
+// itemRefundAmount is what the customer paid for an order line: its price
+// less its share of the category and coupon discounts.
+func itemRefundAmount(item domain.OrderItem) money.Money {
+	return item.TotalPrice.Sub(item.CategoryDiscount).Sub(item.CouponDiscount).NonNegative()
+}
 
-	prodctID, quantity, err := o.orderRepository.GetOrderItemDetails(tx, orderItemIDInt)
+// returnOrder marks a whole delivered order returned inside tx, puts its
+// open lines back on stock and refunds what was paid to userID. The refund
+// is returned for Dispatch once tx commits.
+func (o *OrderUseCase) returnOrder(tx *gorm.DB, orderID, userID int, refundTo string, change models.StatusChange) (domain.Refund, error) {
+	orderProductDetails, err := o.orderRepository.GetProductDetailsFromOrders(tx, orderID)
 	if err != nil {
-		return domain.OrderItem{}, err
+		return domain.Refund{}, err
 	}
 
-	availableStock, err := o.orderRepository.GetProductStock(tx, prodctID)
+	err = o.OrderStatusUseCase.Transition(tx, orderID, models.Return, change)
 	if err != nil {
-		return domain.OrderItem{}, err
+		return domain.Refund{}, err
 	}
-	newStock := availableStock + quantity
-	err = o.orderRepository.UpdateProductStock(tx, prodctID, newStock)
+	if err := o.InvoiceUseCase.CreditNote(tx, orderID, "order returned"); err != nil {
+		return domain.Refund{}, fmt.Errorf("failed to issue credit note: %w", err)
+	}
+
+	refund, err := o.RefundUseCase.Initiate(tx, models.RefundRequest{
+		OrderID:     orderID,
+		UserID:      userID,
+		Destination: refundTo,
+		Reason:      "order returned",
+	})
 	if err != nil {
-		return domain.OrderItem{}, errors.New("failed to restore product stock")
+		return domain.Refund{}, err
 	}
 
-	err = o.orderRepository.CommitTransaction(tx)
+	for _, product := range orderProductDetails {
+		err = o.restoreStock(tx, product.ProductID, product.VariantID, product.Quantity)
+		if err != nil {
+			return domain.Refund{}, err
+		}
+	}
+
+	err = o.orderRepository.UpdateQuantityOfProduct(tx, orderProductDetails)
 	if err != nil {
-		return domain.OrderItem{}, fmt.Errorf("failed to commit transaction: %w", err)
+		return domain.Refund{}, err
 	}
-	return domain.OrderItem{}, nil
+	return refund, nil
 }
 
-func (o *OrderUseCase) ReturnUserOrder(orderID string, userID int) error {
+func (o *OrderUseCase) GetOrderTimeline(orderID string, userID int) ([]domain.OrderStatusHistory, error) {
 	orderIDInt, err := strconv.Atoi(orderID)
 	if err != nil {
-		return fmt.Errorf("invalid order ID format: %w", err)
+		return nil, fmt.Errorf("invalid order ID format: %w", err)
 	}
 
 	orderUserID, err := o.orderRepository.UserOrderRelationship(orderIDInt, userID)
-	if err != nil {
-		return errors.New("order item does not exist")
+	if err != nil || orderUserID == 0 {
+		return nil, errors.New("order does not exist")
 	}
 	if orderUserID != userID {
-		return errors.New("you are not authorized to return this order!")
+		return nil, errors.New("you are not authorized to view this order!")
 	}
+	return o.OrderStatusUseCase.GetTimeline(orderIDInt)
+}
 
-	tx, err := o.orderRepository.BeginTransaction()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer func() {
-		_ = o.orderRepository.RollbackTransaction(tx)
-	}()
+func (o *OrderUseCase) GetRefunds(userID int) ([]domain.Refund, error) {
+	return o.RefundUseCase.GetUserRefunds(userID)
+}
+
+func (o *OrderUseCase) RequestExchange(userID int, req models.ExchangeRequest) (domain.Exchange, error) {
+	return o.ExchangeUseCase.Request(userID, req)
+}
+
+func (o *OrderUseCase) VerifyExchangePayment(userID int, payment models.ExchangePayment) (domain.Exchange, error) {
+	return o.ExchangeUseCase.VerifyPayment(userID, payment)
+}
+
+func (o *OrderUseCase) GetExchanges(userID int) ([]domain.Exchange, error) {
+	return o.ExchangeUseCase.GetUserExchanges(userID)
+}
 
-	orderStatus, err := o.orderRepository.GetOrderStatus(tx, orderIDInt)
+// deliveryRules quotes the carrier for the cart's weight to the order's
+// pincode and runs the delivery rules over the cart, which decide the
+// delivery charge and whether cash on delivery is offered.
+func (o *OrderUseCase) deliveryRules(tx *gorm.DB, order models.Order, pin string, units int) (rules.Outcome, error) {
+	weight, err := o.orderRepository.GetCartWeight(tx, order.UserID, shipping.DefaultItemWeightGrams)
 	if err != nil {
-		return err
-	}
-	if orderStatus == "cancelled" {
-		return errors.New("order was cancelled, return not possible!")
-	}
-	if orderStatus == "returned" {
-		return errors.New("order returned already!")
+		return rules.Outcome{}, err
 	}
-	if orderStatus != "delivered" {
-		return errors.New("order not delivered!")
+	quote, err := o.ShippingUseCase.Quote(pin, weight, order.PaymentMethod == models.COD, order.FinalPrice)
+	if err != nil {
+		return rules.Outcome{}, fmt.Errorf("failed to quote delivery: %w", err)
 	}
-
-	err = o.orderRepository.UpdateUserOrderReturn(tx, orderIDInt, userID)
+	delivered, err := o.orderRepository.CountDeliveredOrders(tx, order.UserID)
 	if err != nil {
-		return errors.New("return failed!")
+		return rules.Outcome{}, err
 	}
 
-	orderProductDetails, err := o.orderRepository.GetProductDetailsFromOrders(tx, orderIDInt)
+	return o.DeliveryRuleUseCase.Evaluate(rules.Cart{
+		Value:         order.FinalPrice,
+		Items:         units,
+		Zone:          quote.Zone,
+		UserTier:      rules.Tier(delivered),
+		PaymentMethod: order.PaymentMethod,
+		CarrierFee:    money.Paise(quote.Total),
+	})
+}
+
+// applyGST fixes the HSN code and GST breakup of each line from its
+// product's category. Tax is carved out of what the customer pays for the
+// line, and is CGST plus SGST when the address is in the seller's state.
+func (o *OrderUseCase) applyGST(tx *gorm.DB, items []domain.OrderItem, addressID int) error {
+	state, err := o.orderRepository.GetAddressState(tx, addressID)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to fetch address state: %w", err)
 	}
+	intraState := tax.IntraState(o.sellerStateCode, state)
 
-	var totalRefundAmount float64
+	for i := range items {
+		productTax, err := o.orderRepository.GetProductTax(tx, items[i].ProductID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch tax details for product ID %d: %w", items[i].ProductID, err)
+		}
+		amount := itemRefundAmount(items[i])
+		var unitValue money.Money
+		if items[i].Quantity > 0 {
+			unitValue = amount.MulDiv(1, int64(items[i].Quantity))
+		}
+		rate := tax.Rate(productTax.GSTRate, productTax.GSTHigherRate, productTax.GSTThreshold, unitValue)
+		breakup := tax.Split(amount, rate, intraState)
 
-	for _, product := range orderProductDetails {
-		totalRefundAmount += product.FinalPrice
+		items[i].HSNCode = productTax.HSNCode
+		items[i].GSTRate = rate
+		items[i].TaxableValue = breakup.TaxableValue
+		items[i].CGST = breakup.CGST
+		items[i].SGST = breakup.SGST
+		items[i].IGST = breakup.IGST
 	}
+	return nil
+}
 
-	// if totalRefundAmount <= 0 {
-	// 	return errors.New("refund amount is zero; cannot update wallet")
-	// }
+// allocateCouponDiscount spreads the order's coupon discount over its lines
+// in proportion to their price, so each line can be refunded on its own.
+// The shares add up to the discount to the paisa.
+func allocateCouponDiscount(items []domain.OrderItem, discount money.Money) {
+	if !discount.IsPositive() || len(items) == 0 {
+		return
+	}
+	weights := make([]money.Money, len(items))
+	for i, item := range items {
+		weights[i] = item.TotalPrice
+	}
+	for i, share := range discount.Allocate(weights...) {
+		items[i].CouponDiscount = share
+	}
+}
 
-	newBalance, err := o.walletRepository.CreateOrUpdateWallet(tx, userID, uint(totalRefundAmount))
-	if err != nil {
-		return err
+// restoreStock puts cancelled or returned quantity back on the variant it
+// was sold from, falling back to the product for items without a variant.
+func (o *OrderUseCase) restoreStock(tx *gorm.DB, productID, variantID, quantity int) error {
+	if variantID != 0 {
+		if err := o.orderRepository.IncrementVariantStock(tx, variantID, quantity); err != nil {
+			return errors.New("failed to restore variant stock")
+		}
+		return nil
 	}
-	walletTxn := models.WalletTransaction{
-		UserID:      userID,
-		Credit:      uint(totalRefundAmount),
-		Debit:       0,
-		EventDate:   time.Now(),
-		TotalAmount: newBalance,
+
+	if err := o.orderRepository.IncrementProductStock(tx, productID, quantity); err != nil {
+		return errors.New("failed to restore product stock")
 	}
+	return nil
+}
 
-	err = o.walletRepository.WalletTransaction(tx, walletTxn)
+// GenerateInvoice returns the order's tax invoice, issuing it on the first
+// download.
+func (o *OrderUseCase) GenerateInvoice(orderID string, userID int) ([]byte, error) {
+	orderIDInt, err := strconv.Atoi(orderID)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("invalid order ID format: %w", err)
 	}
-	for _, product := range orderProductDetails {
-		availableStock, err := o.orderRepository.GetProductStock(tx, product.ProductID)
-		if err != nil {
-			return err
-		}
-		newStock := availableStock + product.Quantity
-		err = o.orderRepository.UpdateProductStock(tx, product.ProductID, newStock)
-		if err != nil {
-			return errors.New("failed to restore product stock")
-		}
+	return o.InvoiceUseCase.Invoice(orderIDInt, userID)
+}
+
+func (o *OrderUseCase) GetCreditNotes(orderID string, userID int) ([]domain.CreditNote, error) {
+	orderIDInt, err := strconv.Atoi(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID format: %w", err)
 	}
+	return o.InvoiceUseCase.GetCreditNotes(orderIDInt, userID)
+}
 
-	err = o.orderRepository.UpdateQuantityOfProduct(tx, orderProductDetails)
+func (o *OrderUseCase) GetCreditNotePDF(creditNoteID string, userID int) ([]byte, error) {
+	creditNoteIDInt, err := strconv.Atoi(creditNoteID)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("invalid credit note ID format: %w", err)
 	}
+	return o.InvoiceUseCase.GetCreditNotePDF(creditNoteIDInt, userID)
+}
 
-	err = o.orderRepository.CommitTransaction(tx)
+// GetTracking returns the shipment and tracking history of one of the
+// user's orders.
+func (o *OrderUseCase) GetTracking(orderID string, userID int) (domain.Tracking, error) {
+	orderIDInt, err := strconv.Atoi(orderID)
 	if err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return domain.Tracking{}, fmt.Errorf("invalid order ID format: %w", err)
 	}
-	return nil
+	if err := o.InvoiceUseCase.checkOwner(orderIDInt, userID); err != nil {
+		return domain.Tracking{}, err
+	}
+	return o.ShippingUseCase.GetTracking(orderIDInt)
+}
 
+// HandleShippingEvent applies a tracking update pushed by the carrier.
+func (o *OrderUseCase) HandleShippingEvent(body []byte, signature string) error {
+	return o.ShippingUseCase.HandleEvent(body, signature)
 }
 
-func (o *OrderUseCase) GenerateInvoice(orderID string, userID int) (*gofpdf.Fpdf, error) {
-	orderDetails, err := o.orderRepository.FetchOrderDetailsFromDB(orderID)
-	if err != nil {
-		return nil, errors.New("Unable to fetch order Details")
-	}
-
-	log.Println("Order ID:", orderID)
-	log.Println("Fetched Order Status:", orderDetails.OrderStatus)
-
-	if orderDetails.OrderStatus != models.Confirm {
-		return nil, errors.New("order status is not success")
-	}
-
-	invoiceNumber := fmt.Sprintf("INV-%s", orderID)
-	date := time.Now().Format("02 Jan 2006")
-	dueDate := time.Now().AddDate(0, 0, 15).Format("02 Jan 2006")
-
-	// Seller Info
-	sellerInfo := fmt.Sprintf("Axis Bank\nAccount Name: Sole-Spot\nAccount No.: 123-456-7890\nPay by: %v", orderDetails.OrderDate)
-
-	// Buyer Info
-	buyerInfo := fmt.Sprintf("%s\nphone: %v\n%s %s\n%s, %s %s",
-		orderDetails.CustomerName,
-		orderDetails.CustomerPhoneNumber,
-		orderDetails.CustomerAddress.HouseName,
-		orderDetails.CustomerAddress.State,
-		orderDetails.CustomerAddress.Street,
-		orderDetails.CustomerAddress.City,
-		orderDetails.CustomerAddress.Pin,
-	)
-	log.Println("CustomerName:", buyerInfo)
-	// Initialize PDF
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
-
-	// Title
-	pdf.SetFont("Arial", "B", 20)
-	pdf.CellFormat(0, 15, "Tax Invoice", "", 1, "C", false, 0, "")
-	pdf.Ln(10)
-
-	// Invoice and Date
-	pdf.SetFont("Arial", "", 12)
-	pdf.SetXY(130, 20)
-	pdf.CellFormat(0, 6, fmt.Sprintf("Invoice No: %s", invoiceNumber), "", 1, "R", false, 0, "")
-	pdf.SetXY(130, 26)
-	pdf.CellFormat(0, 6, fmt.Sprintf("Date: %s", date), "", 1, "R", false, 0, "")
-	pdf.SetXY(130, 32)
-	pdf.CellFormat(0, 6, fmt.Sprintf("Due Date: %s", dueDate), "", 1, "R", false, 0, "")
-
-	// Billing and Shipping
-	pdf.Ln(15)
-	pdf.SetFont("Arial", "B", 12)
-	pdf.CellFormat(0, 10, "Billed To:", "", 1, "", false, 0, "")
-	pdf.SetFont("Arial", "", 12)
-	pdf.MultiCell(0, 10, sellerInfo, "", "L", false)
-	pdf.Ln(5)
-	pdf.SetFont("Arial", "B", 12)
-	pdf.CellFormat(0, 10, "Shipped To:", "", 1, "", false, 0, "")
-	pdf.SetFont("Arial", "", 12)
-	pdf.MultiCell(0, 10, buyerInfo, "", "L", false)
-
-	// Items Table
-	pdf.Ln(10)
-	pdf.SetFont("Arial", "B", 12)
-	pdf.SetFillColor(230, 230, 230)
-	pdf.CellFormat(80, 10, "Item", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(30, 10, "Quantity", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(40, 10, "Price", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(40, 10, "Total", "1", 1, "C", true, 0, "")
-
-	pdf.SetFont("Arial", "", 12)
-	var subtotal float64
-
-	for _, item := range orderDetails.Items {
-		total := float64(item.Quantity) * item.Price
-		subtotal += total
-		pdf.CellFormat(80, 10, item.Name, "1", 0, "", false, 0, "")
-		pdf.CellFormat(30, 10, fmt.Sprintf("%d", item.Quantity), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", item.Price), "1", 0, "R", false, 0, "")
-		pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", total), "1", 1, "R", false, 0, "")
-	}
-
-	// Additional Charges and Final Total
-	totalOfferAmount := orderDetails.RawAmount - orderDetails.GrandTotal
-	totalDiscountAmount := orderDetails.Discount
-	categoryDiscount := orderDetails.CategoryDiscount
-	totalDeliveryCharge := orderDetails.DeliveryCharge
-	finalGrandTotal := orderDetails.FinalPrice
-
-	// Totals Section
-	pdf.Ln(5)
-	pdf.SetFont("Arial", "B", 12)
-	pdf.CellFormat(150, 10, "Subtotal", "1", 0, "R", false, 0, "")
-	pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", subtotal), "1", 1, "R", false, 0, "")
-	pdf.CellFormat(150, 10, "Total Offer Amount", "1", 0, "R", false, 0, "")
-	pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", totalOfferAmount), "1", 1, "R", false, 0, "")
-	pdf.CellFormat(150, 10, "Total Discount Amount", "1", 0, "R", false, 0, "")
-	pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", totalDiscountAmount), "1", 1, "R", false, 0, "")
-	pdf.CellFormat(150, 10, "Total Category Discount Amount", "1", 0, "R", false, 0, "")
-	pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", categoryDiscount), "1", 1, "R", false, 0, "")
-	pdf.CellFormat(150, 10, "Total Delivery Charge", "1", 0, "R", false, 0, "")
-	pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", totalDeliveryCharge), "1", 1, "R", false, 0, "")
-	pdf.CellFormat(150, 10, "Grand Total", "1", 0, "R", false, 0, "")
-	pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", finalGrandTotal), "1", 1, "R", false, 0, "")
-
-	return pdf, nil
+// CheckPincode tells a shopper whether we deliver to a pincode and which
+// payment methods they can use there.
+func (o *OrderUseCase) CheckPincode(pincode string) (models.PincodeServiceability, error) {
+	return o.ShippingUseCase.Serviceability(pincode)
 }
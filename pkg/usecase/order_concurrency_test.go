@@ -0,0 +1,198 @@
+package usecase_test
+
+import (
+	"ecommerce_clean_arch/pkg/config"
+	"ecommerce_clean_arch/pkg/db"
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/gateway"
+	"ecommerce_clean_arch/pkg/giftcard"
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/shipping"
+	"ecommerce_clean_arch/pkg/usecase"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Test_ConcurrentCheckoutDoesNotOversell and the other tests here need a
+// real Postgres, since sqlmock cannot reproduce row locking. Point
+// TEST_DB_DSN at a scratch database to run them, e.g.
+// "host=localhost user=postgres dbname=sole_spot_test sslmode=disable".
+func Test_ConcurrentCheckoutDoesNotOversell(t *testing.T) {
+	database := openTestDB(t)
+	product := seedProduct(t, database, 1)
+
+	// Every buyer has the last pair in their cart and checks out at once.
+	const buyers = 20
+	orders := make([]models.Order, buyers)
+	for i := range orders {
+		orders[i] = seedBuyer(t, database, product, i)
+		orders[i].PaymentMethod = models.Online
+	}
+
+	orderUseCase := newOrderUseCase(t, database)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		placed   int
+		failures []error
+	)
+	for _, order := range orders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := orderUseCase.OrderItemsFromCart(order)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				placed++
+			case !strings.Contains(err.Error(), "insufficient stock"):
+				failures = append(failures, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, failures)
+	assert.Equal(t, 1, placed)
+
+	stock, err := repository.NewOrderRepository(database).GetProductStock(database, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stock)
+}
+
+// Test_RefusedCheckoutReleasesGiftCard checks that a checkout refused after
+// the gift card was locked rolls back, so the card can still be used.
+func Test_RefusedCheckoutReleasesGiftCard(t *testing.T) {
+	database := openTestDB(t)
+	product := seedProduct(t, database, 5)
+	order := seedBuyer(t, database, product, 0)
+
+	code := fmt.Sprintf("GIFT-LOCK-TEST-%d", order.UserID)
+	card := domain.GiftCard{
+		CodeHash:       giftcard.Hash(code),
+		InitialAmount:  money.Rupees(100),
+		Balance:        money.Rupees(100),
+		PurchaserID:    order.UserID,
+		RecipientEmail: "recipient@example.com",
+		Status:         models.GiftCardActive,
+	}
+	require.NoError(t, database.Create(&card).Error)
+	order.GiftCardCode = code
+
+	orderUseCase := newOrderUseCase(t, database)
+
+	// The buyer's wallet is empty, so the wallet pays nothing after the
+	// card is locked.
+	order.PaymentMethod = models.Wallet
+	_, err := orderUseCase.OrderItemsFromCart(order)
+	require.Error(t, err)
+
+	tx := database.Begin()
+	require.NoError(t, tx.Exec("SET LOCAL lock_timeout = '2s'").Error)
+	_, err = repository.NewGiftCardRepository(database).LockGiftCard(tx, card.ID)
+	require.NoError(t, err, "the refused checkout still holds the gift card lock")
+	require.NoError(t, tx.Rollback().Error)
+
+	order.PaymentMethod = models.Online
+	placed, err := orderUseCase.OrderItemsFromCart(order)
+	require.NoError(t, err)
+	assert.Equal(t, money.Rupees(100), placed.GiftCardAmount)
+
+	require.NoError(t, database.First(&card, card.ID).Error)
+	assert.True(t, card.Balance.IsZero())
+}
+
+// openTestDB connects to the database named by TEST_DB_DSN and migrates it,
+// skipping the test when none is set.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN not set; skipping test against Postgres")
+	}
+
+	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(database))
+	require.NoError(t, database.Save(&domain.ServiceablePincode{Pincode: testPin, Deliverable: true, CODAllowed: true, ETADays: 3}).Error)
+	return database
+}
+
+const testPin = "682001"
+
+// seedProduct adds a ₹500 product with stock units left.
+func seedProduct(t *testing.T, database *gorm.DB, stock int) domain.Products {
+	t.Helper()
+	category := domain.Category{Category: "checkout-test", HSNCode: "6403", GSTRate: 12}
+	require.NoError(t, database.Create(&category).Error)
+	product := domain.Products{CategoryID: category.ID, Name: "Last Pair", Stock: stock, Price: money.Rupees(500), OfferPrice: money.Rupees(500)}
+	require.NoError(t, database.Create(&product).Error)
+	return product
+}
+
+// seedBuyer adds a customer with an address and one unit of product in
+// their cart, and returns the order they would place.
+func seedBuyer(t *testing.T, database *gorm.DB, product domain.Products, i int) models.Order {
+	t.Helper()
+	run := time.Now().UnixNano()
+	user := models.User{
+		FirstName: "Buyer",
+		LastName:  fmt.Sprint(i),
+		Email:     fmt.Sprintf("buyer%d-%d@example.com", i, run),
+		Phone:     fmt.Sprintf("+91%05d%05d", run%100000, i),
+	}
+	require.NoError(t, database.Create(&user).Error)
+	address := domain.Address{UserID: user.ID, HouseName: "House", Street: "Street", City: "Kochi", District: "Ernakulam", State: "KL", Pin: testPin}
+	require.NoError(t, database.Create(&address).Error)
+	cart := domain.Cart{UserID: user.ID, ProductID: product.ID, Quantity: 1, Price: product.Price, OfferPrice: product.OfferPrice, TotalPrice: product.OfferPrice}
+	require.NoError(t, database.Create(&cart).Error)
+	return models.Order{UserID: user.ID, AddressID: uint(address.ID)}
+}
+
+// newOrderUseCase wires an OrderUseCase the way the server does, with the
+// fake payment gateway and courier.
+func newOrderUseCase(t *testing.T, database *gorm.DB) *usecase.OrderUseCase {
+	t.Helper()
+	cfg := config.Config{PaymentGateway: gateway.Fake, ShippingProvider: shipping.Fake}
+	paymentGateway, err := gateway.New(cfg)
+	require.NoError(t, err)
+	shippingProvider, err := shipping.New(cfg)
+	require.NoError(t, err)
+
+	orderStatusUseCase := usecase.NewOrderStatusUseCase(*repository.NewOrderStatusRepository(database))
+	orderRepo := repository.NewOrderRepository(database)
+	shippingUseCase := usecase.NewShippingUseCase(*repository.NewShippingRepository(database), *orderRepo, *orderStatusUseCase, shippingProvider, cfg)
+	walletRepo := repository.NewWalletRepository(database)
+	walletUseCase := usecase.NewWalletUseCase(*walletRepo, paymentGateway, cfg)
+	giftCardRepo := repository.NewGiftCardRepository(database)
+	giftCardUseCase := usecase.NewGiftCardUseCase(*giftCardRepo, paymentGateway)
+	refundUseCase := usecase.NewRefundUseCase(*repository.NewRefundRepository(database), *walletRepo, *giftCardRepo, paymentGateway)
+	reservationUseCase := usecase.NewReservationUseCase(*repository.NewReservationRepository(database), *orderStatusUseCase, *refundUseCase, cfg)
+	productRepo := repository.NewProductRepository(database)
+	categoryRepo := repository.NewCategoryRepository(database)
+	exchangeUseCase := usecase.NewExchangeUseCase(*repository.NewExchangeRepository(database), *orderRepo, *productRepo, *categoryRepo, *walletRepo, *orderStatusUseCase, *refundUseCase, paymentGateway, cfg)
+	invoiceUseCase := usecase.NewInvoiceUseCase(*repository.NewInvoiceRepository(database), *orderRepo, *repository.NewOrderStatusRepository(database), cfg)
+	deliveryRuleUseCase := usecase.NewDeliveryRuleUseCase(*repository.NewDeliveryRuleRepository(database))
+
+	return usecase.NewOrderUseCase(*orderRepo, *repository.NewUserRepository(database), *repository.NewCartRepository(database), *walletRepo, *walletUseCase,
+		*giftCardUseCase, *repository.NewCouponRepository(database), *reservationUseCase, *refundUseCase, *orderStatusUseCase, *exchangeUseCase,
+		*repository.NewReturnRepository(database), *invoiceUseCase, *shippingUseCase, *deliveryRuleUseCase, cfg)
+}
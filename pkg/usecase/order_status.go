@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidOrderTransition = errors.New("invalid order status transition")
+
+// orderTransitions is the order state machine: every order status and the
+// statuses it may move to. Cancelled, returned and failed orders are final;
+// exchanged only ever applies to a single delivered line.
+var orderTransitions = map[string][]string{
+	models.Pending:   {models.Confirm, models.Shipped, models.Cancelled, models.Failed},
+	models.Confirm:   {models.Shipped, models.Cancelled, models.Failed},
+	models.Shipped:   {models.Delivered, models.Cancelled, models.Failed},
+	models.Delivered: {models.Return, models.Exchanged},
+	models.Cancelled: nil,
+	models.Return:    nil,
+	models.Failed:    nil,
+	models.Exchanged: nil,
+}
+
+// ValidateOrderTransition reports whether an order may move from one status
+// to another. The error wraps ErrInvalidOrderTransition.
+func ValidateOrderTransition(from, to string) error {
+	if _, known := orderTransitions[to]; !known {
+		return fmt.Errorf("%w: unknown order status %q", ErrInvalidOrderTransition, to)
+	}
+	if from == to {
+		return fmt.Errorf("%w: order is already %s", ErrInvalidOrderTransition, from)
+	}
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot move a %s order to %s", ErrInvalidOrderTransition, from, to)
+}
+
+type OrderStatusUseCase struct {
+	orderStatusRepository repository.OrderStatusRepository
+}
+
+func NewOrderStatusUseCase(orderStatusRepository repository.OrderStatusRepository) *OrderStatusUseCase {
+	return &OrderStatusUseCase{orderStatusRepository: orderStatusRepository}
+}
+
+// Transition moves the order, and every line still open on it, to status
+// inside tx and writes the change to its timeline. It fails without
+// changing anything if the state machine does not allow the move.
+func (s *OrderStatusUseCase) Transition(tx *gorm.DB, orderID int, to string, change models.StatusChange) error {
+	from, err := s.orderStatusRepository.LockOrderStatus(tx, orderID)
+	if err != nil {
+		return err
+	}
+	if err := ValidateOrderTransition(from, to); err != nil {
+		return err
+	}
+	if err := s.orderStatusRepository.UpdateOrderStatus(tx, orderID, to); err != nil {
+		return err
+	}
+	if err := s.orderStatusRepository.UpdateOpenItemStatus(tx, orderID, to); err != nil {
+		return err
+	}
+	return s.Record(tx, orderID, from, to, change)
+}
+
+// TransitionItem moves a single order line to status under the same state
+// machine as orders and returns the line's order. Call SyncOrderStatus
+// afterwards so the order reflects its lines.
+func (s *OrderStatusUseCase) TransitionItem(tx *gorm.DB, orderItemID int, to string, change models.StatusChange) (int, error) {
+	orderID, from, err := s.orderStatusRepository.LockOrderItemStatus(tx, orderItemID)
+	if err != nil {
+		return 0, err
+	}
+	if err := ValidateOrderTransition(from, to); err != nil {
+		return 0, err
+	}
+	if err := s.orderStatusRepository.UpdateOrderItemStatus(tx, orderItemID, to); err != nil {
+		return 0, err
+	}
+	return orderID, s.orderStatusRepository.CreateHistory(tx, domain.OrderStatusHistory{
+		OrderID:     orderID,
+		OrderItemID: orderItemID,
+		FromStatus:  from,
+		ToStatus:    to,
+		Actor:       change.Actor,
+		ActorID:     change.ActorID,
+		Reason:      change.Reason,
+		CreatedAt:   time.Now(),
+	})
+}
+
+// SyncOrderStatus derives the order's status from its lines. While any line
+// is open the order keeps its own status; once none is, the order becomes
+// returned if any line was returned and cancelled otherwise. It returns the
+// status the order moved to, or "" if it did not move.
+func (s *OrderStatusUseCase) SyncOrderStatus(tx *gorm.DB, orderID int, change models.StatusChange) (string, error) {
+	statuses, err := s.orderStatusRepository.GetItemStatuses(tx, orderID)
+	if err != nil {
+		return "", err
+	}
+
+	derived := models.Cancelled
+	for _, status := range statuses {
+		switch status {
+		case models.Cancelled:
+		case models.Return:
+			derived = models.Return
+		default:
+			return "", nil
+		}
+	}
+
+	if err := s.Transition(tx, orderID, derived, change); err != nil {
+		return "", err
+	}
+	return derived, nil
+}
+
+// Record writes a status change to the order's timeline without applying
+// it. It is for statuses set as the order is created, or by an update that
+// is already conditional on the current status.
+func (s *OrderStatusUseCase) Record(tx *gorm.DB, orderID int, from, to string, change models.StatusChange) error {
+	return s.orderStatusRepository.CreateHistory(tx, domain.OrderStatusHistory{
+		OrderID:    orderID,
+		FromStatus: from,
+		ToStatus:   to,
+		Actor:      change.Actor,
+		ActorID:    change.ActorID,
+		Reason:     change.Reason,
+		CreatedAt:  time.Now(),
+	})
+}
+
+func (s *OrderStatusUseCase) GetTimeline(orderID int) ([]domain.OrderStatusHistory, error) {
+	return s.orderStatusRepository.GetHistory(orderID)
+}
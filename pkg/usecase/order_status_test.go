@@ -0,0 +1,43 @@
+package usecase_test
+
+import (
+	"ecommerce_clean_arch/pkg/usecase"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidateOrderTransition(t *testing.T) {
+	testCases := map[string]struct {
+		from    string
+		to      string
+		allowed bool
+	}{
+		"paid order is confirmed":           {from: models.Pending, to: models.Confirm, allowed: true},
+		"cod order is shipped":              {from: models.Pending, to: models.Shipped, allowed: true},
+		"shipped order is delivered":        {from: models.Shipped, to: models.Delivered, allowed: true},
+		"delivered order is returned":       {from: models.Delivered, to: models.Return, allowed: true},
+		"confirmed order is cancelled":      {from: models.Confirm, to: models.Cancelled, allowed: true},
+		"pending order is not delivered":    {from: models.Pending, to: models.Delivered, allowed: false},
+		"delivered order is not cancelled":  {from: models.Delivered, to: models.Cancelled, allowed: false},
+		"undelivered order is not returned": {from: models.Shipped, to: models.Return, allowed: false},
+		"cancelled order is final":          {from: models.Cancelled, to: models.Pending, allowed: false},
+		"delivered item is exchanged":       {from: models.Delivered, to: models.Exchanged, allowed: true},
+		"shipped item is not exchanged":     {from: models.Shipped, to: models.Exchanged, allowed: false},
+		"exchanged item is final":           {from: models.Exchanged, to: models.Return, allowed: false},
+		"same status is rejected":           {from: models.Shipped, to: models.Shipped, allowed: false},
+		"legacy return status is unknown":   {from: models.Delivered, to: "return", allowed: false},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := usecase.ValidateOrderTransition(tc.from, tc.to)
+			if tc.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, usecase.ErrInvalidOrderTransition)
+		})
+	}
+}
@@ -1,22 +1,47 @@
 package usecase
 
 import (
-	"ecommerce_clean_arch/pkg/helper"
+	"crypto/sha256"
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/gateway"
+	"ecommerce_clean_arch/pkg/money"
 	"ecommerce_clean_arch/pkg/repository"
 	"ecommerce_clean_arch/pkg/utils/models"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+)
+
+var (
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	// ErrPaidAfterCancellation is returned for a payment that arrived once
+	// the order had been cancelled; it has been sent back to the customer.
+	ErrPaidAfterCancellation = errors.New("the order was cancelled before the payment arrived, so the payment is being refunded")
+	// ErrNotPayableOnline is returned when a gateway payment is asked for
+	// an order that has nothing left to pay online.
+	ErrNotPayableOnline = errors.New("order cannot be paid online")
+)
 
-	"github.com/razorpay/razorpay-go"
+const (
+	latePaymentRefundMsg  = "payment received after the order was cancelled"
+	splitPaymentFailedMsg = "online part of split payment failed"
 )
 
 type PaymentUsecase struct {
-	PaymentRepo repository.PaymentRepository
+	PaymentRepo        repository.PaymentRepository
+	ReservationUseCase ReservationUseCase
+	RefundUseCase      RefundUseCase
+	OrderStatusUseCase OrderStatusUseCase
+	Gateway            gateway.PaymentGateway
+	WalletUseCase      WalletUseCase
+	GiftCardUseCase    GiftCardUseCase
+	ExchangeUseCase    ExchangeUseCase
 }
 
-func NewPaymentUsecase(paymentRepo repository.PaymentRepository) *PaymentUsecase {
-	return &PaymentUsecase{PaymentRepo: paymentRepo}
+func NewPaymentUsecase(paymentRepo repository.PaymentRepository, reservationUseCase ReservationUseCase, refundUseCase RefundUseCase, orderStatusUseCase OrderStatusUseCase, paymentGateway gateway.PaymentGateway, walletUseCase WalletUseCase, giftCardUseCase GiftCardUseCase, exchangeUseCase ExchangeUseCase) *PaymentUsecase {
+	return &PaymentUsecase{PaymentRepo: paymentRepo, ReservationUseCase: reservationUseCase, RefundUseCase: refundUseCase, OrderStatusUseCase: orderStatusUseCase, Gateway: paymentGateway, WalletUseCase: walletUseCase, GiftCardUseCase: giftCardUseCase, ExchangeUseCase: exchangeUseCase}
 }
 
 func (pay *PaymentUsecase) CreatePayment(orderID string) (models.CombinedOrderDetails, string, error) {
@@ -25,29 +50,28 @@ func (pay *PaymentUsecase) CreatePayment(orderID string) (models.CombinedOrderDe
 	if err != nil {
 		return models.CombinedOrderDetails{}, "", fmt.Errorf("failed to fetch order details: %v", err)
 	}
-
-	client := razorpay.NewClient("rzp_test_X9t6tBXI0YHLgE", "YleZMSEez6iOgZYm2RSluiit")
-	if client == nil {
-		return models.CombinedOrderDetails{}, "", errors.New("failed to create Razorpay client")
+	switch {
+	case combinedOrderDetails.PaymentMethodID != models.PaymentMethodOnline && combinedOrderDetails.PaymentMethodID != models.PaymentMethodSplit:
+		return models.CombinedOrderDetails{}, "", fmt.Errorf("%w: it is not paid online", ErrNotPayableOnline)
+	case combinedOrderDetails.OrderStatus == models.Cancelled || combinedOrderDetails.OrderStatus == models.Failed:
+		return models.CombinedOrderDetails{}, "", fmt.Errorf("%w: it is %s", ErrNotPayableOnline, combinedOrderDetails.OrderStatus)
+	case combinedOrderDetails.PaymentStatus != models.PaymentNotPaid && combinedOrderDetails.PaymentStatus != models.PaymentFailed:
+		return models.CombinedOrderDetails{}, "", fmt.Errorf("%w: its payment is %s", ErrNotPayableOnline, combinedOrderDetails.PaymentStatus)
 	}
 
-	data := map[string]interface{}{
-		"amount":   int(combinedOrderDetails.FinalPrice) * 100,
-		"currency": "INR",
-		"receipt":  "some_receipt_id",
+	// A split payment order has its wallet share already, and a gift card
+	// may have paid part of any order; only the rest is collected online.
+	amount := combinedOrderDetails.FinalPrice.Sub(combinedOrderDetails.WalletAmount).Sub(combinedOrderDetails.GiftCardAmount)
+	if !amount.IsPositive() {
+		return models.CombinedOrderDetails{}, "", fmt.Errorf("%w: nothing is left to pay", ErrNotPayableOnline)
 	}
-
-	body, err := client.Order.Create(data, nil)
+	combinedOrderDetails.AmountDue = amount
+	razorPayOrderID, err := pay.Gateway.CreateOrder(amount.Paise(), "INR", "order_"+orderID)
 	if err != nil {
-		return models.CombinedOrderDetails{}, "", fmt.Errorf("razorpay order creation failed: %v", err)
-	}
-
-	razorPayOrderID, ok := body["id"].(string)
-	if !ok {
-		return models.CombinedOrderDetails{}, "", errors.New("failed to retrieve Razorpay order ID")
+		return models.CombinedOrderDetails{}, "", err
 	}
 
-	err = pay.PaymentRepo.AddRazorPayDetails(orderID, razorPayOrderID)
+	err = pay.PaymentRepo.AddRazorPayDetails(orderID, razorPayOrderID, amount)
 	if err != nil {
 		return models.CombinedOrderDetails{}, "", fmt.Errorf("failed to store Razorpay details: %v", err)
 	}
@@ -55,31 +79,292 @@ func (pay *PaymentUsecase) CreatePayment(orderID string) (models.CombinedOrderDe
 	return combinedOrderDetails, razorPayOrderID, nil
 }
 
+// OnlinePaymentVerification checks the checkout widget's signature for one
+// of the order's own gateway orders before recording the payment, so a
+// payment made for something else cannot be replayed against it.
 func (pay *PaymentUsecase) OnlinePaymentVerification(details models.OnlinePaymentVerification) (*[]models.CombinedOrderDetails, error) {
 	status, err := pay.PaymentRepo.CheckPaymentStatus(details.OrderID)
 	if err != nil {
 		return nil, err
 	}
-	if status == "failed" {
-		return nil, errors.New("payment failed in razorpay")
-	}
-	if status == "paid" {
+	if status == models.PaymentPaid {
 		return nil, errors.New("Already paid")
 	}
-	if status == "not paid" {
-		err := pay.PaymentRepo.UpdatePaymentDetails(details.OrderID, details.PaymentID)
-		if err != nil {
+	// A failed attempt does not close the gateway order; the customer may
+	// retry and succeed, so "failed" is handled like "not paid".
+	if status != models.PaymentNotPaid && status != models.PaymentFailed {
+		return nil, fmt.Errorf("no payment is due on this order, it is %s", status)
+	}
+	ownOrder, err := pay.PaymentRepo.HasGatewayOrder(details.OrderID, details.RazorPayOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if !ownOrder {
+		return nil, errors.New("payment is not for this order")
+	}
+	if !pay.Gateway.VerifySignature(details.RazorPayOrderID, details.PaymentID, details.Signature) {
+		return nil, errors.New("payment is unsuccessful")
+	}
+	err = pay.PaymentRepo.UpdatePaymentDetails(details.OrderID, details.RazorPayOrderID, details.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	return pay.markPaid(details.OrderID, details.RazorPayOrderID, details.PaymentID, 0, "payment verified")
+}
+
+func (pay *PaymentUsecase) GetOrderOwner(orderID int) (int, error) {
+	return pay.PaymentRepo.GetOrderUserID(orderID)
+}
+
+// markPaid turns the order's stock hold into a sale, marks it paid and
+// confirms it. Both the browser verification and the captured webhook end
+// here. If the hold was released because the order was cancelled first,
+// the payment is refunded instead and ErrPaidAfterCancellation returned.
+func (pay *PaymentUsecase) markPaid(orderID int, gatewayOrderID, paymentID string, amount money.Money, reason string) (*[]models.CombinedOrderDetails, error) {
+	err := pay.ReservationUseCase.Commit(orderID)
+	if errors.Is(err, ErrReservationExpired) {
+		if err := pay.refundLatePayment(orderID, gatewayOrderID, paymentID, amount); err != nil {
 			return nil, err
 		}
+		return nil, ErrPaidAfterCancellation
 	}
-	result := helper.VerifyPayment(details.RazorPayOrderID, details.PaymentID, details.Signature, "YleZMSEez6iOgZYm2RSluiit")
-	if !result {
-		return nil, errors.New("payment is unsuccessful")
+	if err != nil {
+		return nil, err
 	}
-	orders, err := pay.PaymentRepo.UpdateOnlinePaymentSucess(details.OrderID)
-	log.Println("OrderID is recieved: ", details.OrderID)
+
+	tx, err := pay.PaymentRepo.BeginTransaction()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	orders, err := pay.PaymentRepo.UpdateOnlinePaymentSucess(tx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	err = pay.OrderStatusUseCase.Transition(tx, orderID, models.Confirm, models.StatusChange{
+		Actor:  models.ActorSystem,
+		Reason: reason,
+	})
 	if err != nil {
 		return nil, err
 	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return orders, nil
 }
+
+// refundLatePayment sends a payment captured for a cancelled order back to
+// the card/UPI it came from. amount is used when the gateway order's own
+// amount was not recorded. A redelivered payment is refunded only once.
+func (pay *PaymentUsecase) refundLatePayment(orderID int, gatewayOrderID, paymentID string, amount money.Money) error {
+	stored, err := pay.PaymentRepo.GetGatewayOrderAmount(gatewayOrderID)
+	if err != nil {
+		return err
+	}
+	if stored.IsPositive() {
+		amount = stored
+	}
+	if !amount.IsPositive() {
+		return fmt.Errorf("no amount recorded for gateway order %s", gatewayOrderID)
+	}
+
+	tx, err := pay.PaymentRepo.BeginTransaction()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	userID, err := pay.PaymentRepo.ClaimLatePayment(tx, orderID)
+	if err != nil {
+		return err
+	}
+	if userID == 0 {
+		return nil
+	}
+	refund, err := pay.RefundUseCase.RefundPayment(tx, models.RefundRequest{
+		OrderID: orderID,
+		UserID:  userID,
+		Amount:  amount,
+		Reason:  latePaymentRefundMsg,
+	}, paymentID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// A gateway failure is recorded on the refund for an admin to retry.
+	if err := pay.RefundUseCase.Dispatch(refund); err != nil {
+		log.Printf("refund of late payment %s for order %d failed: %v", paymentID, orderID, err)
+	}
+	return nil
+}
+
+// HandleWebhook verifies and records a gateway webhook, then applies it.
+// Events already processed are acknowledged without being applied again;
+// failed ones are retried when the gateway redelivers them.
+func (pay *PaymentUsecase) HandleWebhook(body []byte, signature, eventID string) error {
+	if !pay.Gateway.VerifyWebhookSignature(body, signature) {
+		return ErrInvalidWebhookSignature
+	}
+
+	var webhook models.RazorpayWebhook
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return fmt.Errorf("invalid webhook body: %w", err)
+	}
+	if eventID == "" {
+		sum := sha256.Sum256(body)
+		eventID = hex.EncodeToString(sum[:])
+	}
+
+	paymentID := webhook.Payload.Payment.Entity.ID
+	if paymentID == "" {
+		paymentID = webhook.Payload.Refund.Entity.PaymentID
+	}
+	created, err := pay.PaymentRepo.CreatePaymentEvent(domain.PaymentEvent{
+		EventID:        eventID,
+		Event:          webhook.Event,
+		GatewayOrderID: webhook.Payload.Payment.Entity.OrderID,
+		PaymentID:      paymentID,
+		Payload:        string(body),
+		Status:         models.PaymentEventReceived,
+	})
+	if err != nil {
+		return err
+	}
+	if !created {
+		existing, err := pay.PaymentRepo.GetPaymentEvent(eventID)
+		if err != nil {
+			return err
+		}
+		if existing.Status != models.PaymentEventFailed {
+			log.Printf("webhook event %s already recorded, skipping", eventID)
+			return nil
+		}
+	}
+
+	handled, err := pay.applyWebhook(webhook)
+	status, errMsg := models.PaymentEventProcessed, ""
+	switch {
+	case err != nil:
+		status, errMsg = models.PaymentEventFailed, err.Error()
+	case !handled:
+		status = models.PaymentEventIgnored
+	}
+	if updateErr := pay.PaymentRepo.UpdatePaymentEventStatus(eventID, status, errMsg); updateErr != nil {
+		log.Println("failed to update payment event status:", updateErr)
+	}
+	return err
+}
+
+func (pay *PaymentUsecase) applyWebhook(webhook models.RazorpayWebhook) (bool, error) {
+	payment := webhook.Payload.Payment.Entity
+
+	switch webhook.Event {
+	case "payment.captured":
+		// Wallet top-ups, gift cards and exchange price differences are paid
+		// through the gateway too but are not orders.
+		if handled, err := pay.WalletUseCase.CaptureTopUp(payment.OrderID, payment.ID); handled || err != nil {
+			return true, err
+		}
+		if handled, err := pay.GiftCardUseCase.CaptureGiftCard(payment.OrderID, payment.ID); handled || err != nil {
+			return true, err
+		}
+		if handled, err := pay.ExchangeUseCase.CapturePayment(payment.OrderID, payment.ID); handled || err != nil {
+			return true, err
+		}
+		orderID, err := pay.PaymentRepo.GetOrderIDByRazorID(payment.OrderID)
+		if err != nil {
+			return false, err
+		}
+		status, err := pay.PaymentRepo.CheckPaymentStatus(orderID)
+		if err != nil {
+			return false, err
+		}
+		if status != models.PaymentNotPaid && status != models.PaymentFailed {
+			return true, nil
+		}
+		if err := pay.PaymentRepo.UpdatePaymentDetails(orderID, payment.OrderID, payment.ID); err != nil {
+			return false, err
+		}
+		_, err = pay.markPaid(orderID, payment.OrderID, payment.ID, money.Paise(payment.Amount), "payment captured")
+		if errors.Is(err, ErrPaidAfterCancellation) {
+			return true, nil
+		}
+		return true, err
+
+	case "payment.failed":
+		if handled, err := pay.WalletUseCase.FailTopUp(payment.OrderID); handled || err != nil {
+			return true, err
+		}
+		// A gift card or an exchange stays unpaid and can still be paid for.
+		if giftCard, err := pay.GiftCardUseCase.IsGiftCardPayment(payment.OrderID); giftCard || err != nil {
+			return true, err
+		}
+		if exchange, err := pay.ExchangeUseCase.IsExchangePayment(payment.OrderID); exchange || err != nil {
+			return true, err
+		}
+		orderID, err := pay.PaymentRepo.GetOrderIDByRazorID(payment.OrderID)
+		if err != nil {
+			return false, err
+		}
+		log.Printf("payment %s for order %d failed: %s", payment.ID, orderID, payment.ErrorDescription)
+		if err := pay.PaymentRepo.MarkPaymentFailed(orderID); err != nil {
+			return false, err
+		}
+		// A split payment took the wallet share at checkout. Rather than
+		// hold it until the reservation expires, the order is cancelled now
+		// and the share returned.
+		methodID, err := pay.PaymentRepo.GetPaymentMethodID(orderID)
+		if err != nil {
+			return false, err
+		}
+		if methodID == models.PaymentMethodSplit {
+			if _, err := pay.ReservationUseCase.ReleaseUnpaid(orderID, splitPaymentFailedMsg); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case "refund.processed":
+		handled, err := pay.RefundUseCase.SettleGatewayRefund(webhook.Payload.Refund.Entity.ID, true, "")
+		if handled || err != nil {
+			return true, err
+		}
+		// Refunds issued from the gateway dashboard have no refund record;
+		// the order is refunded once the whole payment has been returned.
+		orderID, err := pay.PaymentRepo.GetOrderIDByPaymentID(webhook.Payload.Refund.Entity.PaymentID)
+		if err != nil {
+			return false, err
+		}
+		if payment.Amount > 0 && payment.AmountRefunded >= payment.Amount {
+			return true, pay.PaymentRepo.UpdatePaymentStatus(orderID, models.PaymentRefunded)
+		}
+		return true, nil
+
+	case "refund.failed":
+		return pay.RefundUseCase.SettleGatewayRefund(webhook.Payload.Refund.Entity.ID, false, "refund failed at gateway")
+	}
+
+	return false, nil
+}
+
+// CheckoutKey returns the gateway name and the public key the checkout page
+// needs.
+func (pay *PaymentUsecase) CheckoutKey() (string, string) {
+	return pay.Gateway.Name(), pay.Gateway.KeyID()
+}
+
+// SimulatePayment completes checkout against the fake gateway and returns the
+// payment id and signature to post to verification. Other gateways collect
+// payment in the browser, so this fails for them.
+func (pay *PaymentUsecase) SimulatePayment(gatewayOrderID string) (string, string, error) {
+	fake, ok := pay.Gateway.(*gateway.FakeGateway)
+	if !ok {
+		return "", "", errors.New("payments can only be simulated with the fake gateway")
+	}
+	return fake.Pay(gatewayOrderID)
+}
@@ -29,6 +29,8 @@ func (p *ProductUseCase) AddProduct(product models.AddProduct) (models.ProductRe
 		ID:          products.ID,
 		Category_Id: products.Category_Id,
 		Name:        products.Name,
+		Description: products.Description,
+		Brand:       products.Brand,
 		Stock:       products.Stock,
 		Price:       products.Price,
 		Quantity:    products.Quantity,
@@ -62,3 +64,89 @@ func (p *ProductUseCase) DeleteProduct(productID int) error {
 func (p *ProductUseCase) SearchProduct(categoryID string, sortBy string) ([]domain.Products, error) {
 	return p.ProductRepository.GetProductsByCategory(categoryID, sortBy)
 }
+
+func (p *ProductUseCase) SearchProducts(query models.ProductSearchQuery) (models.ProductSearchResponse, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = 20
+	}
+	if query.Limit > 100 {
+		query.Limit = 100
+	}
+	if query.MinPrice < 0 || query.MaxPrice < 0 || (query.MaxPrice > 0 && query.MinPrice > query.MaxPrice) {
+		return models.ProductSearchResponse{}, errors.New("invalid price range")
+	}
+
+	products, total, err := p.ProductRepository.SearchProducts(query)
+	if err != nil {
+		return models.ProductSearchResponse{}, err
+	}
+	facets, err := p.ProductRepository.GetSearchFacets(query)
+	if err != nil {
+		return models.ProductSearchResponse{}, err
+	}
+	if products == nil {
+		products = []models.ProductSearchResult{}
+	}
+
+	return models.ProductSearchResponse{
+		Products:   products,
+		Facets:     facets,
+		Total:      total,
+		Page:       query.Page,
+		Limit:      query.Limit,
+		TotalPages: int((total + int64(query.Limit) - 1) / int64(query.Limit)),
+	}, nil
+}
+
+func (p *ProductUseCase) AddVariant(variant models.AddVariant) (models.VariantResponse, error) {
+	if variant.Stock < 0 || variant.PriceOverride < 0 {
+		return models.VariantResponse{}, errors.New("invalid stock or price override")
+	}
+	if !isValidSizeSystem(variant.SizeSystem) {
+		return models.VariantResponse{}, errors.New("size system must be one of UK, EU or US")
+	}
+
+	product, err := p.ProductRepository.GetProductByID(variant.ProductID)
+	if err != nil || product.ID == 0 {
+		return models.VariantResponse{}, errors.New("product not found")
+	}
+
+	return p.ProductRepository.AddVariant(variant)
+}
+
+func (p *ProductUseCase) UpdateVariant(variant models.AddVariant, variantID int) (models.VariantResponse, error) {
+	if variant.Stock < 0 || variant.PriceOverride < 0 {
+		return models.VariantResponse{}, errors.New("invalid stock or price override")
+	}
+	if !isValidSizeSystem(variant.SizeSystem) {
+		return models.VariantResponse{}, errors.New("size system must be one of UK, EU or US")
+	}
+
+	return p.ProductRepository.UpdateVariant(variant, variantID)
+}
+
+func (p *ProductUseCase) DeleteVariant(variantID int) error {
+	return p.ProductRepository.DeleteVariant(variantID)
+}
+
+func (p *ProductUseCase) GetProductVariants(productID int) ([]models.VariantResponse, error) {
+	variants, err := p.ProductRepository.GetVariantsByProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if variants == nil {
+		return []models.VariantResponse{}, nil
+	}
+	return variants, nil
+}
+
+func isValidSizeSystem(sizeSystem string) bool {
+	switch sizeSystem {
+	case models.SizeUK, models.SizeEU, models.SizeUS:
+		return true
+	}
+	return false
+}
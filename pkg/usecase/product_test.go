@@ -2,6 +2,7 @@ package usecase_test
 
 import (
 	mockrepository "ecommerce_clean_arch/pkg/Mock/MockRepository"
+	"ecommerce_clean_arch/pkg/money"
 	"ecommerce_clean_arch/pkg/usecase"
 
 	"ecommerce_clean_arch/pkg/utils/models"
@@ -32,8 +33,8 @@ func Test_AddProduct(t *testing.T) {
 				Name:       "Nike",
 				Stock:      20,
 				Quantity:   10,
-				Price:      1500,
-				OfferPrice: 1200,
+				Price:      money.Rupees(1500),
+				OfferPrice: money.Rupees(1200),
 			},
 			expectedOutput: models.ProductResponse{
 				ID:          1,
@@ -41,8 +42,8 @@ func Test_AddProduct(t *testing.T) {
 				Name:        "Nike",
 				Stock:       20,
 				Quantity:    10,
-				Price:       1500,
-				OfferPrice:  1200,
+				Price:       money.Rupees(1500),
+				OfferPrice:  money.Rupees(1200),
 			},
 			stub: func(mockProductRepo *mockrepository.MockProductRepository) {
 				mockProductRepo.EXPECT().AddProduct(models.AddProduct{
@@ -51,16 +52,16 @@ func Test_AddProduct(t *testing.T) {
 					Name:       "Nike",
 					Stock:      20,
 					Quantity:   10,
-					Price:      1500,
-					OfferPrice: 1200,
+					Price:      money.Rupees(1500),
+					OfferPrice: money.Rupees(1200),
 				}).Return(models.ProductResponse{
 					ID:          1,
 					Category_Id: 1,
 					Name:        "Nike",
 					Stock:       20,
 					Quantity:    10,
-					Price:       1500,
-					OfferPrice:  1200,
+					Price:       money.Rupees(1500),
+					OfferPrice:  money.Rupees(1200),
 				}, nil)
 			},
 			expectedError: nil,
@@ -73,7 +74,7 @@ func Test_AddProduct(t *testing.T) {
 				Stock:      20,
 				Quantity:   10,
 				Price:      -1500,
-				OfferPrice: 1000,
+				OfferPrice: money.Rupees(1000),
 			},
 			expectedOutput: models.ProductResponse{},
 			stub: func(mockProductRepo *mockrepository.MockProductRepository) {
@@ -88,8 +89,8 @@ func Test_AddProduct(t *testing.T) {
 				Name:       "Nike",
 				Stock:      20,
 				Quantity:   -10,
-				Price:      1500,
-				OfferPrice: 1000,
+				Price:      money.Rupees(1500),
+				OfferPrice: money.Rupees(1000),
 			},
 			expectedOutput: models.ProductResponse{},
 			stub: func(mockProductRepo *mockrepository.MockProductRepository) {
@@ -104,8 +105,8 @@ func Test_AddProduct(t *testing.T) {
 				Name:       "Nike",
 				Stock:      20,
 				Quantity:   10,
-				Price:      1500,
-				OfferPrice: 1000,
+				Price:      money.Rupees(1500),
+				OfferPrice: money.Rupees(1000),
 			},
 			expectedOutput: models.ProductResponse{},
 			stub: func(mockProductRepo *mockrepository.MockProductRepository) {
@@ -152,8 +153,8 @@ func Test_UpdateProduct(t *testing.T) {
 				Name:        "Convacs",
 				Stock:       15,
 				Quantity:    10,
-				Price:       3000,
-				OfferPrice:  2500,
+				Price:       money.Rupees(3000),
+				OfferPrice:  money.Rupees(2500),
 			},
 			productID: 1,
 			expectedOutput: models.ProductResponse{
@@ -162,8 +163,8 @@ func Test_UpdateProduct(t *testing.T) {
 				Name:        "Convacs",
 				Stock:       15,
 				Quantity:    10,
-				Price:       3000,
-				OfferPrice:  2500,
+				Price:       money.Rupees(3000),
+				OfferPrice:  money.Rupees(2500),
 			},
 			stub: func(mockProductRepo *mockrepository.MockProductRepository) {
 				mockProductRepo.EXPECT().UpdateProduct(models.ProductResponse{
@@ -172,16 +173,16 @@ func Test_UpdateProduct(t *testing.T) {
 					Name:        "Convacs",
 					Stock:       15,
 					Quantity:    10,
-					Price:       3000,
-					OfferPrice:  2500,
+					Price:       money.Rupees(3000),
+					OfferPrice:  money.Rupees(2500),
 				}, 1).Return(models.ProductResponse{
 					ID:          1,
 					Category_Id: 1,
 					Name:        "Convacs",
 					Stock:       15,
 					Quantity:    10,
-					Price:       3000,
-					OfferPrice:  2500,
+					Price:       money.Rupees(3000),
+					OfferPrice:  money.Rupees(2500),
 				}, nil)
 			},
 			expectedError: nil,
@@ -194,7 +195,7 @@ func Test_UpdateProduct(t *testing.T) {
 				Stock:       15,
 				Quantity:    10,
 				Price:       -3000,
-				OfferPrice:  2500,
+				OfferPrice:  money.Rupees(2500),
 			},
 			productID:      1,
 			expectedOutput: models.ProductResponse{},
@@ -210,8 +211,8 @@ func Test_UpdateProduct(t *testing.T) {
 				Name:        "Convacs",
 				Stock:       15,
 				Quantity:    -10,
-				Price:       3000,
-				OfferPrice:  2500,
+				Price:       money.Rupees(3000),
+				OfferPrice:  money.Rupees(2500),
 			},
 			productID:      1,
 			expectedOutput: models.ProductResponse{},
@@ -227,8 +228,8 @@ func Test_UpdateProduct(t *testing.T) {
 				Name:        "Convacs",
 				Stock:       15,
 				Quantity:    10,
-				Price:       3000,
-				OfferPrice:  2500,
+				Price:       money.Rupees(3000),
+				OfferPrice:  money.Rupees(2500),
 			},
 			productID:      1,
 			expectedOutput: models.ProductResponse{},
@@ -254,3 +255,189 @@ func Test_UpdateProduct(t *testing.T) {
 		})
 	}
 }
+
+func Test_AddVariant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductRepo := mockrepository.NewMockProductRepository(ctrl)
+	productUseCase := usecase.NewProductUseCase(mockProductRepo)
+
+	testCases := map[string]struct {
+		input          models.AddVariant
+		expectedOutput models.VariantResponse
+		stub           func(mockProductRepo *mockrepository.MockProductRepository)
+		expectedError  error
+	}{
+		"success": {
+			input: models.AddVariant{
+				ProductID:     1,
+				SKU:           "NIKE-UK9-BLK",
+				SizeSystem:    models.SizeUK,
+				Size:          "9",
+				Colour:        "Black",
+				Stock:         5,
+				PriceOverride: money.Rupees(1300),
+			},
+			expectedOutput: models.VariantResponse{
+				ID:            1,
+				ProductID:     1,
+				SKU:           "NIKE-UK9-BLK",
+				SizeSystem:    models.SizeUK,
+				Size:          "9",
+				Colour:        "Black",
+				Stock:         5,
+				PriceOverride: money.Rupees(1300),
+			},
+			stub: func(mockProductRepo *mockrepository.MockProductRepository) {
+				mockProductRepo.EXPECT().GetProductByID(1).Return(models.ProductResponse{ID: 1, Name: "Nike"}, nil)
+				mockProductRepo.EXPECT().AddVariant(gomock.Any()).Return(models.VariantResponse{
+					ID:            1,
+					ProductID:     1,
+					SKU:           "NIKE-UK9-BLK",
+					SizeSystem:    models.SizeUK,
+					Size:          "9",
+					Colour:        "Black",
+					Stock:         5,
+					PriceOverride: money.Rupees(1300),
+				}, nil)
+			},
+			expectedError: nil,
+		},
+		"negative stock": {
+			input: models.AddVariant{
+				ProductID:  1,
+				SKU:        "NIKE-UK9-BLK",
+				SizeSystem: models.SizeUK,
+				Size:       "9",
+				Colour:     "Black",
+				Stock:      -1,
+			},
+			expectedOutput: models.VariantResponse{},
+			stub:           func(mockProductRepo *mockrepository.MockProductRepository) {},
+			expectedError:  errors.New("invalid stock or price override"),
+		},
+		"invalid size system": {
+			input: models.AddVariant{
+				ProductID:  1,
+				SKU:        "NIKE-XX9-BLK",
+				SizeSystem: "XX",
+				Size:       "9",
+				Colour:     "Black",
+				Stock:      5,
+			},
+			expectedOutput: models.VariantResponse{},
+			stub:           func(mockProductRepo *mockrepository.MockProductRepository) {},
+			expectedError:  errors.New("size system must be one of UK, EU or US"),
+		},
+		"product not found": {
+			input: models.AddVariant{
+				ProductID:  2,
+				SKU:        "PUMA-EU42-WHT",
+				SizeSystem: models.SizeEU,
+				Size:       "42",
+				Colour:     "White",
+				Stock:      5,
+			},
+			expectedOutput: models.VariantResponse{},
+			stub: func(mockProductRepo *mockrepository.MockProductRepository) {
+				mockProductRepo.EXPECT().GetProductByID(2).Return(models.ProductResponse{}, nil)
+			},
+			expectedError: errors.New("product not found"),
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tc.stub(mockProductRepo)
+
+			result, err := productUseCase.AddVariant(tc.input)
+
+			if tc.expectedError != nil {
+				assert.EqualError(t, err, tc.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedOutput, result)
+		})
+	}
+}
+
+func Test_SearchProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProductRepo := mockrepository.NewMockProductRepository(ctrl)
+	productUseCase := usecase.NewProductUseCase(mockProductRepo)
+
+	testCases := map[string]struct {
+		input          models.ProductSearchQuery
+		expectedOutput models.ProductSearchResponse
+		stub           func(mockProductRepo *mockrepository.MockProductRepository)
+		expectedError  error
+	}{
+		"defaults page and limit": {
+			input: models.ProductSearchQuery{Keyword: "running shoe"},
+			expectedOutput: models.ProductSearchResponse{
+				Products:   []models.ProductSearchResult{{ID: 1, Name: "Nike Pegasus"}},
+				Facets:     models.ProductFacets{Brands: []models.FacetCount{{Value: "Nike", Count: 45}}},
+				Total:      45,
+				Page:       1,
+				Limit:      20,
+				TotalPages: 3,
+			},
+			stub: func(mockProductRepo *mockrepository.MockProductRepository) {
+				query := models.ProductSearchQuery{Keyword: "running shoe", Page: 1, Limit: 20}
+				mockProductRepo.EXPECT().SearchProducts(query).
+					Return([]models.ProductSearchResult{{ID: 1, Name: "Nike Pegasus"}}, int64(45), nil)
+				mockProductRepo.EXPECT().GetSearchFacets(query).
+					Return(models.ProductFacets{Brands: []models.FacetCount{{Value: "Nike", Count: 45}}}, nil)
+			},
+			expectedError: nil,
+		},
+		"caps limit": {
+			input: models.ProductSearchQuery{Page: 2, Limit: 500},
+			expectedOutput: models.ProductSearchResponse{
+				Products:   []models.ProductSearchResult{},
+				Total:      0,
+				Page:       2,
+				Limit:      100,
+				TotalPages: 0,
+			},
+			stub: func(mockProductRepo *mockrepository.MockProductRepository) {
+				query := models.ProductSearchQuery{Page: 2, Limit: 100}
+				mockProductRepo.EXPECT().SearchProducts(query).Return(nil, int64(0), nil)
+				mockProductRepo.EXPECT().GetSearchFacets(query).Return(models.ProductFacets{}, nil)
+			},
+			expectedError: nil,
+		},
+		"invalid price range": {
+			input:          models.ProductSearchQuery{MinPrice: 5000, MaxPrice: 1000},
+			expectedOutput: models.ProductSearchResponse{},
+			stub:           func(mockProductRepo *mockrepository.MockProductRepository) {},
+			expectedError:  errors.New("invalid price range"),
+		},
+		"database error": {
+			input:          models.ProductSearchQuery{Keyword: "boots"},
+			expectedOutput: models.ProductSearchResponse{},
+			stub: func(mockProductRepo *mockrepository.MockProductRepository) {
+				mockProductRepo.EXPECT().SearchProducts(gomock.Any()).
+					Return(nil, int64(0), errors.New("database error"))
+			},
+			expectedError: errors.New("database error"),
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tc.stub(mockProductRepo)
+
+			result, err := productUseCase.SearchProducts(tc.input)
+
+			if tc.expectedError != nil {
+				assert.EqualError(t, err, tc.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedOutput, result)
+		})
+	}
+}
@@ -0,0 +1,404 @@
+package usecase
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/gateway"
+	"ecommerce_clean_arch/pkg/ledger"
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefundDestination = errors.New("refund destination must be wallet or source")
+	ErrRefundToSourceNotAllowed = errors.New("refund to the original payment method is only available for online payments")
+)
+
+type RefundUseCase struct {
+	refundRepository   repository.RefundRepository
+	walletRepository   repository.WalletRepository
+	giftCardRepository repository.GiftCardRepository
+	gateway            gateway.PaymentGateway
+}
+
+func NewRefundUseCase(refundRepository repository.RefundRepository, walletRepository repository.WalletRepository, giftCardRepository repository.GiftCardRepository, paymentGateway gateway.PaymentGateway) *RefundUseCase {
+	return &RefundUseCase{
+		refundRepository:   refundRepository,
+		walletRepository:   walletRepository,
+		giftCardRepository: giftCardRepository,
+		gateway:            paymentGateway,
+	}
+}
+
+// refundDestination applies the refund policy: online payments go back to
+// the card/UPI by default but the customer may take wallet credit instead;
+// wallet and cash-on-delivery payments can only be refunded to the wallet.
+func refundDestination(paymentMethodID int, gatewayPaymentID, requested string) (string, error) {
+	if requested != "" && requested != models.RefundToWallet && requested != models.RefundToSource {
+		return "", ErrInvalidRefundDestination
+	}
+
+	if paymentMethodID != models.PaymentMethodOnline && paymentMethodID != models.PaymentMethodSplit {
+		if requested == models.RefundToSource {
+			return "", ErrRefundToSourceNotAllowed
+		}
+		return models.RefundToWallet, nil
+	}
+
+	if requested == models.RefundToWallet {
+		return models.RefundToWallet, nil
+	}
+	if gatewayPaymentID == "" {
+		return "", errors.New("no gateway payment recorded for this order")
+	}
+	return models.RefundToSource, nil
+}
+
+// Destination resolves where a refund of the order would go if the customer
+// asked for requested, so a bad choice is caught before any refund is due.
+func (r *RefundUseCase) Destination(tx *gorm.DB, orderID int, requested string) (string, error) {
+	info, err := r.refundRepository.GetOrderPaymentInfo(tx, orderID)
+	if err != nil {
+		return "", err
+	}
+	return refundDestination(info.PaymentMethodID, info.GatewayPaymentID, requested)
+}
+
+// Initiate records the refund owed on an order inside tx. Wallet and gift
+// card refunds are credited immediately; refunds to source are sent to the
+// gateway by Dispatch once tx has committed. Orders that were never paid
+// produce no refund beyond what a gift card paid, and a zero Refund is
+// returned.
+func (r *RefundUseCase) Initiate(tx *gorm.DB, req models.RefundRequest) (domain.Refund, error) {
+	info, err := r.refundRepository.GetOrderPaymentInfo(tx, req.OrderID)
+	if err != nil {
+		return domain.Refund{}, err
+	}
+	if req.OrderItemID == 0 && req.Amount == 0 {
+		req.Amount = info.FinalPrice
+	}
+	if req.UserID == 0 {
+		req.UserID = info.UserID
+	}
+
+	// A gift card's share goes back to the card first, paid or not, since
+	// it was taken when the order was placed. The rest is refunded as if
+	// the order had cost what the card did not pay.
+	var giftCardRefund domain.Refund
+	if info.GiftCardAmount.IsPositive() && req.Amount.IsPositive() {
+		giftCardShare, rest := SplitRefund(req.Amount, info.GiftCardAmount, info.FinalPrice, req.OrderItemID == 0)
+		if giftCardRefund, err = r.refundToGiftCard(tx, req, info.GiftCardID, giftCardShare); err != nil {
+			return domain.Refund{}, err
+		}
+		info.FinalPrice = info.FinalPrice.Sub(info.GiftCardAmount)
+		req.Amount = rest
+	}
+
+	if info.PaymentMethodID == models.PaymentMethodSplit {
+		refund, err := r.initiateSplit(tx, req, info)
+		if err != nil || refund.ID != 0 {
+			return refund, err
+		}
+		return giftCardRefund, nil
+	}
+	if info.PaymentStatus != models.PaymentPaid || req.Amount <= 0 {
+		if giftCardRefund.ID != 0 && req.OrderItemID == 0 && info.PaymentStatus == models.PaymentPaid {
+			if err := r.refundRepository.UpdatePaymentStatus(tx, req.OrderID, models.PaymentRefunded); err != nil {
+				return domain.Refund{}, err
+			}
+		}
+		return giftCardRefund, nil
+	}
+
+	destination, err := refundDestination(info.PaymentMethodID, info.GatewayPaymentID, req.Destination)
+	if err != nil {
+		return domain.Refund{}, err
+	}
+
+	refund, err := r.refundRepository.CreateRefund(tx, domain.Refund{
+		OrderID:          req.OrderID,
+		OrderItemID:      req.OrderItemID,
+		UserID:           req.UserID,
+		Amount:           req.Amount,
+		Destination:      destination,
+		Status:           models.RefundInitiated,
+		Reason:           req.Reason,
+		GatewayPaymentID: info.GatewayPaymentID,
+	})
+	if err != nil {
+		return domain.Refund{}, fmt.Errorf("failed to record refund: %w", err)
+	}
+
+	paymentStatus := models.PaymentRefundInitiated
+	if destination == models.RefundToWallet {
+		if refund, err = r.settleToWallet(tx, refund); err != nil {
+			return domain.Refund{}, err
+		}
+		paymentStatus = models.PaymentRefunded
+	}
+
+	if req.OrderItemID == 0 {
+		if err := r.refundRepository.UpdatePaymentStatus(tx, req.OrderID, paymentStatus); err != nil {
+			return domain.Refund{}, err
+		}
+	}
+	return refund, nil
+}
+
+// SplitRefund divides a refund of amount on a split payment order between
+// the wallet and the online payment, in the proportion the order was paid.
+// A whole-order refund gives the wallet back everything it still holds.
+// A gift card's share of any order is divided off the same way.
+func SplitRefund(amount, walletAmount, finalPrice money.Money, whole bool) (money.Money, money.Money) {
+	walletShare := walletAmount
+	if !whole && finalPrice.IsPositive() {
+		walletShare = money.Min(walletAmount, amount.MulDiv(walletAmount.Paise(), finalPrice.Paise()))
+	}
+	walletShare = money.Min(walletShare, amount)
+	return walletShare, amount.Sub(walletShare)
+}
+
+// initiateSplit refunds a split payment order. The wallet share always goes
+// back to the wallet, even while the online part is unpaid, since it was
+// taken when the order was placed. The online share goes back to the
+// gateway payment unless the customer asked for wallet credit; the source
+// refund, if any, is returned for Dispatch.
+func (r *RefundUseCase) initiateSplit(tx *gorm.DB, req models.RefundRequest, info models.OrderPaymentInfo) (domain.Refund, error) {
+	if req.Destination != "" && req.Destination != models.RefundToWallet && req.Destination != models.RefundToSource {
+		return domain.Refund{}, ErrInvalidRefundDestination
+	}
+	if req.Amount <= 0 {
+		return domain.Refund{}, nil
+	}
+
+	paid := info.PaymentStatus == models.PaymentPaid
+	walletShare, onlineShare := SplitRefund(req.Amount, info.WalletAmount, info.FinalPrice, req.OrderItemID == 0)
+	if !paid {
+		onlineShare = 0
+	}
+	if err := r.refundRepository.ReduceWalletAmount(tx, req.OrderID, walletShare); err != nil {
+		return domain.Refund{}, fmt.Errorf("failed to update order wallet amount: %w", err)
+	}
+
+	destination := models.RefundToWallet
+	if onlineShare > 0 {
+		var err error
+		destination, err = refundDestination(info.PaymentMethodID, info.GatewayPaymentID, req.Destination)
+		if err != nil {
+			return domain.Refund{}, err
+		}
+	}
+	if destination == models.RefundToWallet {
+		walletShare += onlineShare
+		onlineShare = 0
+	}
+
+	var refund domain.Refund
+	if walletShare > 0 {
+		walletReq := req
+		walletReq.Amount = walletShare
+		created, err := r.createRefund(tx, walletReq, models.RefundToWallet, "")
+		if err != nil {
+			return domain.Refund{}, err
+		}
+		if refund, err = r.settleToWallet(tx, created); err != nil {
+			return domain.Refund{}, err
+		}
+	}
+	if onlineShare > 0 {
+		onlineReq := req
+		onlineReq.Amount = onlineShare
+		var err error
+		if refund, err = r.createRefund(tx, onlineReq, models.RefundToSource, info.GatewayPaymentID); err != nil {
+			return domain.Refund{}, err
+		}
+	}
+
+	if req.OrderItemID == 0 && paid {
+		paymentStatus := models.PaymentRefunded
+		if onlineShare > 0 {
+			paymentStatus = models.PaymentRefundInitiated
+		}
+		if err := r.refundRepository.UpdatePaymentStatus(tx, req.OrderID, paymentStatus); err != nil {
+			return domain.Refund{}, err
+		}
+	}
+	return refund, nil
+}
+
+func (r *RefundUseCase) createRefund(tx *gorm.DB, req models.RefundRequest, destination, gatewayPaymentID string) (domain.Refund, error) {
+	refund, err := r.refundRepository.CreateRefund(tx, domain.Refund{
+		OrderID:          req.OrderID,
+		OrderItemID:      req.OrderItemID,
+		UserID:           req.UserID,
+		Amount:           req.Amount,
+		Destination:      destination,
+		Status:           models.RefundInitiated,
+		Reason:           req.Reason,
+		GatewayPaymentID: gatewayPaymentID,
+	})
+	if err != nil {
+		return domain.Refund{}, fmt.Errorf("failed to record refund: %w", err)
+	}
+	return refund, nil
+}
+
+// RefundPayment returns a payment taken outside the order's own checkout,
+// such as the price difference paid on an exchange. It goes back to the
+// gateway payment when there is one and the customer did not ask for
+// wallet credit, and to the wallet otherwise.
+func (r *RefundUseCase) RefundPayment(tx *gorm.DB, req models.RefundRequest, gatewayPaymentID string) (domain.Refund, error) {
+	destination := models.RefundToWallet
+	if gatewayPaymentID != "" && req.Destination != models.RefundToWallet {
+		destination = models.RefundToSource
+	}
+
+	refund, err := r.refundRepository.CreateRefund(tx, domain.Refund{
+		OrderID:          req.OrderID,
+		OrderItemID:      req.OrderItemID,
+		UserID:           req.UserID,
+		Amount:           req.Amount,
+		Destination:      destination,
+		Status:           models.RefundInitiated,
+		Reason:           req.Reason,
+		GatewayPaymentID: gatewayPaymentID,
+	})
+	if err != nil {
+		return domain.Refund{}, fmt.Errorf("failed to record refund: %w", err)
+	}
+
+	if destination == models.RefundToWallet {
+		return r.settleToWallet(tx, refund)
+	}
+	return refund, nil
+}
+
+// refundToGiftCard puts a gift card's share of a refund back on the card.
+// A card that has expired since cannot be spent, so its share goes to the
+// customer's wallet instead.
+func (r *RefundUseCase) refundToGiftCard(tx *gorm.DB, req models.RefundRequest, giftCardID int, amount money.Money) (domain.Refund, error) {
+	if !amount.IsPositive() {
+		return domain.Refund{}, nil
+	}
+	if err := r.refundRepository.ReduceGiftCardAmount(tx, req.OrderID, amount); err != nil {
+		return domain.Refund{}, fmt.Errorf("failed to update order gift card amount: %w", err)
+	}
+	card, err := r.giftCardRepository.LockGiftCard(tx, giftCardID)
+	if err != nil {
+		return domain.Refund{}, err
+	}
+
+	giftCardReq := req
+	giftCardReq.Amount = amount
+	if giftCardExpired(card, time.Now()) {
+		refund, err := r.createRefund(tx, giftCardReq, models.RefundToWallet, "")
+		if err != nil {
+			return domain.Refund{}, err
+		}
+		return r.settleToWallet(tx, refund)
+	}
+
+	refund, err := r.createRefund(tx, giftCardReq, models.RefundToGiftCard, "")
+	if err != nil {
+		return domain.Refund{}, err
+	}
+	_, err = r.giftCardRepository.Restore(tx, giftCardID, amount, req.OrderID, refund.ID, req.Reason)
+	if err != nil {
+		return domain.Refund{}, fmt.Errorf("failed to restore gift card balance: %w", err)
+	}
+	if err := r.refundRepository.MarkRefundProcessed(tx, refund.ID); err != nil {
+		return domain.Refund{}, err
+	}
+	refund.Status = models.RefundProcessed
+	return refund, nil
+}
+
+func (r *RefundUseCase) settleToWallet(tx *gorm.DB, refund domain.Refund) (domain.Refund, error) {
+	_, err := r.walletRepository.Credit(tx, refund.UserID, refund.Amount, ledger.AccountRefunds, ledger.Source{
+		Type:        ledger.SourceRefund,
+		ID:          refund.ID,
+		Description: refund.Reason,
+	})
+	if err != nil {
+		return domain.Refund{}, err
+	}
+	if err := r.refundRepository.MarkRefundProcessed(tx, refund.ID); err != nil {
+		return domain.Refund{}, err
+	}
+	refund.Status = models.RefundProcessed
+	return refund, nil
+}
+
+// Dispatch asks the gateway to refund a source refund. A failure is recorded
+// on the refund so it can be retried; the cancellation that produced it
+// stands either way.
+func (r *RefundUseCase) Dispatch(refund domain.Refund) error {
+	if refund.ID == 0 || refund.Destination != models.RefundToSource || refund.Status == models.RefundProcessed ||
+		refund.GatewayRefundID != "" {
+		return nil
+	}
+
+	result, err := r.gateway.Refund(refund.GatewayPaymentID, refund.Amount.Paise())
+	if err != nil {
+		log.Printf("refund %d for order %d failed at gateway: %v", refund.ID, refund.OrderID, err)
+		if markErr := r.refundRepository.MarkRefundFailed(refund.ID, err.Error()); markErr != nil {
+			log.Println("failed to record refund failure:", markErr)
+		}
+		return err
+	}
+
+	if err := r.refundRepository.SetGatewayRefundID(refund.ID, result.ID); err != nil {
+		return err
+	}
+	if result.Status == gateway.RefundProcessed {
+		return r.refundRepository.MarkGatewayRefundProcessed(refund)
+	}
+	return nil
+}
+
+// Retry sends a failed refund to the gateway again.
+func (r *RefundUseCase) Retry(refundID int) (domain.Refund, error) {
+	refund, err := r.refundRepository.GetRefundByID(refundID)
+	if err != nil {
+		return domain.Refund{}, err
+	}
+	if refund.Status != models.RefundFailed {
+		return domain.Refund{}, fmt.Errorf("only failed refunds can be retried, this one is %s", refund.Status)
+	}
+
+	refund.GatewayRefundID = ""
+	if err := r.Dispatch(refund); err != nil {
+		return domain.Refund{}, err
+	}
+	return r.refundRepository.GetRefundByID(refundID)
+}
+
+// SettleGatewayRefund applies a refund.processed or refund.failed webhook.
+// It reports false when the refund was not initiated through this service.
+func (r *RefundUseCase) SettleGatewayRefund(gatewayRefundID string, processed bool, reason string) (bool, error) {
+	refund, err := r.refundRepository.GetRefundByGatewayRefundID(gatewayRefundID)
+	if err != nil {
+		return false, nil
+	}
+	if processed {
+		if refund.Status == models.RefundProcessed {
+			return true, nil
+		}
+		return true, r.refundRepository.MarkGatewayRefundProcessed(refund)
+	}
+	return true, r.refundRepository.MarkRefundFailed(refund.ID, reason)
+}
+
+func (r *RefundUseCase) GetUserRefunds(userID int) ([]domain.Refund, error) {
+	return r.refundRepository.GetRefundsByUser(userID)
+}
+
+func (r *RefundUseCase) GetRefunds(status string) ([]domain.Refund, error) {
+	return r.refundRepository.GetRefundsByStatus(status)
+}
@@ -0,0 +1,34 @@
+package usecase_test
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/usecase"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SplitRefund(t *testing.T) {
+	testCases := map[string]struct {
+		amount       money.Money
+		walletAmount money.Money
+		finalPrice   money.Money
+		whole        bool
+		wallet       money.Money
+		online       money.Money
+	}{
+		"whole order gives the wallet its share back":  {amount: money.Rupees(1200), walletAmount: money.Rupees(400), finalPrice: money.Rupees(1200), whole: true, wallet: money.Rupees(400), online: money.Rupees(800)},
+		"line refund is split in proportion":           {amount: money.Rupees(600), walletAmount: money.Rupees(400), finalPrice: money.Rupees(1200), wallet: money.Rupees(200), online: money.Rupees(400)},
+		"shares add up to the paisa":                   {amount: money.Paise(9950), walletAmount: money.Rupees(400), finalPrice: money.Rupees(1200), wallet: money.Paise(3317), online: money.Paise(6633)},
+		"wallet share stays within the amount":         {amount: money.Rupees(300), walletAmount: money.Rupees(400), finalPrice: money.Rupees(1200), whole: true, wallet: money.Rupees(300), online: money.Zero},
+		"gift card that paid everything takes it back": {amount: money.Rupees(600), walletAmount: money.Rupees(1200), finalPrice: money.Rupees(1200), wallet: money.Rupees(600), online: money.Zero},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			wallet, online := usecase.SplitRefund(tc.amount, tc.walletAmount, tc.finalPrice, tc.whole)
+			assert.Equal(t, tc.wallet, wallet)
+			assert.Equal(t, tc.online, online)
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"ecommerce_clean_arch/pkg/config"
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReservationTTL = 15 * time.Minute
+	reservationSweepBatch = 100
+	reservationExpiredMsg = "payment window expired"
+)
+
+var ErrReservationExpired = errors.New("the payment window for this order has expired and the order was cancelled")
+
+type ReservationUseCase struct {
+	reservationRepository repository.ReservationRepository
+	OrderStatusUseCase    OrderStatusUseCase
+	RefundUseCase         RefundUseCase
+	ttl                   time.Duration
+}
+
+func NewReservationUseCase(reservationRepository repository.ReservationRepository, orderStatusUseCase OrderStatusUseCase, refundUseCase RefundUseCase, cfg config.Config) *ReservationUseCase {
+	return &ReservationUseCase{
+		reservationRepository: reservationRepository,
+		OrderStatusUseCase:    orderStatusUseCase,
+		RefundUseCase:         refundUseCase,
+		ttl:                   reservationTTL(cfg),
+	}
+}
+
+// reservationTTL is how long stock stays held for a payment that has not
+// arrived yet.
+func reservationTTL(cfg config.Config) time.Duration {
+	ttl := time.Duration(cfg.ReservationTTLMinutes) * time.Minute
+	if ttl <= 0 {
+		return defaultReservationTTL
+	}
+	return ttl
+}
+
+// Hold records a reservation for each order item. The stock itself has
+// already been decremented by the caller inside the same transaction.
+func (r *ReservationUseCase) Hold(tx *gorm.DB, orderID int, items []domain.OrderItem) error {
+	expiresAt := time.Now().Add(r.ttl)
+
+	var reservations []domain.StockReservation
+	for _, item := range items {
+		reservations = append(reservations, domain.StockReservation{
+			OrderID:   orderID,
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Quantity:  item.Quantity,
+			Status:    models.ReservationHeld,
+			ExpiresAt: expiresAt,
+		})
+	}
+	return r.reservationRepository.CreateReservations(tx, reservations)
+}
+
+// Commit turns the order's held stock into a sale. Orders placed without a
+// reservation are accepted as they are. Once the hold has been released,
+// because the order was cancelled or its payment window ran out, it returns
+// ErrReservationExpired and the payment has to be refunded.
+func (r *ReservationUseCase) Commit(orderID int) error {
+	tx, err := r.reservationRepository.BeginTransaction()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	reservations, err := r.reservationRepository.LockReservations(tx, orderID)
+	if err != nil {
+		return err
+	}
+	if len(reservations) == 0 {
+		return nil
+	}
+
+	held := false
+	for _, reservation := range reservations {
+		switch reservation.Status {
+		case models.ReservationCommitted:
+			return nil
+		case models.ReservationHeld:
+			held = true
+		}
+	}
+	if !held {
+		return ErrReservationExpired
+	}
+
+	if err := r.reservationRepository.CommitReservations(tx, orderID); err != nil {
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// Release drops the order's held reservations without touching stock; the
+// caller restores stock itself, as the cancel flows already do.
+func (r *ReservationUseCase) Release(tx *gorm.DB, orderID int, reason string) error {
+	return r.reservationRepository.ReleaseReservations(tx, orderID, reason)
+}
+
+// ReleaseItem drops the hold on a single cancelled order line, so the
+// sweeper does not put its stock back a second time.
+func (r *ReservationUseCase) ReleaseItem(tx *gorm.DB, orderID, productID, variantID int, reason string) error {
+	return r.reservationRepository.ReleaseItemReservation(tx, orderID, productID, variantID, reason)
+}
+
+// ReleaseExpired releases every reservation whose TTL has passed, puts the
+// stock back and cancels the unpaid order, returning the wallet share of a
+// split payment. It returns the number of orders cancelled.
+func (r *ReservationUseCase) ReleaseExpired() (int, error) {
+	orderIDs, err := r.reservationRepository.GetExpiredOrderIDs(time.Now(), reservationSweepBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	cancelled := 0
+	for _, orderID := range orderIDs {
+		ok, err := r.releaseOrder(orderID, reservationExpiredMsg, true)
+		if err != nil {
+			log.Printf("failed to release reservations for order %d: %v", orderID, err)
+			continue
+		}
+		if ok {
+			cancelled++
+		}
+	}
+	return cancelled, nil
+}
+
+// ReleaseUnpaid cancels a pending order that is still unpaid straight away,
+// as the sweeper would once its hold expires: the held stock goes back and
+// the wallet share of a split payment is returned. It reports whether the
+// order was cancelled.
+func (r *ReservationUseCase) ReleaseUnpaid(orderID int, reason string) (bool, error) {
+	return r.releaseOrder(orderID, reason, false)
+}
+
+// releaseOrder releases the order's held reservations, or with onlyExpired
+// just those past their TTL, and cancels the order if it is still unpaid.
+func (r *ReservationUseCase) releaseOrder(orderID int, reason string, onlyExpired bool) (bool, error) {
+	tx, err := r.reservationRepository.BeginTransaction()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	reservations, err := r.reservationRepository.LockReservations(tx, orderID)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	var expired []domain.StockReservation
+	for _, reservation := range reservations {
+		if reservation.Status == models.ReservationHeld && (!onlyExpired || !reservation.ExpiresAt.After(now)) {
+			expired = append(expired, reservation)
+		}
+	}
+	if len(expired) == 0 {
+		return false, nil
+	}
+
+	orderStatus, paymentStatus, err := r.reservationRepository.LockOrderPayment(tx, orderID)
+	if err != nil {
+		return false, err
+	}
+	unpaid := paymentStatus == models.PaymentNotPaid || paymentStatus == models.PaymentFailed
+	if orderStatus != models.Pending || !unpaid {
+		if paymentStatus == models.PaymentPaid {
+			// Paid in the meantime; the hold has become a sale.
+			if err := r.reservationRepository.CommitReservations(tx, orderID); err != nil {
+				return false, err
+			}
+			return false, tx.Commit().Error
+		}
+		// The order moved on without being paid, so nothing was sold and
+		// the order itself is left as it is.
+		if err := r.restoreExpired(tx, orderID, expired, reason); err != nil {
+			return false, err
+		}
+		return false, tx.Commit().Error
+	}
+	err = r.OrderStatusUseCase.Transition(tx, orderID, models.Cancelled, models.StatusChange{
+		Actor:  models.ActorSystem,
+		Reason: reason,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.restoreExpired(tx, orderID, expired, reason); err != nil {
+		return false, err
+	}
+	// Only the wallet share of an unpaid split payment is owed back, so the
+	// refund is settled here and never needs dispatching.
+	if _, err := r.RefundUseCase.Initiate(tx, models.RefundRequest{OrderID: orderID, Reason: reason}); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit().Error
+}
+
+// restoreExpired puts the stock of released holds back and releases the
+// order's reservations.
+func (r *ReservationUseCase) restoreExpired(tx *gorm.DB, orderID int, expired []domain.StockReservation, reason string) error {
+	for _, reservation := range expired {
+		err := r.reservationRepository.RestoreStock(tx, reservation.ProductID, reservation.VariantID, reservation.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+	return r.reservationRepository.ReleaseReservations(tx, orderID, reason)
+}
+
+// StartSweeper runs ReleaseExpired every interval in the background.
+func (r *ReservationUseCase) StartSweeper(interval time.Duration) {
+	startSweeper("reservation sweeper", "orders", interval, r.ReleaseExpired)
+}
+
+// startSweeper calls release every interval in the background and logs how
+// many holds of what it let go.
+func startSweeper(name, what string, interval time.Duration, release func() (int, error)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			cancelled, err := release()
+			if err != nil {
+				log.Println(name+":", err)
+				continue
+			}
+			if cancelled > 0 {
+				log.Printf("%s: cancelled %d expired %s", name, cancelled, what)
+			}
+		}
+	}()
+}
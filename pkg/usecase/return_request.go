@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"time"
+)
+
+var ErrInvalidReturnTransition = errors.New("invalid return status transition")
+
+// returnTransitions is the return request state machine. A return can be
+// rejected up to the point it passes inspection; refunded and rejected
+// returns are final.
+var returnTransitions = map[string][]string{
+	models.ReturnRequested: {models.ReturnApproved, models.ReturnRejected},
+	models.ReturnApproved:  {models.ReturnPickedUp, models.ReturnRejected},
+	models.ReturnPickedUp:  {models.ReturnInspected, models.ReturnRejected},
+	models.ReturnInspected: {models.ReturnRefunded},
+	models.ReturnRefunded:  nil,
+	models.ReturnRejected:  nil,
+}
+
+// ValidateReturnTransition reports whether a return request may move from
+// one status to another. The error wraps ErrInvalidReturnTransition.
+func ValidateReturnTransition(from, to string) error {
+	if _, known := returnTransitions[to]; !known {
+		return fmt.Errorf("%w: unknown return status %q", ErrInvalidReturnTransition, to)
+	}
+	for _, next := range returnTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot move a %s return to %s", ErrInvalidReturnTransition, from, to)
+}
+
+// RequestReturn opens a return of a delivered order, or of one of its lines.
+// The order is left as it is until the return passes inspection.
+func (o *OrderUseCase) RequestReturn(userID int, req models.ReturnRequest) (domain.ReturnRequest, error) {
+	if !slices.Contains(models.ReturnReasonCodes, req.ReasonCode) {
+		return domain.ReturnRequest{}, fmt.Errorf("reason code must be one of %v", models.ReturnReasonCodes)
+	}
+	if req.PhotoURL != "" {
+		photo, err := url.ParseRequestURI(req.PhotoURL)
+		if err != nil || (photo.Scheme != "http" && photo.Scheme != "https") {
+			return domain.ReturnRequest{}, errors.New("photo URL must be an http or https link")
+		}
+	}
+
+	orderUserID, err := o.orderRepository.UserOrderRelationship(req.OrderID, userID)
+	if err != nil || orderUserID == 0 {
+		return domain.ReturnRequest{}, errors.New("order does not exist")
+	}
+	if orderUserID != userID {
+		return domain.ReturnRequest{}, errors.New("you are not authorized to return this order!")
+	}
+
+	tx, err := o.orderRepository.BeginTransaction()
+	if err != nil {
+		return domain.ReturnRequest{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = o.orderRepository.RollbackTransaction(tx)
+	}()
+
+	status, err := o.orderRepository.GetOrderStatus(tx, req.OrderID)
+	if err != nil {
+		return domain.ReturnRequest{}, err
+	}
+	if req.OrderItemID != 0 {
+		item, err := o.orderRepository.GetOrderItem(tx, req.OrderItemID)
+		if err != nil {
+			return domain.ReturnRequest{}, err
+		}
+		if item.OrderID != req.OrderID {
+			return domain.ReturnRequest{}, errors.New("order item does not belong to this order")
+		}
+		status = item.Status
+	}
+	if status != models.Delivered {
+		return domain.ReturnRequest{}, fmt.Errorf("only delivered orders can be returned, this one is %s", status)
+	}
+
+	open, err := o.returnRepository.HasOpenReturn(tx, req.OrderID, req.OrderItemID)
+	if err != nil {
+		return domain.ReturnRequest{}, err
+	}
+	if open {
+		return domain.ReturnRequest{}, errors.New("a return is already in progress for this order")
+	}
+
+	refundTo, err := o.RefundUseCase.Destination(tx, req.OrderID, req.RefundTo)
+	if err != nil {
+		return domain.ReturnRequest{}, err
+	}
+
+	request, err := o.returnRepository.CreateReturnRequest(tx, domain.ReturnRequest{
+		OrderID:     req.OrderID,
+		OrderItemID: req.OrderItemID,
+		UserID:      userID,
+		ReasonCode:  req.ReasonCode,
+		Comments:    req.Comments,
+		PhotoURL:    req.PhotoURL,
+		RefundTo:    refundTo,
+		Status:      models.ReturnRequested,
+	})
+	if err != nil {
+		return domain.ReturnRequest{}, fmt.Errorf("failed to record return request: %w", err)
+	}
+
+	err = o.orderRepository.CommitTransaction(tx)
+	if err != nil {
+		return domain.ReturnRequest{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return request, nil
+}
+
+// UpdateReturnStatus moves a return request along its state machine on an
+// admin's say. Moving an inspected return to refunded is what marks the
+// order or line returned, restocks it and refunds the customer.
+func (o *OrderUseCase) UpdateReturnStatus(returnID int, to string, adminID int, note string) (domain.ReturnRequest, error) {
+	tx, err := o.orderRepository.BeginTransaction()
+	if err != nil {
+		return domain.ReturnRequest{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = o.orderRepository.RollbackTransaction(tx)
+	}()
+
+	request, err := o.returnRepository.LockReturnRequest(tx, returnID)
+	if err != nil {
+		return domain.ReturnRequest{}, err
+	}
+	if err := ValidateReturnTransition(request.Status, to); err != nil {
+		return domain.ReturnRequest{}, err
+	}
+
+	var refund domain.Refund
+	if to == models.ReturnRefunded {
+		change := models.StatusChange{
+			Actor:   models.ActorAdmin,
+			ActorID: adminID,
+			Reason:  fmt.Sprintf("return %d passed inspection", request.ID),
+		}
+		if request.OrderItemID == 0 {
+			refund, err = o.returnOrder(tx, request.OrderID, request.UserID, request.RefundTo, change)
+		} else {
+			var item domain.OrderItem
+			item, err = o.orderRepository.GetOrderItem(tx, request.OrderItemID)
+			if err == nil {
+				refund, err = o.closeOrderItem(tx, item, request.UserID, models.Return, request.RefundTo, change)
+			}
+		}
+		if err != nil {
+			return domain.ReturnRequest{}, err
+		}
+		request.RefundID = refund.ID
+	}
+
+	now := time.Now()
+	switch to {
+	case models.ReturnApproved:
+		request.ApprovedAt = &now
+	case models.ReturnPickedUp:
+		request.PickedUpAt = &now
+	case models.ReturnInspected:
+		request.InspectedAt = &now
+	case models.ReturnRefunded, models.ReturnRejected:
+		request.ResolvedAt = &now
+	}
+	request.Status = to
+	if note != "" {
+		request.AdminNote = note
+	}
+	if err := o.returnRepository.SaveReturnRequest(tx, request); err != nil {
+		return domain.ReturnRequest{}, err
+	}
+
+	err = o.orderRepository.CommitTransaction(tx)
+	if err != nil {
+		return domain.ReturnRequest{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if err := o.RefundUseCase.Dispatch(refund); err != nil {
+		log.Println("refund will need to be retried:", err)
+	}
+	return request, nil
+}
+
+func (o *OrderUseCase) GetReturnRequests(userID int) ([]domain.ReturnRequest, error) {
+	return o.returnRepository.GetReturnRequestsByUser(userID)
+}
+
+func (o *OrderUseCase) GetAllReturnRequests(status string) ([]domain.ReturnRequest, error) {
+	return o.returnRepository.GetReturnRequestsByStatus(status)
+}
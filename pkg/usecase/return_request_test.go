@@ -0,0 +1,41 @@
+package usecase_test
+
+import (
+	"ecommerce_clean_arch/pkg/usecase"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidateReturnTransition(t *testing.T) {
+	testCases := map[string]struct {
+		from    string
+		to      string
+		allowed bool
+	}{
+		"requested return is approved":        {from: models.ReturnRequested, to: models.ReturnApproved, allowed: true},
+		"requested return is rejected":        {from: models.ReturnRequested, to: models.ReturnRejected, allowed: true},
+		"approved return is picked up":        {from: models.ReturnApproved, to: models.ReturnPickedUp, allowed: true},
+		"picked up return is inspected":       {from: models.ReturnPickedUp, to: models.ReturnInspected, allowed: true},
+		"failed inspection rejects":           {from: models.ReturnPickedUp, to: models.ReturnRejected, allowed: true},
+		"inspected return is refunded":        {from: models.ReturnInspected, to: models.ReturnRefunded, allowed: true},
+		"requested return is not refunded":    {from: models.ReturnRequested, to: models.ReturnRefunded, allowed: false},
+		"uncollected return is not inspected": {from: models.ReturnApproved, to: models.ReturnInspected, allowed: false},
+		"inspected return is not rejected":    {from: models.ReturnInspected, to: models.ReturnRejected, allowed: false},
+		"refunded return is final":            {from: models.ReturnRefunded, to: models.ReturnRequested, allowed: false},
+		"same status is rejected":             {from: models.ReturnApproved, to: models.ReturnApproved, allowed: false},
+		"unknown status":                      {from: models.ReturnRequested, to: "cancelled", allowed: false},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := usecase.ValidateReturnTransition(tc.from, tc.to)
+			if tc.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, usecase.ErrInvalidReturnTransition)
+		})
+	}
+}
@@ -0,0 +1,429 @@
+package usecase
+
+import (
+	"ecommerce_clean_arch/pkg/config"
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/shipping"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrCarrierManagedStatus = errors.New("shipped and delivered are set by the carrier's tracking events")
+
+// bookableStatuses are the order statuses a parcel can be booked in. Cash on
+// delivery orders stay pending until they ship.
+var bookableStatuses = []string{models.Pending, models.Confirm}
+
+type ShippingUseCase struct {
+	shippingRepository repository.ShippingRepository
+	orderRepository    repository.OrderRepository
+	OrderStatusUseCase OrderStatusUseCase
+	provider           shipping.ShippingProvider
+	dispatchCutoff     time.Duration
+}
+
+func NewShippingUseCase(shippingRepository repository.ShippingRepository, orderRepository repository.OrderRepository, orderStatusUseCase OrderStatusUseCase, provider shipping.ShippingProvider, cfg config.Config) *ShippingUseCase {
+	dispatchCutoff := shipping.DefaultDispatchCutoff
+	if cfg.DispatchCutoff != "" {
+		cutoff, err := shipping.ParseCutoff(cfg.DispatchCutoff)
+		if err != nil {
+			log.Printf("%v, using the default", err)
+		} else {
+			dispatchCutoff = cutoff
+		}
+	}
+
+	return &ShippingUseCase{
+		shippingRepository: shippingRepository,
+		orderRepository:    orderRepository,
+		OrderStatusUseCase: orderStatusUseCase,
+		provider:           provider,
+		dispatchCutoff:     dispatchCutoff,
+	}
+}
+
+// Quote prices delivery of a parcel to pincode. orderValue is what the
+// courier collects when cod is set.
+func (s *ShippingUseCase) Quote(pincode string, weightGrams int, cod bool, orderValue money.Money) (shipping.Quote, error) {
+	return s.provider.Rate(shipping.RateRequest{
+		DestinationPincode: pincode,
+		WeightGrams:        weightGrams,
+		COD:                cod,
+		OrderValue:         orderValue.Paise(),
+	})
+}
+
+// CarrierManaged reports whether orders are marked shipped and delivered by
+// the carrier's tracking events rather than by hand.
+func (s *ShippingUseCase) CarrierManaged() bool {
+	return s.provider.SendsEvents()
+}
+
+// CreateShipment books an order's parcel with the carrier.
+func (s *ShippingUseCase) CreateShipment(orderID int) (domain.Shipment, error) {
+	tx, err := s.orderRepository.BeginTransaction()
+	if err != nil {
+		return domain.Shipment{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = s.orderRepository.RollbackTransaction(tx)
+	}()
+
+	if _, err := s.OrderStatusUseCase.orderStatusRepository.LockOrderStatus(tx, orderID); err != nil {
+		return domain.Shipment{}, err
+	}
+	existing, err := s.shippingRepository.GetShipment(orderID)
+	if err != nil {
+		return domain.Shipment{}, err
+	}
+	if existing.ID != 0 {
+		return domain.Shipment{}, fmt.Errorf("order is already booked under AWB %s", existing.AWB)
+	}
+
+	details, err := s.shippingRepository.GetShipmentDetails(tx, orderID, shipping.DefaultItemWeightGrams)
+	if err != nil {
+		return domain.Shipment{}, err
+	}
+	if !slices.Contains(bookableStatuses, details.OrderStatus) {
+		return domain.Shipment{}, fmt.Errorf("a %s order cannot be shipped", details.OrderStatus)
+	}
+	cod := details.PaymentMethodID == models.PaymentMethodCOD && details.PaymentStatus != models.PaymentPaid
+	if !cod && details.PaymentStatus != models.PaymentPaid {
+		return domain.Shipment{}, errors.New("order has not been paid for")
+	}
+
+	var codAmount money.Money
+	if cod {
+		codAmount = details.FinalPrice.Sub(details.GiftCardAmount)
+	}
+	booked, err := s.provider.CreateShipment(shipping.ShipmentRequest{
+		OrderID:            orderID,
+		DestinationPincode: details.Pin,
+		WeightGrams:        details.WeightGrams,
+		COD:                cod,
+		CODAmount:          codAmount.Paise(),
+		Name:               details.Name,
+		Phone:              details.Phone,
+		Address: fmt.Sprintf("%s, %s, %s, %s, %s %s",
+			details.HouseName, details.Street, details.City, details.District, details.State, details.Pin),
+	})
+	if err != nil {
+		return domain.Shipment{}, fmt.Errorf("carrier could not book the shipment: %w", err)
+	}
+
+	shipment, err := s.shippingRepository.CreateShipment(tx, domain.Shipment{
+		OrderID:     orderID,
+		Provider:    s.provider.Name(),
+		AWB:         booked.AWB,
+		Zone:        booked.Quote.Zone,
+		WeightGrams: details.WeightGrams,
+		Freight:     money.Paise(booked.Quote.Freight),
+		CODCharge:   money.Paise(booked.Quote.CODCharge),
+		CODAmount:   codAmount,
+		Status:      shipping.StatusCreated,
+	})
+	if err != nil {
+		return domain.Shipment{}, fmt.Errorf("failed to record shipment: %w", err)
+	}
+
+	err = s.orderRepository.CommitTransaction(tx)
+	if err != nil {
+		return domain.Shipment{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return shipment, nil
+}
+
+// HandleEvent applies a carrier's tracking webhook. A pickup marks the
+// order shipped and a delivery marks it delivered, collecting cash on
+// delivery; other events are only recorded. Each status is applied once.
+func (s *ShippingUseCase) HandleEvent(body []byte, signature string) error {
+	event, err := s.provider.ParseEvent(body, signature)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.orderRepository.BeginTransaction()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = s.orderRepository.RollbackTransaction(tx)
+	}()
+
+	shipment, err := s.shippingRepository.LockShipmentByAWB(tx, event.AWB)
+	if err != nil {
+		return err
+	}
+	created, err := s.shippingRepository.CreateShipmentEvent(tx, domain.ShipmentEvent{
+		ShipmentID:  shipment.ID,
+		Status:      event.Status,
+		Location:    event.Location,
+		Description: event.Description,
+		OccurredAt:  event.OccurredAt,
+	})
+	if err != nil {
+		return err
+	}
+	if !created {
+		log.Printf("tracking event %s for %s already recorded, skipping", event.Status, event.AWB)
+		return nil
+	}
+
+	shipment.Status = event.Status
+	if event.Status == shipping.StatusDelivered {
+		shipment.DeliveredAt = &event.OccurredAt
+	}
+	if err := s.shippingRepository.SaveShipment(tx, shipment); err != nil {
+		return err
+	}
+
+	change := models.StatusChange{
+		Actor:  models.ActorSystem,
+		Reason: fmt.Sprintf("carrier: %s at %s (AWB %s)", event.Status, event.Location, event.AWB),
+	}
+	switch event.Status {
+	case shipping.StatusPickedUp:
+		err = s.advanceOrder(tx, shipment.OrderID, models.Shipped, change)
+	case shipping.StatusDelivered:
+		err = s.advanceOrder(tx, shipment.OrderID, models.Shipped, change)
+		if err == nil {
+			err = s.advanceOrder(tx, shipment.OrderID, models.Delivered, change)
+		}
+		if err == nil {
+			err = s.shippingRepository.MarkCODPaid(tx, shipment.OrderID)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.orderRepository.CommitTransaction(tx)
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// advanceOrder moves the order to status unless it is already there or
+// past it, since carriers report late and out of order.
+func (s *ShippingUseCase) advanceOrder(tx *gorm.DB, orderID int, to string, change models.StatusChange) error {
+	from, err := s.OrderStatusUseCase.orderStatusRepository.LockOrderStatus(tx, orderID)
+	if err != nil {
+		return err
+	}
+	if from == to || (to == models.Shipped && from == models.Delivered) {
+		return nil
+	}
+	if err := s.OrderStatusUseCase.Transition(tx, orderID, to, change); err != nil {
+		return err
+	}
+	if to == models.Shipped {
+		return s.ReestimateDelivery(tx, orderID, time.Now())
+	}
+	return nil
+}
+
+// GetTracking returns an order's shipment and its tracking history.
+func (s *ShippingUseCase) GetTracking(orderID int) (domain.Tracking, error) {
+	shipment, err := s.shippingRepository.GetShipment(orderID)
+	if err != nil {
+		return domain.Tracking{}, err
+	}
+	if shipment.ID == 0 {
+		return domain.Tracking{}, errors.New("order has not been shipped yet")
+	}
+	events, err := s.shippingRepository.GetShipmentEvents(shipment.ID)
+	if err != nil {
+		return domain.Tracking{}, err
+	}
+	return domain.Tracking{Shipment: shipment, Events: events}, nil
+}
+
+// EstimateDelivery returns the window an order placed at placedAt reaches
+// a pincode in, given the pincode's transit days.
+func (s *ShippingUseCase) EstimateDelivery(placedAt time.Time, transitDays int) (time.Time, time.Time) {
+	return shipping.EstimateDelivery(placedAt, s.dispatchCutoff, transitDays)
+}
+
+// DeliveryEstimate is EstimateDelivery for an order placed now, looking the
+// pincode up first.
+func (s *ShippingUseCase) DeliveryEstimate(pincode string) (models.DeliveryEstimate, error) {
+	serviceability, err := s.Serviceability(pincode)
+	if err != nil {
+		return models.DeliveryEstimate{}, err
+	}
+	estimate := models.DeliveryEstimate{Pincode: pincode, Deliverable: serviceability.Deliverable}
+	if estimate.Deliverable {
+		estimate.From, estimate.To = s.EstimateDelivery(time.Now(), serviceability.ETADays)
+	}
+	return estimate, nil
+}
+
+// ReestimateDelivery counts an order's delivery window again from the day
+// it shipped.
+func (s *ShippingUseCase) ReestimateDelivery(tx *gorm.DB, orderID int, shippedAt time.Time) error {
+	pin, err := s.shippingRepository.GetOrderPin(tx, orderID)
+	if err != nil {
+		return err
+	}
+	serviceability, err := s.Serviceability(pin)
+	if err != nil {
+		return err
+	}
+	from, to := shipping.EstimateFromDispatch(shippedAt, serviceability.ETADays)
+	return s.shippingRepository.UpdateDeliveryEstimate(tx, orderID, from, to)
+}
+
+// Serviceability looks a pincode up in the serviceability table. Until a
+// table has been uploaded every valid pincode is served, cash on delivery
+// included; after that, pincodes missing from it are not.
+func (s *ShippingUseCase) Serviceability(pincode string) (models.PincodeServiceability, error) {
+	if !shipping.ValidPincode(pincode) {
+		return models.PincodeServiceability{}, shipping.ErrInvalidPincode
+	}
+	result := models.PincodeServiceability{Pincode: pincode}
+
+	entry, err := s.shippingRepository.GetPincode(pincode)
+	if err != nil {
+		return models.PincodeServiceability{}, err
+	}
+	if entry.Pincode != "" {
+		result.Deliverable = entry.Deliverable
+		result.CODAllowed = entry.Deliverable && entry.CODAllowed
+		result.ETADays = entry.ETADays
+	} else {
+		count, err := s.shippingRepository.CountPincodes()
+		if err != nil {
+			return models.PincodeServiceability{}, err
+		}
+		result.Deliverable = count == 0
+		result.CODAllowed = count == 0
+	}
+
+	result.PaymentMethods = []string{}
+	if result.Deliverable {
+		if result.CODAllowed {
+			result.PaymentMethods = append(result.PaymentMethods, models.COD)
+		}
+		result.PaymentMethods = append(result.PaymentMethods, models.Online, models.Wallet, models.Split)
+	}
+	return result, nil
+}
+
+// UploadPincodes loads a serviceability CSV, adding new pincodes and
+// replacing the ones already listed. A bad row rejects the whole file.
+func (s *ShippingUseCase) UploadPincodes(r io.Reader) (int, error) {
+	pincodes, err := ParsePincodeCSV(r)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.orderRepository.BeginTransaction()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = s.orderRepository.RollbackTransaction(tx)
+	}()
+
+	for _, pincode := range pincodes {
+		if err := s.shippingRepository.SavePincode(tx, pincode); err != nil {
+			return 0, fmt.Errorf("failed to save pincode %s: %w", pincode.Pincode, err)
+		}
+	}
+
+	err = s.orderRepository.CommitTransaction(tx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(pincodes), nil
+}
+
+func (s *ShippingUseCase) GetPincodes(prefix string) ([]domain.ServiceablePincode, error) {
+	return s.shippingRepository.GetPincodes(prefix)
+}
+
+// ParsePincodeCSV reads serviceability rows of pincode, deliverable,
+// cod_allowed and eta_days. A header row is skipped, flags accept
+// true/false, yes/no, y/n and 1/0, and an empty ETA means unknown.
+func ParsePincodeCSV(r io.Reader) ([]domain.ServiceablePincode, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	var pincodes []domain.ServiceablePincode
+	seen := make(map[string]int)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "pincode") {
+			continue
+		}
+
+		pincode := strings.TrimSpace(record[0])
+		if !shipping.ValidPincode(pincode) {
+			return nil, fmt.Errorf("line %d: invalid pincode %q", line, pincode)
+		}
+		if first, ok := seen[pincode]; ok {
+			return nil, fmt.Errorf("line %d: pincode %s is already on line %d", line, pincode, first)
+		}
+		seen[pincode] = line
+
+		deliverable, err := parseFlag(record[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: deliverable: %w", line, err)
+		}
+		codAllowed, err := parseFlag(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: cod_allowed: %w", line, err)
+		}
+		if codAllowed && !deliverable {
+			return nil, fmt.Errorf("line %d: cash on delivery cannot be allowed where pincode %s is not deliverable", line, pincode)
+		}
+		var etaDays int
+		if eta := strings.TrimSpace(record[3]); eta != "" {
+			etaDays, err = strconv.Atoi(eta)
+			if err != nil || etaDays < 0 {
+				return nil, fmt.Errorf("line %d: invalid eta_days %q", line, eta)
+			}
+		}
+
+		pincodes = append(pincodes, domain.ServiceablePincode{
+			Pincode:     pincode,
+			Deliverable: deliverable,
+			CODAllowed:  codAllowed,
+			ETADays:     etaDays,
+		})
+	}
+	if len(pincodes) == 0 {
+		return nil, errors.New("CSV has no pincodes")
+	}
+	return pincodes, nil
+}
+
+func parseFlag(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "y", "1":
+		return true, nil
+	case "false", "no", "n", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected yes or no, got %q", value)
+}
@@ -0,0 +1,75 @@
+package usecase_test
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/usecase"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParsePincodeCSV(t *testing.T) {
+	testCases := map[string]struct {
+		csv       string
+		want      []domain.ServiceablePincode
+		expectErr string
+	}{
+		"with header": {
+			csv: "pincode,deliverable,cod_allowed,eta_days\n560001,yes,yes,2\n781001,true,false,7\n",
+			want: []domain.ServiceablePincode{
+				{Pincode: "560001", Deliverable: true, CODAllowed: true, ETADays: 2},
+				{Pincode: "781001", Deliverable: true, CODAllowed: false, ETADays: 7},
+			},
+		},
+		"without header and blank eta": {
+			csv: "110001, y, n,\n744101,0,0,\n",
+			want: []domain.ServiceablePincode{
+				{Pincode: "110001", Deliverable: true},
+				{Pincode: "744101"},
+			},
+		},
+		"invalid pincode": {
+			csv:       "pincode,deliverable,cod_allowed,eta_days\n56001,yes,yes,2\n",
+			expectErr: "line 2: invalid pincode",
+		},
+		"duplicate pincode": {
+			csv:       "560001,yes,yes,2\n560001,yes,no,3\n",
+			expectErr: "line 2: pincode 560001 is already on line 1",
+		},
+		"bad flag": {
+			csv:       "560001,maybe,yes,2\n",
+			expectErr: "line 1: deliverable",
+		},
+		"cod where undeliverable": {
+			csv:       "560001,no,yes,2\n",
+			expectErr: "line 1: cash on delivery cannot be allowed",
+		},
+		"negative eta": {
+			csv:       "560001,yes,yes,-1\n",
+			expectErr: "line 1: invalid eta_days",
+		},
+		"missing column": {
+			csv:       "560001,yes,yes\n",
+			expectErr: "invalid CSV",
+		},
+		"header only": {
+			csv:       "pincode,deliverable,cod_allowed,eta_days\n",
+			expectErr: "CSV has no pincodes",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got, err := usecase.ParsePincodeCSV(strings.NewReader(tc.csv))
+			if tc.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
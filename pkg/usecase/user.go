@@ -17,11 +17,12 @@ import (
 )
 
 type UserUseCase struct {
-	userRepo interfaces.UserRepository
+	userRepo        interfaces.UserRepository
+	shippingUseCase *ShippingUseCase
 }
 
-func NewUserUseCase(userRepo interfaces.UserRepository) *UserUseCase {
-	return &UserUseCase{userRepo: userRepo}
+func NewUserUseCase(userRepo interfaces.UserRepository, shippingUseCase *ShippingUseCase) *UserUseCase {
+	return &UserUseCase{userRepo: userRepo, shippingUseCase: shippingUseCase}
 }
 
 func (uc *UserUseCase) IsEmailExists(email string) bool {
@@ -343,6 +344,12 @@ func (uc *UserUseCase) GetProducts() ([]models.ProductResponse, error) {
 	}
 	return productDetails, nil
 }
+
+// DeliveryEstimate tells a shopper browsing products when an order placed
+// now would reach their pincode.
+func (uc *UserUseCase) DeliveryEstimate(pincode string) (models.DeliveryEstimate, error) {
+	return uc.shippingUseCase.DeliveryEstimate(pincode)
+}
 func (cat *UserUseCase) ListCategory() ([]domain.Category, error) {
 	categoryDetails, err := cat.userRepo.ListCategory()
 	if err != nil {
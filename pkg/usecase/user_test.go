@@ -1,16 +1,25 @@
 package usecase_test
 
 import (
+	"ecommerce_clean_arch/pkg/money"
 	"errors"
+	"regexp"
 	"testing"
 
 	mockrepository "ecommerce_clean_arch/pkg/Mock/MockRepository"
+	"ecommerce_clean_arch/pkg/config"
 	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/shipping"
 	"ecommerce_clean_arch/pkg/usecase"
 	"ecommerce_clean_arch/pkg/utils/models"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 func Test_GetAllAddresses(t *testing.T) {
@@ -18,7 +27,7 @@ func Test_GetAllAddresses(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUserRepo := mockrepository.NewMockUserRepository(ctrl)
-	userUseCase := usecase.NewUserUseCase(mockUserRepo)
+	userUseCase := usecase.NewUserUseCase(mockUserRepo, nil)
 
 	testCases := map[string]struct {
 		userID int
@@ -77,7 +86,7 @@ func Test_GetAllProducts(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockProductRepo := mockrepository.NewMockUserRepository(ctrl)
-	userUseCase := usecase.NewUserUseCase(mockProductRepo)
+	userUseCase := usecase.NewUserUseCase(mockProductRepo, nil)
 
 	testCases := map[string]struct {
 		stub func(m *mockrepository.MockUserRepository)
@@ -93,8 +102,8 @@ func Test_GetAllProducts(t *testing.T) {
 						Name:        "nike",
 						Quantity:    10,
 						Stock:       5,
-						Price:       3000,
-						OfferPrice:  2000,
+						Price:       money.Rupees(3000),
+						OfferPrice:  money.Rupees(2000),
 					},
 				}, nil)
 			},
@@ -105,8 +114,8 @@ func Test_GetAllProducts(t *testing.T) {
 					Name:        "nike",
 					Quantity:    10,
 					Stock:       5,
-					Price:       3000,
-					OfferPrice:  2000,
+					Price:       money.Rupees(3000),
+					OfferPrice:  money.Rupees(2000),
 				},
 			},
 			err: nil,
@@ -135,7 +144,7 @@ func Test_GetAllCategories(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockCategoryRepo := mockrepository.NewMockUserRepository(ctrl)
-	userUseCase := usecase.NewUserUseCase(mockCategoryRepo)
+	userUseCase := usecase.NewUserUseCase(mockCategoryRepo, nil)
 
 	testCases := map[string]struct {
 		stub func(m *mockrepository.MockUserRepository)
@@ -181,3 +190,75 @@ func Test_GetAllCategories(t *testing.T) {
 		})
 	}
 }
+
+func Test_DeliveryEstimate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	query := regexp.QuoteMeta("SELECT * FROM serviceable_pincodes WHERE pincode = $1")
+
+	testCases := map[string]struct {
+		pincode     string
+		stub        func(mock sqlmock.Sqlmock)
+		deliverable bool
+		err         error
+	}{
+		"deliverable": {
+			pincode: "682001",
+			stub: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"pincode", "deliverable", "cod_allowed", "eta_days"}).AddRow("682001", true, true, 3)
+				mock.ExpectQuery(query).WithArgs("682001").WillReturnRows(rows)
+			},
+			deliverable: true,
+			err:         nil,
+		},
+		"not deliverable": {
+			pincode: "194101",
+			stub: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"pincode", "deliverable", "cod_allowed", "eta_days"}).AddRow("194101", false, false, 0)
+				mock.ExpectQuery(query).WithArgs("194101").WillReturnRows(rows)
+			},
+			deliverable: false,
+			err:         nil,
+		},
+		"invalid pincode": {
+			pincode:     "68200A",
+			stub:        func(mock sqlmock.Sqlmock) {},
+			deliverable: false,
+			err:         shipping.ErrInvalidPincode,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockSQL, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer mockSQL.Close()
+
+			db, err := gorm.Open(postgres.New(postgres.Config{Conn: mockSQL}), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			assert.NoError(t, err)
+
+			cfg := config.Config{ShippingProvider: shipping.Fake}
+			provider, err := shipping.New(cfg)
+			assert.NoError(t, err)
+			shippingUseCase := usecase.NewShippingUseCase(*repository.NewShippingRepository(db), *repository.NewOrderRepository(db),
+				usecase.OrderStatusUseCase{}, provider, cfg)
+			userUseCase := usecase.NewUserUseCase(mockrepository.NewMockUserRepository(ctrl), shippingUseCase)
+
+			tc.stub(mock)
+			got, err := userUseCase.DeliveryEstimate(tc.pincode)
+
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.deliverable, got.Deliverable)
+			if tc.deliverable {
+				assert.Equal(t, tc.pincode, got.Pincode)
+				assert.False(t, got.From.IsZero())
+				assert.False(t, got.To.Before(got.From))
+			} else {
+				assert.True(t, got.From.IsZero())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -1,16 +1,84 @@
 package usecase
 
 import (
+	"ecommerce_clean_arch/pkg/config"
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/gateway"
+	"ecommerce_clean_arch/pkg/ledger"
+	"ecommerce_clean_arch/pkg/money"
 	"ecommerce_clean_arch/pkg/repository"
+	"ecommerce_clean_arch/pkg/shipping"
 	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTopUpMin      = 100
+	defaultTopUpMax      = 10000
+	defaultTopUpDailyCap = 20000
+	// defaultAdjustmentApprovalThreshold is in whole rupees.
+	defaultAdjustmentApprovalThreshold = 1000
+	// topUpPendingHold is how long a top-up awaiting payment counts against
+	// the daily cap, so the cap cannot be beaten by starting several at once
+	// while an abandoned one does not block the rest of the day.
+	topUpPendingHold = 30 * time.Minute
 )
 
+// TopUpLimits bound what a customer can add to their wallet.
+type TopUpLimits struct {
+	Min      money.Money
+	Max      money.Money
+	DailyCap money.Money
+}
+
+// Check reports why amount cannot be topped up by a customer who has
+// already topped up today so far today, if it cannot.
+func (l TopUpLimits) Check(amount, today money.Money) error {
+	if amount < l.Min {
+		return fmt.Errorf("the smallest top-up is Rs. %s", l.Min)
+	}
+	if amount > l.Max {
+		return fmt.Errorf("the largest top-up is Rs. %s", l.Max)
+	}
+	if today.Add(amount) > l.DailyCap {
+		return fmt.Errorf("top-ups are limited to Rs. %s a day; you can add up to Rs. %s more today",
+			l.DailyCap, l.DailyCap.Sub(today).NonNegative())
+	}
+	return nil
+}
+
 type WalletUseCase struct {
 	repository repository.WalletRepository
+	gateway    gateway.PaymentGateway
+	limits     TopUpLimits
+	approval   AdjustmentApproval
 }
 
-func NewWalletUseCase(repo repository.WalletRepository) *WalletUseCase {
-	return &WalletUseCase{repository: repo}
+func NewWalletUseCase(repo repository.WalletRepository, paymentGateway gateway.PaymentGateway, cfg config.Config) *WalletUseCase {
+	limits := TopUpLimits{
+		Min:      money.Rupees(int64(cfg.WalletTopUpMin)),
+		Max:      money.Rupees(int64(cfg.WalletTopUpMax)),
+		DailyCap: money.Rupees(int64(cfg.WalletTopUpDailyCap)),
+	}
+	if limits.Min <= 0 {
+		limits.Min = money.Rupees(defaultTopUpMin)
+	}
+	if limits.Max <= 0 {
+		limits.Max = money.Rupees(defaultTopUpMax)
+	}
+	if limits.DailyCap <= 0 {
+		limits.DailyCap = money.Rupees(defaultTopUpDailyCap)
+	}
+	approval := AdjustmentApproval{Threshold: money.Rupees(int64(cfg.WalletAdjustmentApprovalThreshold))}
+	if approval.Threshold <= 0 {
+		approval.Threshold = money.Rupees(defaultAdjustmentApprovalThreshold)
+	}
+	return &WalletUseCase{repository: repo, gateway: paymentGateway, limits: limits, approval: approval}
 }
 
 func (wal *WalletUseCase) GetUserWallet(userID int) (*models.UserWallet, error) {
@@ -28,3 +96,179 @@ func (wal *WalletUseCase) GetWalletTransaction(userID int) (*[]models.WalletTran
 	}
 	return transaction, nil
 }
+
+// TopUp starts adding amount to the customer's wallet and creates the
+// gateway order the checkout widget collects it through. The wallet row
+// is locked while the daily cap is checked, so concurrent top-ups cannot
+// both slip under it.
+func (wal *WalletUseCase) TopUp(userID int, amount money.Money) (models.WalletTopUpCheckout, error) {
+	tx, err := wal.repository.BeginTransaction()
+	if err != nil {
+		return models.WalletTopUpCheckout{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := wal.repository.LockWallet(tx, userID); err != nil {
+		return models.WalletTopUpCheckout{}, err
+	}
+	now := time.Now()
+	since := topUpDayStart(now)
+	pendingSince := now.Add(-topUpPendingHold)
+	if pendingSince.Before(since) {
+		pendingSince = since
+	}
+	today, err := wal.repository.GetTopUpTotal(tx, userID, since, pendingSince)
+	if err != nil {
+		return models.WalletTopUpCheckout{}, err
+	}
+	if err := wal.limits.Check(amount, today); err != nil {
+		return models.WalletTopUpCheckout{}, err
+	}
+
+	topUp, err := wal.repository.CreateTopUp(tx, domain.WalletTopUp{
+		UserID: userID,
+		Amount: amount,
+		Status: models.TopUpPending,
+	})
+	if err != nil {
+		return models.WalletTopUpCheckout{}, err
+	}
+	topUp.GatewayOrderID, err = wal.gateway.CreateOrder(amount.Paise(), "INR", "topup_"+strconv.Itoa(topUp.ID))
+	if err != nil {
+		return models.WalletTopUpCheckout{}, fmt.Errorf("failed to create payment for the top-up: %w", err)
+	}
+	if err := wal.repository.SaveTopUp(tx, topUp); err != nil {
+		return models.WalletTopUpCheckout{}, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return models.WalletTopUpCheckout{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return models.WalletTopUpCheckout{
+		TopUpID:        topUp.ID,
+		Amount:         topUp.Amount,
+		GatewayOrderID: topUp.GatewayOrderID,
+		Gateway:        wal.gateway.Name(),
+		KeyID:          wal.gateway.KeyID(),
+	}, nil
+}
+
+// VerifyTopUp checks the checkout widget's signature for a top-up and
+// credits the wallet.
+func (wal *WalletUseCase) VerifyTopUp(userID int, payment models.WalletTopUpPayment) (domain.WalletTopUp, error) {
+	tx, err := wal.repository.BeginTransaction()
+	if err != nil {
+		return domain.WalletTopUp{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	topUp, err := wal.repository.LockTopUp(tx, payment.TopUpID)
+	if err != nil {
+		return domain.WalletTopUp{}, err
+	}
+	if topUp.UserID != userID {
+		return domain.WalletTopUp{}, errors.New("top-up not found")
+	}
+	if topUp.Status == models.TopUpCredited {
+		return domain.WalletTopUp{}, errors.New("this top-up has already been credited")
+	}
+	if topUp.GatewayOrderID != payment.GatewayOrderID ||
+		!wal.gateway.VerifySignature(payment.GatewayOrderID, payment.PaymentID, payment.Signature) {
+		return domain.WalletTopUp{}, errors.New("payment is unsuccessful")
+	}
+
+	topUp, err = wal.creditTopUp(tx, topUp, payment.PaymentID)
+	if err != nil {
+		return domain.WalletTopUp{}, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return domain.WalletTopUp{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return topUp, nil
+}
+
+// CaptureTopUp credits the top-up a captured gateway payment was for, when
+// the customer closed the page before it was verified. It reports false
+// for payments that are not top-ups.
+func (wal *WalletUseCase) CaptureTopUp(gatewayOrderID, paymentID string) (bool, error) {
+	topUpID, err := wal.repository.GetTopUpIDByGatewayOrderID(gatewayOrderID)
+	if err != nil || topUpID == 0 {
+		return false, err
+	}
+
+	tx, err := wal.repository.BeginTransaction()
+	if err != nil {
+		return true, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	topUp, err := wal.repository.LockTopUp(tx, topUpID)
+	if err != nil {
+		return true, err
+	}
+	if topUp.Status == models.TopUpCredited {
+		return true, nil
+	}
+	if _, err := wal.creditTopUp(tx, topUp, paymentID); err != nil {
+		return true, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return true, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// FailTopUp records a failed attempt at paying a top-up, which then no
+// longer counts against the daily cap. It reports false for payments that
+// are not top-ups.
+func (wal *WalletUseCase) FailTopUp(gatewayOrderID string) (bool, error) {
+	topUpID, err := wal.repository.GetTopUpIDByGatewayOrderID(gatewayOrderID)
+	if err != nil || topUpID == 0 {
+		return false, err
+	}
+
+	tx, err := wal.repository.BeginTransaction()
+	if err != nil {
+		return true, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	topUp, err := wal.repository.LockTopUp(tx, topUpID)
+	if err != nil {
+		return true, err
+	}
+	if topUp.Status != models.TopUpPending {
+		return true, nil
+	}
+	topUp.Status = models.TopUpFailed
+	if err := wal.repository.SaveTopUp(tx, topUp); err != nil {
+		return true, err
+	}
+	return true, tx.Commit().Error
+}
+
+func (wal *WalletUseCase) creditTopUp(tx *gorm.DB, topUp domain.WalletTopUp, paymentID string) (domain.WalletTopUp, error) {
+	_, err := wal.repository.Credit(tx, topUp.UserID, topUp.Amount, ledger.AccountGateway, ledger.Source{
+		Type:        ledger.SourceTopUp,
+		ID:          topUp.ID,
+		Description: "wallet top-up",
+	})
+	if err != nil {
+		return domain.WalletTopUp{}, err
+	}
+	now := time.Now()
+	topUp.GatewayPaymentID = paymentID
+	topUp.Status = models.TopUpCredited
+	topUp.CreditedAt = &now
+	if err := wal.repository.SaveTopUp(tx, topUp); err != nil {
+		return domain.WalletTopUp{}, err
+	}
+	return topUp, nil
+}
+
+// topUpDayStart is midnight in India on the day of now; the daily cap
+// resets then.
+func topUpDayStart(now time.Time) time.Time {
+	local := now.In(shipping.IST)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, shipping.IST)
+}
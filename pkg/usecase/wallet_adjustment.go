@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AdjustmentApproval is the maker-checker rule for admin wallet
+// adjustments: one above Threshold is only applied once an admin other than
+// the one who made it approves it.
+type AdjustmentApproval struct {
+	Threshold money.Money
+}
+
+// Required reports whether an adjustment of amount needs approval.
+func (a AdjustmentApproval) Required(amount money.Money) bool {
+	return amount > a.Threshold
+}
+
+// CheckApprover reports why adminID cannot approve adjustment, if they
+// cannot.
+func (a AdjustmentApproval) CheckApprover(adjustment domain.WalletAdjustment, adminID int) error {
+	if adjustment.Status != models.AdjustmentPending {
+		return fmt.Errorf("adjustment is %s, not awaiting approval", adjustment.Status)
+	}
+	if adjustment.RequestedBy == adminID {
+		return errors.New("an adjustment must be approved by a different admin from the one who made it")
+	}
+	return nil
+}
+
+// RequestAdjustment records an admin's credit or debit to a customer's
+// wallet. It is applied straight away unless it is above the approval
+// threshold, in which case it waits for ApproveAdjustment.
+func (wal *WalletUseCase) RequestAdjustment(adminID int, req models.WalletAdjustmentRequest) (domain.WalletAdjustment, error) {
+	if req.Direction != models.AdjustmentCredit && req.Direction != models.AdjustmentDebit {
+		return domain.WalletAdjustment{}, fmt.Errorf("direction must be %s or %s", models.AdjustmentCredit, models.AdjustmentDebit)
+	}
+	if !req.Amount.IsPositive() {
+		return domain.WalletAdjustment{}, errors.New("amount must be greater than zero")
+	}
+	if !slices.Contains(models.WalletAdjustmentReasonCodes, req.ReasonCode) {
+		return domain.WalletAdjustment{}, fmt.Errorf("reason code must be one of %v", models.WalletAdjustmentReasonCodes)
+	}
+	if req.OrderID != nil {
+		orderUserID, err := wal.repository.GetOrderUserID(*req.OrderID)
+		if err != nil {
+			return domain.WalletAdjustment{}, err
+		}
+		if orderUserID != req.UserID {
+			return domain.WalletAdjustment{}, errors.New("the linked order was not placed by this user")
+		}
+	}
+
+	tx, err := wal.repository.BeginTransaction()
+	if err != nil {
+		return domain.WalletAdjustment{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	adjustment, err := wal.repository.CreateAdjustment(tx, domain.WalletAdjustment{
+		UserID:      req.UserID,
+		Direction:   req.Direction,
+		Amount:      req.Amount,
+		ReasonCode:  req.ReasonCode,
+		Note:        req.Note,
+		OrderID:     req.OrderID,
+		Status:      models.AdjustmentPending,
+		RequestedBy: adminID,
+	})
+	if err != nil {
+		return domain.WalletAdjustment{}, err
+	}
+	if !wal.approval.Required(adjustment.Amount) {
+		adjustment, err = wal.applyAdjustment(tx, adjustment, adminID)
+		if err != nil {
+			return domain.WalletAdjustment{}, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return domain.WalletAdjustment{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return adjustment, nil
+}
+
+// ApproveAdjustment applies a pending adjustment on behalf of adminID, who
+// must not be the admin who made it.
+func (wal *WalletUseCase) ApproveAdjustment(decision models.WalletAdjustmentDecision, adminID int) (domain.WalletAdjustment, error) {
+	tx, err := wal.repository.BeginTransaction()
+	if err != nil {
+		return domain.WalletAdjustment{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	adjustment, err := wal.repository.LockAdjustment(tx, decision.AdjustmentID)
+	if err != nil {
+		return domain.WalletAdjustment{}, err
+	}
+	if err := wal.approval.CheckApprover(adjustment, adminID); err != nil {
+		return domain.WalletAdjustment{}, err
+	}
+	now := time.Now()
+	adjustment.ReviewedBy = &adminID
+	adjustment.ReviewNote = decision.Note
+	adjustment.ReviewedAt = &now
+	adjustment, err = wal.applyAdjustment(tx, adjustment, adminID)
+	if err != nil {
+		return domain.WalletAdjustment{}, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return domain.WalletAdjustment{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return adjustment, nil
+}
+
+// RejectAdjustment turns down a pending adjustment. The admin who made it
+// can reject it too, to withdraw it.
+func (wal *WalletUseCase) RejectAdjustment(decision models.WalletAdjustmentDecision, adminID int) (domain.WalletAdjustment, error) {
+	tx, err := wal.repository.BeginTransaction()
+	if err != nil {
+		return domain.WalletAdjustment{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	adjustment, err := wal.repository.LockAdjustment(tx, decision.AdjustmentID)
+	if err != nil {
+		return domain.WalletAdjustment{}, err
+	}
+	if adjustment.Status != models.AdjustmentPending {
+		return domain.WalletAdjustment{}, fmt.Errorf("adjustment is %s, not awaiting approval", adjustment.Status)
+	}
+	now := time.Now()
+	adjustment.Status = models.AdjustmentRejected
+	adjustment.ReviewedBy = &adminID
+	adjustment.ReviewNote = decision.Note
+	adjustment.ReviewedAt = &now
+	if err := wal.repository.SaveAdjustment(tx, adjustment); err != nil {
+		return domain.WalletAdjustment{}, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return domain.WalletAdjustment{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return adjustment, nil
+}
+
+func (wal *WalletUseCase) GetAdjustments(status string, userID int) ([]domain.WalletAdjustment, error) {
+	return wal.repository.GetAdjustments(status, userID)
+}
+
+// applyAdjustment moves the money, recording adminID as the admin behind
+// the wallet transaction.
+func (wal *WalletUseCase) applyAdjustment(tx *gorm.DB, adjustment domain.WalletAdjustment, adminID int) (domain.WalletAdjustment, error) {
+	if _, err := wal.repository.Adjust(tx, adjustment, adminID); err != nil {
+		return domain.WalletAdjustment{}, err
+	}
+	now := time.Now()
+	adjustment.Status = models.AdjustmentApplied
+	adjustment.AppliedAt = &now
+	if err := wal.repository.SaveAdjustment(tx, adjustment); err != nil {
+		return domain.WalletAdjustment{}, err
+	}
+	return adjustment, nil
+}
@@ -0,0 +1,83 @@
+package usecase_test
+
+import (
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/money"
+	"ecommerce_clean_arch/pkg/usecase"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TopUpLimits(t *testing.T) {
+	limits := usecase.TopUpLimits{
+		Min:      money.Rupees(100),
+		Max:      money.Rupees(10000),
+		DailyCap: money.Rupees(20000),
+	}
+	testCases := map[string]struct {
+		amount    money.Money
+		today     money.Money
+		expectErr bool
+	}{
+		"within limits":           {amount: money.Rupees(500), today: money.Rupees(1000)},
+		"exactly the minimum":     {amount: money.Rupees(100)},
+		"below the minimum":       {amount: money.Paise(9999), expectErr: true},
+		"above the maximum":       {amount: money.Paise(1000001), expectErr: true},
+		"reaches the daily cap":   {amount: money.Rupees(10000), today: money.Rupees(10000)},
+		"goes over the daily cap": {amount: money.Rupees(5000), today: money.Paise(1500001), expectErr: true},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := limits.Check(tc.amount, tc.today)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_AdjustmentApproval(t *testing.T) {
+	approval := usecase.AdjustmentApproval{Threshold: money.Rupees(1000)}
+	assert.False(t, approval.Required(money.Rupees(1000)))
+	assert.True(t, approval.Required(money.Paise(100001)))
+
+	testCases := map[string]struct {
+		adjustment domain.WalletAdjustment
+		adminID    int
+		expectErr  bool
+	}{
+		"another admin approves": {
+			adjustment: domain.WalletAdjustment{Status: models.AdjustmentPending, RequestedBy: 1},
+			adminID:    2,
+		},
+		"maker cannot approve their own": {
+			adjustment: domain.WalletAdjustment{Status: models.AdjustmentPending, RequestedBy: 1},
+			adminID:    1,
+			expectErr:  true,
+		},
+		"already applied": {
+			adjustment: domain.WalletAdjustment{Status: models.AdjustmentApplied, RequestedBy: 1},
+			adminID:    2,
+			expectErr:  true,
+		},
+		"already rejected": {
+			adjustment: domain.WalletAdjustment{Status: models.AdjustmentRejected, RequestedBy: 1},
+			adminID:    2,
+			expectErr:  true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := approval.CheckApprover(tc.adjustment, tc.adminID)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"bytes"
+	"ecommerce_clean_arch/pkg/barcode"
+	"ecommerce_clean_arch/pkg/domain"
+	"ecommerce_clean_arch/pkg/utils/models"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// A 4x6 inch thermal label.
+const (
+	labelWidth  = 101.6
+	labelHeight = 152.4
+)
+
+// GeneratePackingSlip lists what goes in an order's parcel and where it
+// goes, without prices, so the slip can travel inside the box.
+func (ad *AdminUseCase) GeneratePackingSlip(orderID string) ([]byte, error) {
+	orderIDInt, err := strconv.Atoi(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID format: %w", err)
+	}
+
+	details, err := ad.adminrepository.GetShippingDetails(orderIDInt)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := ad.adminrepository.GetPackingLines(orderIDInt)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("order has nothing left to pack")
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 20)
+	pdf.CellFormat(0, 15, "Packing Slip", "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 12)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Order No: %s", details.OrderId), "", 1, "R", false, 0, "")
+	pdf.CellFormat(0, 6, fmt.Sprintf("Date: %s", time.Now().Format("02 Jan 2006")), "", 1, "R", false, 0, "")
+
+	pdf.Ln(5)
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(0, 10, "Ship To:", "", 1, "", false, 0, "")
+	pdf.SetFont("Arial", "", 12)
+	pdf.MultiCell(0, 7, shippingAddress(details), "", "L", false)
+
+	pdf.Ln(8)
+	pdf.SetFont("Arial", "B", 12)
+	pdf.SetFillColor(230, 230, 230)
+	pdf.CellFormat(10, 10, "#", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(70, 10, "Item", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(40, 10, "SKU", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(25, 10, "Size", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(25, 10, "Colour", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(20, 10, "Qty", "1", 1, "C", true, 0, "")
+
+	pdf.SetFont("Arial", "", 11)
+	var units int
+	for i, line := range lines {
+		units += line.Quantity
+		pdf.CellFormat(10, 10, strconv.Itoa(i+1), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(70, 10, line.ProductName, "1", 0, "", false, 0, "")
+		pdf.CellFormat(40, 10, line.SKU, "1", 0, "", false, 0, "")
+		pdf.CellFormat(25, 10, sizeLabel(line.Size, line.SizeSystem), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(25, 10, line.Colour, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(20, 10, strconv.Itoa(line.Quantity), "1", 1, "C", false, 0, "")
+	}
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(170, 10, "Total Units", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(20, 10, strconv.Itoa(units), "1", 1, "C", false, 0, "")
+
+	return outputPDF(pdf)
+}
+
+// GeneratePickList totals what has to be picked for every pending order,
+// grouped by product and then by size.
+func (ad *AdminUseCase) GeneratePickList() ([]byte, error) {
+	lines, err := ad.adminrepository.GetPickLines(models.Pending)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 20)
+	pdf.CellFormat(0, 15, "Pick List", "", 1, "C", false, 0, "")
+	pdf.SetFont("Arial", "", 12)
+	pdf.CellFormat(0, 6, fmt.Sprintf("Pending orders as of %s", time.Now().Format("02 Jan 2006 15:04")), "", 1, "C", false, 0, "")
+	pdf.Ln(8)
+
+	if len(lines) == 0 {
+		pdf.CellFormat(0, 10, "Nothing to pick.", "", 1, "C", false, 0, "")
+		return outputPDF(pdf)
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.SetFillColor(230, 230, 230)
+	pdf.CellFormat(50, 10, "SKU", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(35, 10, "Size", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(35, 10, "Colour", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(25, 10, "Orders", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(25, 10, "Qty", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(20, 10, "Picked", "1", 1, "C", true, 0, "")
+
+	var units int
+	for i, line := range lines {
+		if i == 0 || line.ProductID != lines[i-1].ProductID {
+			pdf.SetFont("Arial", "B", 11)
+			pdf.CellFormat(190, 9, line.ProductName, "1", 1, "", false, 0, "")
+		}
+		units += line.Quantity
+		pdf.SetFont("Arial", "", 11)
+		pdf.CellFormat(50, 9, line.SKU, "1", 0, "", false, 0, "")
+		pdf.CellFormat(35, 9, sizeLabel(line.Size, line.SizeSystem), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(35, 9, line.Colour, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(25, 9, strconv.Itoa(line.Orders), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(25, 9, strconv.Itoa(line.Quantity), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(20, 9, "", "1", 1, "C", false, 0, "")
+	}
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(145, 10, "Total Units", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(25, 10, strconv.Itoa(units), "1", 0, "C", false, 0, "")
+	pdf.CellFormat(20, 10, "", "1", 1, "C", false, 0, "")
+
+	return outputPDF(pdf)
+}
+
+// GenerateShippingLabel renders a 4x6 inch label with the delivery address
+// and a Code 128 barcode of the order id.
+func (ad *AdminUseCase) GenerateShippingLabel(orderID string) ([]byte, error) {
+	orderIDInt, err := strconv.Atoi(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order ID format: %w", err)
+	}
+
+	details, err := ad.adminrepository.GetShippingDetails(orderIDInt)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := barcode.Encode(details.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		UnitStr: "mm",
+		Size:    gofpdf.SizeType{Wd: labelWidth, Ht: labelHeight},
+	})
+	pdf.SetMargins(5, 5, 5)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(0, 5, "FROM: Sole-Spot", "", 1, "", false, 0, "")
+	pdf.Line(5, 12, labelWidth-5, 12)
+
+	pdf.SetY(15)
+	pdf.SetFont("Arial", "B", 14)
+	pdf.CellFormat(0, 7, "SHIP TO:", "", 1, "", false, 0, "")
+	pdf.SetFont("Arial", "", 13)
+	pdf.MultiCell(0, 6, shippingAddress(details), "", "L", false)
+
+	pdf.SetY(75)
+	pdf.SetFont("Arial", "B", 16)
+	if details.PaymentStatus == models.PaymentPaid {
+		pdf.CellFormat(0, 10, "PREPAID", "1", 1, "C", false, 0, "")
+	} else {
+		pdf.CellFormat(0, 10, fmt.Sprintf("COLLECT Rs. %s", details.FinalPrice.Sub(details.GiftCardAmount)), "1", 1, "C", false, 0, "")
+	}
+
+	// Ten modules of quiet zone on each side.
+	barWidth := labelWidth - 10
+	module := barWidth / float64(len(modules)+20)
+	if module > 0.6 {
+		module = 0.6
+	}
+	x := (labelWidth - module*float64(len(modules))) / 2
+	pdf.SetFillColor(0, 0, 0)
+	for i, bar := range modules {
+		if bar {
+			pdf.Rect(x+float64(i)*module, 100, module, 30, "F")
+		}
+	}
+
+	pdf.SetY(132)
+	pdf.SetFont("Arial", "B", 14)
+	pdf.CellFormat(0, 8, fmt.Sprintf("ORDER %s", details.OrderId), "", 1, "C", false, 0, "")
+
+	return outputPDF(pdf)
+}
+
+func shippingAddress(details models.CombinedOrderDetails) string {
+	return fmt.Sprintf("%s\n%s\n%s\n%s, %s\n%s - %s\nPhone: %s",
+		details.Name,
+		details.HouseName,
+		details.Street,
+		details.City,
+		details.District,
+		details.State,
+		details.Pin,
+		details.Phone,
+	)
+}
+
+func sizeLabel(size, sizeSystem string) string {
+	if size == "" {
+		return "-"
+	}
+	if sizeSystem == "" {
+		return size
+	}
+	return fmt.Sprintf("%s %s", sizeSystem, size)
+}
+
+func outputPDF(pdf *gofpdf.Fpdf) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// BookShipment books an order's parcel with the configured carrier.
+func (ad *AdminUseCase) BookShipment(orderID int) (domain.Shipment, error) {
+	return ad.OrderUseCase.ShippingUseCase.CreateShipment(orderID)
+}
+
+func (ad *AdminUseCase) GetShipmentTracking(orderID string) (domain.Tracking, error) {
+	orderIDInt, err := strconv.Atoi(orderID)
+	if err != nil {
+		return domain.Tracking{}, fmt.Errorf("invalid order ID format: %w", err)
+	}
+	return ad.OrderUseCase.ShippingUseCase.GetTracking(orderIDInt)
+}
+
+// UploadPincodes loads a serviceability CSV of pincode, deliverable,
+// cod_allowed and eta_days rows.
+func (ad *AdminUseCase) UploadPincodes(r io.Reader) (int, error) {
+	return ad.OrderUseCase.ShippingUseCase.UploadPincodes(r)
+}
+
+func (ad *AdminUseCase) GetPincodes(prefix string) ([]domain.ServiceablePincode, error) {
+	return ad.OrderUseCase.ShippingUseCase.GetPincodes(prefix)
+}
+
+func (ad *AdminUseCase) GetDeliveryRules() ([]domain.DeliveryRule, error) {
+	return ad.OrderUseCase.DeliveryRuleUseCase.GetRules()
+}
+
+func (ad *AdminUseCase) AddDeliveryRule(rule domain.DeliveryRule) (domain.DeliveryRule, error) {
+	return ad.OrderUseCase.DeliveryRuleUseCase.AddRule(rule)
+}
+
+func (ad *AdminUseCase) UpdateDeliveryRule(rule domain.DeliveryRule, ruleID int) (domain.DeliveryRule, error) {
+	return ad.OrderUseCase.DeliveryRuleUseCase.UpdateRule(rule, ruleID)
+}
+
+func (ad *AdminUseCase) DeleteDeliveryRule(ruleID int) error {
+	return ad.OrderUseCase.DeliveryRuleUseCase.DeleteRule(ruleID)
+}
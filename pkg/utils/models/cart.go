@@ -1,6 +1,7 @@
 package models
 
 import (
+	"ecommerce_clean_arch/pkg/money"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,15 +11,16 @@ type Cart struct {
 	ID               int            `json:"id"`
 	UserID           int            `json:"user_id"`
 	ProductID        int            `json:"product_id"`
+	VariantID        int            `json:"variant_id"`
 	Quantity         int            `json:"quantity"`
-	Price            int            `json:"price"`
-	OfferPrice       int            `json:"offer_price"`
-	CategoryDiscount float64        `json:"category_discount"`
-	TotalPrice       float64        `json:"total_price"`
+	Price            money.Money    `json:"price"`
+	OfferPrice       money.Money    `json:"offer_price"`
+	CategoryDiscount money.Money    `json:"category_discount"`
+	TotalPrice       money.Money    `json:"total_price"`
 	CreatedAt        time.Time      `json:"created_at"`
 	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 type CartResponse struct {
-	TotalPrice float64 `json:"total_price"`
-	Cart       []Cart  `json:"cart"`
+	TotalPrice money.Money `json:"total_price"`
+	Cart       []Cart      `json:"cart"`
 }
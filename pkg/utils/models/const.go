@@ -9,6 +9,132 @@ const (
 	Online    = "ONLINE"
 	COD       = "COD"
 	Wallet    = "WALLET"
+	Split     = "SPLIT"
+	GiftCard  = "GIFT_CARD"
 	Return    = "returned"
-	
+	Failed    = "failed"
+	// Exchanged marks a delivered order line that was swapped for another
+	// size; the replacement ships on an order of its own.
+	Exchanged = "exchanged"
 )
+
+// ClosedItemStatuses are the order item statuses that no longer move with
+// the order or count towards its stock and status.
+var ClosedItemStatuses = []string{Cancelled, Return, Exchanged}
+
+// Who moved an order to a new status, as written to its timeline.
+const (
+	ActorUser   = "user"
+	ActorAdmin  = "admin"
+	ActorSystem = "system"
+)
+
+const (
+	SizeUK = "UK"
+	SizeEU = "EU"
+	SizeUS = "US"
+)
+
+const (
+	ReservationHeld      = "held"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+)
+
+const (
+	PaymentNotPaid  = "not paid"
+	PaymentPaid     = "paid"
+	PaymentFailed   = "failed"
+	PaymentRefunded = "refunded"
+	// PaymentRefundInitiated means a refund to the original payment method
+	// was requested from the gateway and has not settled yet.
+	PaymentRefundInitiated = "refund initiated"
+)
+
+const (
+	PaymentMethodCOD    = 1
+	PaymentMethodOnline = 2
+	PaymentMethodWallet = 3
+	PaymentMethodSplit  = 4
+	// PaymentMethodGiftCard is an order paid in full by a gift card.
+	PaymentMethodGiftCard = 5
+)
+
+const (
+	RefundToWallet = "wallet"
+	RefundToSource = "source"
+	// RefundToGiftCard puts back what a gift card paid.
+	RefundToGiftCard = "gift_card"
+
+	RefundInitiated = "initiated"
+	RefundProcessed = "processed"
+	RefundFailed    = "failed"
+)
+
+const (
+	ExchangeRequested = "requested"
+	ExchangeApproved  = "approved"
+	ExchangeRejected  = "rejected"
+	// ExchangeCompleted means the old pair has been collected and restocked.
+	ExchangeCompleted = "completed"
+	// ExchangeExpired means the price difference was not paid in time and
+	// the held size went back on stock.
+	ExchangeExpired = "expired"
+)
+
+// Gift card statuses. A card is pending until it is paid for, and only an
+// active card can be redeemed.
+const (
+	GiftCardPending = "pending"
+	GiftCardActive  = "active"
+)
+
+// Wallet top-up statuses. A failed attempt leaves the gateway order open,
+// so a failed top-up can still be paid and credited.
+const (
+	TopUpPending  = "pending"
+	TopUpFailed   = "failed"
+	TopUpCredited = "credited"
+)
+
+// Admin wallet adjustments. An adjustment above the approval threshold is
+// pending until a second admin approves or rejects it; the rest are applied
+// as soon as they are made.
+const (
+	AdjustmentCredit = "credit"
+	AdjustmentDebit  = "debit"
+
+	AdjustmentPending  = "pending"
+	AdjustmentApplied  = "applied"
+	AdjustmentRejected = "rejected"
+)
+
+// WalletAdjustmentReasonCodes are the reasons an admin can give for
+// adjusting a wallet.
+var WalletAdjustmentReasonCodes = []string{"goodwill", "refund_reversal", "compensation", "correction"}
+
+// Return request statuses. A request is approved, the parcel picked up and
+// inspected before the refund goes out; it can be rejected at any step
+// before inspection passes.
+const (
+	ReturnRequested = "requested"
+	ReturnApproved  = "approved"
+	ReturnPickedUp  = "picked_up"
+	ReturnInspected = "inspected"
+	ReturnRefunded  = "refunded"
+	ReturnRejected  = "rejected"
+)
+
+// ReturnReasonCodes are the reasons a customer can give for a return.
+var ReturnReasonCodes = []string{"wrong_size", "damaged", "defective", "wrong_item", "not_as_described", "changed_mind"}
+
+// Document series. Invoices are numbered SS/2026-27/000123 and credit notes
+// SS/CN/2026-27/000012, each counting from 1 every financial year.
+const (
+	InvoiceSeries    = "SS"
+	CreditNoteSeries = "SS/CN"
+)
+
+// InvoiceableStatuses are the order statuses an invoice can first be issued
+// in. Once issued it can be downloaded whatever happens to the order.
+var InvoiceableStatuses = []string{Confirm, Shipped, Delivered}
@@ -0,0 +1,25 @@
+package models
+
+// ExchangeRequest asks for a delivered order line in another size.
+// SettleWith says how a price difference is paid or refunded, wallet or
+// source, and may be empty when the sizes cost the same.
+type ExchangeRequest struct {
+	OrderItemID int    `json:"order_item_id" binding:"required"`
+	VariantID   int    `json:"variant_id" binding:"required"`
+	Reason      string `json:"reason"`
+	SettleWith  string `json:"settle_with"`
+}
+
+// ExchangePayment is what the checkout widget returns once the customer has
+// paid the price difference of an exchange online.
+type ExchangePayment struct {
+	ExchangeID     int    `json:"exchange_id" binding:"required"`
+	GatewayOrderID string `json:"gateway_order_id" binding:"required"`
+	PaymentID      string `json:"payment_id" binding:"required"`
+	Signature      string `json:"signature" binding:"required"`
+}
+
+type ExchangeDecision struct {
+	ExchangeID int    `json:"exchange_id" binding:"required"`
+	Note       string `json:"note"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"time"
+)
+
+type GiftCardPurchase struct {
+	Amount         money.Money `json:"amount" binding:"required"`
+	RecipientName  string      `json:"recipient_name" binding:"required"`
+	RecipientEmail string      `json:"recipient_email" binding:"required,email"`
+	Message        string      `json:"message" binding:"max=500"`
+}
+
+// GiftCardCheckout is what the checkout widget needs to collect payment for
+// a gift card.
+type GiftCardCheckout struct {
+	GiftCardID     int         `json:"gift_card_id"`
+	Amount         money.Money `json:"amount"`
+	GatewayOrderID string      `json:"gateway_order_id"`
+	Gateway        string      `json:"gateway"`
+	KeyID          string      `json:"key_id"`
+}
+
+// GiftCardPayment is what the checkout widget returns once the customer has
+// paid for a gift card.
+type GiftCardPayment struct {
+	GiftCardID     int    `json:"gift_card_id" binding:"required"`
+	GatewayOrderID string `json:"gateway_order_id" binding:"required"`
+	PaymentID      string `json:"payment_id" binding:"required"`
+	Signature      string `json:"signature" binding:"required"`
+}
+
+type GiftCardCode struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// GiftCardBalance is what anyone holding a code can see of its card.
+type GiftCardBalance struct {
+	Last4     string      `json:"last4"`
+	Balance   money.Money `json:"balance"`
+	Status    string      `json:"status"`
+	Expired   bool        `json:"expired"`
+	ExpiresAt *time.Time  `json:"expires_at"`
+}
@@ -1,36 +1,55 @@
 package models
 
-import "time"
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"time"
+)
 
 type Order struct {
-	OrderId          int        `gorm:"primaryKey;autoIncrement" json:"order_id"`
-	UserID           int        `json:"user_id" gorm:"not null"`
-	AddressID        uint       `json:"address_id"`
-	Address          Address    `json:"-" gorm:"foreignkey:AddressID"`
-	CouponID         *uint      `json:"coupon_id,omitempty"`
-	CouponCode       string     `json:"coupon_code"`
-	RawTotal         float64    `json:"raw_total"`
-	Discount         float64    `json:"discount"`
-	DiscountAmount   float64    `json:"discount_amount"`
-	CategoryDiscount float64    `json:"category_discount"`
-	GrandTotal       float64    `json:"grand_total"`
-	DeliveryCharge   float64    `json:"delivery_charge"`
-	PaymentStatus    string     `json:"payment_status"`
-	PaymentMethodID  uint       `json:"paymentmethod_id"`
-	OrderDate        time.Time  `json:"order_date"`
-	CreatedAt        time.Time  `json:"created_at"`
-	UpdatedAt        time.Time  `json:"updated_at"`
-	DeletedAt        *time.Time `json:"deleted_at" gorm:"index"`
-	OrderStatus      string     `gorm:"column:order_status"`
-	FinalPrice       float64    `json:"final_price"`
-	PaymentMethod    string     `json:"-" gorm:"foreignkey:PaymentMethodID"`
+	OrderId          int         `gorm:"primaryKey;autoIncrement" json:"order_id"`
+	UserID           int         `json:"user_id" gorm:"not null"`
+	AddressID        uint        `json:"address_id"`
+	Address          Address     `json:"-" gorm:"foreignkey:AddressID"`
+	CouponID         *uint       `json:"coupon_id,omitempty"`
+	CouponCode       string      `json:"coupon_code"`
+	RawTotal         money.Money `json:"raw_total"`
+	Discount         float64     `json:"discount"`
+	DiscountAmount   money.Money `json:"discount_amount"`
+	CategoryDiscount money.Money `json:"category_discount"`
+	GrandTotal       money.Money `json:"grand_total"`
+	DeliveryCharge   money.Money `json:"delivery_charge"`
+	PaymentStatus    string      `json:"payment_status"`
+	PaymentMethodID  uint        `json:"paymentmethod_id"`
+	OrderDate        time.Time   `json:"order_date"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	DeletedAt        *time.Time  `json:"deleted_at" gorm:"index"`
+	OrderStatus      string      `gorm:"column:order_status"`
+	FinalPrice       money.Money `json:"final_price"`
+	PaymentMethod    string      `json:"-" gorm:"foreignkey:PaymentMethodID"`
+
+	// EstimatedDeliveryFrom and EstimatedDeliveryTo are the window the order
+	// should arrive in, estimated at placement and again once it ships.
+	EstimatedDeliveryFrom *time.Time `json:"estimated_delivery_from" gorm:"type:date"`
+	EstimatedDeliveryTo   *time.Time `json:"estimated_delivery_to" gorm:"type:date"`
+
+	// WalletAmount is the part of FinalPrice taken from the wallet on a
+	// split payment; the rest is paid online.
+	WalletAmount money.Money `json:"wallet_amount"`
+
+	// GiftCardCode is the code the customer typed at checkout; only the
+	// card's ID is stored. GiftCardAmount is the part of FinalPrice the card
+	// paid, which the payment method does not have to.
+	GiftCardCode   string      `json:"-" gorm:"-"`
+	GiftCardID     int         `json:"gift_card_id,omitempty"`
+	GiftCardAmount money.Money `json:"gift_card_amount"`
 }
 type OrderFromCart struct {
-	PaymentID uint    `json:"payment_id" binding:"required"`
-	AddressID uint    `json:"address_id" binding:"required"`
-	ProductID int     `json:"product_id"`
-	Quantity  int     `json:"quantity"`
-	Price     float64 `json:"price"`
+	PaymentID uint        `json:"payment_id" binding:"required"`
+	AddressID uint        `json:"address_id" binding:"required"`
+	ProductID int         `json:"product_id"`
+	Quantity  int         `json:"quantity"`
+	Price     money.Money `json:"price"`
 }
 
 type OrderIncoming struct {
@@ -40,9 +59,10 @@ type OrderIncoming struct {
 }
 
 type OrderProducts struct {
-	ProductID  int     `json:"product_id"`
-	Quantity   int     `json:"quantity"`
-	FinalPrice float64 `json:"total_price"`
+	ProductID  int         `json:"product_id"`
+	VariantID  int         `json:"variant_id"`
+	Quantity   int         `json:"quantity"`
+	FinalPrice money.Money `json:"total_price"`
 }
 
 type OrdersDetails struct {
@@ -53,29 +73,32 @@ type OrdersDetails struct {
 	OrderDate           time.Time
 	Items               []InvoiceItem
 	OrderStatus         string
-	CategoryDiscount    float64
-	GrandTotal          float64
-	RawAmount           float64
-	FinalPrice          float64
-	Discount            float64
-	DeliveryCharge      float64
+	CategoryDiscount    money.Money
+	GrandTotal          money.Money
+	RawAmount           money.Money
+	FinalPrice          money.Money
+	Discount            money.Money
+	DeliveryCharge      money.Money
 }
 
 type OrderProductDetails struct {
-	ProductID   uint    `json:"product_id"`
-	ProductName string  `json:"product_name"`
-	Quantity    int     `json:"quantity"`
-	TotalPrice  float64 `json:"total_price"`
+	OrderItemID int         `json:"order_item_id"`
+	ProductID   uint        `json:"product_id"`
+	ProductName string      `json:"product_name"`
+	VariantID   uint        `json:"variant_id,omitempty"`
+	Quantity    int         `json:"quantity"`
+	TotalPrice  money.Money `json:"total_price"`
+	Status      string      `json:"status"`
 }
 
 type OrderDetails struct {
-	OrderId          int     `json:"order_id"`
-	DiscountAmount   float64 `json:"discount_amount"`
-	CategoryDiscount float64 `json:"category_discount"`
-	GrandTotal       float64 `json:"grand_total"`
-	FinalPrice       float64 `json:"final_price"`
-	OrderStatus      string  `json:"order_status"`
-	PaymentStatus    string  `json:"payment_status"`
+	OrderId          int         `json:"order_id"`
+	DiscountAmount   money.Money `json:"discount_amount"`
+	CategoryDiscount money.Money `json:"category_discount"`
+	GrandTotal       money.Money `json:"grand_total"`
+	FinalPrice       money.Money `json:"final_price"`
+	OrderStatus      string      `json:"order_status"`
+	PaymentStatus    string      `json:"payment_status"`
 }
 
 type FullOrderDetails struct {
@@ -84,19 +107,24 @@ type FullOrderDetails struct {
 }
 
 type CombinedOrderDetails struct {
-	OrderId       string  `json:"order_id"`
-	FinalPrice    float64 `json:"final_price"`
-	OrderStatus   string  `json:"order_status"`
-	PaymentStatus string  `json:"payment_status"`
-	Name          string  `json:"first_name"`
-	Email         string  `json:"email"`
-	Phone         string  `json:"phone"`
-	HouseName     string  `json:"house_name" validate:"required"`
-	State         string  `json:"state" validate:"required"`
-	District      string  `json:"district" validate:"required"`
-	Pin           string  `json:"pin" validate:"required"`
-	Street        string  `json:"street"`
-	City          string  `json:"city"`
+	OrderId        string      `json:"order_id"`
+	FinalPrice     money.Money `json:"final_price"`
+	WalletAmount   money.Money `json:"wallet_amount"`
+	GiftCardAmount money.Money `json:"gift_card_amount"`
+	// AmountDue is what is left to collect through the gateway.
+	AmountDue       money.Money `json:"amount_due" gorm:"-"`
+	PaymentMethodID uint        `json:"payment_method_id"`
+	OrderStatus     string      `json:"order_status"`
+	PaymentStatus   string      `json:"payment_status"`
+	Name            string      `json:"first_name"`
+	Email           string      `json:"email"`
+	Phone           string      `json:"phone"`
+	HouseName       string      `json:"house_name" validate:"required"`
+	State           string      `json:"state" validate:"required"`
+	District        string      `json:"district" validate:"required"`
+	Pin             string      `json:"pin" validate:"required"`
+	Street          string      `json:"street"`
+	City            string      `json:"city"`
 }
 
 type OrderCount struct {
@@ -110,14 +138,72 @@ type OrderCount struct {
 }
 
 type AmountInformation struct {
-	TotalAmountBeforeDeduction float64 `json:"total_amount_before_deduction"`
-	TotalCouponDeduction       float64 `json:"total_coupon_deduction"`
-	TotalProuctOfferDeduction  float64 `json:"total_product_offer_deduction"`
-	TotalAmountAfterDeduction  float64 `json:"total_amount_after_deduction"`
+	TotalAmountBeforeDeduction money.Money `json:"total_amount_before_deduction"`
+	TotalCouponDeduction       money.Money `json:"total_coupon_deduction"`
+	TotalProuctOfferDeduction  money.Money `json:"total_product_offer_deduction"`
+	TotalAmountAfterDeduction  money.Money `json:"total_amount_after_deduction"`
 }
 
 type InvoiceItem struct {
-	Name     string  `json:"name"`
-	Quantity uint    `json:"quantity"`
-	Price    float64 `json:"price"`
+	Name         string      `json:"name"`
+	Variant      string      `json:"variant,omitempty"`
+	Quantity     uint        `json:"quantity"`
+	Price        money.Money `json:"price"`
+	HSNCode      string      `json:"hsn_code"`
+	GSTRate      float64     `json:"gst_rate"`
+	TaxableValue money.Money `json:"taxable_value"`
+	CGST         money.Money `json:"cgst"`
+	SGST         money.Money `json:"sgst"`
+	IGST         money.Money `json:"igst"`
+}
+
+// ProductTax is the GST treatment a product takes from its category.
+type ProductTax struct {
+	HSNCode       string
+	GSTRate       float64
+	GSTHigherRate float64
+	GSTThreshold  money.Money
+}
+
+// PackingLine is an order line as the warehouse sees it, without prices.
+type PackingLine struct {
+	OrderItemID int    `json:"order_item_id"`
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	SKU         string `json:"sku"`
+	Size        string `json:"size"`
+	SizeSystem  string `json:"size_system"`
+	Colour      string `json:"colour"`
+	Quantity    int    `json:"quantity"`
+}
+
+// PickLine is the quantity of one product and size to pick across orders.
+type PickLine struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	SKU         string `json:"sku"`
+	Size        string `json:"size"`
+	SizeSystem  string `json:"size_system"`
+	Colour      string `json:"colour"`
+	Quantity    int    `json:"quantity"`
+	Orders      int    `json:"orders"`
+}
+
+// ShipmentDetails is what a courier booking needs to know about an order.
+type ShipmentDetails struct {
+	OrderID         int
+	OrderStatus     string
+	PaymentStatus   string
+	PaymentMethodID int
+	FinalPrice      money.Money
+	GiftCardAmount  money.Money
+	Name            string
+	Phone           string
+	HouseName       string
+	Street          string
+	City            string
+	District        string
+	State           string
+	Pin             string
+	WeightGrams     int
 }
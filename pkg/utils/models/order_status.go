@@ -0,0 +1,8 @@
+package models
+
+// StatusChange says who is changing an order's status and why.
+type StatusChange struct {
+	Actor   string
+	ActorID int
+	Reason  string
+}
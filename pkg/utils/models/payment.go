@@ -1,20 +1,55 @@
 package models
 
+import "ecommerce_clean_arch/pkg/money"
+
 type PaymentMethod struct {
 	ID           uint   `gorm:"primarykey"`
 	Payment_Name string `json:"payment_name"`
 }
 
 type RazorPay struct {
-	ID        int    `json:"id" gorm:"primarykey not null"`
-	OrderID   string `json:"order_id"`
-	RazorID   string `json:"razor_id"`
-	PaymentID string `json:"payment_id"`
+	ID        int         `json:"id" gorm:"primarykey not null"`
+	OrderID   string      `json:"order_id"`
+	RazorID   string      `json:"razor_id"`
+	PaymentID string      `json:"payment_id"`
+	Amount    money.Money `json:"amount"`
 }
 
 type OnlinePaymentVerification struct {
 	PaymentID       string `json:"payment_id" validate:"required"`
 	OrderID         int    `json:"order_id" validate:"required"`
-	RazorPayOrderID string `json:"razorpay_order_id" validate:"required`
+	RazorPayOrderID string `json:"razorpay_order_id" validate:"required"`
 	Signature       string `json:"signature" validate:"required"`
 }
+
+const (
+	PaymentEventReceived  = "received"
+	PaymentEventProcessed = "processed"
+	PaymentEventIgnored   = "ignored"
+	PaymentEventFailed    = "failed"
+)
+
+// RazorpayWebhook is the subset of a Razorpay webhook body the server acts on.
+type RazorpayWebhook struct {
+	Event   string `json:"event"`
+	Payload struct {
+		Payment struct {
+			Entity struct {
+				ID               string `json:"id"`
+				OrderID          string `json:"order_id"`
+				Status           string `json:"status"`
+				Amount           int64  `json:"amount"`
+				AmountRefunded   int64  `json:"amount_refunded"`
+				ErrorDescription string `json:"error_description"`
+			} `json:"entity"`
+		} `json:"payment"`
+		Refund struct {
+			Entity struct {
+				ID        string `json:"id"`
+				PaymentID string `json:"payment_id"`
+				Amount    int64  `json:"amount"`
+				Status    string `json:"status"`
+			} `json:"entity"`
+		} `json:"refund"`
+	} `json:"payload"`
+}
@@ -1,42 +1,50 @@
 package models
 
 import (
+	"ecommerce_clean_arch/pkg/money"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type AddProduct struct {
-	ID         int     `gorm:"id"`
-	CategoryID int     `json:"category_id"`
-	Name       string  `json:"name"`
-	Stock      int     `json:"stock"`
-	Quantity   int     `json:"quantity"`
-	Price      float64 `json:"price"`
-	OfferPrice float64 `json:"offer_price"`
-	CreatedAt  time.Time
-	DeletedAt  gorm.DeletedAt
+	ID          int         `gorm:"id"`
+	CategoryID  int         `json:"category_id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Brand       string      `json:"brand"`
+	Stock       int         `json:"stock"`
+	Quantity    int         `json:"quantity"`
+	Price       money.Money `json:"price"`
+	OfferPrice  money.Money `json:"offer_price"`
+	CreatedAt   time.Time
+	DeletedAt   gorm.DeletedAt
 }
 type ProductResponse struct {
-	ID          int     `json:"id" `
-	Category_Id int     `json:"category_id"`
-	Name        string  `json:"name" `
-	Stock       int     `json:"stock"`
-	Quantity    int     `json:"quantity"`
-	Price       float64 `json:"price"`
-	OfferPrice  float64 `json:"offer_price"`
+	ID          int         `json:"id" `
+	Category_Id int         `json:"category_id"`
+	Name        string      `json:"name" `
+	Description string      `json:"description"`
+	Brand       string      `json:"brand"`
+	Stock       int         `json:"stock"`
+	Quantity    int         `json:"quantity"`
+	Price       money.Money `json:"price"`
+	OfferPrice  money.Money `json:"offer_price"`
 	CreatedAt   time.Time
 	DeletedAt   gorm.DeletedAt
+
+	// EstimatedDelivery is set when the listing is asked for a pincode.
+	EstimatedDelivery *DeliveryEstimate `json:"estimated_delivery,omitempty" gorm:"-"`
 }
 type SearchItems struct {
 	Name string `json:"name" binding:"required"`
 }
 type ProductDetails struct {
-	Name       string  `json:"name"`
-	TotalPrice float64 `json:"total_price"`
-	Price      float64 `json:"price" `
-	Total      float64 `json:"total"`
-	Quantity   int     `json:"quantity"`
+	Name       string      `json:"name"`
+	TotalPrice money.Money `json:"total_price"`
+	Price      money.Money `json:"price" `
+	Total      money.Money `json:"total"`
+	Quantity   int         `json:"quantity"`
 }
 
 type BestSellingProduct struct {
@@ -50,3 +58,76 @@ type BestSellingCategory struct {
 	CategoryName string `json:"category_name"`
 	TotalSold    int    `json:"total_sold"`
 }
+
+type AddVariant struct {
+	ProductID     int         `json:"product_id" validate:"required"`
+	SKU           string      `json:"sku" validate:"required"`
+	SizeSystem    string      `json:"size_system" validate:"required,oneof=UK EU US"`
+	Size          string      `json:"size" validate:"required"`
+	Colour        string      `json:"colour" validate:"required"`
+	Stock         int         `json:"stock"`
+	PriceOverride money.Money `json:"price_override"`
+	Images        []string    `json:"images"`
+}
+
+type VariantResponse struct {
+	ID            int         `json:"id"`
+	ProductID     int         `json:"product_id"`
+	SKU           string      `json:"sku"`
+	SizeSystem    string      `json:"size_system"`
+	Size          string      `json:"size"`
+	Colour        string      `json:"colour"`
+	Stock         int         `json:"stock"`
+	PriceOverride money.Money `json:"price_override"`
+	Images        []string    `json:"images" gorm:"-"`
+}
+
+type ProductSearchQuery struct {
+	Keyword     string   `form:"q"`
+	CategoryIDs []int    `form:"category_id"`
+	Brands      []string `form:"brand"`
+	Sizes       []string `form:"size"`
+	MinPrice    float64  `form:"min_price"`
+	MaxPrice    float64  `form:"max_price"`
+	MinRating   float64  `form:"min_rating"`
+	SortBy      string   `form:"sort_by"`
+	Page        int      `form:"page"`
+	Limit       int      `form:"limit"`
+}
+
+type ProductSearchResult struct {
+	ID          int         `json:"id"`
+	CategoryID  int         `json:"category_id"`
+	Category    string      `json:"category"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Brand       string      `json:"brand"`
+	Stock       int         `json:"stock"`
+	Price       money.Money `json:"price"`
+	OfferPrice  money.Money `json:"offer_price"`
+	Rating      float64     `json:"rating"`
+	ReviewCount int         `json:"review_count"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
+}
+
+type ProductFacets struct {
+	Categories  []FacetCount `json:"categories"`
+	Brands      []FacetCount `json:"brands"`
+	Sizes       []FacetCount `json:"sizes"`
+	PriceRanges []FacetCount `json:"price_ranges"`
+	Ratings     []FacetCount `json:"ratings"`
+}
+
+type ProductSearchResponse struct {
+	Products   []ProductSearchResult `json:"products"`
+	Facets     ProductFacets         `json:"facets"`
+	Total      int64                 `json:"total"`
+	Page       int                   `json:"page"`
+	Limit      int                   `json:"limit"`
+	TotalPages int                   `json:"total_pages"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "ecommerce_clean_arch/pkg/money"
+
+// RefundRequest describes money owed back on an order. OrderItemID is zero
+// when the whole order is refunded, in which case a zero Amount means the
+// order's final price. Destination is the customer's choice and may be empty
+// to use the default for the payment method.
+type RefundRequest struct {
+	OrderID     int
+	OrderItemID int
+	UserID      int
+	Amount      money.Money
+	Destination string
+	Reason      string
+}
+
+type OrderPaymentInfo struct {
+	UserID           int
+	PaymentMethodID  int
+	PaymentStatus    string
+	FinalPrice       money.Money
+	WalletAmount     money.Money
+	GiftCardID       int
+	GiftCardAmount   money.Money
+	GatewayPaymentID string
+}
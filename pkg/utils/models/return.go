@@ -0,0 +1,26 @@
+package models
+
+// ReturnRequest asks to send back a delivered order, or one line of it when
+// OrderItemID is set. ReasonCode is one of ReturnReasonCodes and PhotoURL
+// may point at a picture of the item.
+type ReturnRequest struct {
+	OrderID     int    `json:"order_id" binding:"required"`
+	OrderItemID int    `json:"order_item_id"`
+	ReasonCode  string `json:"reason_code" binding:"required"`
+	Comments    string `json:"comments"`
+	PhotoURL    string `json:"photo_url"`
+	RefundTo    string `json:"refund_to"`
+}
+
+type ReturnDecision struct {
+	ReturnID int    `json:"return_id" binding:"required"`
+	Note     string `json:"note"`
+}
+
+// ReturnInspection is the outcome of checking a returned parcel. A failed
+// inspection rejects the return.
+type ReturnInspection struct {
+	ReturnID int    `json:"return_id" binding:"required"`
+	Passed   *bool  `json:"passed" binding:"required"`
+	Note     string `json:"note"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type ShipmentBooking struct {
+	OrderID int `json:"order_id" binding:"required"`
+}
+
+// PincodeServiceability answers whether an order can be delivered to a
+// pincode and which payment methods are offered there.
+type PincodeServiceability struct {
+	Pincode        string   `json:"pincode"`
+	Deliverable    bool     `json:"deliverable"`
+	CODAllowed     bool     `json:"cod_allowed"`
+	ETADays        int      `json:"eta_days"`
+	PaymentMethods []string `json:"payment_methods"`
+}
+
+// PincodeUpload reports the result of a serviceability CSV upload.
+type PincodeUpload struct {
+	Rows int `json:"rows"`
+}
+
+// DeliveryEstimate is the window an order placed now would arrive in at a
+// pincode. From and To are zero when the pincode is not served.
+type DeliveryEstimate struct {
+	Pincode     string    `json:"pincode"`
+	Deliverable bool      `json:"deliverable"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"ecommerce_clean_arch/pkg/money"
 	"time"
 
 	"gorm.io/gorm"
@@ -106,6 +107,6 @@ type CheckoutDetails struct {
 	Address        []Address
 	Payment_Method []PaymentDetails
 	Cart           []Cart
-	Grand_Total    float64
-	Total_Price    float64
+	Grand_Total    money.Money
+	Total_Price    money.Money
 }
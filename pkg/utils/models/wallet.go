@@ -1,18 +1,88 @@
 package models
 
-import "time"
+import (
+	"ecommerce_clean_arch/pkg/money"
+	"time"
+)
 
 type UserWallet struct {
-	UserID   string `json:"userID"`
-	WalletID string `json:"walletID"`
-	Balance  uint   `json:"currentBalance" gorm:"column:balance"`
+	UserID   string      `json:"userID"`
+	WalletID string      `json:"walletID"`
+	Balance  money.Money `json:"currentBalance" gorm:"column:balance"`
 }
 
 type WalletTransaction struct {
-	TransactionID uint      `json:"transactionID"`
-	UserID        int       `json:"userID"`
-	Credit        uint      `json:"credit,omitempty"`
-	Debit         uint      `json:"debit,omitempty"`
-	EventDate     time.Time `json:"eventDate"`
-	TotalAmount   uint      `json:"totalAmount"`
+	TransactionID  uint        `json:"transactionID"`
+	UserID         int         `json:"userID"`
+	Credit         money.Money `json:"credit,omitempty"`
+	Debit          money.Money `json:"debit,omitempty"`
+	EventDate      time.Time   `json:"eventDate"`
+	TotalAmount    money.Money `json:"totalAmount"`
+	SourceType     string      `json:"sourceType,omitempty"`
+	SourceID       int         `json:"sourceID,omitempty"`
+	JournalEntryID int         `json:"journalEntryID,omitempty"`
+	Actor          string      `json:"actor,omitempty"`
+	ActorID        int         `json:"actorID,omitempty"`
+}
+
+// UnbalancedEntry is a journal entry the ledger check found broken.
+type UnbalancedEntry struct {
+	JournalEntryID int         `json:"journal_entry_id"`
+	SourceType     string      `json:"source_type"`
+	SourceID       int         `json:"source_id"`
+	Imbalance      money.Money `json:"imbalance"`
+	Postings       int         `json:"postings"`
+}
+
+// WalletDrift is a wallet whose cached balance disagrees with the ledger.
+type WalletDrift struct {
+	UserID        int         `json:"user_id"`
+	WalletBalance money.Money `json:"wallet_balance"`
+	LedgerBalance money.Money `json:"ledger_balance"`
+}
+
+// LedgerReport is the outcome of checking the ledger.
+type LedgerReport struct {
+	UnbalancedEntries []UnbalancedEntry `json:"unbalanced_entries"`
+	WalletDrift       []WalletDrift     `json:"wallet_drift"`
+	Repaired          int               `json:"repaired"`
+}
+
+type WalletTopUpRequest struct {
+	Amount money.Money `json:"amount" binding:"required"`
+}
+
+// WalletTopUpCheckout is what the checkout widget needs to collect a
+// top-up.
+type WalletTopUpCheckout struct {
+	TopUpID        int         `json:"top_up_id"`
+	Amount         money.Money `json:"amount"`
+	GatewayOrderID string      `json:"gateway_order_id"`
+	Gateway        string      `json:"gateway"`
+	KeyID          string      `json:"key_id"`
+}
+
+// WalletTopUpPayment is what the checkout widget returns once the customer
+// has paid for a top-up.
+type WalletTopUpPayment struct {
+	TopUpID        int    `json:"top_up_id" binding:"required"`
+	GatewayOrderID string `json:"gateway_order_id" binding:"required"`
+	PaymentID      string `json:"payment_id" binding:"required"`
+	Signature      string `json:"signature" binding:"required"`
+}
+
+// WalletAdjustmentRequest is an admin asking to credit or debit a
+// customer's wallet by hand. OrderID optionally links the order it is for.
+type WalletAdjustmentRequest struct {
+	UserID     int         `json:"user_id" binding:"required"`
+	Direction  string      `json:"direction" binding:"required"`
+	Amount     money.Money `json:"amount" binding:"required"`
+	ReasonCode string      `json:"reason_code" binding:"required"`
+	Note       string      `json:"note" binding:"required"`
+	OrderID    *int        `json:"order_id"`
+}
+
+type WalletAdjustmentDecision struct {
+	AdjustmentID int    `json:"adjustment_id" binding:"required"`
+	Note         string `json:"note"`
 }
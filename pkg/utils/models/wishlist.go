@@ -1,12 +1,14 @@
 package models
 
+import "ecommerce_clean_arch/pkg/money"
+
 type WishlistRequest struct {
 	UserID    int `json:"user_id"`
 	ProductID int `json:"product_id"`
 }
 
 type WishListResponse struct {
-	ProductID    uint    `json:"product_id"`
-	ProductName  string  `json:"product_name"`
-	ProductPrice float64 `json:"product_price"`
+	ProductID    uint        `json:"product_id"`
+	ProductName  string      `json:"product_name"`
+	ProductPrice money.Money `json:"product_price"`
 }
@@ -21,6 +21,13 @@ func GenerateOTP() string {
 }
 
 func SendOTPEmail(email, otp string) error {
+	log.Println("UserOTP", otp)
+	return SendEmail(email, "Your OTP Code", fmt.Sprintf("Your OTP code is %s", otp))
+}
+
+// SendEmail sends a plain text email through the SMTP server in the
+// environment.
+func SendEmail(email, subject, body string) error {
 	smtpHost := os.Getenv("SMTP_HOST")
 	smtpPort := os.Getenv("SMTP_PORT")
 	smtpUser := os.Getenv("SMTP_USERNAME")
@@ -34,10 +41,6 @@ func SendOTPEmail(email, otp string) error {
 
 	from := smtpUser
 	to := []string{email}
-	subject := "Your OTP Code"
-	body := fmt.Sprintf("Your OTP code is %s", otp)
-
-	log.Println("UserOTP", otp)
 
 	header := textproto.MIMEHeader{}
 	header.Set("From", from)